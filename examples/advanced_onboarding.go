@@ -0,0 +1,138 @@
+package examples
+
+import (
+	"onboarding-system/internal/types"
+)
+
+// CreateAdvancedOnboardingGraph creates an onboarding graph with several entry nodes, node rules that
+// limit paths by business type and activation rules for individual and company users
+func CreateAdvancedOnboardingGraph() *types.Graph {
+	graph := types.NewGraph("Advanced Onboarding", "Onboarding flow with multiple entry points and activation rules")
+
+	inputNode := func(id, name string, field types.Field) *types.Node {
+		field.Required = true
+		return &types.Node{
+			ID:          id,
+			Type:        types.NodeTypeInput,
+			Name:        name,
+			Description: "Provide your " + name,
+			Fields:      []types.Field{field},
+			Validation: types.ValidationRules{
+				RequiredFields: []string{field.ID},
+			},
+		}
+	}
+
+	nodes := []*types.Node{
+		{ID: "start", Type: types.NodeTypeStart, Name: "Start", Description: "Begin onboarding"},
+		inputNode("email", "Email", types.Field{ID: "email", Name: "email", Type: types.FieldTypeEmail}),
+		inputNode("phone", "Phone", types.Field{
+			ID:         "phone",
+			Name:       "phone",
+			Type:       types.FieldTypeText,
+			Validation: types.FieldValidation{Pattern: `^[6-9][0-9]{9}$`},
+		}),
+		inputNode("business_type", "Business Type", types.Field{
+			ID:      "business_type",
+			Name:    "business_type",
+			Type:    types.FieldTypeSelect,
+			Options: []string{"individual", "company"},
+		}),
+		inputNode("gst", "GST", types.Field{
+			ID:         "gst_number",
+			Name:       "gst_number",
+			Type:       types.FieldTypeText,
+			Validation: types.FieldValidation{Pattern: `^[0-9]{2}[A-Z]{5}[0-9]{4}[A-Z][1-9A-Z]Z[0-9A-Z]$`},
+		}),
+		inputNode("company_cert", "Company Certificate", types.Field{ID: "incorporation_certificate", Name: "incorporation_certificate", Type: types.FieldTypeFile}),
+		inputNode("pan", "Company PAN", types.Field{
+			ID:         "company_pan",
+			Name:       "company_pan",
+			Type:       types.FieldTypeText,
+			Validation: types.FieldValidation{Pattern: `^[A-Z]{5}[0-9]{4}[A-Z]$`},
+		}),
+		inputNode("user_pan", "User PAN", types.Field{
+			ID:         "pan_number",
+			Name:       "pan_number",
+			Type:       types.FieldTypeText,
+			Validation: types.FieldValidation{Pattern: `^[A-Z]{5}[0-9]{4}[A-Z]$`},
+		}),
+		inputNode("bank_account", "Bank Account", types.Field{ID: "account_number", Name: "account_number", Type: types.FieldTypeText}),
+		{ID: "activated", Type: types.NodeTypeEnd, Name: "Activated", Description: "Account activated"},
+	}
+	for _, node := range nodes {
+		graph.Nodes[node.ID] = node
+	}
+
+	always := types.EdgeCondition{Type: "always"}
+	edges := []*types.Edge{
+		{ID: "start_to_email", FromNodeID: "start", ToNodeID: "email", Condition: always},
+		{ID: "start_to_phone", FromNodeID: "start", ToNodeID: "phone", Condition: always},
+		{ID: "email_to_phone", FromNodeID: "email", ToNodeID: "phone", Condition: always},
+		{ID: "phone_to_business_type", FromNodeID: "phone", ToNodeID: "business_type", Condition: always},
+		{ID: "business_type_to_gst", FromNodeID: "business_type", ToNodeID: "gst", Condition: types.EdgeCondition{
+			Type:     "field_value",
+			Field:    "business_type",
+			Operator: "ne",
+			Value:    "individual",
+		}},
+		{ID: "business_type_to_user_pan", FromNodeID: "business_type", ToNodeID: "user_pan", Condition: types.EdgeCondition{
+			Type:     "field_value",
+			Field:    "business_type",
+			Operator: "eq",
+			Value:    "individual",
+		}},
+		{ID: "gst_to_company_cert", FromNodeID: "gst", ToNodeID: "company_cert", Condition: always},
+		{ID: "company_cert_to_pan", FromNodeID: "company_cert", ToNodeID: "pan", Condition: always},
+		{ID: "pan_to_user_pan", FromNodeID: "pan", ToNodeID: "user_pan", Condition: always},
+		{ID: "user_pan_to_bank_account", FromNodeID: "user_pan", ToNodeID: "bank_account", Condition: always},
+		{ID: "bank_account_to_activated", FromNodeID: "bank_account", ToNodeID: "activated", Condition: always},
+	}
+	for _, edge := range edges {
+		graph.Edges[edge.ID] = edge
+	}
+
+	graph.StartNodeID = "start"
+	graph.EntryNodes = []string{"email", "phone", "user_pan", "business_type", "gst", "company_cert"}
+
+	// Individuals never see the company documents
+	graph.NodeRules = []types.NodeRule{
+		{
+			ID:       "individual_path_limitation",
+			NodeID:   "business_type",
+			RuleType: "path_limitation",
+			Conditions: []types.ActivationCondition{
+				{Field: "business_type", Operator: "eq", Value: "individual", Required: true},
+			},
+			Actions: []types.RuleAction{
+				{Type: types.RuleActionExcludeNode, Target: "gst"},
+				{Type: types.RuleActionExcludeNode, Target: "company_cert"},
+				{Type: types.RuleActionDisableEdge, Target: "business_type_to_gst"},
+			},
+		},
+	}
+
+	graph.ActivationRules = []types.ActivationRule{
+		{
+			ID:            "individual_activation",
+			Name:          "Individual Activation",
+			Description:   "Individuals are activated once phone, PAN and bank account are verified",
+			RequiredNodes: []string{"phone", "user_pan", "bank_account"},
+			ExcludedNodes: []string{"gst", "company_cert"},
+			Conditions: []types.ActivationCondition{
+				{Field: "business_type", Operator: "eq", Value: "individual", Required: true},
+			},
+		},
+		{
+			ID:            "company_activation",
+			Name:          "Company Activation",
+			Description:   "Companies are activated once every business document is verified",
+			RequiredNodes: []string{"phone", "business_type", "gst", "company_cert", "pan", "user_pan", "bank_account"},
+			Conditions: []types.ActivationCondition{
+				{Field: "business_type", Operator: "eq", Value: "company", Required: true},
+			},
+		},
+	}
+
+	return graph
+}
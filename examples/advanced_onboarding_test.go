@@ -0,0 +1,170 @@
+package examples
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"onboarding-system/internal/config"
+	"onboarding-system/internal/onboarding"
+	"onboarding-system/internal/storage"
+	"onboarding-system/internal/types"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestAdvancedOnboarding(t *testing.T) {
+	logger := logrus.New()
+	logger.SetLevel(logrus.ErrorLevel) // Suppress verbose logs during tests
+
+	storage := storage.NewMemoryStorage(logger)
+	advancedService := onboarding.NewAdvancedService(storage, &config.Config{}, logger)
+	ctx := context.Background()
+
+	graph := CreateAdvancedOnboardingGraph()
+	if err := storage.SaveGraph(ctx, graph); err != nil {
+		t.Fatalf("Failed to save graph: %v", err)
+	}
+
+	t.Run("EntryNodes", func(t *testing.T) {
+		entryNodes, err := advancedService.GetEntryNodes(ctx, graph.ID)
+		if err != nil {
+			t.Fatalf("Failed to get entry nodes: %v", err)
+		}
+		if len(entryNodes) != 7 {
+			t.Fatalf("Expected 7 entry nodes, got %d", len(entryNodes))
+		}
+		if entryNodes[0].ID != "start" {
+			t.Errorf("Expected start node first, got %s", entryNodes[0].ID)
+		}
+
+		_, err = advancedService.StartAdvancedSession(ctx, "test-user", graph.ID, "bank_account")
+		if !errors.Is(err, onboarding.ErrEntryNodeNotAllowed) {
+			t.Errorf("Expected ErrEntryNodeNotAllowed, got %v", err)
+		}
+	})
+
+	t.Run("IndividualActivation", func(t *testing.T) {
+		session, err := advancedService.StartAdvancedSession(ctx, "individual-user", graph.ID, "phone")
+		if err != nil {
+			t.Fatalf("Failed to start advanced session: %v", err)
+		}
+		if session.CurrentNodeID != "phone" {
+			t.Errorf("Expected session to start at phone, got %s", session.CurrentNodeID)
+		}
+
+		steps := []struct {
+			data     map[string]interface{}
+			nextNode string
+		}{
+			{map[string]interface{}{"phone": "9876543210"}, "business_type"},
+			{map[string]interface{}{"business_type": "individual"}, "user_pan"},
+			{map[string]interface{}{"pan_number": "ABCDE1234F"}, "bank_account"},
+		}
+		for _, step := range steps {
+			result, err := advancedService.SubmitAdvancedNodeData(ctx, session.ID, step.data)
+			if err != nil {
+				t.Fatalf("Failed to submit %v: %v", step.data, err)
+			}
+			if result.NextNodeID != step.nextNode {
+				t.Fatalf("Expected next node %s, got %s", step.nextNode, result.NextNodeID)
+			}
+		}
+
+		// The business type rule excluded the company path
+		stored, err := storage.GetSession(ctx, session.ID)
+		if err != nil {
+			t.Fatalf("Failed to get session: %v", err)
+		}
+		if !containsString(stored.AdvancedState.ExcludedNodes, "gst") || !containsString(stored.AdvancedState.ExcludedNodes, "company_cert") {
+			t.Errorf("Expected gst and company_cert to be excluded, got %v", stored.AdvancedState.ExcludedNodes)
+		}
+		if !containsString(stored.AdvancedState.DisabledEdges, "business_type_to_gst") {
+			t.Errorf("Expected business_type_to_gst to be disabled, got %v", stored.AdvancedState.DisabledEdges)
+		}
+		if stored.AdvancedState.ActivationStatus != types.ActivationStatusPending {
+			t.Errorf("Expected activation to be pending, got %s", stored.AdvancedState.ActivationStatus)
+		}
+
+		result, err := advancedService.SubmitAdvancedNodeData(ctx, session.ID, map[string]interface{}{"account_number": "1234567890"})
+		if err != nil {
+			t.Fatalf("Failed to submit bank account: %v", err)
+		}
+		if result.Metadata["activation_status"] != types.ActivationStatusActivated {
+			t.Errorf("Expected activation, got %v", result.Metadata["activation_status"])
+		}
+
+		// Activation completes the session and is persisted with it
+		stored, err = storage.GetSession(ctx, session.ID)
+		if err != nil {
+			t.Fatalf("Failed to get session: %v", err)
+		}
+		if stored.Status != types.SessionStatusCompleted {
+			t.Errorf("Expected session to be completed, got %s", stored.Status)
+		}
+		if stored.AdvancedState.EntryNodeID != "phone" {
+			t.Errorf("Expected entry node phone, got %s", stored.AdvancedState.EntryNodeID)
+		}
+		if len(stored.AdvancedState.VisitedNodes) != 4 || len(stored.AdvancedState.PathHistory) != 4 {
+			t.Errorf("Expected 4 visited nodes and path steps, got %v and %d", stored.AdvancedState.VisitedNodes, len(stored.AdvancedState.PathHistory))
+		}
+
+		report, err := advancedService.CheckActivationStatus(ctx, session.ID)
+		if err != nil {
+			t.Fatalf("Failed to check activation status: %v", err)
+		}
+		if !report.Activation.CanActivate || !containsString(report.Activation.ActivatedRules, "individual_activation") {
+			t.Errorf("Expected individual_activation to be satisfied, got %+v", report.Activation)
+		}
+	})
+
+	t.Run("CompanyPending", func(t *testing.T) {
+		session, err := advancedService.StartAdvancedSession(ctx, "company-user", graph.ID, "gst")
+		if err != nil {
+			t.Fatalf("Failed to start advanced session: %v", err)
+		}
+
+		result, err := advancedService.SubmitAdvancedNodeData(ctx, session.ID, map[string]interface{}{
+			"business_type": "company",
+			"gst_number":    "27ABCDE1234F1Z5",
+		})
+		if err != nil {
+			t.Fatalf("Failed to submit GST: %v", err)
+		}
+		if result.NextNodeID != "company_cert" {
+			t.Errorf("Expected next node company_cert, got %s", result.NextNodeID)
+		}
+
+		report, err := advancedService.CheckActivationStatus(ctx, session.ID)
+		if err != nil {
+			t.Fatalf("Failed to check activation status: %v", err)
+		}
+		if report.ActivationStatus != types.ActivationStatusPending || report.Activation.CanActivate {
+			t.Errorf("Expected activation to be pending, got %s", report.ActivationStatus)
+		}
+		if len(report.Activation.MissingRequirements) == 0 {
+			t.Error("Expected missing requirements for company activation")
+		}
+	})
+
+	t.Run("RegularSession", func(t *testing.T) {
+		session, err := advancedService.StartSession(ctx, "regular-user", graph.ID)
+		if err != nil {
+			t.Fatalf("Failed to start session: %v", err)
+		}
+
+		_, err = advancedService.CheckActivationStatus(ctx, session.ID)
+		if !errors.Is(err, onboarding.ErrNotAdvancedSession) {
+			t.Errorf("Expected ErrNotAdvancedSession, got %v", err)
+		}
+	})
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
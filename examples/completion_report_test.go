@@ -0,0 +1,114 @@
+package examples
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"onboarding-system/internal/config"
+	"onboarding-system/internal/onboarding"
+	"onboarding-system/internal/storage"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestCompletionReport(t *testing.T) {
+	logger := logrus.New()
+	logger.SetLevel(logrus.ErrorLevel) // Suppress verbose logs during tests
+
+	storage := storage.NewMemoryStorage(logger)
+	service := onboarding.NewService(storage, &config.Config{})
+	ctx := context.Background()
+
+	graph := CreateProductionOnboardingGraph()
+	if err := storage.SaveGraph(ctx, graph); err != nil {
+		t.Fatalf("Failed to save graph: %v", err)
+	}
+
+	session, err := service.StartSession(ctx, "test-user", graph.ID)
+	if err != nil {
+		t.Fatalf("Failed to start session: %v", err)
+	}
+	session.Data = map[string]interface{}{
+		"business_type":   "individual",
+		"payment_channel": "website",
+		"business_name":   "Acme Traders",
+	}
+	if err := service.UpdateSession(ctx, session); err != nil {
+		t.Fatalf("Failed to update session: %v", err)
+	}
+
+	report, err := service.GetCompletionReport(ctx, session.ID)
+	if err != nil {
+		t.Fatalf("Failed to get completion report: %v", err)
+	}
+	if report.Complete {
+		t.Fatal("Expected session to be incomplete")
+	}
+	if len(report.RuleGroups) != 2 {
+		t.Fatalf("Expected 2 rule groups, got %d", len(report.RuleGroups))
+	}
+	// The basic path needs no MCC policy, so it is closest to completion
+	if report.ClosestRuleGroup != "basic_path" {
+		t.Errorf("Expected basic_path to be closest, got %s", report.ClosestRuleGroup)
+	}
+
+	basic := report.RuleGroups[0]
+	if basic.ID != "basic_path" || basic.Satisfied != 3 || basic.Total != 9 {
+		t.Errorf("Expected basic_path with 3 of 9 requirements, got %s with %d of %d", basic.ID, basic.Satisfied, basic.Total)
+	}
+	if len(basic.Nodes) != 3 || basic.Nodes[0].NodeID != graph.StartNodeID || !basic.Nodes[0].Satisfied {
+		t.Fatalf("Expected the business type node to be satisfied, got %+v", basic.Nodes)
+	}
+	if basic.Nodes[2].Satisfied || len(basic.Nodes[2].MissingFields) != 5 {
+		t.Errorf("Expected 5 business info fields to be missing, got %+v", basic.Nodes[2])
+	}
+
+	rules := make(map[string]onboarding.ConditionalRuleReport)
+	for _, rule := range basic.ConditionalRules {
+		rules[rule.RuleID] = rule
+	}
+	website := rules["website_url_required"]
+	if !website.Fired || website.Satisfied || website.Reason != "payment_channel eq website" {
+		t.Errorf("Expected website rule to fire because payment_channel eq website, got %+v", website)
+	}
+	app := rules["app_urls_required"]
+	if app.Fired || !app.Satisfied || app.Reason != "not payment_channel eq app" {
+		t.Errorf("Expected app rule not to fire, got %+v", app)
+	}
+
+	// Completing the session fails with the same report
+	err = service.CompleteSession(ctx, session)
+	var incompleteErr *onboarding.IncompleteSessionError
+	if !errors.As(err, &incompleteErr) {
+		t.Fatalf("Expected IncompleteSessionError, got %v", err)
+	}
+	if incompleteErr.Report == nil || incompleteErr.Report.ClosestRuleGroup != "basic_path" {
+		t.Errorf("Expected error to carry the completion report, got %+v", incompleteErr.Report)
+	}
+
+	for field, value := range map[string]interface{}{
+		"website_url":            "https://acme.example",
+		"brand_name":             "Acme",
+		"business_address_line1": "1 Main Road",
+		"business_city":          "Pune",
+		"business_state":         "Maharashtra",
+		"business_pincode":       "411001",
+	} {
+		session.Data[field] = value
+	}
+	if err := service.UpdateSession(ctx, session); err != nil {
+		t.Fatalf("Failed to update session: %v", err)
+	}
+
+	report, err = service.GetCompletionReport(ctx, session.ID)
+	if err != nil {
+		t.Fatalf("Failed to get completion report: %v", err)
+	}
+	if !report.Complete || !report.RuleGroups[0].Complete {
+		t.Errorf("Expected basic_path to complete the session, got %+v", report.RuleGroups[0].MissingRequirements)
+	}
+	if err := service.CompleteSession(ctx, session); err != nil {
+		t.Errorf("Failed to complete session: %v", err)
+	}
+}
@@ -1,9 +1,12 @@
 package examples
 
 import (
+	"context"
 	"testing"
 
+	"onboarding-system/internal/config"
 	"onboarding-system/internal/onboarding"
+	"onboarding-system/internal/storage"
 
 	"github.com/sirupsen/logrus"
 )
@@ -239,3 +242,133 @@ func BenchmarkNodeStatusUpdate(b *testing.B) {
 		dynamicGraph.UpdateNodeStatus(testNodeID, "completed", map[string]interface{}{})
 	}
 }
+
+func TestDependencyExpressions(t *testing.T) {
+	logger := logrus.New()
+	logger.SetLevel(logrus.ErrorLevel)
+
+	dynamicEngine := onboarding.NewDynamicEngine(logger)
+	dynamicGraph := dynamicEngine.ConvertToDynamicGraph(CreateEnhancedDynamicProductionOnboardingGraph(), "individual")
+	bmcDocument := dynamicGraph.DynamicNodes["bmc_document_node_id"]
+	if bmcDocument == nil || bmcDocument.Status != onboarding.NodeStatusDependent {
+		t.Fatal("Expected the BMC document node to start as dependent")
+	}
+
+	tests := []struct {
+		name   string
+		data   map[string]interface{}
+		status onboarding.NodeStatus
+		reason string
+	}{
+		{
+			name:   "regulated subcategory with code",
+			data:   map[string]interface{}{"subcategory": "finance", "payment_channel": "website"},
+			status: onboarding.NodeStatusMandatory,
+			reason: "subcategory in [finance, healthcare] and not payment_channel eq no_code",
+		},
+		{
+			name:   "regulated subcategory without code",
+			data:   map[string]interface{}{"subcategory": "healthcare", "payment_channel": "no_code"},
+			status: onboarding.NodeStatusDependent,
+			reason: "payment_channel eq no_code",
+		},
+		{
+			name:   "unregulated subcategory",
+			data:   map[string]interface{}{"subcategory": "retail", "payment_channel": "website"},
+			status: onboarding.NodeStatusDependent,
+			reason: "not subcategory in [finance, healthcare]",
+		},
+		{
+			name:   "nothing submitted",
+			data:   map[string]interface{}{},
+			status: onboarding.NodeStatusDependent,
+			reason: "subcategory is not set",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, reason := dynamicEngine.ExplainNodeDependencies(bmcDocument, tt.data)
+			if status != tt.status {
+				t.Errorf("Expected status %s, got %s", tt.status, status)
+			}
+			if reason != tt.reason {
+				t.Errorf("Expected reason %q, got %q", tt.reason, reason)
+			}
+		})
+	}
+
+	t.Run("field comparison", func(t *testing.T) {
+		node := &onboarding.DynamicNode{Dependencies: []onboarding.NodeDependency{
+			{FieldID: "business_type", Operator: "eq", Value: "llp"},
+			{Expression: &onboarding.DependencyExpression{Any: []onboarding.DependencyExpression{
+				{Field: "billing_state", Operator: "ne", ValueField: "registered_state"},
+				{Field: "gst_state", Operator: "ne", ValueField: "registered_state"},
+			}}},
+		}}
+
+		status, reason := dynamicEngine.ExplainNodeDependencies(node, map[string]interface{}{
+			"business_type":    "individual",
+			"billing_state":    "goa",
+			"registered_state": "goa",
+		})
+		if status != onboarding.NodeStatusDependent {
+			t.Errorf("Expected status dependent, got %s", status)
+		}
+		if expected := "not business_type eq llp and (not billing_state ne registered_state and gst_state is not set)"; reason != expected {
+			t.Errorf("Expected reason %q, got %q", expected, reason)
+		}
+
+		status, reason = dynamicEngine.ExplainNodeDependencies(node, map[string]interface{}{
+			"business_type":    "individual",
+			"billing_state":    "goa",
+			"registered_state": "kerala",
+		})
+		if status != onboarding.NodeStatusMandatory || reason != "billing_state ne registered_state" {
+			t.Errorf("Expected mandatory because billing_state ne registered_state, got %s because %s", status, reason)
+		}
+	})
+}
+
+func TestDependencyExpressionStatusPersistence(t *testing.T) {
+	logger := logrus.New()
+	logger.SetLevel(logrus.ErrorLevel)
+
+	storage := storage.NewMemoryStorage(logger)
+	dynamicService := onboarding.NewDynamicService(storage, &config.Config{}, logger)
+
+	graph := CreateEnhancedDynamicProductionOnboardingGraph()
+	if err := storage.SaveGraph(context.Background(), graph); err != nil {
+		t.Fatalf("Failed to save graph: %v", err)
+	}
+
+	ctx := context.Background()
+	session, err := dynamicService.StartDynamicSession(ctx, graph.ID, "expression-user")
+	if err != nil {
+		t.Fatalf("Failed to start dynamic session: %v", err)
+	}
+
+	// The dependency expression is persisted with the node status
+	saved := session.DynamicState.NodeStatuses["bmc_document_node_id"]
+	if len(saved.Dependencies) != 1 || saved.Dependencies[0].Expression == nil || len(saved.Dependencies[0].Expression.All) != 2 {
+		t.Fatalf("Expected the dependency expression to be persisted, got %+v", saved.Dependencies)
+	}
+
+	session.Data["subcategory"] = "finance"
+	session.Data["payment_channel"] = "app"
+	if err := dynamicService.SaveSession(ctx, session); err != nil {
+		t.Fatalf("Failed to save session: %v", err)
+	}
+
+	status, err := dynamicService.GetDynamicNodeStatus(ctx, session.ID)
+	if err != nil {
+		t.Fatalf("Failed to get node status: %v", err)
+	}
+	node := status["nodes"].(map[string]interface{})["bmc_document_node_id"].(map[string]interface{})
+	if node["status"] != string(onboarding.NodeStatusMandatory) {
+		t.Errorf("Expected the BMC document to be mandatory, got %v", node["status"])
+	}
+	if expected := "mandatory because subcategory in [finance, healthcare] and not payment_channel eq no_code"; node["explanation"] != expected {
+		t.Errorf("Expected explanation %q, got %v", expected, node["explanation"])
+	}
+}
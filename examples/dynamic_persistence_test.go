@@ -3,6 +3,7 @@ package examples
 import (
 	"context"
 	"fmt"
+	"sync"
 	"testing"
 
 	"onboarding-system/internal/config"
@@ -253,6 +254,89 @@ func TestDynamicPersistencePerformance(t *testing.T) {
 	}
 }
 
+func TestConcurrentDynamicSessionsAreIsolated(t *testing.T) {
+	logger := logrus.New()
+	logger.SetLevel(logrus.ErrorLevel)
+
+	storage := storage.NewMemoryStorage(logger)
+	config := &config.Config{}
+	dynamicService := onboarding.NewDynamicService(storage, config, logger)
+
+	graph := CreateProductionOnboardingGraph()
+	if err := storage.SaveGraph(context.Background(), graph); err != nil {
+		t.Fatalf("Failed to save graph: %v", err)
+	}
+
+	// runSession starts a session, switches it to a business type and submits the first node
+	runSession := func(userID, businessType string) (map[string]string, error) {
+		ctx := context.Background()
+		session, err := dynamicService.StartDynamicSession(ctx, graph.ID, userID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to start session: %w", err)
+		}
+		if err := dynamicService.UpdateBusinessTypeDynamic(ctx, session.ID, businessType); err != nil {
+			return nil, fmt.Errorf("failed to update business type: %w", err)
+		}
+		data := map[string]interface{}{
+			"business_type": businessType,
+			"pan_number":    "ABCDE1234F",
+		}
+		if _, err := dynamicService.SubmitNodeDataDynamic(ctx, session.ID, data); err != nil {
+			return nil, fmt.Errorf("failed to submit node data: %w", err)
+		}
+
+		status, err := dynamicService.GetDynamicNodeStatus(ctx, session.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get node status: %w", err)
+		}
+		if status["business_type"] != businessType {
+			return nil, fmt.Errorf("expected business type %s, got %v", businessType, status["business_type"])
+		}
+
+		statuses := make(map[string]string)
+		for nodeID, node := range status["nodes"].(map[string]interface{}) {
+			statuses[nodeID] = node.(map[string]interface{})["status"].(string)
+		}
+		return statuses, nil
+	}
+
+	businessTypes := []string{"individual", "proprietorship", "private_limited", "partnership", "trust"}
+
+	// Statuses of a session that runs on its own
+	expected := make(map[string]map[string]string)
+	for _, businessType := range businessTypes {
+		statuses, err := runSession("reference-"+businessType, businessType)
+		if err != nil {
+			t.Fatalf("Reference session for %s: %v", businessType, err)
+		}
+		expected[businessType] = statuses
+	}
+	if fmt.Sprint(expected["individual"]) == fmt.Sprint(expected["private_limited"]) {
+		t.Fatal("Expected business types to lead to different node statuses")
+	}
+
+	sessionsPerType := 10
+	var wg sync.WaitGroup
+	for _, businessType := range businessTypes {
+		for i := 0; i < sessionsPerType; i++ {
+			wg.Add(1)
+			go func(businessType string, i int) {
+				defer wg.Done()
+
+				statuses, err := runSession(fmt.Sprintf("%s-user-%d", businessType, i), businessType)
+				if err != nil {
+					t.Errorf("Session %d for %s: %v", i, businessType, err)
+					return
+				}
+				if fmt.Sprint(statuses) != fmt.Sprint(expected[businessType]) {
+					t.Errorf("Session %d for %s: expected statuses %v, got %v", i, businessType, expected[businessType], statuses)
+				}
+			}(businessType, i)
+		}
+	}
+	wg.Wait()
+}
+
 func BenchmarkDynamicPersistence(b *testing.B) {
 	logger := logrus.New()
 	logger.SetLevel(logrus.ErrorLevel)
@@ -233,7 +233,7 @@ func CreateDynamicProductionOnboardingGraph() *types.Graph {
 				{
 					Field:    "business_type",
 					Operator: "in",
-					Value:    "private_limited,public_limited",
+					Value:    []string{"private_limited", "public_limited"},
 					Rule:     "cin_document and certificate_of_incorporation are required for limited companies",
 				},
 				{
@@ -735,5 +735,8 @@ func CreateDynamicProductionOnboardingGraph() *types.Graph {
 	// Set the start node
 	graph.StartNodeID = startNode.ID
 
+	// Add completion paths
+	graph.RuleGroups = buildProductionRuleGroups(startNode.ID, paymentChannelNode.ID, mccPolicyNode.ID, businessInfoNode.ID)
+
 	return graph
 }
@@ -335,7 +335,7 @@ func CreateEnhancedDynamicProductionOnboardingGraph() *types.Graph {
 				{
 					Field:    "business_type",
 					Operator: "in",
-					Value:    "private_limited,public_limited",
+					Value:    []interface{}{"private_limited", "public_limited"},
 					Rule:     "cin_document and certificate_of_incorporation are required for limited companies",
 				},
 				{
@@ -405,11 +405,13 @@ func CreateEnhancedDynamicProductionOnboardingGraph() *types.Graph {
 		IsDependent:   true,
 		Dependencies: []types.NodeDependency{
 			{
-				FieldID:      "subcategory",
-				Operator:     "ne",
-				Value:        "",
-				Condition:    "Subcategory must be selected",
-				BusinessType: "",
+				Condition: "Regulated subcategories need a BMC document unless onboarding without code",
+				Expression: &types.DependencyExpression{
+					All: []types.DependencyExpression{
+						{Field: "subcategory", Operator: "in", Value: []interface{}{"finance", "healthcare"}},
+						{Not: &types.DependencyExpression{Field: "payment_channel", Operator: "eq", Value: "no_code"}},
+					},
+				},
 			},
 		},
 		IncomingEdges: []string{},
@@ -586,6 +588,9 @@ func CreateEnhancedDynamicProductionOnboardingGraph() *types.Graph {
 	// Add cross-node validation rules
 	graph.CrossNodeValidation = buildCrossNodeValidationRules()
 
+	// Add completion paths
+	graph.RuleGroups = buildProductionRuleGroups(startNode.ID, paymentChannelNode.ID, mccPolicyNode.ID, businessInfoNode.ID)
+
 	return graph
 }
 
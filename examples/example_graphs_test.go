@@ -0,0 +1,45 @@
+package examples
+
+import (
+	"context"
+	"testing"
+
+	"onboarding-system/internal/config"
+	"onboarding-system/internal/graphlint"
+	"onboarding-system/internal/onboarding"
+	"onboarding-system/internal/storage"
+	"onboarding-system/internal/types"
+
+	"github.com/sirupsen/logrus"
+)
+
+// exampleGraphs lists every graph the server seeds or the examples ship
+var exampleGraphs = map[string]func() *types.Graph{
+	"production":                  CreateProductionOnboardingGraph,
+	"dynamic_production":          CreateDynamicProductionOnboardingGraph,
+	"enhanced_dynamic_production": CreateEnhancedDynamicProductionOnboardingGraph,
+	"unified":                     CreateUnifiedOnboardingGraph,
+	"advanced":                    CreateAdvancedOnboardingGraph,
+}
+
+func TestExampleGraphsLint(t *testing.T) {
+	logger := logrus.New()
+	logger.SetLevel(logrus.ErrorLevel) // Suppress verbose logs during tests
+
+	for name, create := range exampleGraphs {
+		t.Run(name, func(t *testing.T) {
+			report := graphlint.Lint(create())
+			for _, diagnostic := range report.Diagnostics {
+				if diagnostic.Severity == graphlint.SeverityError {
+					t.Errorf("%s: %s", diagnostic.Code, diagnostic.Message)
+				}
+			}
+
+			// Seeding goes through CreateGraph, which rejects graphs with lint errors
+			service := onboarding.NewService(storage.NewMemoryStorage(logger), &config.Config{})
+			if err := service.CreateGraph(context.Background(), create()); err != nil {
+				t.Errorf("Failed to create graph: %v", err)
+			}
+		})
+	}
+}
@@ -510,6 +510,9 @@ func CreateProductionOnboardingGraph() *types.Graph {
 	// Set start node
 	graph.StartNodeID = startNode.ID
 
+	// Add completion paths
+	graph.RuleGroups = buildProductionRuleGroups(startNode.ID, paymentChannelNode.ID, mccPolicyNode.ID, businessInfoNode.ID)
+
 	return graph
 }
 
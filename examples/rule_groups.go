@@ -0,0 +1,74 @@
+package examples
+
+import (
+	"onboarding-system/internal/types"
+)
+
+// productionBusinessTypes lists the business types covered by the production rule groups
+var productionBusinessTypes = []string{
+	"individual", "proprietorship", "private_limited", "public_limited",
+	"partnership", "llp", "trust", "society", "huf",
+}
+
+// buildProductionRuleGroups creates the completion paths shared by the production graphs
+func buildProductionRuleGroups(businessTypeNodeID, paymentChannelNodeID, mccPolicyNodeID, businessInfoNodeID string) []types.RuleGroup {
+	businessInfoFields := []string{"business_name", "brand_name", "business_address_line1", "business_city", "business_state", "business_pincode"}
+
+	conditionalFields := map[string]types.ConditionalFieldRule{
+		"website_url_required": {
+			NodeID:      paymentChannelNodeID,
+			FieldID:     "website_url",
+			Condition:   "payment_channel",
+			Operator:    "eq",
+			Value:       "website",
+			Description: "Website URL is required when payment channel is website",
+		},
+		"app_urls_required": {
+			NodeID:      paymentChannelNodeID,
+			FieldID:     "android_url",
+			Condition:   "payment_channel",
+			Operator:    "eq",
+			Value:       "app",
+			Description: "Android and iOS URLs are required when payment channel is app",
+		},
+	}
+
+	return []types.RuleGroup{
+		{
+			ID:            "basic_path",
+			Name:          "Basic Path",
+			Description:   "Basic onboarding with essential fields only",
+			BusinessTypes: productionBusinessTypes,
+			RequiredNodes: []string{
+				businessTypeNodeID,
+				paymentChannelNodeID,
+				businessInfoNodeID,
+			},
+			RequiredFields: map[string][]string{
+				businessTypeNodeID:   {"business_type"},
+				paymentChannelNodeID: {"payment_channel"},
+				businessInfoNodeID:   businessInfoFields,
+			},
+			ConditionalFields: conditionalFields,
+		},
+		{
+			ID:            "complete_path",
+			Name:          "Complete Path",
+			Description:   "Complete onboarding with all fields including documents",
+			BusinessTypes: productionBusinessTypes,
+			RequiredNodes: []string{
+				businessTypeNodeID,
+				paymentChannelNodeID,
+				mccPolicyNodeID,
+				businessInfoNodeID,
+			},
+			RequiredFields: map[string][]string{
+				businessTypeNodeID:   {"business_type"},
+				paymentChannelNodeID: {"payment_channel"},
+				mccPolicyNodeID:      {"subcategory"},
+				businessInfoNodeID:   businessInfoFields,
+			},
+			ConditionalFields: conditionalFields,
+		},
+	}
+}
@@ -105,7 +105,7 @@ func CreateUnifiedOnboardingGraph() *types.Graph {
 			Type:     types.FieldTypeText,
 			Required: false, // Only required for certain business types
 			Validation: types.FieldValidation{
-				Pattern:     `^[A-Z]{2}[0-9]{2}[A-Z]{2}[0-9]{4}$`,
+				Pattern:     `^[LU][0-9]{5}[A-Z]{2}[0-9]{4}[A-Z]{3}[0-9]{6}$`,
 				CustomRules: []string{"cin_validation"},
 			},
 		},
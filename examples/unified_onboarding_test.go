@@ -0,0 +1,70 @@
+package examples
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"onboarding-system/internal/config"
+	"onboarding-system/internal/onboarding"
+	"onboarding-system/internal/storage"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestUnifiedOnboardingCompletion(t *testing.T) {
+	logger := logrus.New()
+	logger.SetLevel(logrus.ErrorLevel) // Suppress verbose logs during tests
+
+	storage := storage.NewMemoryStorage(logger)
+	service := onboarding.NewService(storage, &config.Config{})
+	ctx := context.Background()
+
+	// The unified graph defines no rule groups, so a company's business type must not make it
+	// depend on them
+	graph := CreateUnifiedOnboardingGraph()
+	if err := service.CreateGraph(ctx, graph); err != nil {
+		t.Fatalf("Failed to create unified graph: %v", err)
+	}
+
+	session, err := service.StartSession(ctx, "company-user", graph.ID)
+	if err != nil {
+		t.Fatalf("Failed to start session: %v", err)
+	}
+	session.Data = map[string]interface{}{
+		"user_type":     "company",
+		"business_type": "private_limited",
+	}
+	if err := service.UpdateSession(ctx, session); err != nil {
+		t.Fatalf("Failed to update session: %v", err)
+	}
+
+	err = service.CompleteSession(ctx, session)
+	var incompleteErr *onboarding.IncompleteSessionError
+	if !errors.As(err, &incompleteErr) || len(incompleteErr.MissingNodes) == 0 {
+		t.Fatalf("Expected the company steps to be missing, got %v", err)
+	}
+
+	// Fill every required field of the steps a company needs
+	companySteps := map[string]bool{
+		"Business Type": true, "Company Information": true, "Contact Information": true, "Identity Documents": true,
+		"Tax Information": true, "Bank Details": true, "Document Upload": true,
+	}
+	for _, node := range graph.Nodes {
+		if !companySteps[node.Name] {
+			continue
+		}
+		for _, field := range node.Fields {
+			if _, filled := session.Data[field.ID]; field.Required && !filled {
+				session.Data[field.ID] = "provided"
+			}
+		}
+	}
+	if err := service.UpdateSession(ctx, session); err != nil {
+		t.Fatalf("Failed to update session: %v", err)
+	}
+
+	if err := service.CompleteSession(ctx, session); err != nil {
+		t.Errorf("Expected the unified session to complete, got %v", err)
+	}
+}
@@ -0,0 +1,157 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"onboarding-system/internal/onboarding"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+// AdvancedHandlers handles entry node and activation API endpoints
+type AdvancedHandlers struct {
+	advancedService *onboarding.AdvancedService
+	logger          *logrus.Logger
+}
+
+// NewAdvancedHandlers creates a new advanced handlers instance
+func NewAdvancedHandlers(advancedService *onboarding.AdvancedService, logger *logrus.Logger) *AdvancedHandlers {
+	return &AdvancedHandlers{
+		advancedService: advancedService,
+		logger:          logger,
+	}
+}
+
+// GetEntryNodes returns the nodes a new session of a graph may start from
+func (ah *AdvancedHandlers) GetEntryNodes(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	graphID := vars["id"]
+
+	entryNodes, err := ah.advancedService.GetEntryNodes(r.Context(), graphID)
+	if err != nil {
+		ah.logger.WithError(err).WithField("graph_id", graphID).Error("Failed to get entry nodes")
+		http.Error(w, "Graph not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"graph_id":    graphID,
+		"entry_nodes": entryNodes,
+	})
+}
+
+// StartAdvancedSession starts a new session at one of the graph's entry nodes
+func (ah *AdvancedHandlers) StartAdvancedSession(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		GraphID     string `json:"graph_id"`
+		UserID      string `json:"user_id"`
+		EntryNodeID string `json:"entry_node_id"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		ah.logger.WithError(err).Error("Failed to decode start advanced session request")
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if req.GraphID == "" || req.UserID == "" {
+		http.Error(w, "graph_id and user_id are required", http.StatusBadRequest)
+		return
+	}
+
+	session, err := ah.advancedService.StartAdvancedSession(r.Context(), req.UserID, req.GraphID, req.EntryNodeID)
+	if err != nil {
+		if errors.Is(err, onboarding.ErrEntryNodeNotAllowed) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		ah.logger.WithError(err).Error("Failed to start advanced session")
+		http.Error(w, "Failed to start session", http.StatusInternalServerError)
+		return
+	}
+
+	setSessionETag(w, session.Revision)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(session)
+}
+
+// SubmitAdvancedNodeData submits node data, applies node rules and checks activation
+func (ah *AdvancedHandlers) SubmitAdvancedNodeData(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	sessionID := vars["id"]
+
+	var data map[string]interface{}
+	if err := json.NewDecoder(r.Body).Decode(&data); err != nil {
+		ah.logger.WithError(err).Error("Failed to decode submit node data request")
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	ctx, err := ifMatchContext(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	result, err := ah.advancedService.SubmitAdvancedNodeData(ctx, sessionID, data)
+	if err != nil {
+		if writeSessionConflict(w, err) || writeSessionStateError(w, err) || writeNodeValidationError(w, err) {
+			return
+		}
+		ah.logger.WithError(err).Error("Failed to submit node data")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	setSessionETag(w, result.SessionRevision)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// GetActivationStatus returns a session's activation status and the rules it still has to meet
+func (ah *AdvancedHandlers) GetActivationStatus(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	sessionID := vars["id"]
+
+	report, err := ah.advancedService.CheckActivationStatus(r.Context(), sessionID)
+	if err != nil {
+		if errors.Is(err, onboarding.ErrNotAdvancedSession) {
+			http.Error(w, "Session was not started from an entry node and has no activation status", http.StatusBadRequest)
+			return
+		}
+		ah.logger.WithError(err).WithField("session_id", sessionID).Error("Failed to check activation status")
+		http.Error(w, "Session not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
+// RegisterAdvancedRoutes registers entry node and activation API routes
+func (ah *AdvancedHandlers) RegisterAdvancedRoutes(router *mux.Router) {
+	api := router.PathPrefix("/api/v1/advanced").Subrouter()
+
+	api.HandleFunc("/graphs/{id}/entry-nodes", ah.GetEntryNodes).Methods("GET")
+	api.HandleFunc("/sessions", ah.StartAdvancedSession).Methods("POST")
+	api.HandleFunc("/sessions/{id}/submit", ah.SubmitAdvancedNodeData).Methods("POST")
+	api.HandleFunc("/sessions/{id}/activation", ah.GetActivationStatus).Methods("GET")
+
+	// CORS support
+	api.HandleFunc("/graphs/{id}/entry-nodes", ah.corsHandler).Methods("OPTIONS")
+	api.HandleFunc("/sessions", ah.corsHandler).Methods("OPTIONS")
+	api.HandleFunc("/sessions/{id}/submit", ah.corsHandler).Methods("OPTIONS")
+	api.HandleFunc("/sessions/{id}/activation", ah.corsHandler).Methods("OPTIONS")
+}
+
+// corsHandler handles CORS preflight requests
+func (ah *AdvancedHandlers) corsHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
+	w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, If-Match")
+	w.WriteHeader(http.StatusOK)
+}
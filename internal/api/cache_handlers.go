@@ -0,0 +1,20 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// GetCacheStats handles reporting the storage cache hit and miss counters
+func (h *Handlers) GetCacheStats(w http.ResponseWriter, r *http.Request) {
+	response := map[string]interface{}{
+		"enabled": false,
+	}
+	if stats, ok := h.onboardingService.CacheStats(); ok {
+		response["enabled"] = true
+		response["stats"] = stats
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
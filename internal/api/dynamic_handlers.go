@@ -49,6 +49,7 @@ func (dh *DynamicHandlers) StartDynamicSession(w http.ResponseWriter, r *http.Re
 		return
 	}
 
+	setSessionETag(w, session.Revision)
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(session)
 }
@@ -65,13 +66,23 @@ func (dh *DynamicHandlers) SubmitNodeDataDynamic(w http.ResponseWriter, r *http.
 		return
 	}
 
-	result, err := dh.dynamicService.SubmitNodeDataDynamic(r.Context(), sessionID, data)
+	ctx, err := ifMatchContext(r)
 	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	result, err := dh.dynamicService.SubmitNodeDataDynamic(ctx, sessionID, data)
+	if err != nil {
+		if writeSessionConflict(w, err) || writeSessionStateError(w, err) || writeNodeValidationError(w, err) {
+			return
+		}
 		dh.logger.WithError(err).Error("Failed to submit node data")
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
+	setSessionETag(w, result.SessionRevision)
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(result)
 }
@@ -112,8 +123,17 @@ func (dh *DynamicHandlers) UpdateBusinessType(w http.ResponseWriter, r *http.Req
 		return
 	}
 
-	err := dh.dynamicService.UpdateBusinessTypeDynamic(r.Context(), sessionID, req.BusinessType)
+	ctx, err := ifMatchContext(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	err = dh.dynamicService.UpdateBusinessTypeDynamic(ctx, sessionID, req.BusinessType)
 	if err != nil {
+		if writeSessionConflict(w, err) || writeSessionStateError(w, err) {
+			return
+		}
 		dh.logger.WithError(err).Error("Failed to update business type")
 		http.Error(w, "Failed to update business type", http.StatusInternalServerError)
 		return
@@ -0,0 +1,62 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"onboarding-system/internal/onboarding"
+
+	"github.com/gorilla/mux"
+)
+
+// GetSessionEvents handles listing the events recorded for a session
+func (h *Handlers) GetSessionEvents(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	sessionID := vars["id"]
+
+	events, err := h.onboardingService.GetSessionEvents(r.Context(), sessionID)
+	if err != nil {
+		h.logger.WithError(err).WithField("session_id", sessionID).Error("Failed to get session events")
+		http.Error(w, "Failed to get session events", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(events)
+}
+
+// ReplaySession handles rebuilding a session as it was at the time given by the as_of query
+// parameter (RFC 3339), or as it is now if as_of is omitted
+func (h *Handlers) ReplaySession(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	sessionID := vars["id"]
+
+	asOf := time.Now()
+	if value := r.URL.Query().Get("as_of"); value != "" {
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			http.Error(w, "as_of must be an RFC 3339 time", http.StatusBadRequest)
+			return
+		}
+		asOf = parsed
+	}
+
+	session, err := h.onboardingService.GetSessionAsOf(r.Context(), sessionID, asOf)
+	if err != nil {
+		if errors.Is(err, onboarding.ErrNoSessionEvents) {
+			http.Error(w, "No session events recorded at that time", http.StatusNotFound)
+			return
+		}
+		h.logger.WithError(err).WithField("session_id", sessionID).Error("Failed to replay session")
+		http.Error(w, "Failed to replay session", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"as_of":   asOf,
+		"session": session,
+	})
+}
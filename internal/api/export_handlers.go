@@ -0,0 +1,64 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"onboarding-system/internal/graphexport"
+	"onboarding-system/internal/onboarding"
+
+	"github.com/gorilla/mux"
+)
+
+// ExportGraph handles rendering a graph as DOT, Mermaid or draw.io.
+// With session_id the session's revision is exported with its path highlighted;
+// with revision a published revision is exported instead of the default one.
+func (h *Handlers) ExportGraph(w http.ResponseWriter, r *http.Request) {
+	graphID := mux.Vars(r)["id"]
+	query := r.URL.Query()
+
+	format := graphexport.Format(query.Get("format"))
+	if format == "" {
+		format = graphexport.FormatDOT
+	}
+
+	var graph *onboarding.Graph
+	var overlay *graphexport.Overlay
+	var err error
+
+	switch {
+	case query.Get("session_id") != "":
+		session, sessionErr := h.onboardingService.GetSession(r.Context(), query.Get("session_id"))
+		if sessionErr != nil || session.GraphID != graphID {
+			http.Error(w, "Session not found", http.StatusNotFound)
+			return
+		}
+		graph, err = h.onboardingService.GetSessionGraph(r.Context(), session)
+		if err == nil {
+			overlay = graphexport.SessionOverlay(graph, session)
+		}
+	case query.Get("revision") != "":
+		revision, convErr := strconv.Atoi(query.Get("revision"))
+		if convErr != nil {
+			http.Error(w, "Invalid revision", http.StatusBadRequest)
+			return
+		}
+		graph, err = h.onboardingService.GetGraphVersion(r.Context(), graphID, revision)
+	default:
+		graph, err = h.onboardingService.GetGraph(r.Context(), graphID)
+	}
+	if err != nil {
+		h.logger.WithError(err).WithField("graph_id", graphID).Error("Failed to get graph for export")
+		http.Error(w, "Graph not found", http.StatusNotFound)
+		return
+	}
+
+	output, err := graphexport.Render(graph, format, overlay)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", graphexport.ContentType(format))
+	w.Write([]byte(output))
+}
@@ -3,12 +3,12 @@ package api
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"os"
 	"path/filepath"
-	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -74,15 +74,42 @@ func (h *Handlers) Router() *mux.Router {
 	api.HandleFunc("/graphs", h.ListGraphs).Methods("GET")
 	api.HandleFunc("/graphs", h.CreateGraph).Methods("POST")
 	api.HandleFunc("/graphs", h.corsHandler).Methods("OPTIONS")
+	api.HandleFunc("/graphs/validate", h.ValidateGraph).Methods("POST")
+	api.HandleFunc("/graphs/validate", h.corsHandler).Methods("OPTIONS")
+	api.HandleFunc("/graphs/import", h.ImportGraph).Methods("POST")
+	api.HandleFunc("/graphs/import", h.corsHandler).Methods("OPTIONS")
 	api.HandleFunc("/graphs/{id}", h.GetGraph).Methods("GET")
 	api.HandleFunc("/graphs/{id}", h.UpdateGraph).Methods("PUT")
 	api.HandleFunc("/graphs/{id}", h.DeleteGraph).Methods("DELETE")
 	api.HandleFunc("/graphs/{id}", h.corsHandler).Methods("OPTIONS")
 
+	// Rule group routes
+	api.HandleFunc("/graphs/{id}/rule-groups", h.ListRuleGroups).Methods("GET")
+	api.HandleFunc("/graphs/{id}/rule-groups", h.CreateRuleGroup).Methods("POST")
+	api.HandleFunc("/graphs/{id}/rule-groups", h.corsHandler).Methods("OPTIONS")
+	api.HandleFunc("/graphs/{id}/rule-groups/{group_id}", h.GetRuleGroup).Methods("GET")
+	api.HandleFunc("/graphs/{id}/rule-groups/{group_id}", h.UpdateRuleGroup).Methods("PUT")
+	api.HandleFunc("/graphs/{id}/rule-groups/{group_id}", h.DeleteRuleGroup).Methods("DELETE")
+	api.HandleFunc("/graphs/{id}/rule-groups/{group_id}", h.corsHandler).Methods("OPTIONS")
+
+	// Graph version routes
+	api.HandleFunc("/graphs/{id}/versions", h.ListGraphVersions).Methods("GET")
+	api.HandleFunc("/graphs/{id}/versions", h.corsHandler).Methods("OPTIONS")
+	api.HandleFunc("/graphs/{id}/versions/{revision}", h.GetGraphVersion).Methods("GET")
+	api.HandleFunc("/graphs/{id}/versions/{revision}", h.corsHandler).Methods("OPTIONS")
+	api.HandleFunc("/graphs/{id}/versions/{revision}/default", h.SetDefaultGraphVersion).Methods("POST")
+	api.HandleFunc("/graphs/{id}/versions/{revision}/default", h.corsHandler).Methods("OPTIONS")
+	api.HandleFunc("/graphs/{id}/diff", h.DiffGraphVersions).Methods("GET")
+	api.HandleFunc("/graphs/{id}/diff", h.corsHandler).Methods("OPTIONS")
+	api.HandleFunc("/graphs/{id}/export", h.ExportGraph).Methods("GET")
+	api.HandleFunc("/graphs/{id}/export", h.corsHandler).Methods("OPTIONS")
+
 	// Session routes
 	api.HandleFunc("/sessions", h.ListSessions).Methods("GET")
 	api.HandleFunc("/sessions", h.StartSession).Methods("POST")
 	api.HandleFunc("/sessions", h.corsHandler).Methods("OPTIONS")
+	api.HandleFunc("/sessions/resume", h.ResumeSessionWithToken).Methods("POST")
+	api.HandleFunc("/sessions/resume", h.corsHandler).Methods("OPTIONS")
 	api.HandleFunc("/sessions/{id}", h.GetSession).Methods("GET")
 	api.HandleFunc("/sessions/{id}", h.corsHandler).Methods("OPTIONS")
 	api.HandleFunc("/sessions/{id}/current", h.GetCurrentNode).Methods("GET")
@@ -93,12 +120,22 @@ func (h *Handlers) Router() *mux.Router {
 	api.HandleFunc("/sessions/{id}/submit", h.corsHandler).Methods("OPTIONS")
 	api.HandleFunc("/sessions/{id}/complete", h.CompleteSession).Methods("POST")
 	api.HandleFunc("/sessions/{id}/complete", h.corsHandler).Methods("OPTIONS")
+	api.HandleFunc("/sessions/{id}/completion-report", h.GetCompletionReport).Methods("GET")
+	api.HandleFunc("/sessions/{id}/completion-report", h.corsHandler).Methods("OPTIONS")
 	api.HandleFunc("/sessions/{id}/back", h.GoBack).Methods("POST")
 	api.HandleFunc("/sessions/{id}/back", h.corsHandler).Methods("OPTIONS")
 	api.HandleFunc("/sessions/{id}/retry", h.RetrySession).Methods("POST")
 	api.HandleFunc("/sessions/{id}/retry", h.corsHandler).Methods("OPTIONS")
+	api.HandleFunc("/sessions/{id}/pause", h.PauseSession).Methods("POST")
+	api.HandleFunc("/sessions/{id}/pause", h.corsHandler).Methods("OPTIONS")
+	api.HandleFunc("/sessions/{id}/resume", h.ResumeSession).Methods("POST")
+	api.HandleFunc("/sessions/{id}/resume", h.corsHandler).Methods("OPTIONS")
 	api.HandleFunc("/sessions/{id}/history", h.GetSessionHistory).Methods("GET")
 	api.HandleFunc("/sessions/{id}/history", h.corsHandler).Methods("OPTIONS")
+	api.HandleFunc("/sessions/{id}/events", h.GetSessionEvents).Methods("GET")
+	api.HandleFunc("/sessions/{id}/events", h.corsHandler).Methods("OPTIONS")
+	api.HandleFunc("/sessions/{id}/replay", h.ReplaySession).Methods("GET")
+	api.HandleFunc("/sessions/{id}/replay", h.corsHandler).Methods("OPTIONS")
 	api.HandleFunc("/users/{user_id}/sessions", h.ListUserSessions).Methods("GET")
 	api.HandleFunc("/users/{user_id}/sessions", h.corsHandler).Methods("OPTIONS")
 
@@ -119,6 +156,20 @@ func (h *Handlers) Router() *mux.Router {
 	api.HandleFunc("/admin/graphs/{id}/visual", h.corsHandler).Methods("OPTIONS")
 	api.HandleFunc("/admin/sessions/{id}/graph-visual", h.GetSessionGraphVisual).Methods("GET")
 	api.HandleFunc("/admin/sessions/{id}/graph-visual", h.corsHandler).Methods("OPTIONS")
+	api.HandleFunc("/admin/sessions/{id}/migrate", h.MigrateSession).Methods("POST")
+	api.HandleFunc("/admin/sessions/{id}/migrate", h.corsHandler).Methods("OPTIONS")
+	api.HandleFunc("/admin/sessions/{id}/reactivate", h.ReactivateSession).Methods("POST")
+	api.HandleFunc("/admin/sessions/{id}/reactivate", h.corsHandler).Methods("OPTIONS")
+	api.HandleFunc("/admin/graphs/{id}/migrate", h.MigrateGraphSessions).Methods("POST")
+	api.HandleFunc("/admin/graphs/{id}/migrate", h.corsHandler).Methods("OPTIONS")
+	api.HandleFunc("/admin/outbox", h.ListOutboxMessages).Methods("GET")
+	api.HandleFunc("/admin/outbox", h.corsHandler).Methods("OPTIONS")
+	api.HandleFunc("/admin/outbox/replay", h.ReplayDeadLetters).Methods("POST")
+	api.HandleFunc("/admin/outbox/replay", h.corsHandler).Methods("OPTIONS")
+	api.HandleFunc("/admin/outbox/{id}/replay", h.ReplayOutboxMessage).Methods("POST")
+	api.HandleFunc("/admin/outbox/{id}/replay", h.corsHandler).Methods("OPTIONS")
+	api.HandleFunc("/admin/cache", h.GetCacheStats).Methods("GET")
+	api.HandleFunc("/admin/cache", h.corsHandler).Methods("OPTIONS")
 
 	// Eligible nodes route
 	api.HandleFunc("/sessions/{id}/eligible-nodes", h.GetEligibleNodes).Methods("GET")
@@ -169,6 +220,10 @@ func (h *Handlers) CreateGraph(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := h.onboardingService.CreateGraph(r.Context(), &graph); err != nil {
+		if errors.Is(err, onboarding.ErrInvalidGraph) {
+			h.writeInvalidGraph(w, err)
+			return
+		}
 		h.logger.WithError(err).Error("Failed to create graph")
 		http.Error(w, "Failed to create graph", http.StatusInternalServerError)
 		return
@@ -179,6 +234,50 @@ func (h *Handlers) CreateGraph(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(graph)
 }
 
+// ValidateGraph handles linting a graph without saving it
+func (h *Handlers) ValidateGraph(w http.ResponseWriter, r *http.Request) {
+	var graph onboarding.Graph
+	if err := json.NewDecoder(r.Body).Decode(&graph); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	report := h.onboardingService.ValidateGraph(r.Context(), &graph)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
+// writeInvalidGraph responds with the lint report of a rejected graph
+func (h *Handlers) writeInvalidGraph(w http.ResponseWriter, err error) {
+	var validationErr *onboarding.GraphValidationError
+	if !errors.As(err, &validationErr) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusBadRequest)
+	json.NewEncoder(w).Encode(validationErr.Report)
+}
+
+// writeNodeValidationError responds with the validation errors of rejected node data. It reports
+// whether err was a validation error.
+func writeNodeValidationError(w http.ResponseWriter, err error) bool {
+	var validationErr *onboarding.NodeValidationError
+	if !errors.As(err, &validationErr) {
+		return false
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusBadRequest)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"error":  onboarding.ErrValidationFailed.Error(),
+		"errors": validationErr.Errors,
+	})
+	return true
+}
+
 // GetGraph handles getting a graph by ID
 func (h *Handlers) GetGraph(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
@@ -209,6 +308,10 @@ func (h *Handlers) UpdateGraph(w http.ResponseWriter, r *http.Request) {
 	graph.ID = graphID
 
 	if err := h.onboardingService.CreateGraph(r.Context(), &graph); err != nil {
+		if errors.Is(err, onboarding.ErrInvalidGraph) {
+			h.writeInvalidGraph(w, err)
+			return
+		}
 		h.logger.WithError(err).WithField("graph_id", graphID).Error("Failed to update graph")
 		http.Error(w, "Failed to update graph", http.StatusInternalServerError)
 		return
@@ -255,6 +358,7 @@ func (h *Handlers) StartSession(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	setSessionETag(w, session.Revision)
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(session)
@@ -272,6 +376,7 @@ func (h *Handlers) GetSession(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	setSessionETag(w, session.Revision)
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(session)
 }
@@ -298,48 +403,39 @@ func (h *Handlers) NavigateToNode(w http.ResponseWriter, r *http.Request) {
 	sessionID := vars["id"]
 	nodeID := vars["node_id"]
 
-	// Check if the node exists and is accessible
-	session, err := h.onboardingService.GetSession(r.Context(), sessionID)
+	ctx, err := ifMatchContext(r)
 	if err != nil {
-		h.logger.WithError(err).Error("Failed to get session")
-		http.Error(w, "Session not found", http.StatusNotFound)
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	graph, err := h.onboardingService.GetGraph(r.Context(), session.GraphID)
+	// Get the session to navigate
+	session, err := h.onboardingService.GetSession(ctx, sessionID)
 	if err != nil {
-		h.logger.WithError(err).Error("Failed to get graph")
-		http.Error(w, "Graph not found", http.StatusNotFound)
-		return
-	}
-
-	node, exists := graph.Nodes[nodeID]
-	if !exists {
-		h.logger.WithField("node_id", nodeID).Error("Node not found")
-		http.Error(w, "Node not found", http.StatusNotFound)
+		if writeSessionConflict(w, err) || writeSessionStateError(w, err) {
+			return
+		}
+		h.logger.WithError(err).Error("Failed to get session")
+		http.Error(w, "Session not found", http.StatusNotFound)
 		return
 	}
 
-	// Check if the node is accessible from the current session state
-	// For now, we'll allow navigation to any node that exists in the graph
-	// In a more sophisticated system, you might want to check if the node is reachable
-
-	// Update the session's current node
-	session.CurrentNodeID = nodeID
-	session.UpdatedAt = time.Now()
-
-	if err := h.onboardingService.UpdateSession(r.Context(), session); err != nil {
-		h.logger.WithError(err).Error("Failed to update session")
+	node, err := h.onboardingService.NavigateToNode(ctx, session, nodeID)
+	if err != nil {
+		if writeSessionConflict(w, err) || writeSessionStateError(w, err) {
+			return
+		}
+		if errors.Is(err, onboarding.ErrNodeNotFound) {
+			h.logger.WithField("node_id", nodeID).Error("Node not found")
+			http.Error(w, "Node not found", http.StatusNotFound)
+			return
+		}
+		h.logger.WithError(err).Error("Failed to navigate to node")
 		http.Error(w, "Failed to navigate to node", http.StatusInternalServerError)
 		return
 	}
 
-	h.logger.WithFields(logrus.Fields{
-		"session_id": sessionID,
-		"node_id":    nodeID,
-		"node_name":  node.Name,
-	}).Info("Navigated to node")
-
+	setSessionETag(w, session.Revision)
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]interface{}{
 		"success": true,
@@ -366,13 +462,23 @@ func (h *Handlers) SubmitNodeData(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	result, err := h.onboardingService.SubmitNodeData(r.Context(), sessionID, data)
+	ctx, err := ifMatchContext(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	result, err := h.onboardingService.SubmitNodeData(ctx, sessionID, data)
 	if err != nil {
+		if writeSessionConflict(w, err) || writeSessionStateError(w, err) || writeNodeValidationError(w, err) {
+			return
+		}
 		h.logger.WithError(err).WithField("session_id", sessionID).Error("Failed to submit node data")
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
+	setSessionETag(w, result.SessionRevision)
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(result)
 }
@@ -389,58 +495,74 @@ func (h *Handlers) CompleteSession(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Get the session to check if completion is valid
-	session, err := h.onboardingService.GetSession(r.Context(), sessionID)
+	ctx, err := ifMatchContext(r)
 	if err != nil {
-		h.logger.WithError(err).WithField("session_id", sessionID).Error("Failed to get session")
-		http.Error(w, "Session not found", http.StatusNotFound)
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	// Get the graph to validate completion
-	graph, err := h.onboardingService.GetGraph(r.Context(), session.GraphID)
+	// Get the session to check if completion is valid
+	session, err := h.onboardingService.GetSession(ctx, sessionID)
 	if err != nil {
-		h.logger.WithError(err).WithField("session_id", sessionID).Error("Failed to get graph")
-		http.Error(w, "Graph not found", http.StatusNotFound)
-		return
-	}
-
-	// Check if all required nodes have been completed
-	pathValid, missingNodes := h.onboardingService.ValidatePathCompleteness(r.Context(), graph, session.CurrentNodeID, session.Data, session.History)
-	if !pathValid {
-		h.logger.WithFields(logrus.Fields{
-			"session_id":    sessionID,
-			"current_node":  session.CurrentNodeID,
-			"missing_nodes": missingNodes,
-		}).Warn("Cannot complete session - missing required nodes")
-		http.Error(w, fmt.Sprintf("Cannot complete onboarding yet: you must complete the following steps first: %v", missingNodes), http.StatusBadRequest)
+		if writeSessionConflict(w, err) || writeSessionStateError(w, err) {
+			return
+		}
+		h.logger.WithError(err).WithField("session_id", sessionID).Error("Failed to get session")
+		http.Error(w, "Session not found", http.StatusNotFound)
 		return
 	}
 
-	// All required nodes completed, mark session as complete
-	session.Status = "completed"
-	now := time.Now()
-	session.CompletedAt = &now
-	session.CurrentNodeID = ""
-	session.UpdatedAt = now
-
-	if err := h.onboardingService.UpdateSession(r.Context(), session); err != nil {
-		h.logger.WithError(err).WithField("session_id", sessionID).Error("Failed to update session")
+	if err := h.onboardingService.CompleteSession(ctx, session); err != nil {
+		if writeSessionConflict(w, err) || writeSessionStateError(w, err) {
+			return
+		}
+		var incompleteErr *onboarding.IncompleteSessionError
+		if errors.As(err, &incompleteErr) {
+			h.logger.WithFields(logrus.Fields{
+				"session_id":    sessionID,
+				"current_node":  session.CurrentNodeID,
+				"missing_nodes": incompleteErr.MissingNodes,
+			}).Warn("Cannot complete session - missing required nodes")
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"error":             fmt.Sprintf("Cannot complete onboarding yet: %v", err),
+				"missing_nodes":     incompleteErr.MissingNodes,
+				"completion_report": incompleteErr.Report,
+			})
+			return
+		}
+		h.logger.WithError(err).WithField("session_id", sessionID).Error("Failed to complete session")
 		http.Error(w, "Failed to complete session", http.StatusInternalServerError)
 		return
 	}
 
-	h.logger.WithField("session_id", sessionID).Info("Session completed successfully")
-
+	setSessionETag(w, session.Revision)
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]interface{}{
 		"success":        true,
 		"message":        "Onboarding completed successfully!",
 		"session_status": "completed",
-		"completed_at":   now,
+		"completed_at":   session.CompletedAt,
 	})
 }
 
+// GetCompletionReport explains what a session still needs to be completed
+func (h *Handlers) GetCompletionReport(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	sessionID := vars["id"]
+
+	report, err := h.onboardingService.GetCompletionReport(r.Context(), sessionID)
+	if err != nil {
+		h.logger.WithError(err).WithField("session_id", sessionID).Error("Failed to get completion report")
+		http.Error(w, "Session not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
 // GoBack handles going back to the previous node
 func (h *Handlers) GoBack(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
@@ -453,8 +575,17 @@ func (h *Handlers) GoBack(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	node, err := h.onboardingService.GoBack(r.Context(), sessionID)
+	ctx, err := ifMatchContext(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	node, err := h.onboardingService.GoBack(ctx, sessionID)
 	if err != nil {
+		if writeSessionConflict(w, err) || writeSessionStateError(w, err) {
+			return
+		}
 		h.logger.WithError(err).WithField("session_id", sessionID).Error("Failed to go back")
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
@@ -469,7 +600,16 @@ func (h *Handlers) RetrySession(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	sessionID := vars["id"]
 
-	if err := h.onboardingService.RetrySession(r.Context(), sessionID); err != nil {
+	ctx, err := ifMatchContext(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.onboardingService.RetrySession(ctx, sessionID); err != nil {
+		if writeSessionConflict(w, err) || writeSessionStateError(w, err) {
+			return
+		}
 		h.logger.WithError(err).WithField("session_id", sessionID).Error("Failed to retry session")
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
@@ -495,38 +635,40 @@ func (h *Handlers) GetSessionHistory(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(history)
 }
 
-// ListUserSessions handles listing sessions for a user
+// ListUserSessions handles listing a user's sessions a page at a time
 func (h *Handlers) ListUserSessions(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	userID := vars["user_id"]
 
-	// Parse query parameters
-	limitStr := r.URL.Query().Get("limit")
-	offsetStr := r.URL.Query().Get("offset")
-
-	limit := 10 // default
-	offset := 0 // default
-
-	if limitStr != "" {
-		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 {
-			limit = l
-		}
+	query, err := parseSessionQuery(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
 	}
+	query.UserID = userID
 
-	if offsetStr != "" {
-		if o, err := strconv.Atoi(offsetStr); err == nil && o >= 0 {
-			offset = o
-		}
+	page, ok := h.listSessionsPage(w, r, query)
+	if !ok {
+		return
 	}
 
-	// Note: This would need to be implemented in the service layer with pagination
-	h.logger.WithFields(logrus.Fields{
-		"user_id": userID,
-		"limit":   limit,
-		"offset":  offset,
-	}).Info("List user sessions requested")
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(page)
+}
 
-	http.Error(w, "Not implemented", http.StatusNotImplemented)
+// listSessionsPage runs a session listing, responding with the error if it fails
+func (h *Handlers) listSessionsPage(w http.ResponseWriter, r *http.Request, query onboarding.SessionQuery) (*onboarding.SessionPage, bool) {
+	page, err := h.onboardingService.ListSessionsQuery(r.Context(), query)
+	if err != nil {
+		if errors.Is(err, onboarding.ErrInvalidCursor) {
+			http.Error(w, "Invalid cursor; it must come from the same listing with the same sort", http.StatusBadRequest)
+			return nil, false
+		}
+		h.logger.WithError(err).Error("Failed to list sessions")
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return nil, false
+	}
+	return page, true
 }
 
 // ListSessions handles listing all sessions
@@ -544,7 +686,8 @@ func (h *Handlers) corsHandler(w http.ResponseWriter, r *http.Request) {
 	// Set CORS headers
 	w.Header().Set("Access-Control-Allow-Origin", "*")
 	w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-	w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Requested-With")
+	w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Requested-With, If-Match")
+	w.Header().Set("Access-Control-Expose-Headers", "ETag")
 	w.Header().Set("Access-Control-Max-Age", "86400")
 
 	w.WriteHeader(http.StatusOK)
@@ -556,7 +699,8 @@ func (h *Handlers) corsMiddleware(next http.Handler) http.Handler {
 		// Set CORS headers
 		w.Header().Set("Access-Control-Allow-Origin", "*")
 		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Requested-With")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Requested-With, If-Match")
+		w.Header().Set("Access-Control-Expose-Headers", "ETag")
 		w.Header().Set("Access-Control-Max-Age", "86400")
 
 		// Handle preflight requests
@@ -752,17 +896,23 @@ func (h *Handlers) CheckOngoingUploads(sessionID string) bool {
 	return false
 }
 
-// ListAllSessions returns all sessions for admin dashboard
+// ListAllSessions returns a page of sessions for the admin dashboard
 func (h *Handlers) ListAllSessions(w http.ResponseWriter, r *http.Request) {
-	sessions, err := h.onboardingService.ListAllSessions(r.Context())
+	query, err := parseSessionQuery(r)
 	if err != nil {
-		h.logger.WithError(err).Error("Failed to list all sessions")
-		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
+	page, ok := h.listSessionsPage(w, r, query)
+	if !ok {
+		return
+	}
+	sessions := page.Sessions
+
 	// Enhance sessions with additional info for admin dashboard
 	enhancedSessions := make([]map[string]interface{}, 0, len(sessions))
+	graphs := make(map[string]*onboarding.Graph)
 	for _, session := range sessions {
 		// Calculate progress
 		progress := h.calculateSessionProgress(session)
@@ -782,8 +932,14 @@ func (h *Handlers) ListAllSessions(w http.ResponseWriter, r *http.Request) {
 
 		// Add current node name if available
 		if session.CurrentNodeID != "" {
-			// Try to get graph to find node name
-			if graph, err := h.onboardingService.GetGraph(r.Context(), session.GraphID); err == nil {
+			// Sessions on a page mostly share graphs, so each revision is loaded once
+			graphKey := fmt.Sprintf("%s@%d", session.GraphID, session.GraphRevision)
+			graph, loaded := graphs[graphKey]
+			if !loaded {
+				graph, _ = h.onboardingService.GetSessionGraph(r.Context(), session)
+				graphs[graphKey] = graph
+			}
+			if graph != nil {
 				if node, exists := graph.Nodes[session.CurrentNodeID]; exists {
 					enhancedSession["current_node_name"] = node.Name
 				}
@@ -796,7 +952,13 @@ func (h *Handlers) ListAllSessions(w http.ResponseWriter, r *http.Request) {
 	h.logger.WithField("count", len(sessions)).Info("List all sessions requested (admin)")
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(enhancedSessions)
+	response := map[string]interface{}{
+		"sessions": enhancedSessions,
+	}
+	if page.NextCursor != "" {
+		response["next_cursor"] = page.NextCursor
+	}
+	json.NewEncoder(w).Encode(response)
 }
 
 // GetSessionDetails returns detailed information about a session for admin
@@ -829,7 +991,7 @@ func (h *Handlers) GetSessionDetails(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Get graph to understand node structure
-	graph, err := h.onboardingService.GetGraph(r.Context(), session.GraphID)
+	graph, err := h.onboardingService.GetSessionGraph(r.Context(), session)
 	if err != nil {
 		h.logger.WithError(err).Error("Failed to get graph")
 		http.Error(w, "Failed to get graph", http.StatusInternalServerError)
@@ -893,7 +1055,7 @@ func (h *Handlers) GetSessionGraphVisual(w http.ResponseWriter, r *http.Request)
 	}
 
 	// Get graph
-	graph, err := h.onboardingService.GetGraph(r.Context(), session.GraphID)
+	graph, err := h.onboardingService.GetSessionGraph(r.Context(), session)
 	if err != nil {
 		h.logger.WithError(err).Error("Failed to get graph")
 		http.Error(w, "Graph not found", http.StatusNotFound)
@@ -1193,7 +1355,7 @@ func (h *Handlers) getSessionUploadedFiles(sessionID string) []map[string]interf
 // calculateSessionProgress calculates the progress percentage for a session
 func (h *Handlers) calculateSessionProgress(session *onboarding.Session) int {
 	// Get the graph to count total nodes
-	graph, err := h.onboardingService.GetGraph(context.Background(), session.GraphID)
+	graph, err := h.onboardingService.GetSessionGraph(context.Background(), session)
 	if err != nil {
 		return 0
 	}
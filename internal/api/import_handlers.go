@@ -0,0 +1,55 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"onboarding-system/internal/graphimport"
+	"onboarding-system/internal/onboarding"
+)
+
+// ImportGraph handles creating a graph from a requirements spreadsheet.
+// The CSV is read from the "file" form field of a multipart request or from the raw body.
+func (h *Handlers) ImportGraph(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	if format := query.Get("format"); format != "" && format != "csv" {
+		http.Error(w, "Unsupported import format: "+format, http.StatusBadRequest)
+		return
+	}
+
+	var body io.Reader = r.Body
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		file, _, err := r.FormFile("file")
+		if err != nil {
+			http.Error(w, "Missing file", http.StatusBadRequest)
+			return
+		}
+		defer file.Close()
+		body = file
+	}
+
+	opts := graphimport.Options{
+		GraphID:     query.Get("graph_id"),
+		Name:        query.Get("name"),
+		Description: query.Get("description"),
+		Version:     query.Get("version"),
+	}
+
+	result, err := h.onboardingService.ImportGraphCSV(r.Context(), body, opts)
+	if err != nil {
+		if errors.Is(err, onboarding.ErrInvalidGraph) {
+			h.writeInvalidGraph(w, err)
+			return
+		}
+		h.logger.WithError(err).Error("Failed to import graph")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(result)
+}
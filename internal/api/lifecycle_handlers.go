@@ -0,0 +1,53 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"onboarding-system/internal/onboarding"
+
+	"github.com/gorilla/mux"
+)
+
+// ReactivateSession handles returning a paused or expired session to active
+func (h *Handlers) ReactivateSession(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	sessionID := vars["id"]
+
+	ctx, err := ifMatchContext(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	session, err := h.onboardingService.ReactivateSession(ctx, sessionID)
+	if err != nil {
+		switch {
+		case writeSessionConflict(w, err):
+		case errors.Is(err, onboarding.ErrSessionNotInactive), errors.Is(err, onboarding.ErrRetriesExhausted):
+			http.Error(w, err.Error(), http.StatusConflict)
+		default:
+			h.logger.WithError(err).WithField("session_id", sessionID).Error("Failed to reactivate session")
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		}
+		return
+	}
+
+	setSessionETag(w, session.Revision)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(session)
+}
+
+// writeSessionStateError responds to changes rejected because a session is paused or expired
+func writeSessionStateError(w http.ResponseWriter, err error) bool {
+	switch {
+	case errors.Is(err, onboarding.ErrSessionExpired):
+		http.Error(w, "Session has expired after inactivity; an admin can reactivate it", http.StatusGone)
+	case errors.Is(err, onboarding.ErrSessionPaused):
+		http.Error(w, "Session is paused; resume it before making changes", http.StatusConflict)
+	default:
+		return false
+	}
+	return true
+}
@@ -0,0 +1,70 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"onboarding-system/internal/onboarding"
+
+	"github.com/gorilla/mux"
+)
+
+// MigrateSession handles migrating a session to another graph revision.
+// The migration is a dry run unless the request has commit=true.
+func (h *Handlers) MigrateSession(w http.ResponseWriter, r *http.Request) {
+	sessionID := mux.Vars(r)["id"]
+
+	var plan onboarding.MigrationPlan
+	if err := json.NewDecoder(r.Body).Decode(&plan); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	dryRun := r.URL.Query().Get("commit") != "true"
+
+	report, err := h.onboardingService.MigrateSession(r.Context(), sessionID, plan, dryRun)
+	if err != nil {
+		if writeSessionConflict(w, err) {
+			return
+		}
+		h.logger.WithError(err).WithField("session_id", sessionID).Error("Failed to migrate session")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if !dryRun && !report.Applied {
+		w.WriteHeader(http.StatusConflict)
+	}
+	json.NewEncoder(w).Encode(report)
+}
+
+// MigrateGraphSessions handles migrating every active session of a graph to another revision.
+// The migration is a dry run unless the request has commit=true.
+func (h *Handlers) MigrateGraphSessions(w http.ResponseWriter, r *http.Request) {
+	graphID := mux.Vars(r)["id"]
+
+	var plan onboarding.MigrationPlan
+	if err := json.NewDecoder(r.Body).Decode(&plan); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	dryRun := r.URL.Query().Get("commit") != "true"
+
+	report, err := h.onboardingService.MigrateGraphSessions(r.Context(), graphID, plan, dryRun)
+	if err != nil {
+		h.logger.WithError(err).WithField("graph_id", graphID).Error("Failed to migrate graph sessions")
+		if report == nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	// A run that stopped early still reports the sessions it migrated
+	w.Header().Set("Content-Type", "application/json")
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+	json.NewEncoder(w).Encode(report)
+}
@@ -0,0 +1,90 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"onboarding-system/internal/onboarding"
+
+	"github.com/gorilla/mux"
+)
+
+// maxOutboxListLimit caps how many outbox messages one listing returns
+const maxOutboxListLimit = 1000
+
+// ListOutboxMessages handles listing webhook outbox messages, dead letters by default
+func (h *Handlers) ListOutboxMessages(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	status := onboarding.OutboxStatus(query.Get("status"))
+	switch status {
+	case "":
+		status = onboarding.OutboxStatusDead
+	case onboarding.OutboxStatusPending, onboarding.OutboxStatusDelivered, onboarding.OutboxStatusDead:
+	default:
+		http.Error(w, fmt.Sprintf("Invalid status %q; expected pending, delivered or dead", status), http.StatusBadRequest)
+		return
+	}
+
+	limit := 100 // default
+	if limitStr := query.Get("limit"); limitStr != "" {
+		l, err := strconv.Atoi(limitStr)
+		if err != nil || l <= 0 {
+			http.Error(w, "Invalid limit; expected a positive integer", http.StatusBadRequest)
+			return
+		}
+		if l > maxOutboxListLimit {
+			l = maxOutboxListLimit
+		}
+		limit = l
+	}
+
+	messages, err := h.onboardingService.ListOutboxMessages(r.Context(), status, limit)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to list outbox messages")
+		http.Error(w, "Failed to list outbox messages", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(messages)
+}
+
+// ReplayOutboxMessage handles requeueing one dead-lettered webhook delivery
+func (h *Handlers) ReplayOutboxMessage(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	messageID := vars["id"]
+
+	message, err := h.onboardingService.RequeueOutboxMessage(r.Context(), messageID)
+	if err != nil {
+		switch {
+		case errors.Is(err, onboarding.ErrOutboxMessageNotDead):
+			http.Error(w, err.Error(), http.StatusConflict)
+		default:
+			h.logger.WithError(err).WithField("message_id", messageID).Error("Failed to requeue outbox message")
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(message)
+}
+
+// ReplayDeadLetters handles requeueing every dead-lettered webhook delivery
+func (h *Handlers) ReplayDeadLetters(w http.ResponseWriter, r *http.Request) {
+	requeued, err := h.onboardingService.RequeueDeadLetters(r.Context())
+	if err != nil {
+		h.logger.WithError(err).WithField("requeued", requeued).Error("Failed to requeue dead letters")
+		http.Error(w, "Failed to requeue dead letters", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"requeued": requeued,
+	})
+}
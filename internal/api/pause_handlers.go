@@ -0,0 +1,99 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"onboarding-system/internal/onboarding"
+
+	"github.com/gorilla/mux"
+)
+
+// PauseSession handles pausing a session and issuing its resume token
+func (h *Handlers) PauseSession(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	sessionID := vars["id"]
+
+	ctx, err := ifMatchContext(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	result, err := h.onboardingService.PauseSession(ctx, sessionID)
+	if err != nil {
+		h.writePauseError(w, err, sessionID, "Failed to pause session")
+		return
+	}
+
+	setSessionETag(w, result.Session.Revision)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// ResumeSession handles resuming a paused session by ID
+func (h *Handlers) ResumeSession(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	sessionID := vars["id"]
+
+	ctx, err := ifMatchContext(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	result, err := h.onboardingService.ResumeSession(ctx, sessionID)
+	if err != nil {
+		h.writePauseError(w, err, sessionID, "Failed to resume session")
+		return
+	}
+
+	h.writeResumeResult(w, result)
+}
+
+// ResumeSessionWithToken handles resuming the paused session a resume token was issued for
+func (h *Handlers) ResumeSessionWithToken(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		ResumeToken string `json:"resume_token"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	if request.ResumeToken == "" {
+		http.Error(w, "resume_token is required", http.StatusBadRequest)
+		return
+	}
+
+	result, err := h.onboardingService.ResumeSessionWithToken(r.Context(), request.ResumeToken)
+	if err != nil {
+		h.writePauseError(w, err, "", "Failed to resume session")
+		return
+	}
+
+	h.writeResumeResult(w, result)
+}
+
+// writeResumeResult responds with where a resumed session picks up
+func (h *Handlers) writeResumeResult(w http.ResponseWriter, result *onboarding.ResumeResult) {
+	setSessionETag(w, result.Session.Revision)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// writePauseError maps pause and resume errors to HTTP status codes
+func (h *Handlers) writePauseError(w http.ResponseWriter, err error, sessionID, message string) {
+	switch {
+	case writeSessionConflict(w, err), writeSessionStateError(w, err):
+	case errors.Is(err, onboarding.ErrResumeTokenInvalid):
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+	case errors.Is(err, onboarding.ErrSessionNotActive), errors.Is(err, onboarding.ErrSessionNotPaused):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		h.logger.WithError(err).WithField("session_id", sessionID).Error(message)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	}
+}
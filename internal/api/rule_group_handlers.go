@@ -0,0 +1,111 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"onboarding-system/internal/onboarding"
+
+	"github.com/gorilla/mux"
+)
+
+// ListRuleGroups handles listing the rule groups of a graph
+func (h *Handlers) ListRuleGroups(w http.ResponseWriter, r *http.Request) {
+	graphID := mux.Vars(r)["id"]
+
+	ruleGroups, err := h.onboardingService.ListRuleGroups(r.Context(), graphID)
+	if err != nil {
+		h.logger.WithError(err).WithField("graph_id", graphID).Error("Failed to list rule groups")
+		http.Error(w, "Graph not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(ruleGroups)
+}
+
+// GetRuleGroup handles getting a single rule group
+func (h *Handlers) GetRuleGroup(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	graphID := vars["id"]
+	ruleGroupID := vars["group_id"]
+
+	ruleGroup, err := h.onboardingService.GetRuleGroup(r.Context(), graphID, ruleGroupID)
+	if err != nil {
+		h.logger.WithError(err).WithField("graph_id", graphID).Error("Failed to get rule group")
+		http.Error(w, "Rule group not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(ruleGroup)
+}
+
+// CreateRuleGroup handles adding a rule group to a graph
+func (h *Handlers) CreateRuleGroup(w http.ResponseWriter, r *http.Request) {
+	graphID := mux.Vars(r)["id"]
+
+	var ruleGroup onboarding.RuleGroup
+	if err := json.NewDecoder(r.Body).Decode(&ruleGroup); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.onboardingService.CreateRuleGroup(r.Context(), graphID, ruleGroup); err != nil {
+		h.writeRuleGroupError(w, err, graphID, "Failed to create rule group")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(ruleGroup)
+}
+
+// UpdateRuleGroup handles replacing a rule group on a graph
+func (h *Handlers) UpdateRuleGroup(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	graphID := vars["id"]
+
+	var ruleGroup onboarding.RuleGroup
+	if err := json.NewDecoder(r.Body).Decode(&ruleGroup); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	ruleGroup.ID = vars["group_id"]
+
+	if err := h.onboardingService.UpdateRuleGroup(r.Context(), graphID, ruleGroup); err != nil {
+		h.writeRuleGroupError(w, err, graphID, "Failed to update rule group")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(ruleGroup)
+}
+
+// DeleteRuleGroup handles removing a rule group from a graph
+func (h *Handlers) DeleteRuleGroup(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	graphID := vars["id"]
+
+	if err := h.onboardingService.DeleteRuleGroup(r.Context(), graphID, vars["group_id"]); err != nil {
+		h.writeRuleGroupError(w, err, graphID, "Failed to delete rule group")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// writeRuleGroupError maps rule group errors to HTTP status codes
+func (h *Handlers) writeRuleGroupError(w http.ResponseWriter, err error, graphID, message string) {
+	switch {
+	case errors.Is(err, onboarding.ErrInvalidGraph):
+		h.writeInvalidGraph(w, err)
+	case errors.Is(err, onboarding.ErrRuleGroupNotFound):
+		http.Error(w, "Rule group not found", http.StatusNotFound)
+	default:
+		h.logger.WithError(err).WithField("graph_id", graphID).Error(message)
+		http.Error(w, message, http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,84 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"onboarding-system/internal/onboarding"
+)
+
+// parseSessionQuery reads session listing filters, sorting and paging from the query string:
+//
+//	status=active,paused  graph_id  user_id  business_type  current_node_id
+//	created_from  created_to  updated_from  updated_to   (RFC 3339; from is inclusive, to exclusive)
+//	sort=created_at|updated_at  order=desc|asc  limit  cursor
+func parseSessionQuery(r *http.Request) (onboarding.SessionQuery, error) {
+	values := r.URL.Query()
+	// Listings used to take an offset; ignoring it would silently return the first page again
+	if values.Has("offset") {
+		return onboarding.SessionQuery{}, fmt.Errorf("offset is not supported: page with the next_cursor of the previous page")
+	}
+
+	query := onboarding.SessionQuery{
+		UserID:        values.Get("user_id"),
+		GraphID:       values.Get("graph_id"),
+		BusinessType:  values.Get("business_type"),
+		CurrentNodeID: values.Get("current_node_id"),
+		Cursor:        values.Get("cursor"),
+	}
+
+	for _, status := range strings.Split(values.Get("status"), ",") {
+		if status = strings.TrimSpace(status); status != "" {
+			query.Statuses = append(query.Statuses, onboarding.SessionStatus(status))
+		}
+	}
+
+	times := []struct {
+		name   string
+		target *time.Time
+	}{
+		{"created_from", &query.CreatedFrom},
+		{"created_to", &query.CreatedTo},
+		{"updated_from", &query.UpdatedFrom},
+		{"updated_to", &query.UpdatedTo},
+	}
+	for _, t := range times {
+		value := values.Get(t.name)
+		if value == "" {
+			continue
+		}
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return query, fmt.Errorf("invalid %s %q: expected an RFC 3339 time", t.name, value)
+		}
+		*t.target = parsed
+	}
+
+	switch sortBy := onboarding.SessionSortField(values.Get("sort")); sortBy {
+	case "", onboarding.SessionSortCreatedAt, onboarding.SessionSortUpdatedAt:
+		query.SortBy = sortBy
+	default:
+		return query, fmt.Errorf("invalid sort %q: expected created_at or updated_at", sortBy)
+	}
+
+	switch order := values.Get("order"); order {
+	case "", "desc":
+	case "asc":
+		query.Ascending = true
+	default:
+		return query, fmt.Errorf("invalid order %q: expected asc or desc", order)
+	}
+
+	if limit := values.Get("limit"); limit != "" {
+		l, err := strconv.Atoi(limit)
+		if err != nil || l <= 0 || l > onboarding.MaxSessionPageSize {
+			return query, fmt.Errorf("invalid limit %q: expected 1 to %d", limit, onboarding.MaxSessionPageSize)
+		}
+		query.Limit = l
+	}
+
+	return query, nil
+}
@@ -0,0 +1,52 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"onboarding-system/internal/onboarding"
+)
+
+// setSessionETag exposes a session revision as the response's ETag
+func setSessionETag(w http.ResponseWriter, revision int) {
+	w.Header().Set("ETag", strconv.Quote(strconv.Itoa(revision)))
+}
+
+// ifMatchContext returns the request context, expecting the session revision named by the
+// If-Match header if there is one. A missing header or "*" accepts any revision.
+func ifMatchContext(r *http.Request) (context.Context, error) {
+	header := strings.TrimSpace(r.Header.Get("If-Match"))
+	if header == "" || header == "*" {
+		return r.Context(), nil
+	}
+
+	revision, err := strconv.Atoi(strings.Trim(strings.TrimPrefix(header, "W/"), `"`))
+	if err != nil {
+		return nil, fmt.Errorf("invalid If-Match header %q: expected a session ETag", header)
+	}
+
+	return onboarding.WithExpectedRevision(r.Context(), revision), nil
+}
+
+// writeSessionConflict responds with 409 and the current session if err is a revision conflict
+func writeSessionConflict(w http.ResponseWriter, err error) bool {
+	var conflictErr *onboarding.SessionConflictError
+	if !errors.As(err, &conflictErr) {
+		return false
+	}
+
+	setSessionETag(w, conflictErr.Current.Revision)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusConflict)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"error":    "Session was modified by another request; reapply your change to the current session",
+		"revision": conflictErr.Current.Revision,
+		"session":  conflictErr.Current,
+	})
+	return true
+}
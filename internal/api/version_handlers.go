@@ -0,0 +1,98 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+// ListGraphVersions handles listing the published revisions of a graph
+func (h *Handlers) ListGraphVersions(w http.ResponseWriter, r *http.Request) {
+	graphID := mux.Vars(r)["id"]
+
+	versions, err := h.onboardingService.ListGraphVersions(r.Context(), graphID)
+	if err != nil {
+		h.logger.WithError(err).WithField("graph_id", graphID).Error("Failed to list graph versions")
+		http.Error(w, "Graph not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(versions)
+}
+
+// GetGraphVersion handles getting a specific revision of a graph
+func (h *Handlers) GetGraphVersion(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	graphID := vars["id"]
+
+	revision, err := strconv.Atoi(vars["revision"])
+	if err != nil {
+		http.Error(w, "Invalid revision", http.StatusBadRequest)
+		return
+	}
+
+	graph, err := h.onboardingService.GetGraphVersion(r.Context(), graphID, revision)
+	if err != nil {
+		h.logger.WithError(err).WithFields(logrus.Fields{
+			"graph_id": graphID,
+			"revision": revision,
+		}).Error("Failed to get graph version")
+		http.Error(w, "Graph version not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(graph)
+}
+
+// SetDefaultGraphVersion handles marking a revision as the default for new sessions
+func (h *Handlers) SetDefaultGraphVersion(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	graphID := vars["id"]
+
+	revision, err := strconv.Atoi(vars["revision"])
+	if err != nil {
+		http.Error(w, "Invalid revision", http.StatusBadRequest)
+		return
+	}
+
+	graph, err := h.onboardingService.SetDefaultGraphVersion(r.Context(), graphID, revision)
+	if err != nil {
+		h.logger.WithError(err).WithFields(logrus.Fields{
+			"graph_id": graphID,
+			"revision": revision,
+		}).Error("Failed to set default graph version")
+		http.Error(w, "Graph version not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(graph)
+}
+
+// DiffGraphVersions handles comparing two revisions of a graph
+func (h *Handlers) DiffGraphVersions(w http.ResponseWriter, r *http.Request) {
+	graphID := mux.Vars(r)["id"]
+	query := r.URL.Query()
+
+	from, fromErr := strconv.Atoi(query.Get("from"))
+	to, toErr := strconv.Atoi(query.Get("to"))
+	if fromErr != nil || toErr != nil {
+		http.Error(w, "from and to revisions are required", http.StatusBadRequest)
+		return
+	}
+
+	diff, err := h.onboardingService.DiffGraphVersions(r.Context(), graphID, from, to)
+	if err != nil {
+		h.logger.WithError(err).WithField("graph_id", graphID).Error("Failed to diff graph versions")
+		http.Error(w, "Graph version not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(diff)
+}
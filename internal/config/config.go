@@ -3,6 +3,7 @@ package config
 import (
 	"os"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -12,6 +13,7 @@ type Config struct {
 	Database   DatabaseConfig
 	Redis      RedisConfig
 	Onboarding OnboardingConfig
+	Webhooks   WebhookConfig
 }
 
 // ServerConfig holds HTTP server configuration
@@ -22,28 +24,57 @@ type ServerConfig struct {
 
 // DatabaseConfig holds database connection configuration
 type DatabaseConfig struct {
-	Host     string
-	Port     int
-	User     string
-	Password string
-	DBName   string
-	SSLMode  string
+	Driver     string // postgres, sqlite or memory; empty picks postgres when configured, else memory
+	SQLitePath string // Database file used by the sqlite driver
+	Host       string
+	Port       int
+	User       string
+	Password   string
+	DBName     string
+	SSLMode    string
 }
 
 // RedisConfig holds Redis configuration
 type RedisConfig struct {
-	Host     string
-	Port     int
-	Password string
-	DB       int
+	Host       string
+	Port       int
+	Password   string
+	DB         int
+	SessionTTL time.Duration // How long a cached session lives, 0 disables session caching
+	GraphTTL   time.Duration // How long a cached graph lives, 0 disables graph caching
+	LocalTTL   time.Duration // How long an instance keeps its own copy of a cache entry, 0 disables
 }
 
 // OnboardingConfig holds onboarding-specific configuration
 type OnboardingConfig struct {
 	MaxRetries      int
 	RetryDelay      time.Duration
-	SessionTimeout  time.Duration
-	ValidationRules string // Path to validation rules file
+	SessionTimeout  time.Duration // Inactivity after which a session expires, 0 disables
+	PauseAfter      time.Duration // Inactivity after which a session is paused, 0 disables
+	SweepInterval   time.Duration // How often idle sessions are paused and expired, 0 disables
+	ResumeSecret    string        // Key resume tokens are signed with; a random key is used when empty
+	ResumeTokenTTL  time.Duration // How long a resume token issued on pause stays valid
+	ValidationRules string        // Path to validation rules file
+	GraphsDir       string        // Directory of YAML/JSON graph definitions loaded at startup
+	GraphsReload    time.Duration // Poll interval for reloading graph definitions, 0 disables
+}
+
+// WebhookConfig holds webhook delivery configuration
+type WebhookConfig struct {
+	URLs          []string      // Endpoints every delivered event is posted to
+	Secret        string        // Key payloads are signed with
+	Events        []string      // Event types to deliver; empty delivers all
+	MaxAttempts   int           // Attempts before a message is moved to the dead-letter queue
+	RetryBackoff  time.Duration // Delay before the first retry; doubles with every attempt
+	PollInterval  time.Duration // How often the outbox is checked for due messages, 0 disables delivery
+	Timeout       time.Duration // Timeout for a single delivery request
+	Retention     time.Duration // How long delivered messages are kept in the outbox, 0 keeps them
+	DeadRetention time.Duration // How long dead-lettered messages are kept for replay, 0 keeps them
+}
+
+// Enabled reports whether webhooks are delivered. Without delivery, events are not added to the outbox.
+func (c WebhookConfig) Enabled() bool {
+	return len(c.URLs) > 0 && c.PollInterval > 0
 }
 
 // Load loads configuration from environment variables
@@ -54,24 +85,46 @@ func Load() (*Config, error) {
 			Timeout: getDurationEnv("SERVER_TIMEOUT", 30*time.Second),
 		},
 		Database: DatabaseConfig{
-			Host:     getEnv("DB_HOST", ""),
-			Port:     getIntEnv("DB_PORT", 5432),
-			User:     getEnv("DB_USER", ""),
-			Password: getEnv("DB_PASSWORD", ""),
-			DBName:   getEnv("DB_NAME", ""),
-			SSLMode:  getEnv("DB_SSLMODE", "disable"),
+			Driver:     getEnv("DB_DRIVER", ""),
+			SQLitePath: getEnv("SQLITE_PATH", "./data/onboarding.db"),
+			Host:       getEnv("DB_HOST", ""),
+			Port:       getIntEnv("DB_PORT", 5432),
+			User:       getEnv("DB_USER", ""),
+			Password:   getEnv("DB_PASSWORD", ""),
+			DBName:     getEnv("DB_NAME", ""),
+			SSLMode:    getEnv("DB_SSLMODE", "disable"),
 		},
 		Redis: RedisConfig{
-			Host:     getEnv("REDIS_HOST", "localhost"),
-			Port:     getIntEnv("REDIS_PORT", 6379),
-			Password: getEnv("REDIS_PASSWORD", ""),
-			DB:       getIntEnv("REDIS_DB", 0),
+			Host:       getEnv("REDIS_HOST", "localhost"),
+			Port:       getIntEnv("REDIS_PORT", 6379),
+			Password:   getEnv("REDIS_PASSWORD", ""),
+			DB:         getIntEnv("REDIS_DB", 0),
+			SessionTTL: getDurationEnv("REDIS_SESSION_TTL", 24*time.Hour),
+			GraphTTL:   getDurationEnv("REDIS_GRAPH_TTL", 24*time.Hour),
+			LocalTTL:   getDurationEnv("REDIS_LOCAL_CACHE_TTL", 30*time.Second),
 		},
 		Onboarding: OnboardingConfig{
 			MaxRetries:      getIntEnv("ONBOARDING_MAX_RETRIES", 3),
 			RetryDelay:      getDurationEnv("ONBOARDING_RETRY_DELAY", 5*time.Second),
 			SessionTimeout:  getDurationEnv("ONBOARDING_SESSION_TIMEOUT", 24*time.Hour),
+			PauseAfter:      getDurationEnv("ONBOARDING_SESSION_PAUSE_AFTER", 0),
+			SweepInterval:   getDurationEnv("ONBOARDING_SESSION_SWEEP_INTERVAL", 5*time.Minute),
+			ResumeSecret:    getEnv("ONBOARDING_RESUME_TOKEN_SECRET", ""),
+			ResumeTokenTTL:  getDurationEnv("ONBOARDING_RESUME_TOKEN_TTL", 7*24*time.Hour),
 			ValidationRules: getEnv("VALIDATION_RULES_PATH", "./config/validation_rules.yaml"),
+			GraphsDir:       getEnv("ONBOARDING_GRAPHS_DIR", "./config/graphs"),
+			GraphsReload:    getDurationEnv("ONBOARDING_GRAPHS_RELOAD_INTERVAL", 0),
+		},
+		Webhooks: WebhookConfig{
+			URLs:          getListEnv("WEBHOOK_URLS"),
+			Secret:        getEnv("WEBHOOK_SECRET", ""),
+			Events:        getListEnv("WEBHOOK_EVENTS"),
+			MaxAttempts:   getIntEnv("WEBHOOK_MAX_ATTEMPTS", 8),
+			RetryBackoff:  getDurationEnv("WEBHOOK_RETRY_BACKOFF", 30*time.Second),
+			PollInterval:  getDurationEnv("WEBHOOK_POLL_INTERVAL", 5*time.Second),
+			Timeout:       getDurationEnv("WEBHOOK_TIMEOUT", 10*time.Second),
+			Retention:     getDurationEnv("WEBHOOK_RETENTION", 24*time.Hour),
+			DeadRetention: getDurationEnv("WEBHOOK_DEAD_RETENTION", 30*24*time.Hour),
 		},
 	}
 
@@ -102,3 +155,14 @@ func getDurationEnv(key string, defaultValue time.Duration) time.Duration {
 	}
 	return defaultValue
 }
+
+// getListEnv returns the comma-separated values of key, skipping empty ones
+func getListEnv(key string) []string {
+	var values []string
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
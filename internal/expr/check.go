@@ -0,0 +1,178 @@
+package expr
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// builtin describes a function callable from expressions
+type builtin struct {
+	params []Type // Any accepts every type
+	result Type
+	call   func(args []interface{}) (interface{}, error)
+}
+
+// check type-checks a syntax tree against env and returns its static type
+func check(n node, env Env) (Type, error) {
+	switch n := n.(type) {
+	case *literalNode:
+		return typeOfValue(n.value), nil
+
+	case *identNode:
+		if env == nil {
+			return Any, nil
+		}
+		t, ok := env[n.name]
+		if !ok {
+			return Any, &Error{Pos: n.pos, Msg: fmt.Sprintf("unknown identifier %q", n.name)}
+		}
+		return t, nil
+
+	case *listNode:
+		for _, item := range n.items {
+			if _, err := check(item, env); err != nil {
+				return Any, err
+			}
+		}
+		return List, nil
+
+	case *unaryNode:
+		t, err := check(n.operand, env)
+		if err != nil {
+			return Any, err
+		}
+		if n.op == "!" {
+			if !compatible(t, Bool) {
+				return Any, &Error{Pos: n.pos, Msg: fmt.Sprintf("operator ! expects bool, got %s", t)}
+			}
+			return Bool, nil
+		}
+		if !compatible(t, Number) {
+			return Any, &Error{Pos: n.pos, Msg: fmt.Sprintf("operator - expects number, got %s", t)}
+		}
+		return Number, nil
+
+	case *binaryNode:
+		return checkBinary(n, env)
+
+	case *callNode:
+		fn, ok := builtins[n.name]
+		if !ok {
+			return Any, &Error{Pos: n.pos, Msg: fmt.Sprintf("unknown function %q", n.name)}
+		}
+		if len(n.args) != len(fn.params) {
+			return Any, &Error{Pos: n.pos, Msg: fmt.Sprintf("%s expects %d argument(s), got %d", n.name, len(fn.params), len(n.args))}
+		}
+		for i, arg := range n.args {
+			t, err := check(arg, env)
+			if err != nil {
+				return Any, err
+			}
+			if !compatible(t, fn.params[i]) {
+				return Any, &Error{Pos: arg.position(), Msg: fmt.Sprintf("argument %d of %s expects %s, got %s", i+1, n.name, fn.params[i], t)}
+			}
+		}
+		if n.name == "matches" {
+			if lit, ok := n.args[1].(*literalNode); ok {
+				if _, err := regexp.Compile(fmt.Sprint(lit.value)); err != nil {
+					return Any, &Error{Pos: lit.pos, Msg: fmt.Sprintf("invalid pattern: %v", err)}
+				}
+			}
+		}
+		return fn.result, nil
+	}
+
+	return Any, &Error{Pos: n.position(), Msg: "unsupported expression"}
+}
+
+// checkBinary type-checks an infix operation
+func checkBinary(n *binaryNode, env Env) (Type, error) {
+	left, err := check(n.left, env)
+	if err != nil {
+		return Any, err
+	}
+	right, err := check(n.right, env)
+	if err != nil {
+		return Any, err
+	}
+
+	mismatch := func() (Type, error) {
+		return Any, &Error{Pos: n.pos, Msg: fmt.Sprintf("operator %s cannot be applied to %s and %s", n.op, left, right)}
+	}
+
+	switch n.op {
+	case "&&", "||":
+		if !compatible(left, Bool) || !compatible(right, Bool) {
+			return mismatch()
+		}
+		return Bool, nil
+
+	case "==", "!=":
+		if left == Null || right == Null || comparable(left, right) {
+			return Bool, nil
+		}
+		return mismatch()
+
+	case "<", "<=", ">", ">=":
+		if left == Bool || right == Bool || left == List || right == List || !comparable(left, right) {
+			return mismatch()
+		}
+		return Bool, nil
+
+	case "in", "not in":
+		if right != Any && right != List && right != String {
+			return mismatch()
+		}
+		return Bool, nil
+
+	case "+":
+		switch {
+		case left == String && right == String:
+			return String, nil
+		case compatible(left, Number) && compatible(right, Number) && (left == Number || right == Number):
+			return Number, nil
+		case left == Any || right == Any:
+			return Any, nil
+		}
+		return mismatch()
+
+	case "-", "*", "/", "%":
+		if !compatible(left, Number) || !compatible(right, Number) {
+			return mismatch()
+		}
+		return Number, nil
+	}
+
+	return mismatch()
+}
+
+// compatible reports whether a value of type got may be used where want is expected
+func compatible(got, want Type) bool {
+	return got == Any || want == Any || got == want
+}
+
+// comparable reports whether two types may be compared; numeric strings
+// compare against numbers because form data usually arrives as text
+func comparable(a, b Type) bool {
+	if compatible(a, b) {
+		return true
+	}
+	return (a == String && b == Number) || (a == Number && b == String)
+}
+
+// typeOfValue returns the static type of a runtime value
+func typeOfValue(v interface{}) Type {
+	switch v.(type) {
+	case nil:
+		return Null
+	case bool:
+		return Bool
+	case float64, float32, int, int32, int64:
+		return Number
+	case string:
+		return String
+	case []interface{}, []string:
+		return List
+	}
+	return Any
+}
@@ -0,0 +1,379 @@
+package expr
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// evaluator walks a syntax tree against a data map
+type evaluator struct {
+	data map[string]interface{}
+}
+
+// builtins are the functions available to every expression
+var builtins = map[string]builtin{
+	"len": {params: []Type{Any}, result: Number, call: func(args []interface{}) (interface{}, error) {
+		switch v := args[0].(type) {
+		case nil:
+			return float64(0), nil
+		case string:
+			return float64(len([]rune(v))), nil
+		case []interface{}:
+			return float64(len(v)), nil
+		case []string:
+			return float64(len(v)), nil
+		case map[string]interface{}:
+			return float64(len(v)), nil
+		}
+		return float64(len([]rune(toString(args[0])))), nil
+	}},
+	"lower": {params: []Type{String}, result: String, call: func(args []interface{}) (interface{}, error) {
+		return strings.ToLower(toString(args[0])), nil
+	}},
+	"upper": {params: []Type{String}, result: String, call: func(args []interface{}) (interface{}, error) {
+		return strings.ToUpper(toString(args[0])), nil
+	}},
+	"trim": {params: []Type{String}, result: String, call: func(args []interface{}) (interface{}, error) {
+		return strings.TrimSpace(toString(args[0])), nil
+	}},
+	"matches": {params: []Type{String, String}, result: Bool, call: func(args []interface{}) (interface{}, error) {
+		re, err := compilePattern(toString(args[1]))
+		if err != nil {
+			return nil, err
+		}
+		return re.MatchString(toString(args[0])), nil
+	}},
+	"contains": {params: []Type{Any, Any}, result: Bool, call: func(args []interface{}) (interface{}, error) {
+		return contains(args[0], args[1]), nil
+	}},
+	"starts_with": {params: []Type{String, String}, result: Bool, call: func(args []interface{}) (interface{}, error) {
+		return strings.HasPrefix(toString(args[0]), toString(args[1])), nil
+	}},
+	"ends_with": {params: []Type{String, String}, result: Bool, call: func(args []interface{}) (interface{}, error) {
+		return strings.HasSuffix(toString(args[0]), toString(args[1])), nil
+	}},
+	"exists": {params: []Type{Any}, result: Bool, call: func(args []interface{}) (interface{}, error) {
+		if s, ok := args[0].(string); ok {
+			return strings.TrimSpace(s) != "", nil
+		}
+		return args[0] != nil, nil
+	}},
+	"number": {params: []Type{Any}, result: Number, call: func(args []interface{}) (interface{}, error) {
+		n, ok := toNumber(args[0])
+		if !ok {
+			return nil, fmt.Errorf("cannot convert %v to number", args[0])
+		}
+		return n, nil
+	}},
+	"string": {params: []Type{Any}, result: String, call: func(args []interface{}) (interface{}, error) {
+		return toString(args[0]), nil
+	}},
+}
+
+// patternCache avoids recompiling regular expressions on every evaluation
+var (
+	patternCache = make(map[string]*regexp.Regexp)
+	patternMutex sync.Mutex
+)
+
+func compilePattern(pattern string) (*regexp.Regexp, error) {
+	patternMutex.Lock()
+	defer patternMutex.Unlock()
+
+	if re, ok := patternCache[pattern]; ok {
+		return re, nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+	}
+	patternCache[pattern] = re
+	return re, nil
+}
+
+func (ev *evaluator) eval(n node) (interface{}, error) {
+	switch n := n.(type) {
+	case *literalNode:
+		return n.value, nil
+
+	case *identNode:
+		return normalize(ev.data[n.name]), nil
+
+	case *listNode:
+		items := make([]interface{}, 0, len(n.items))
+		for _, item := range n.items {
+			v, err := ev.eval(item)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, v)
+		}
+		return items, nil
+
+	case *unaryNode:
+		v, err := ev.eval(n.operand)
+		if err != nil {
+			return nil, err
+		}
+		if n.op == "!" {
+			return !truthy(v), nil
+		}
+		number, ok := toNumber(v)
+		if !ok {
+			return nil, &Error{Pos: n.pos, Msg: fmt.Sprintf("cannot negate %v", v)}
+		}
+		return -number, nil
+
+	case *binaryNode:
+		return ev.evalBinary(n)
+
+	case *callNode:
+		fn := builtins[n.name]
+		args := make([]interface{}, 0, len(n.args))
+		for _, arg := range n.args {
+			v, err := ev.eval(arg)
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, v)
+		}
+		result, err := fn.call(args)
+		if err != nil {
+			return nil, &Error{Pos: n.pos, Msg: err.Error()}
+		}
+		return result, nil
+	}
+
+	return nil, &Error{Pos: n.position(), Msg: "unsupported expression"}
+}
+
+func (ev *evaluator) evalBinary(n *binaryNode) (interface{}, error) {
+	left, err := ev.eval(n.left)
+	if err != nil {
+		return nil, err
+	}
+
+	// Short-circuit logical operators
+	switch n.op {
+	case "&&":
+		if !truthy(left) {
+			return false, nil
+		}
+		right, err := ev.eval(n.right)
+		if err != nil {
+			return nil, err
+		}
+		return truthy(right), nil
+	case "||":
+		if truthy(left) {
+			return true, nil
+		}
+		right, err := ev.eval(n.right)
+		if err != nil {
+			return nil, err
+		}
+		return truthy(right), nil
+	}
+
+	right, err := ev.eval(n.right)
+	if err != nil {
+		return nil, err
+	}
+
+	switch n.op {
+	case "==":
+		return equal(left, right), nil
+	case "!=":
+		return !equal(left, right), nil
+	case "<", "<=", ">", ">=":
+		cmp, ok := compare(left, right)
+		if !ok {
+			return false, nil
+		}
+		switch n.op {
+		case "<":
+			return cmp < 0, nil
+		case "<=":
+			return cmp <= 0, nil
+		case ">":
+			return cmp > 0, nil
+		default:
+			return cmp >= 0, nil
+		}
+	case "in":
+		return contains(right, left), nil
+	case "not in":
+		return !contains(right, left), nil
+	case "+":
+		ls, lok := left.(string)
+		rs, rok := right.(string)
+		if lok && rok {
+			return ls + rs, nil
+		}
+		return arithmetic(n, left, right)
+	case "-", "*", "/", "%":
+		return arithmetic(n, left, right)
+	}
+
+	return nil, &Error{Pos: n.pos, Msg: fmt.Sprintf("unsupported operator %s", n.op)}
+}
+
+// arithmetic applies a numeric operator
+func arithmetic(n *binaryNode, left, right interface{}) (interface{}, error) {
+	l, lok := toNumber(left)
+	r, rok := toNumber(right)
+	if !lok || !rok {
+		return nil, &Error{Pos: n.pos, Msg: fmt.Sprintf("operator %s expects numbers, got %v and %v", n.op, left, right)}
+	}
+
+	switch n.op {
+	case "+":
+		return l + r, nil
+	case "-":
+		return l - r, nil
+	case "*":
+		return l * r, nil
+	case "/":
+		if r == 0 {
+			return nil, &Error{Pos: n.pos, Msg: "division by zero"}
+		}
+		return l / r, nil
+	default:
+		if r == 0 {
+			return nil, &Error{Pos: n.pos, Msg: "division by zero"}
+		}
+		return math.Mod(l, r), nil
+	}
+}
+
+// normalize converts integer and []string values to the evaluator's canonical forms
+func normalize(v interface{}) interface{} {
+	switch v := v.(type) {
+	case int:
+		return float64(v)
+	case int32:
+		return float64(v)
+	case int64:
+		return float64(v)
+	case float32:
+		return float64(v)
+	case []string:
+		items := make([]interface{}, len(v))
+		for i, s := range v {
+			items[i] = s
+		}
+		return items
+	}
+	return v
+}
+
+// truthy interprets a value as a boolean; nil and empty values are false
+func truthy(v interface{}) bool {
+	switch v := v.(type) {
+	case nil:
+		return false
+	case bool:
+		return v
+	case float64:
+		return v != 0
+	case string:
+		return v != "" && v != "false"
+	case []interface{}:
+		return len(v) > 0
+	}
+	return true
+}
+
+// toNumber converts numbers and numeric strings to float64
+func toNumber(v interface{}) (float64, bool) {
+	switch v := normalize(v).(type) {
+	case float64:
+		return v, true
+	case string:
+		n, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		return n, err == nil
+	}
+	return 0, false
+}
+
+// toString renders a value as text
+func toString(v interface{}) string {
+	switch v := normalize(v).(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return fmt.Sprintf("%v", v)
+}
+
+// equal compares two values, treating numeric strings as numbers
+func equal(a, b interface{}) bool {
+	a, b = normalize(a), normalize(b)
+
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+
+	_, aIsNum := a.(float64)
+	_, bIsNum := b.(float64)
+	if aIsNum || bIsNum {
+		an, aok := toNumber(a)
+		bn, bok := toNumber(b)
+		if aok && bok {
+			return an == bn
+		}
+		return false
+	}
+
+	if ab, ok := a.(bool); ok {
+		bb, ok := b.(bool)
+		return ok && ab == bb
+	}
+
+	return toString(a) == toString(b)
+}
+
+// compare orders two values numerically when possible, otherwise lexically
+func compare(a, b interface{}) (int, bool) {
+	an, aok := toNumber(a)
+	bn, bok := toNumber(b)
+	if aok && bok {
+		switch {
+		case an < bn:
+			return -1, true
+		case an > bn:
+			return 1, true
+		}
+		return 0, true
+	}
+
+	as, aok := normalize(a).(string)
+	bs, bok := normalize(b).(string)
+	if aok && bok {
+		return strings.Compare(as, bs), true
+	}
+	return 0, false
+}
+
+// contains reports whether container (a list or string) holds item
+func contains(container, item interface{}) bool {
+	switch c := normalize(container).(type) {
+	case []interface{}:
+		for _, element := range c {
+			if equal(element, item) {
+				return true
+			}
+		}
+		return false
+	case string:
+		return item != nil && strings.Contains(c, toString(item))
+	}
+	return false
+}
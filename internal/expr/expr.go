@@ -0,0 +1,163 @@
+// Package expr implements the small expression language used for custom
+// validation rules, edge conditions and cross-node logic.
+//
+// Expressions are compiled (parsed and type-checked) once when a graph is
+// saved and then evaluated against session data, e.g.
+//
+//	len(pan_number) == 10 && business_type in ["llp", "trust"]
+package expr
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Type is the static type of an expression or identifier
+type Type int
+
+const (
+	Any Type = iota
+	Bool
+	Number
+	String
+	List
+	Null
+)
+
+func (t Type) String() string {
+	switch t {
+	case Bool:
+		return "bool"
+	case Number:
+		return "number"
+	case String:
+		return "string"
+	case List:
+		return "list"
+	case Null:
+		return "null"
+	default:
+		return "any"
+	}
+}
+
+// Env declares the identifiers available to an expression and their types.
+// A nil Env accepts any identifier with type Any.
+type Env map[string]Type
+
+// Error describes a syntax or type error at a position in the source
+type Error struct {
+	Source string
+	Pos    int
+	Msg    string
+}
+
+func (e *Error) Error() string {
+	if e.Source == "" {
+		return fmt.Sprintf("%s at position %d", e.Msg, e.Pos)
+	}
+	return fmt.Sprintf("%s at position %d in %q", e.Msg, e.Pos, e.Source)
+}
+
+// Program is a compiled expression ready for evaluation
+type Program struct {
+	source string
+	root   node
+}
+
+// Compile parses and type-checks an expression against env
+func Compile(source string, env Env) (*Program, error) {
+	root, err := parse(source)
+	if err != nil {
+		return nil, withSource(err, source)
+	}
+
+	resultType, err := check(root, env)
+	if err != nil {
+		return nil, withSource(err, source)
+	}
+	if resultType != Bool && resultType != Any {
+		return nil, &Error{Source: source, Pos: 0, Msg: fmt.Sprintf("expression must evaluate to bool, got %s", resultType)}
+	}
+
+	return &Program{source: source, root: root}, nil
+}
+
+// Source returns the original expression text
+func (p *Program) Source() string {
+	return p.source
+}
+
+// Eval evaluates the program against data and returns the raw result
+func (p *Program) Eval(data map[string]interface{}) (interface{}, error) {
+	ev := &evaluator{data: data}
+	result, err := ev.eval(p.root)
+	if err != nil {
+		return nil, withSource(err, p.source)
+	}
+	return result, nil
+}
+
+// EvalBool evaluates the program and interprets the result as a boolean
+func (p *Program) EvalBool(data map[string]interface{}) (bool, error) {
+	result, err := p.Eval(data)
+	if err != nil {
+		return false, err
+	}
+	return truthy(result), nil
+}
+
+// IsIdentifier reports whether s is a bare identifier such as a named rule
+func IsIdentifier(s string) bool {
+	tokens, err := lex(s)
+	if err != nil {
+		return false
+	}
+	return len(tokens) == 2 && tokens[0].kind == tokenIdent
+}
+
+// withSource attaches the expression text to an *Error
+func withSource(err error, source string) error {
+	if exprErr, ok := err.(*Error); ok {
+		return &Error{Source: source, Pos: exprErr.Pos, Msg: exprErr.Msg}
+	}
+	return err
+}
+
+// Cache memoizes compiled programs by source text
+type Cache struct {
+	programs map[string]*Program
+	mutex    sync.RWMutex
+}
+
+// NewCache creates an empty program cache
+func NewCache() *Cache {
+	return &Cache{programs: make(map[string]*Program)}
+}
+
+// Get returns a previously compiled program
+func (c *Cache) Get(source string) (*Program, bool) {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	program, ok := c.programs[source]
+	return program, ok
+}
+
+// Compile returns the cached program for source, compiling it with a nil Env if needed
+func (c *Cache) Compile(source string) (*Program, error) {
+	if program, ok := c.Get(source); ok {
+		return program, nil
+	}
+
+	program, err := Compile(source, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mutex.Lock()
+	c.programs[source] = program
+	c.mutex.Unlock()
+
+	return program, nil
+}
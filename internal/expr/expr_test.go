@@ -0,0 +1,116 @@
+package expr
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEval(t *testing.T) {
+	data := map[string]interface{}{
+		"pan_number":     "ABCDE1234F",
+		"business_type":  "llp",
+		"annual_revenue": "250000",
+		"employees":      12,
+		"channels":       []interface{}{"website", "app"},
+		"tags":           []string{"priority"},
+		"empty":          "",
+	}
+
+	tests := []struct {
+		name   string
+		source string
+		want   bool
+	}{
+		{"length and membership", `len(pan_number) == 10 && business_type in ["llp","trust"]`, true},
+		{"not in", `business_type not in ["individual"]`, true},
+		{"keyword operators", `business_type == "llp" and not (employees < 10)`, true},
+		{"or short circuit", `business_type == "llp" || number(missing) > 0`, true},
+		{"numeric string comparison", `annual_revenue >= 100000`, true},
+		{"int data", `employees == 12`, true},
+		{"arithmetic", `employees * 2 - 4 == 20`, true},
+		{"list membership", `"app" in channels`, true},
+		{"string slice membership", `"priority" in tags`, true},
+		{"substring", `"BCD" in pan_number`, true},
+		{"matches", `matches(pan_number, "^[A-Z]{5}[0-9]{4}[A-Z]$")`, true},
+		{"string functions", `lower(pan_number) == "abcde1234f" && starts_with(pan_number, "ABC") && ends_with(pan_number, "F")`, true},
+		{"contains", `contains(channels, "website")`, true},
+		{"missing is null", `missing == null`, true},
+		{"exists", `exists(pan_number) && !exists(empty) && !exists(missing)`, true},
+		{"missing is falsy", `missing`, false},
+		{"mismatch", `business_type == "trust"`, false},
+		{"concatenation", `business_type + "_entity" == "llp_entity"`, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			program, err := Compile(tt.source, nil)
+			require.NoError(t, err)
+
+			got, err := program.EvalBool(data)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	env := Env{
+		"pan_number":    String,
+		"business_type": String,
+		"employees":     Number,
+		"is_verified":   Bool,
+	}
+
+	tests := []struct {
+		name   string
+		source string
+	}{
+		{"syntax error", `len(pan_number == 10`},
+		{"unterminated string", `business_type == "llp`},
+		{"unknown identifier", `pan_numbr == "x"`},
+		{"unknown function", `size(pan_number) == 10`},
+		{"wrong arity", `len(pan_number, 1) == 10`},
+		{"bool vs string", `is_verified == "yes"`},
+		{"non-bool result", `len(pan_number)`},
+		{"ordering on bool", `is_verified > 1`},
+		{"logic on number", `employees && is_verified`},
+		{"invalid regex", `matches(pan_number, "[A-Z")`},
+		{"dangling not", `business_type not "llp"`},
+		{"trailing tokens", `is_verified is_verified`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Compile(tt.source, env)
+			require.Error(t, err)
+
+			var exprErr *Error
+			assert.ErrorAs(t, err, &exprErr)
+			assert.Equal(t, tt.source, exprErr.Source)
+		})
+	}
+}
+
+func TestIsIdentifier(t *testing.T) {
+	assert.True(t, IsIdentifier("pan_validation"))
+	assert.True(t, IsIdentifier("  gst_validation "))
+	assert.False(t, IsIdentifier("len(pan_number) == 10"))
+	assert.False(t, IsIdentifier("not_valid && other"))
+	assert.False(t, IsIdentifier(""))
+}
+
+func TestCache(t *testing.T) {
+	cache := NewCache()
+
+	first, err := cache.Compile(`business_type == "llp"`)
+	require.NoError(t, err)
+
+	second, err := cache.Compile(`business_type == "llp"`)
+	require.NoError(t, err)
+	assert.Same(t, first, second)
+
+	_, err = cache.Compile(`business_type ==`)
+	assert.Error(t, err)
+}
@@ -0,0 +1,168 @@
+package expr
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// tokenKind identifies the lexical class of a token
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdent
+	tokenNumber
+	tokenString
+	tokenOperator
+	tokenLParen
+	tokenRParen
+	tokenLBracket
+	tokenRBracket
+	tokenComma
+)
+
+// token is a single lexical element of an expression
+type token struct {
+	kind  tokenKind
+	text  string
+	value interface{} // decoded literal value for numbers and strings
+	pos   int
+}
+
+// keywords that are lexed as operators rather than identifiers
+var keywordOperators = map[string]bool{
+	"in":  true,
+	"not": true,
+	"and": true,
+	"or":  true,
+}
+
+// lex splits an expression into tokens
+func lex(source string) ([]token, error) {
+	tokens := make([]token, 0)
+	runes := []rune(source)
+	i := 0
+
+	for i < len(runes) {
+		r := runes[i]
+
+		switch {
+		case unicode.IsSpace(r):
+			i++
+
+		case r == '(':
+			tokens = append(tokens, token{kind: tokenLParen, text: "(", pos: i})
+			i++
+		case r == ')':
+			tokens = append(tokens, token{kind: tokenRParen, text: ")", pos: i})
+			i++
+		case r == '[':
+			tokens = append(tokens, token{kind: tokenLBracket, text: "[", pos: i})
+			i++
+		case r == ']':
+			tokens = append(tokens, token{kind: tokenRBracket, text: "]", pos: i})
+			i++
+		case r == ',':
+			tokens = append(tokens, token{kind: tokenComma, text: ",", pos: i})
+			i++
+
+		case r == '"' || r == '\'':
+			start := i
+			text, next, err := lexString(runes, i)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, token{kind: tokenString, text: string(runes[start:next]), value: text, pos: start})
+			i = next
+
+		case unicode.IsDigit(r):
+			start := i
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.') {
+				i++
+			}
+			text := string(runes[start:i])
+			number, err := strconv.ParseFloat(text, 64)
+			if err != nil {
+				return nil, &Error{Pos: start, Msg: fmt.Sprintf("invalid number %q", text)}
+			}
+			tokens = append(tokens, token{kind: tokenNumber, text: text, value: number, pos: start})
+
+		case unicode.IsLetter(r) || r == '_':
+			start := i
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '_') {
+				i++
+			}
+			text := string(runes[start:i])
+			if keywordOperators[text] {
+				tokens = append(tokens, token{kind: tokenOperator, text: text, pos: start})
+			} else {
+				tokens = append(tokens, token{kind: tokenIdent, text: text, pos: start})
+			}
+
+		default:
+			start := i
+			op, ok := lexOperator(runes, i)
+			if !ok {
+				return nil, &Error{Pos: start, Msg: fmt.Sprintf("unexpected character %q", r)}
+			}
+			tokens = append(tokens, token{kind: tokenOperator, text: op, pos: start})
+			i += len([]rune(op))
+		}
+	}
+
+	tokens = append(tokens, token{kind: tokenEOF, pos: len(runes)})
+	return tokens, nil
+}
+
+// lexString reads a quoted string literal starting at runes[start]
+func lexString(runes []rune, start int) (string, int, error) {
+	quote := runes[start]
+	var sb strings.Builder
+	i := start + 1
+
+	for i < len(runes) {
+		r := runes[i]
+		if r == quote {
+			return sb.String(), i + 1, nil
+		}
+		if r == '\\' && i+1 < len(runes) {
+			i++
+			switch runes[i] {
+			case 'n':
+				sb.WriteRune('\n')
+			case 't':
+				sb.WriteRune('\t')
+			default:
+				sb.WriteRune(runes[i])
+			}
+			i++
+			continue
+		}
+		sb.WriteRune(r)
+		i++
+	}
+
+	return "", 0, &Error{Pos: start, Msg: "unterminated string literal"}
+}
+
+// lexOperator reads a symbolic operator starting at runes[i]
+func lexOperator(runes []rune, i int) (string, bool) {
+	twoChar := []string{"==", "!=", "<=", ">=", "&&", "||"}
+	if i+1 < len(runes) {
+		candidate := string(runes[i : i+2])
+		for _, op := range twoChar {
+			if candidate == op {
+				return op, true
+			}
+		}
+	}
+
+	switch runes[i] {
+	case '<', '>', '!', '+', '-', '*', '/', '%':
+		return string(runes[i]), true
+	}
+
+	return "", false
+}
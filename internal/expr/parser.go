@@ -0,0 +1,324 @@
+package expr
+
+import (
+	"fmt"
+)
+
+// node is an element of the expression syntax tree
+type node interface {
+	position() int
+}
+
+// literalNode is a constant value (number, string, bool or null)
+type literalNode struct {
+	pos   int
+	value interface{}
+}
+
+// identNode references a value in the evaluation data
+type identNode struct {
+	pos  int
+	name string
+}
+
+// listNode is a list literal such as ["llp", "trust"]
+type listNode struct {
+	pos   int
+	items []node
+}
+
+// unaryNode applies a prefix operator (! or -)
+type unaryNode struct {
+	pos     int
+	op      string
+	operand node
+}
+
+// binaryNode applies an infix operator
+type binaryNode struct {
+	pos   int
+	op    string
+	left  node
+	right node
+}
+
+// callNode invokes a builtin function
+type callNode struct {
+	pos  int
+	name string
+	args []node
+}
+
+func (n *literalNode) position() int { return n.pos }
+func (n *identNode) position() int   { return n.pos }
+func (n *listNode) position() int    { return n.pos }
+func (n *unaryNode) position() int   { return n.pos }
+func (n *binaryNode) position() int  { return n.pos }
+func (n *callNode) position() int    { return n.pos }
+
+// parser is a recursive-descent parser over a token stream
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+// parse builds a syntax tree from an expression source
+func parse(source string) (node, error) {
+	tokens, err := lex(source)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+
+	if tok := p.peek(); tok.kind != tokenEOF {
+		return nil, &Error{Pos: tok.pos, Msg: fmt.Sprintf("unexpected %q", tok.text)}
+	}
+
+	return root, nil
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokenEOF {
+		p.pos++
+	}
+	return tok
+}
+
+// isOperator reports whether the next token is one of the given operators
+func (p *parser) isOperator(ops ...string) bool {
+	tok := p.peek()
+	if tok.kind != tokenOperator {
+		return false
+	}
+	for _, op := range ops {
+		if tok.text == op {
+			return true
+		}
+	}
+	return false
+}
+
+// parseOr parses a || b (also spelled "or")
+func (p *parser) parseOr() (node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.isOperator("||", "or") {
+		tok := p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryNode{pos: tok.pos, op: "||", left: left, right: right}
+	}
+
+	return left, nil
+}
+
+// parseAnd parses a && b (also spelled "and")
+func (p *parser) parseAnd() (node, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.isOperator("&&", "and") {
+		tok := p.next()
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryNode{pos: tok.pos, op: "&&", left: left, right: right}
+	}
+
+	return left, nil
+}
+
+// parseNot parses !a (also spelled "not a")
+func (p *parser) parseNot() (node, error) {
+	if p.isOperator("!", "not") {
+		tok := p.next()
+		operand, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return &unaryNode{pos: tok.pos, op: "!", operand: operand}, nil
+	}
+
+	return p.parseComparison()
+}
+
+// parseComparison parses a single (non-associative) comparison
+func (p *parser) parseComparison() (node, error) {
+	left, err := p.parseAdditive()
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case p.isOperator("==", "!=", "<", "<=", ">", ">=", "in"):
+		tok := p.next()
+		right, err := p.parseAdditive()
+		if err != nil {
+			return nil, err
+		}
+		return &binaryNode{pos: tok.pos, op: tok.text, left: left, right: right}, nil
+
+	case p.isOperator("not"):
+		tok := p.next()
+		if !p.isOperator("in") {
+			return nil, &Error{Pos: p.peek().pos, Msg: "expected \"in\" after \"not\""}
+		}
+		p.next()
+		right, err := p.parseAdditive()
+		if err != nil {
+			return nil, err
+		}
+		return &binaryNode{pos: tok.pos, op: "not in", left: left, right: right}, nil
+	}
+
+	return left, nil
+}
+
+// parseAdditive parses a + b and a - b
+func (p *parser) parseAdditive() (node, error) {
+	left, err := p.parseMultiplicative()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.isOperator("+", "-") {
+		tok := p.next()
+		right, err := p.parseMultiplicative()
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryNode{pos: tok.pos, op: tok.text, left: left, right: right}
+	}
+
+	return left, nil
+}
+
+// parseMultiplicative parses a * b, a / b and a % b
+func (p *parser) parseMultiplicative() (node, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.isOperator("*", "/", "%") {
+		tok := p.next()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryNode{pos: tok.pos, op: tok.text, left: left, right: right}
+	}
+
+	return left, nil
+}
+
+// parseUnary parses numeric negation
+func (p *parser) parseUnary() (node, error) {
+	if p.isOperator("-") {
+		tok := p.next()
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &unaryNode{pos: tok.pos, op: "-", operand: operand}, nil
+	}
+
+	return p.parsePrimary()
+}
+
+// parsePrimary parses literals, identifiers, calls, lists and parenthesised expressions
+func (p *parser) parsePrimary() (node, error) {
+	tok := p.next()
+
+	switch tok.kind {
+	case tokenNumber, tokenString:
+		return &literalNode{pos: tok.pos, value: tok.value}, nil
+
+	case tokenIdent:
+		switch tok.text {
+		case "true":
+			return &literalNode{pos: tok.pos, value: true}, nil
+		case "false":
+			return &literalNode{pos: tok.pos, value: false}, nil
+		case "null", "nil":
+			return &literalNode{pos: tok.pos, value: nil}, nil
+		}
+
+		if p.peek().kind == tokenLParen {
+			p.next()
+			args, err := p.parseArgs(tokenRParen)
+			if err != nil {
+				return nil, err
+			}
+			return &callNode{pos: tok.pos, name: tok.text, args: args}, nil
+		}
+
+		return &identNode{pos: tok.pos, name: tok.text}, nil
+
+	case tokenLBracket:
+		items, err := p.parseArgs(tokenRBracket)
+		if err != nil {
+			return nil, err
+		}
+		return &listNode{pos: tok.pos, items: items}, nil
+
+	case tokenLParen:
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.next(); closing.kind != tokenRParen {
+			return nil, &Error{Pos: closing.pos, Msg: "expected \")\""}
+		}
+		return inner, nil
+
+	case tokenEOF:
+		return nil, &Error{Pos: tok.pos, Msg: "unexpected end of expression"}
+	}
+
+	return nil, &Error{Pos: tok.pos, Msg: fmt.Sprintf("unexpected %q", tok.text)}
+}
+
+// parseArgs parses a comma-separated list of expressions terminated by closing
+func (p *parser) parseArgs(closing tokenKind) ([]node, error) {
+	args := make([]node, 0)
+
+	if p.peek().kind == closing {
+		p.next()
+		return args, nil
+	}
+
+	for {
+		arg, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, arg)
+
+		tok := p.next()
+		if tok.kind == closing {
+			return args, nil
+		}
+		if tok.kind != tokenComma {
+			return nil, &Error{Pos: tok.pos, Msg: fmt.Sprintf("expected \",\" but found %q", tok.text)}
+		}
+	}
+}
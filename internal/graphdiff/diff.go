@@ -0,0 +1,273 @@
+// Package graphdiff compares two revisions of an onboarding graph.
+package graphdiff
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+
+	"onboarding-system/internal/types"
+)
+
+// ChangeType describes how an element changed between revisions
+type ChangeType string
+
+const (
+	ChangeAdded    ChangeType = "added"
+	ChangeRemoved  ChangeType = "removed"
+	ChangeModified ChangeType = "modified"
+)
+
+// Element kinds reported in a diff
+const (
+	KindGraph          = "graph"
+	KindNode           = "node"
+	KindField          = "field"
+	KindEdge           = "edge"
+	KindRuleGroup      = "rule_group"
+	KindCrossNodeRule  = "cross_node_rule"
+	KindActivationRule = "activation_rule"
+	KindNodeRule       = "node_rule"
+)
+
+// Change is a single difference between two graph revisions
+type Change struct {
+	Type   ChangeType `json:"type"`
+	Kind   string     `json:"kind"`
+	ID     string     `json:"id"`
+	NodeID string     `json:"node_id,omitempty"`
+	Detail string     `json:"detail,omitempty"`
+}
+
+// Diff lists the changes between two graph revisions
+type Diff struct {
+	GraphID      string   `json:"graph_id"`
+	FromRevision int      `json:"from_revision"`
+	ToRevision   int      `json:"to_revision"`
+	Changes      []Change `json:"changes"`
+}
+
+// HasChanges reports whether the revisions differ
+func (d *Diff) HasChanges() bool {
+	return len(d.Changes) > 0
+}
+
+// Compare returns the changes needed to turn from into to.
+// Timestamps and derived edge tracking are ignored.
+func Compare(from, to *types.Graph) *Diff {
+	diff := &Diff{
+		GraphID:      to.ID,
+		FromRevision: from.Revision,
+		ToRevision:   to.Revision,
+		Changes:      make([]Change, 0),
+	}
+
+	if from.Name != to.Name {
+		diff.add(ChangeModified, KindGraph, "name", "", "")
+	}
+	if from.Version != to.Version {
+		diff.add(ChangeModified, KindGraph, "version", "", from.Version+" -> "+to.Version)
+	}
+	if from.StartNodeID != to.StartNodeID {
+		diff.add(ChangeModified, KindGraph, "start_node_id", "", from.StartNodeID+" -> "+to.StartNodeID)
+	}
+	if !reflect.DeepEqual(from.EntryNodes, to.EntryNodes) && (len(from.EntryNodes) > 0 || len(to.EntryNodes) > 0) {
+		diff.add(ChangeModified, KindGraph, "entry_nodes", "", strings.Join(from.EntryNodes, ",")+" -> "+strings.Join(to.EntryNodes, ","))
+	}
+
+	diff.compareNodes(from.Nodes, to.Nodes)
+	diff.compareEdges(from.Edges, to.Edges)
+	diff.compareRuleGroups(from.RuleGroups, to.RuleGroups)
+	diff.compareCrossNodeRules(from.CrossNodeValidation, to.CrossNodeValidation)
+	diff.compareActivationRules(from.ActivationRules, to.ActivationRules)
+	diff.compareNodeRules(from.NodeRules, to.NodeRules)
+
+	return diff
+}
+
+func (d *Diff) add(changeType ChangeType, kind, id, nodeID, detail string) {
+	d.Changes = append(d.Changes, Change{Type: changeType, Kind: kind, ID: id, NodeID: nodeID, Detail: detail})
+}
+
+func (d *Diff) compareNodes(from, to map[string]*types.Node) {
+	for _, id := range unionKeys(from, to) {
+		before, after := from[id], to[id]
+		switch {
+		case before == nil:
+			d.add(ChangeAdded, KindNode, id, "", after.Name)
+		case after == nil:
+			d.add(ChangeRemoved, KindNode, id, "", before.Name)
+		default:
+			if nodeChanged(before, after) {
+				d.add(ChangeModified, KindNode, id, "", after.Name)
+			}
+			d.compareFields(id, before.Fields, after.Fields)
+		}
+	}
+}
+
+// nodeChanged compares the node attributes that affect the flow, excluding fields
+func nodeChanged(before, after *types.Node) bool {
+	return before.Type != after.Type ||
+		before.Name != after.Name ||
+		before.Description != after.Description ||
+		before.IsIndependent != after.IsIndependent ||
+		before.IsDependent != after.IsDependent ||
+		!reflect.DeepEqual(before.Validation, after.Validation) ||
+		!reflect.DeepEqual(before.Dependencies, after.Dependencies)
+}
+
+func (d *Diff) compareFields(nodeID string, from, to []types.Field) {
+	before := make(map[string]types.Field, len(from))
+	for _, field := range from {
+		before[field.ID] = field
+	}
+	after := make(map[string]types.Field, len(to))
+	for _, field := range to {
+		after[field.ID] = field
+	}
+
+	for _, id := range unionKeys(before, after) {
+		b, inBefore := before[id]
+		a, inAfter := after[id]
+		switch {
+		case !inBefore:
+			d.add(ChangeAdded, KindField, id, nodeID, string(a.Type))
+		case !inAfter:
+			d.add(ChangeRemoved, KindField, id, nodeID, string(b.Type))
+		case !reflect.DeepEqual(b, a):
+			d.add(ChangeModified, KindField, id, nodeID, string(a.Type))
+		}
+	}
+}
+
+func (d *Diff) compareEdges(from, to map[string]*types.Edge) {
+	for _, id := range unionKeys(from, to) {
+		before, after := from[id], to[id]
+		switch {
+		case before == nil:
+			d.add(ChangeAdded, KindEdge, id, "", after.FromNodeID+" -> "+after.ToNodeID)
+		case after == nil:
+			d.add(ChangeRemoved, KindEdge, id, "", before.FromNodeID+" -> "+before.ToNodeID)
+		case before.FromNodeID != after.FromNodeID || before.ToNodeID != after.ToNodeID ||
+			!reflect.DeepEqual(before.Condition, after.Condition):
+			d.add(ChangeModified, KindEdge, id, "", after.FromNodeID+" -> "+after.ToNodeID)
+		}
+	}
+}
+
+func (d *Diff) compareRuleGroups(from, to []types.RuleGroup) {
+	before := make(map[string]types.RuleGroup, len(from))
+	for _, ruleGroup := range from {
+		before[ruleGroup.ID] = ruleGroup
+	}
+	after := make(map[string]types.RuleGroup, len(to))
+	for _, ruleGroup := range to {
+		after[ruleGroup.ID] = ruleGroup
+	}
+
+	for _, id := range unionKeys(before, after) {
+		b, inBefore := before[id]
+		a, inAfter := after[id]
+		switch {
+		case !inBefore:
+			d.add(ChangeAdded, KindRuleGroup, id, "", a.Name)
+		case !inAfter:
+			d.add(ChangeRemoved, KindRuleGroup, id, "", b.Name)
+		case !reflect.DeepEqual(b, a):
+			d.add(ChangeModified, KindRuleGroup, id, "", a.Name)
+		}
+	}
+}
+
+func (d *Diff) compareCrossNodeRules(from, to []types.CrossNodeValidationRule) {
+	before := make(map[string]types.CrossNodeValidationRule, len(from))
+	for _, rule := range from {
+		before[rule.ID] = rule
+	}
+	after := make(map[string]types.CrossNodeValidationRule, len(to))
+	for _, rule := range to {
+		after[rule.ID] = rule
+	}
+
+	for _, id := range unionKeys(before, after) {
+		b, inBefore := before[id]
+		a, inAfter := after[id]
+		switch {
+		case !inBefore:
+			d.add(ChangeAdded, KindCrossNodeRule, id, "", a.Name)
+		case !inAfter:
+			d.add(ChangeRemoved, KindCrossNodeRule, id, "", b.Name)
+		case !reflect.DeepEqual(b, a):
+			d.add(ChangeModified, KindCrossNodeRule, id, "", a.Name)
+		}
+	}
+}
+
+func (d *Diff) compareActivationRules(from, to []types.ActivationRule) {
+	before := make(map[string]types.ActivationRule, len(from))
+	for _, rule := range from {
+		before[rule.ID] = rule
+	}
+	after := make(map[string]types.ActivationRule, len(to))
+	for _, rule := range to {
+		after[rule.ID] = rule
+	}
+
+	for _, id := range unionKeys(before, after) {
+		b, inBefore := before[id]
+		a, inAfter := after[id]
+		switch {
+		case !inBefore:
+			d.add(ChangeAdded, KindActivationRule, id, "", a.Name)
+		case !inAfter:
+			d.add(ChangeRemoved, KindActivationRule, id, "", b.Name)
+		case !reflect.DeepEqual(b, a):
+			d.add(ChangeModified, KindActivationRule, id, "", a.Name)
+		}
+	}
+}
+
+func (d *Diff) compareNodeRules(from, to []types.NodeRule) {
+	before := make(map[string]types.NodeRule, len(from))
+	for _, rule := range from {
+		before[rule.ID] = rule
+	}
+	after := make(map[string]types.NodeRule, len(to))
+	for _, rule := range to {
+		after[rule.ID] = rule
+	}
+
+	for _, id := range unionKeys(before, after) {
+		b, inBefore := before[id]
+		a, inAfter := after[id]
+		switch {
+		case !inBefore:
+			d.add(ChangeAdded, KindNodeRule, id, a.NodeID, a.RuleType)
+		case !inAfter:
+			d.add(ChangeRemoved, KindNodeRule, id, b.NodeID, b.RuleType)
+		case !reflect.DeepEqual(b, a):
+			d.add(ChangeModified, KindNodeRule, id, a.NodeID, a.RuleType)
+		}
+	}
+}
+
+// unionKeys returns the sorted keys present in either map
+func unionKeys[V any](a, b map[string]V) []string {
+	seen := make(map[string]bool, len(a)+len(b))
+	keys := make([]string, 0, len(a)+len(b))
+	for key := range a {
+		if !seen[key] {
+			seen[key] = true
+			keys = append(keys, key)
+		}
+	}
+	for key := range b {
+		if !seen[key] {
+			seen[key] = true
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
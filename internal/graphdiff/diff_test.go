@@ -0,0 +1,130 @@
+package graphdiff
+
+import (
+	"testing"
+	"time"
+
+	"onboarding-system/internal/types"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newGraph returns a revision with one of every element a diff compares
+func newGraph(revision int) *types.Graph {
+	return &types.Graph{
+		ID:          "kyc",
+		Name:        "KYC",
+		Version:     "1.0.0",
+		Revision:    revision,
+		StartNodeID: "start",
+		Nodes: map[string]*types.Node{
+			"start": {ID: "start", Type: types.NodeTypeStart, Name: "Start", Fields: []types.Field{
+				{ID: "business_type", Name: "Business Type", Type: types.FieldTypeSelect, Options: []string{"llp"}},
+			}},
+			"pan": {ID: "pan", Type: types.NodeTypeInput, Name: "PAN", Fields: []types.Field{
+				{ID: "pan_number", Name: "PAN", Type: types.FieldTypeText, Required: true},
+			}, OutgoingEdges: []string{}},
+		},
+		Edges: map[string]*types.Edge{
+			"start_to_pan": {ID: "start_to_pan", FromNodeID: "start", ToNodeID: "pan", Condition: types.EdgeCondition{Type: "always"}},
+		},
+		RuleGroups:          []types.RuleGroup{{ID: "llp", Name: "LLP", BusinessTypes: []string{"llp"}, RequiredNodes: []string{"pan"}}},
+		CrossNodeValidation: []types.CrossNodeValidationRule{{ID: "type_match", Name: "Type match"}},
+		ActivationRules:     []types.ActivationRule{{ID: "llp_activation", Name: "LLP activation", RequiredNodes: []string{"pan"}}},
+		NodeRules:           []types.NodeRule{{ID: "pan_check", NodeID: "pan", RuleType: "validation"}},
+		CreatedAt:           time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC),
+		UpdatedAt:           time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC),
+	}
+}
+
+func TestCompareIdenticalRevisions(t *testing.T) {
+	from, to := newGraph(1), newGraph(2)
+
+	// Timestamps and edge tracking are not part of the definition
+	to.UpdatedAt = to.UpdatedAt.Add(time.Hour)
+	to.Nodes["pan"].IncomingEdges = []string{"start_to_pan"}
+
+	diff := Compare(from, to)
+	assert.False(t, diff.HasChanges(), "unexpected changes: %+v", diff.Changes)
+	assert.Equal(t, "kyc", diff.GraphID)
+	assert.Equal(t, 1, diff.FromRevision)
+	assert.Equal(t, 2, diff.ToRevision)
+}
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		name     string
+		change   func(graph *types.Graph)
+		expected []Change
+	}{
+		{"graph name", func(g *types.Graph) { g.Name = "KYC v2" },
+			[]Change{{Type: ChangeModified, Kind: KindGraph, ID: "name"}}},
+		{"graph version", func(g *types.Graph) { g.Version = "2.0.0" },
+			[]Change{{Type: ChangeModified, Kind: KindGraph, ID: "version", Detail: "1.0.0 -> 2.0.0"}}},
+		{"start node", func(g *types.Graph) { g.StartNodeID = "pan" },
+			[]Change{{Type: ChangeModified, Kind: KindGraph, ID: "start_node_id", Detail: "start -> pan"}}},
+		{"entry nodes", func(g *types.Graph) { g.EntryNodes = []string{"start", "pan"} },
+			[]Change{{Type: ChangeModified, Kind: KindGraph, ID: "entry_nodes", Detail: " -> start,pan"}}},
+		{"node added", func(g *types.Graph) { g.Nodes["gst"] = &types.Node{ID: "gst", Name: "GST"} },
+			[]Change{{Type: ChangeAdded, Kind: KindNode, ID: "gst", Detail: "GST"}}},
+		{"node removed with its edge", func(g *types.Graph) {
+			delete(g.Nodes, "pan")
+			delete(g.Edges, "start_to_pan")
+		}, []Change{
+			{Type: ChangeRemoved, Kind: KindNode, ID: "pan", Detail: "PAN"},
+			{Type: ChangeRemoved, Kind: KindEdge, ID: "start_to_pan", Detail: "start -> pan"},
+		}},
+		{"node modified", func(g *types.Graph) { g.Nodes["pan"].Validation.RequiredFields = []string{"pan_number"} },
+			[]Change{{Type: ChangeModified, Kind: KindNode, ID: "pan", Detail: "PAN"}}},
+		{"field added", func(g *types.Graph) {
+			g.Nodes["pan"].Fields = append(g.Nodes["pan"].Fields, types.Field{ID: "pan_name", Type: types.FieldTypeText})
+		}, []Change{{Type: ChangeAdded, Kind: KindField, ID: "pan_name", NodeID: "pan", Detail: "text"}}},
+		{"field removed", func(g *types.Graph) { g.Nodes["pan"].Fields = nil },
+			[]Change{{Type: ChangeRemoved, Kind: KindField, ID: "pan_number", NodeID: "pan", Detail: "text"}}},
+		{"field modified", func(g *types.Graph) { g.Nodes["start"].Fields[0].Options = []string{"llp", "trust"} },
+			[]Change{{Type: ChangeModified, Kind: KindField, ID: "business_type", NodeID: "start", Detail: "select"}}},
+		{"edge condition", func(g *types.Graph) {
+			g.Edges["start_to_pan"].Condition = types.EdgeCondition{Type: "field_value", Field: "business_type", Value: "llp"}
+		}, []Change{{Type: ChangeModified, Kind: KindEdge, ID: "start_to_pan", Detail: "start -> pan"}}},
+		{"rule group", func(g *types.Graph) { g.RuleGroups[0].BusinessTypes = []string{"llp", "trust"} },
+			[]Change{{Type: ChangeModified, Kind: KindRuleGroup, ID: "llp", Detail: "LLP"}}},
+		{"rule group added", func(g *types.Graph) {
+			g.RuleGroups = append(g.RuleGroups, types.RuleGroup{ID: "trust", Name: "Trust"})
+		}, []Change{{Type: ChangeAdded, Kind: KindRuleGroup, ID: "trust", Detail: "Trust"}}},
+		{"cross-node rule removed", func(g *types.Graph) { g.CrossNodeValidation = nil },
+			[]Change{{Type: ChangeRemoved, Kind: KindCrossNodeRule, ID: "type_match", Detail: "Type match"}}},
+		{"activation rule", func(g *types.Graph) { g.ActivationRules[0].ExcludedNodes = []string{"start"} },
+			[]Change{{Type: ChangeModified, Kind: KindActivationRule, ID: "llp_activation", Detail: "LLP activation"}}},
+		{"node rule", func(g *types.Graph) { g.NodeRules[0].RuleType = "path_limitation" },
+			[]Change{{Type: ChangeModified, Kind: KindNodeRule, ID: "pan_check", NodeID: "pan", Detail: "path_limitation"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			from, to := newGraph(1), newGraph(2)
+			tt.change(to)
+
+			diff := Compare(from, to)
+			assert.True(t, diff.HasChanges())
+			assert.Equal(t, tt.expected, diff.Changes)
+		})
+	}
+}
+
+func TestCompareOrdersChanges(t *testing.T) {
+	from, to := newGraph(1), newGraph(2)
+	to.Name = "KYC v2"
+	to.Nodes["aadhaar"] = &types.Node{ID: "aadhaar", Name: "Aadhaar"}
+	to.Nodes["gst"] = &types.Node{ID: "gst", Name: "GST"}
+	delete(to.Nodes, "pan")
+	to.NodeRules = nil
+
+	// Graph attributes come first, then each kind in turn with IDs sorted
+	assert.Equal(t, []Change{
+		{Type: ChangeModified, Kind: KindGraph, ID: "name"},
+		{Type: ChangeAdded, Kind: KindNode, ID: "aadhaar", Detail: "Aadhaar"},
+		{Type: ChangeAdded, Kind: KindNode, ID: "gst", Detail: "GST"},
+		{Type: ChangeRemoved, Kind: KindNode, ID: "pan", Detail: "PAN"},
+		{Type: ChangeRemoved, Kind: KindNodeRule, ID: "pan_check", NodeID: "pan", Detail: "validation"},
+	}, Compare(from, to).Changes)
+}
@@ -0,0 +1,75 @@
+package graphexport
+
+import (
+	"fmt"
+	"strings"
+
+	"onboarding-system/internal/types"
+)
+
+// dotShapes maps node types to Graphviz shapes
+var dotShapes = map[types.NodeType]string{
+	types.NodeTypeStart:      "ellipse",
+	types.NodeTypeInput:      "box",
+	types.NodeTypeValidation: "hexagon",
+	types.NodeTypeDecision:   "diamond",
+	types.NodeTypeEnd:        "doublecircle",
+}
+
+// DOT renders a graph in Graphviz DOT format
+func DOT(graph *types.Graph, overlay *Overlay) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "digraph %s {\n", dotQuote(graph.Name))
+	b.WriteString("  rankdir=LR;\n")
+	b.WriteString("  node [fontname=\"Helvetica\", style=\"rounded,filled\", fillcolor=\"white\"];\n")
+	b.WriteString("  edge [fontname=\"Helvetica\", fontsize=10];\n")
+
+	for _, nodeID := range sortedNodeIDs(graph) {
+		node := graph.Nodes[nodeID]
+		if node == nil {
+			continue
+		}
+
+		shape, ok := dotShapes[node.Type]
+		if !ok {
+			shape = "box"
+		}
+		attrs := []string{"label=" + dotQuote(nodeLabel(node)), "shape=" + shape}
+		if overlay.VisitedNodes[nodeID] {
+			attrs = append(attrs, `fillcolor="#d5f5d5"`)
+		}
+		if nodeID == overlay.CurrentNodeID {
+			attrs = append(attrs, `color="#e67e22"`, "penwidth=3")
+		}
+		fmt.Fprintf(&b, "  %s [%s];\n", dotQuote(nodeID), strings.Join(attrs, ", "))
+	}
+
+	for _, edgeID := range sortedEdgeIDs(graph) {
+		edge := graph.Edges[edgeID]
+		attrs := make([]string, 0, 3)
+		if label := EdgeLabel(edge.Condition); label != "" {
+			attrs = append(attrs, "label="+dotQuote(label))
+		}
+		if overlay.VisitedEdges[edgeID] {
+			attrs = append(attrs, `color="#27ae60"`, "penwidth=2")
+		}
+
+		line := fmt.Sprintf("  %s -> %s", dotQuote(edge.FromNodeID), dotQuote(edge.ToNodeID))
+		if len(attrs) > 0 {
+			line += " [" + strings.Join(attrs, ", ") + "]"
+		}
+		b.WriteString(line + ";\n")
+	}
+
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// dotQuote quotes a DOT identifier or label
+func dotQuote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	s = strings.ReplaceAll(s, "\n", `\n`)
+	return `"` + s + `"`
+}
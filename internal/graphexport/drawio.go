@@ -0,0 +1,96 @@
+package graphexport
+
+import (
+	"fmt"
+	"html"
+	"strings"
+
+	"onboarding-system/internal/types"
+)
+
+// drawioShapes maps node types to draw.io styles
+var drawioShapes = map[types.NodeType]string{
+	types.NodeTypeStart:      "ellipse;whiteSpace=wrap;html=1;",
+	types.NodeTypeInput:      "rounded=1;whiteSpace=wrap;html=1;",
+	types.NodeTypeValidation: "shape=hexagon;perimeter=hexagonPerimeter2;whiteSpace=wrap;html=1;",
+	types.NodeTypeDecision:   "rhombus;whiteSpace=wrap;html=1;",
+	types.NodeTypeEnd:        "ellipse;shape=doubleEllipse;whiteSpace=wrap;html=1;",
+}
+
+// Layout of draw.io nodes, one column per breadth-first layer
+const (
+	drawioNodeWidth  = 160
+	drawioNodeHeight = 60
+	drawioColumnGap  = 220
+	drawioRowGap     = 100
+)
+
+// DrawIO renders a graph as a draw.io (diagrams.net) XML document
+func DrawIO(graph *types.Graph, overlay *Overlay) string {
+	var b strings.Builder
+
+	b.WriteString(`<mxfile host="onboarding-system">` + "\n")
+	fmt.Fprintf(&b, "  <diagram name=%s id=%s>\n", xmlAttr(graph.Name), xmlAttr(graph.ID))
+	b.WriteString(`    <mxGraphModel grid="1" gridSize="10" guides="1" tooltips="1" connect="1" arrows="1" fold="1" page="1" pageScale="1" math="0" shadow="0">` + "\n")
+	b.WriteString("      <root>\n")
+	b.WriteString(`        <mxCell id="0" />` + "\n")
+	b.WriteString(`        <mxCell id="1" parent="0" />` + "\n")
+
+	cellIDs := make(map[string]string)
+	for column, layer := range nodeLayers(graph) {
+		for row, nodeID := range layer {
+			node := graph.Nodes[nodeID]
+			if node == nil {
+				continue
+			}
+			cellID := fmt.Sprintf("node-%d-%d", column, row)
+			cellIDs[nodeID] = cellID
+
+			style, ok := drawioShapes[node.Type]
+			if !ok {
+				style = drawioShapes[types.NodeTypeInput]
+			}
+			if overlay.VisitedNodes[nodeID] {
+				style += "fillColor=#d5f5d5;strokeColor=#27ae60;"
+			}
+			if nodeID == overlay.CurrentNodeID {
+				style += "fillColor=#fdebd0;strokeColor=#e67e22;strokeWidth=3;"
+			}
+
+			fmt.Fprintf(&b, "        <mxCell id=%s value=%s style=%s vertex=\"1\" parent=\"1\">\n", xmlAttr(cellID), xmlAttr(nodeLabel(node)), xmlAttr(style))
+			fmt.Fprintf(&b, "          <mxGeometry x=\"%d\" y=\"%d\" width=\"%d\" height=\"%d\" as=\"geometry\" />\n",
+				40+column*drawioColumnGap, 40+row*drawioRowGap, drawioNodeWidth, drawioNodeHeight)
+			b.WriteString("        </mxCell>\n")
+		}
+	}
+
+	for i, edgeID := range sortedEdgeIDs(graph) {
+		edge := graph.Edges[edgeID]
+		source, sourceOK := cellIDs[edge.FromNodeID]
+		target, targetOK := cellIDs[edge.ToNodeID]
+		if !sourceOK || !targetOK {
+			continue
+		}
+
+		style := "edgeStyle=orthogonalEdgeStyle;rounded=0;orthogonalLoop=1;jettySize=auto;html=1;"
+		if overlay.VisitedEdges[edgeID] {
+			style += "strokeColor=#27ae60;strokeWidth=3;"
+		}
+
+		fmt.Fprintf(&b, "        <mxCell id=%s value=%s style=%s edge=\"1\" parent=\"1\" source=%s target=%s>\n",
+			xmlAttr(fmt.Sprintf("edge-%d", i)), xmlAttr(EdgeLabel(edge.Condition)), xmlAttr(style), xmlAttr(source), xmlAttr(target))
+		b.WriteString(`          <mxGeometry relative="1" as="geometry" />` + "\n")
+		b.WriteString("        </mxCell>\n")
+	}
+
+	b.WriteString("      </root>\n")
+	b.WriteString("    </mxGraphModel>\n")
+	b.WriteString("  </diagram>\n")
+	b.WriteString("</mxfile>\n")
+	return b.String()
+}
+
+// xmlAttr quotes and escapes an XML attribute value
+func xmlAttr(s string) string {
+	return `"` + html.EscapeString(s) + `"`
+}
@@ -0,0 +1,210 @@
+// Package graphexport renders onboarding graphs as Graphviz DOT, Mermaid flowcharts and draw.io diagrams.
+package graphexport
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"onboarding-system/internal/types"
+)
+
+// Format is an export format
+type Format string
+
+const (
+	FormatDOT     Format = "dot"
+	FormatMermaid Format = "mermaid"
+	FormatDrawIO  Format = "drawio"
+)
+
+// Overlay highlights a session's path on an exported graph
+type Overlay struct {
+	VisitedNodes  map[string]bool
+	VisitedEdges  map[string]bool
+	CurrentNodeID string
+}
+
+// SessionOverlay builds an overlay from the forward steps of a session's history
+func SessionOverlay(graph *types.Graph, session *types.Session) *Overlay {
+	overlay := &Overlay{
+		VisitedNodes:  make(map[string]bool),
+		VisitedEdges:  make(map[string]bool),
+		CurrentNodeID: session.CurrentNodeID,
+	}
+
+	path := make([]string, 0, len(session.History)+1)
+	for _, step := range session.History {
+		if step.Action == "forward" {
+			path = append(path, step.NodeID)
+		}
+	}
+	if session.CurrentNodeID != "" && (len(path) == 0 || path[len(path)-1] != session.CurrentNodeID) {
+		path = append(path, session.CurrentNodeID)
+	}
+
+	for i, nodeID := range path {
+		overlay.VisitedNodes[nodeID] = true
+		if i == 0 {
+			continue
+		}
+		for _, edgeID := range sortedEdgeIDs(graph) {
+			edge := graph.Edges[edgeID]
+			if edge.FromNodeID == path[i-1] && edge.ToNodeID == nodeID {
+				overlay.VisitedEdges[edgeID] = true
+				break
+			}
+		}
+	}
+
+	return overlay
+}
+
+// Render renders a graph in the given format; overlay may be nil
+func Render(graph *types.Graph, format Format, overlay *Overlay) (string, error) {
+	if overlay == nil {
+		overlay = &Overlay{}
+	}
+
+	switch format {
+	case FormatDOT:
+		return DOT(graph, overlay), nil
+	case FormatMermaid:
+		return Mermaid(graph, overlay), nil
+	case FormatDrawIO:
+		return DrawIO(graph, overlay), nil
+	default:
+		return "", fmt.Errorf("unsupported export format: %s", format)
+	}
+}
+
+// ContentType returns the MIME type of an export format
+func ContentType(format Format) string {
+	switch format {
+	case FormatDOT:
+		return "text/vnd.graphviz; charset=utf-8"
+	case FormatDrawIO:
+		return "application/xml; charset=utf-8"
+	default:
+		return "text/plain; charset=utf-8"
+	}
+}
+
+// EdgeLabel describes the condition for traversing an edge; unconditional edges have no label
+func EdgeLabel(condition types.EdgeCondition) string {
+	switch condition.Type {
+	case "", "always":
+		return ""
+	case "field_value":
+		operator := condition.Operator
+		switch operator {
+		case "", "eq", "equals":
+			operator = "=="
+		case "ne", "not_equals":
+			operator = "!="
+		case "gt":
+			operator = ">"
+		case "gte":
+			operator = ">="
+		case "lt":
+			operator = "<"
+		case "lte":
+			operator = "<="
+		}
+		return fmt.Sprintf("%s %s %v", condition.Field, operator, formatValue(condition.Value))
+	case "custom":
+		return condition.CustomRule
+	default:
+		return condition.Type
+	}
+}
+
+func formatValue(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case []interface{}:
+		items := make([]string, 0, len(v))
+		for _, item := range v {
+			items = append(items, formatValue(item))
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	case []string:
+		return "[" + strings.Join(v, ", ") + "]"
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
+
+// nodeLabel is the display name of a node
+func nodeLabel(node *types.Node) string {
+	if node.Name != "" {
+		return node.Name
+	}
+	return node.ID
+}
+
+// sortedNodeIDs orders nodes breadth-first from the start node, then any remaining nodes by ID
+func sortedNodeIDs(graph *types.Graph) []string {
+	layers := nodeLayers(graph)
+	ids := make([]string, 0, len(graph.Nodes))
+	for _, layer := range layers {
+		ids = append(ids, layer...)
+	}
+	return ids
+}
+
+// nodeLayers groups nodes by their breadth-first distance from the start node.
+// Nodes that cannot be reached form a final layer.
+func nodeLayers(graph *types.Graph) [][]string {
+	successors := make(map[string][]string)
+	for _, edgeID := range sortedEdgeIDs(graph) {
+		edge := graph.Edges[edgeID]
+		successors[edge.FromNodeID] = append(successors[edge.FromNodeID], edge.ToNodeID)
+	}
+
+	layers := make([][]string, 0)
+	seen := make(map[string]bool)
+	if _, exists := graph.Nodes[graph.StartNodeID]; exists {
+		current := []string{graph.StartNodeID}
+		seen[graph.StartNodeID] = true
+		for len(current) > 0 {
+			layers = append(layers, current)
+			next := make([]string, 0)
+			for _, nodeID := range current {
+				for _, successor := range successors[nodeID] {
+					if _, exists := graph.Nodes[successor]; exists && !seen[successor] {
+						seen[successor] = true
+						next = append(next, successor)
+					}
+				}
+			}
+			sort.Strings(next)
+			current = next
+		}
+	}
+
+	rest := make([]string, 0)
+	for nodeID := range graph.Nodes {
+		if !seen[nodeID] {
+			rest = append(rest, nodeID)
+		}
+	}
+	if len(rest) > 0 {
+		sort.Strings(rest)
+		layers = append(layers, rest)
+	}
+
+	return layers
+}
+
+func sortedEdgeIDs(graph *types.Graph) []string {
+	ids := make([]string, 0, len(graph.Edges))
+	for edgeID, edge := range graph.Edges {
+		if edge != nil {
+			ids = append(ids, edgeID)
+		}
+	}
+	sort.Strings(ids)
+	return ids
+}
@@ -0,0 +1,102 @@
+package graphexport
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	"onboarding-system/internal/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestGraph builds start -> kyc -> end with a conditional edge to a review decision
+func newTestGraph() *types.Graph {
+	graph := types.NewGraph("Export \"Test\"", "")
+	graph.Nodes = map[string]*types.Node{
+		"start":  {ID: "start", Type: types.NodeTypeStart, Name: "Start"},
+		"kyc":    {ID: "kyc", Type: types.NodeTypeInput, Name: "KYC & PAN"},
+		"review": {ID: "review", Type: types.NodeTypeDecision, Name: "Review"},
+		"end":    {ID: "end", Type: types.NodeTypeEnd, Name: "Done"},
+	}
+	graph.Edges = map[string]*types.Edge{
+		"e1": {ID: "e1", FromNodeID: "start", ToNodeID: "kyc", Condition: types.EdgeCondition{Type: "always"}},
+		"e2": {ID: "e2", FromNodeID: "kyc", ToNodeID: "end", Condition: types.EdgeCondition{Type: "field_value", Field: "business_type", Operator: "eq", Value: "individual"}},
+		"e3": {ID: "e3", FromNodeID: "kyc", ToNodeID: "review", Condition: types.EdgeCondition{Type: "custom", CustomRule: `business_type in ["llp"]`}},
+	}
+	graph.StartNodeID = "start"
+	return graph
+}
+
+func TestEdgeLabel(t *testing.T) {
+	graph := newTestGraph()
+
+	assert.Equal(t, "", EdgeLabel(graph.Edges["e1"].Condition))
+	assert.Equal(t, "business_type == individual", EdgeLabel(graph.Edges["e2"].Condition))
+	assert.Equal(t, `business_type in ["llp"]`, EdgeLabel(graph.Edges["e3"].Condition))
+}
+
+func TestDOT(t *testing.T) {
+	overlay := &Overlay{VisitedNodes: map[string]bool{"start": true}, VisitedEdges: map[string]bool{"e1": true}, CurrentNodeID: "kyc"}
+
+	out := DOT(newTestGraph(), overlay)
+
+	assert.True(t, strings.HasPrefix(out, `digraph "Export \"Test\"" {`))
+	assert.Contains(t, out, `"review" [label="Review", shape=diamond];`)
+	assert.Contains(t, out, `"end" [label="Done", shape=doublecircle];`)
+	assert.Contains(t, out, `"kyc" -> "end" [label="business_type == individual"];`)
+	assert.Contains(t, out, `"start" -> "kyc" [color="#27ae60", penwidth=2];`)
+	assert.Contains(t, out, `"kyc" [label="KYC & PAN", shape=box, color="#e67e22", penwidth=3];`)
+}
+
+func TestMermaid(t *testing.T) {
+	out := Mermaid(newTestGraph(), &Overlay{CurrentNodeID: "kyc", VisitedEdges: map[string]bool{"e1": true}})
+
+	assert.True(t, strings.HasPrefix(out, "flowchart LR\n"))
+	assert.Contains(t, out, `n0(["Start"])`)
+	assert.Contains(t, out, `n1["KYC & PAN"]`)
+	assert.Contains(t, out, `n1 -->|"business_type in [#quot;llp#quot;]"| n3`)
+	assert.Contains(t, out, "class n1 current")
+	assert.Contains(t, out, "linkStyle 0 stroke")
+}
+
+func TestDrawIO(t *testing.T) {
+	out := DrawIO(newTestGraph(), &Overlay{})
+
+	var doc struct {
+		Cells []struct {
+			ID     string `xml:"id,attr"`
+			Value  string `xml:"value,attr"`
+			Style  string `xml:"style,attr"`
+			Source string `xml:"source,attr"`
+		} `xml:"diagram>mxGraphModel>root>mxCell"`
+	}
+	require.NoError(t, xml.Unmarshal([]byte(out), &doc))
+
+	// Two root cells, four nodes and three edges
+	require.Len(t, doc.Cells, 9)
+	assert.Equal(t, "Start", doc.Cells[2].Value)
+	assert.True(t, strings.HasPrefix(doc.Cells[2].Style, "ellipse"))
+	assert.Equal(t, "KYC & PAN", doc.Cells[3].Value)
+}
+
+func TestSessionOverlay(t *testing.T) {
+	graph := newTestGraph()
+	session := types.NewSession("user", graph.ID)
+	session.History = []types.SessionStep{
+		{NodeID: "start", Action: "forward"},
+		{NodeID: "kyc", Action: "forward"},
+	}
+	session.CurrentNodeID = "end"
+
+	overlay := SessionOverlay(graph, session)
+
+	assert.Equal(t, map[string]bool{"start": true, "kyc": true, "end": true}, overlay.VisitedNodes)
+	assert.Equal(t, map[string]bool{"e1": true, "e2": true}, overlay.VisitedEdges)
+}
+
+func TestRenderUnsupportedFormat(t *testing.T) {
+	_, err := Render(newTestGraph(), Format("svg"), nil)
+	assert.Error(t, err)
+}
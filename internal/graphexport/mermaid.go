@@ -0,0 +1,89 @@
+package graphexport
+
+import (
+	"fmt"
+	"strings"
+
+	"onboarding-system/internal/types"
+)
+
+// mermaidShapes maps node types to Mermaid flowchart shape delimiters
+var mermaidShapes = map[types.NodeType][2]string{
+	types.NodeTypeStart:      {"([", "])"},
+	types.NodeTypeInput:      {"[", "]"},
+	types.NodeTypeValidation: {"{{", "}}"},
+	types.NodeTypeDecision:   {"{", "}"},
+	types.NodeTypeEnd:        {"(((", ")))"},
+}
+
+// Mermaid renders a graph as a Mermaid flowchart
+func Mermaid(graph *types.Graph, overlay *Overlay) string {
+	var b strings.Builder
+
+	b.WriteString("flowchart LR\n")
+
+	// Node IDs are often UUIDs, so nodes get short aliases
+	aliases := make(map[string]string)
+	for i, nodeID := range sortedNodeIDs(graph) {
+		node := graph.Nodes[nodeID]
+		if node == nil {
+			continue
+		}
+		alias := fmt.Sprintf("n%d", i)
+		aliases[nodeID] = alias
+
+		shape, ok := mermaidShapes[node.Type]
+		if !ok {
+			shape = mermaidShapes[types.NodeTypeInput]
+		}
+		fmt.Fprintf(&b, "  %s%s\"%s\"%s\n", alias, shape[0], mermaidEscape(nodeLabel(node)), shape[1])
+	}
+
+	visitedLinks := make([]string, 0)
+	link := 0
+	for _, edgeID := range sortedEdgeIDs(graph) {
+		edge := graph.Edges[edgeID]
+		from, fromOK := aliases[edge.FromNodeID]
+		to, toOK := aliases[edge.ToNodeID]
+		if !fromOK || !toOK {
+			continue
+		}
+
+		if label := EdgeLabel(edge.Condition); label != "" {
+			fmt.Fprintf(&b, "  %s -->|\"%s\"| %s\n", from, mermaidEscape(label), to)
+		} else {
+			fmt.Fprintf(&b, "  %s --> %s\n", from, to)
+		}
+		if overlay.VisitedEdges[edgeID] {
+			visitedLinks = append(visitedLinks, fmt.Sprintf("%d", link))
+		}
+		link++
+	}
+
+	if len(overlay.VisitedNodes) > 0 || overlay.CurrentNodeID != "" {
+		b.WriteString("  classDef visited fill:#d5f5d5,stroke:#27ae60\n")
+		b.WriteString("  classDef current fill:#fdebd0,stroke:#e67e22,stroke-width:3px\n")
+		for _, nodeID := range sortedNodeIDs(graph) {
+			alias, ok := aliases[nodeID]
+			if !ok {
+				continue
+			}
+			if nodeID == overlay.CurrentNodeID {
+				fmt.Fprintf(&b, "  class %s current\n", alias)
+			} else if overlay.VisitedNodes[nodeID] {
+				fmt.Fprintf(&b, "  class %s visited\n", alias)
+			}
+		}
+	}
+	if len(visitedLinks) > 0 {
+		fmt.Fprintf(&b, "  linkStyle %s stroke:#27ae60,stroke-width:3px\n", strings.Join(visitedLinks, ","))
+	}
+
+	return b.String()
+}
+
+// mermaidEscape escapes text inside a quoted Mermaid label
+func mermaidEscape(s string) string {
+	s = strings.ReplaceAll(s, `"`, "#quot;")
+	return strings.ReplaceAll(s, "\n", " ")
+}
@@ -0,0 +1,429 @@
+// Package graphimport builds onboarding graphs from requirement spreadsheets.
+package graphimport
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+
+	"onboarding-system/internal/types"
+)
+
+// Requirement levels recorded per business type in field metadata
+const (
+	RequirementMandatory   = "mandatory"
+	RequirementOptional    = "optional"
+	RequirementConditional = "conditional"
+)
+
+// Options configures a CSV import
+type Options struct {
+	GraphID     string // reuse an existing graph ID so the import publishes a new revision
+	Name        string
+	Description string
+	Version     string
+}
+
+// Result is an imported graph together with the cells that could not be fully interpreted
+type Result struct {
+	Graph    *types.Graph `json:"graph"`
+	Warnings []string     `json:"warnings"`
+}
+
+var (
+	possibleValuesPattern = regexp.MustCompile("(?i)possible values\\s*\\[([^\\]]*)\\]")
+	conditionalPattern    = regexp.MustCompile("(?i)^rules?\\s*:\\s*mandatory if\\s+`([^`]+)`\\s+selected in\\s+`([^`]+)`(?:\\s+else\\s+optional)?\\.?$")
+	nonIdentifierPattern  = regexp.MustCompile(`[^a-z0-9]+`)
+)
+
+// requirement is the parsed content of a single requirement cell
+type requirement struct {
+	level     string
+	options   []string
+	value     string // option that makes a conditional field mandatory
+	condition string // component whose value is checked
+	text      string
+}
+
+// field is a component row with its requirement per business type
+type field struct {
+	id           string
+	name         string
+	row          int
+	requirements map[string]requirement
+}
+
+// section is a group of components that becomes a node
+type section struct {
+	id     string
+	name   string
+	fields []*field
+}
+
+// ImportCSV builds a graph from the requirements CSV format.
+// The first two columns are Section and Component; every further column is a business type.
+// A blank Section continues the previous one. Cells are `Mandatory`, `Optional`,
+// `Mandatory ; possible values [A,B]` or `Rule : Mandatory if `A` selected in `Component` else Optional`.
+func ImportCSV(r io.Reader, opts Options) (*Result, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read csv: %w", err)
+	}
+	if len(records) < 2 {
+		return nil, fmt.Errorf("csv must have a header and at least one row")
+	}
+
+	header := records[0]
+	if len(header) < 3 || !strings.EqualFold(strings.TrimSpace(header[0]), "section") || !strings.EqualFold(strings.TrimSpace(header[1]), "component") {
+		return nil, fmt.Errorf("csv header must be Section, Component and one column per business type")
+	}
+
+	businessTypes := make([]string, 0, len(header)-2)
+	for _, column := range header[2:] {
+		businessType := Identifier(column)
+		if businessType == "" {
+			return nil, fmt.Errorf("csv header has an empty business type column")
+		}
+		businessTypes = append(businessTypes, businessType)
+	}
+
+	result := &Result{Warnings: make([]string, 0)}
+	warnf := func(row int, format string, args ...interface{}) {
+		result.Warnings = append(result.Warnings, fmt.Sprintf("row %d: ", row)+fmt.Sprintf(format, args...))
+	}
+
+	// Group components into sections
+	sections := make([]*section, 0)
+	fieldsByName := make(map[string]*field)
+	for i, record := range records[1:] {
+		row := i + 2
+		if len(record) < 2 || strings.TrimSpace(strings.Join(record, "")) == "" {
+			continue
+		}
+
+		if name := strings.TrimSpace(record[0]); name != "" {
+			for _, existing := range sections {
+				if existing.id == Identifier(name) {
+					return nil, fmt.Errorf("row %d: section %q is listed more than once", row, name)
+				}
+			}
+			sections = append(sections, &section{id: Identifier(name), name: name})
+		}
+		if len(sections) == 0 {
+			return nil, fmt.Errorf("row %d: component has no section", row)
+		}
+
+		name := strings.TrimSpace(record[1])
+		if name == "" {
+			warnf(row, "skipped row without a component")
+			continue
+		}
+		f := &field{id: Identifier(name), name: name, row: row, requirements: make(map[string]requirement)}
+		if _, exists := fieldsByName[f.id]; exists {
+			return nil, fmt.Errorf("row %d: component %q is listed more than once", row, name)
+		}
+		fieldsByName[f.id] = f
+
+		for j, businessType := range businessTypes {
+			cell := ""
+			if j+2 < len(record) {
+				cell = record[j+2]
+			}
+			req := parseRequirement(cell)
+			if req.level == "" {
+				warnf(row, "%s for %s: could not interpret %q, importing it as optional", name, businessType, req.text)
+				req.level = RequirementOptional
+			}
+			f.requirements[businessType] = req
+		}
+
+		current := sections[len(sections)-1]
+		current.fields = append(current.fields, f)
+	}
+
+	if len(sections) == 0 {
+		return nil, fmt.Errorf("csv has no sections")
+	}
+
+	graph := buildGraph(sections, fieldsByName, businessTypes, opts, warnf)
+	result.Graph = graph
+	return result, nil
+}
+
+// parseRequirement interprets a requirement cell; an unrecognised cell has an empty level
+func parseRequirement(cell string) requirement {
+	text := strings.Join(strings.Fields(cell), " ")
+	req := requirement{text: text}
+
+	if match := possibleValuesPattern.FindStringSubmatch(text); match != nil {
+		for _, option := range strings.Split(match[1], ",") {
+			if option = Identifier(option); option != "" {
+				req.options = append(req.options, option)
+			}
+		}
+		text = strings.TrimSpace(possibleValuesPattern.ReplaceAllString(text, ""))
+		text = strings.TrimSpace(strings.TrimSuffix(text, ";"))
+	}
+
+	switch strings.ToLower(text) {
+	case "mandatory", "required":
+		req.level = RequirementMandatory
+	case "optional", "":
+		req.level = RequirementOptional
+	default:
+		if match := conditionalPattern.FindStringSubmatch(text); match != nil {
+			req.level = RequirementConditional
+			req.value = Identifier(match[1])
+			req.condition = Identifier(match[2])
+		}
+	}
+
+	return req
+}
+
+// buildGraph turns parsed sections into a linear graph with a rule group per business type
+func buildGraph(sections []*section, fieldsByName map[string]*field, businessTypes []string, opts Options, warnf func(int, string, ...interface{})) *types.Graph {
+	name := opts.Name
+	if name == "" {
+		name = "Imported Onboarding"
+	}
+	graph := types.NewGraph(name, opts.Description)
+	if opts.GraphID != "" {
+		graph.ID = opts.GraphID
+	}
+	if opts.Version != "" {
+		graph.Version = opts.Version
+	}
+	graph.Metadata["source"] = "csv"
+	graph.Metadata["business_types"] = businessTypes
+
+	// Every requirement depends on the business type, so ask for it first if the csv does not
+	_, hasBusinessType := fieldsByName["business_type"]
+	if !hasBusinessType {
+		sections = append([]*section{{id: "business_type_selection", name: "Business Type Selection"}}, sections...)
+	}
+
+	ruleGroups := make(map[string]*types.RuleGroup, len(businessTypes))
+	for _, businessType := range businessTypes {
+		ruleGroups[businessType] = &types.RuleGroup{
+			ID:                businessType + "_requirements",
+			Name:              strings.ReplaceAll(businessType, "_", " ") + " requirements",
+			Description:       "Imported from csv",
+			BusinessTypes:     []string{businessType},
+			RequiredNodes:     make([]string, 0),
+			RequiredFields:    make(map[string][]string),
+			ConditionalFields: make(map[string]types.ConditionalFieldRule),
+		}
+	}
+
+	var previous *types.Node
+	for i, sec := range sections {
+		nodeType := types.NodeTypeInput
+		if i == 0 {
+			nodeType = types.NodeTypeStart
+		}
+		node := newNode(sec.id, nodeType, sec.name)
+
+		requiredFor := make([]string, 0)
+		asksBusinessType := i == 0 && !hasBusinessType
+		for _, f := range sec.fields {
+			node.Fields = append(node.Fields, buildField(f, businessTypes))
+			if f.id == "business_type" {
+				asksBusinessType = true
+			}
+		}
+		if i == 0 && !hasBusinessType {
+			node.Fields = append(node.Fields, businessTypeField(businessTypes))
+		}
+
+		for _, nodeField := range node.Fields {
+			if nodeField.Required {
+				node.Validation.RequiredFields = append(node.Validation.RequiredFields, nodeField.ID)
+			}
+		}
+
+		for _, businessType := range businessTypes {
+			group := ruleGroups[businessType]
+			mandatory := make([]string, 0)
+			for _, f := range sec.fields {
+				req := f.requirements[businessType]
+				switch req.level {
+				case RequirementMandatory:
+					mandatory = append(mandatory, f.id)
+				case RequirementConditional:
+					condition, exists := fieldsByName[req.condition]
+					if !exists {
+						warnf(f.row, "%s for %s: condition refers to unknown component %q, importing it as optional", f.name, businessType, req.condition)
+						continue
+					}
+					group.ConditionalFields[f.id] = types.ConditionalFieldRule{
+						NodeID:      sec.id,
+						FieldID:     f.id,
+						Condition:   condition.id,
+						Operator:    "eq",
+						Value:       req.value,
+						Description: req.text,
+					}
+				}
+			}
+			if asksBusinessType {
+				mandatory = appendMissing(mandatory, "business_type")
+			}
+			if len(mandatory) > 0 {
+				group.RequiredNodes = append(group.RequiredNodes, sec.id)
+				group.RequiredFields[sec.id] = mandatory
+				requiredFor = append(requiredFor, businessType)
+			}
+		}
+		node.Metadata["required_for"] = requiredFor
+
+		graph.Nodes[node.ID] = node
+		if previous == nil {
+			graph.StartNodeID = node.ID
+		} else {
+			connect(graph, previous, node)
+		}
+		previous = node
+	}
+
+	end := newNode("onboarding_complete", types.NodeTypeEnd, "Onboarding Complete")
+	end.Metadata["required_for"] = []string{}
+	graph.Nodes[end.ID] = end
+	connect(graph, previous, end)
+
+	// Conditions on select fields must use one of the field's options
+	for _, businessType := range businessTypes {
+		group := ruleGroups[businessType]
+		for fieldID, rule := range group.ConditionalFields {
+			condition := fieldsByName[rule.Condition]
+			options := condition.requirements[businessType].options
+			if len(options) > 0 && !contains(options, rule.Value) {
+				warnf(fieldsByName[fieldID].row, "%s for %s: %q is not a possible value of %s", fieldsByName[fieldID].name, businessType, rule.Value, condition.name)
+			}
+		}
+		graph.RuleGroups = append(graph.RuleGroups, *group)
+	}
+
+	return graph
+}
+
+// buildField creates a field; it is required only if every business type requires it
+func buildField(f *field, businessTypes []string) types.Field {
+	result := types.Field{
+		ID:       f.id,
+		Name:     f.name,
+		Type:     types.FieldTypeText,
+		Required: true,
+		Metadata: make(map[string]interface{}),
+	}
+
+	requirements := make(map[string]string, len(businessTypes))
+	ruleText := make(map[string]string)
+	for _, businessType := range businessTypes {
+		req := f.requirements[businessType]
+		requirements[businessType] = req.level
+		if req.level != RequirementMandatory {
+			result.Required = false
+		}
+		if req.text != "" && !strings.EqualFold(req.text, req.level) {
+			ruleText[businessType] = req.text
+		}
+		for _, option := range req.options {
+			if !contains(result.Options, option) {
+				result.Options = append(result.Options, option)
+			}
+		}
+	}
+	result.Metadata["requirements"] = requirements
+	if len(ruleText) > 0 {
+		result.Metadata["rule_text"] = ruleText
+	}
+
+	lower := strings.ToLower(f.name)
+	switch {
+	case f.id == "business_type":
+		return mergeBusinessTypeField(result, businessTypes)
+	case len(result.Options) > 0:
+		result.Type = types.FieldTypeSelect
+	case strings.Contains(lower, "document"):
+		result.Type = types.FieldTypeFile
+	case strings.HasSuffix(lower, " url"):
+		result.Validation.Pattern = `^https?://[^\s/$.?#].[^\s]*$`
+	}
+
+	return result
+}
+
+// businessTypeField is the select field used when the csv does not list a business type component
+func businessTypeField(businessTypes []string) types.Field {
+	return mergeBusinessTypeField(types.Field{
+		ID:       "business_type",
+		Name:     "Business type",
+		Required: true,
+		Metadata: make(map[string]interface{}),
+	}, businessTypes)
+}
+
+// mergeBusinessTypeField makes the business type a required select over the csv's business types
+func mergeBusinessTypeField(f types.Field, businessTypes []string) types.Field {
+	f.Type = types.FieldTypeSelect
+	f.Required = true
+	f.Options = append([]string(nil), businessTypes...)
+	return f
+}
+
+func newNode(id string, nodeType types.NodeType, name string) *types.Node {
+	now := time.Now()
+	return &types.Node{
+		ID:        id,
+		Type:      nodeType,
+		Name:      name,
+		Fields:    make([]types.Field, 0),
+		Metadata:  make(map[string]interface{}),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+// connect adds an unconditional edge between two nodes
+func connect(graph *types.Graph, from, to *types.Node) {
+	edge := &types.Edge{
+		ID:         from.ID + "_to_" + to.ID,
+		FromNodeID: from.ID,
+		ToNodeID:   to.ID,
+		Condition:  types.EdgeCondition{Type: "always"},
+		Metadata:   make(map[string]interface{}),
+		CreatedAt:  time.Now(),
+	}
+	graph.Edges[edge.ID] = edge
+	from.OutgoingEdges = append(from.OutgoingEdges, edge.ID)
+	to.IncomingEdges = append(to.IncomingEdges, edge.ID)
+}
+
+// Identifier converts a spreadsheet label such as "MCC & Policy verification" to an ID like "mcc_policy_verification"
+func Identifier(label string) string {
+	return strings.Trim(nonIdentifierPattern.ReplaceAllString(strings.ToLower(label), "_"), "_")
+}
+
+func appendMissing(values []string, value string) []string {
+	if contains(values, value) {
+		return values
+	}
+	return append(values, value)
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,84 @@
+package graphimport
+
+import (
+	"strings"
+	"testing"
+
+	"onboarding-system/internal/graphlint"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testCSV = `Section,Component,Individual,Private Limited
+Business type,PAN number,Mandatory,Mandatory
+,Business type,Mandatory,Mandatory
+Payment channel,Payment channel ,"Mandatory ; possible values [Website,App,No-code]","Mandatory ; possible values [Website,App,No-code]"
+,Website url,Rule : Mandatory if ` + "`Website`" + ` selected in ` + "`Payment channel`" + ` else Optional,Mandatory
+Business details,Business document ,Optional,Mandatory
+,BMC document,Mandatory on certain ` + "`SubCategory`" + ` else Optional ,Optional
+`
+
+func TestImportCSV(t *testing.T) {
+	result, err := ImportCSV(strings.NewReader(testCSV), Options{Name: "Imported"})
+	require.NoError(t, err)
+
+	graph := result.Graph
+	assert.Equal(t, "business_type", graph.StartNodeID)
+	assert.Len(t, graph.Nodes, 4)
+	assert.True(t, graphlint.Lint(graph).Valid)
+
+	businessType := graph.Nodes["business_type"].Fields[1]
+	assert.Equal(t, []string{"individual", "private_limited"}, businessType.Options)
+
+	channel := graph.Nodes["payment_channel"].Fields[0]
+	assert.Equal(t, "payment_channel", channel.ID)
+	assert.Equal(t, []string{"website", "app", "no_code"}, channel.Options)
+	assert.True(t, channel.Required)
+
+	websiteURL := graph.Nodes["payment_channel"].Fields[1]
+	assert.False(t, websiteURL.Required)
+	assert.Equal(t, map[string]string{"individual": RequirementConditional, "private_limited": RequirementMandatory}, websiteURL.Metadata["requirements"])
+
+	assert.Equal(t, []string{"private_limited"}, graph.Nodes["business_details"].Metadata["required_for"])
+
+	require.Len(t, graph.RuleGroups, 2)
+	individual := graph.RuleGroups[0]
+	assert.Equal(t, []string{"individual"}, individual.BusinessTypes)
+	assert.NotContains(t, individual.RequiredNodes, "business_details")
+	assert.Equal(t, "website", individual.ConditionalFields["website_url"].Value)
+	assert.Equal(t, "payment_channel", individual.ConditionalFields["website_url"].Condition)
+
+	privateLimited := graph.RuleGroups[1]
+	assert.Equal(t, []string{"business_document"}, privateLimited.RequiredFields["business_details"])
+	assert.Equal(t, []string{"payment_channel", "website_url"}, privateLimited.RequiredFields["payment_channel"])
+
+	require.Len(t, result.Warnings, 1)
+	assert.Contains(t, result.Warnings[0], "BMC document for individual")
+}
+
+func TestImportCSVAddsBusinessTypeNode(t *testing.T) {
+	result, err := ImportCSV(strings.NewReader("Section,Component,Individual\nDetails,Business name,Mandatory\n"), Options{})
+	require.NoError(t, err)
+
+	start := result.Graph.Nodes[result.Graph.StartNodeID]
+	assert.Equal(t, "business_type_selection", start.ID)
+	assert.Equal(t, "business_type", start.Fields[0].ID)
+	assert.True(t, graphlint.Lint(result.Graph).Valid)
+}
+
+func TestImportCSVErrors(t *testing.T) {
+	tests := map[string]string{
+		"bad header":        "Name,Value\nA,B\n",
+		"no business types": "Section,Component\nA,B\n",
+		"duplicate":         "Section,Component,Individual\nA,Name,Mandatory\n,Name,Optional\n",
+		"no rows":           "Section,Component,Individual\n",
+	}
+
+	for name, input := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := ImportCSV(strings.NewReader(input), Options{})
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,425 @@
+// Package graphlint performs static analysis of onboarding graphs before they are saved.
+package graphlint
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+
+	"onboarding-system/internal/types"
+)
+
+// Severity indicates whether a diagnostic blocks saving the graph
+type Severity string
+
+const (
+	SeverityError   Severity = "error"   // Graph is rejected
+	SeverityWarning Severity = "warning" // Graph is accepted but likely wrong
+)
+
+// Location identifies the part of the graph a diagnostic refers to
+type Location struct {
+	NodeID  string `json:"node_id,omitempty"`
+	EdgeID  string `json:"edge_id,omitempty"`
+	FieldID string `json:"field_id,omitempty"`
+	RuleID  string `json:"rule_id,omitempty"`
+}
+
+// Diagnostic is a single finding of the linter
+type Diagnostic struct {
+	Severity Severity `json:"severity"`
+	Code     string   `json:"code"`
+	Location Location `json:"location"`
+	Message  string   `json:"message"`
+}
+
+// Report is the result of linting a graph
+type Report struct {
+	Valid       bool         `json:"valid"`
+	Errors      int          `json:"errors"`
+	Warnings    int          `json:"warnings"`
+	Diagnostics []Diagnostic `json:"diagnostics"`
+}
+
+// HasErrors reports whether the report contains any error diagnostics
+func (r *Report) HasErrors() bool {
+	return r.Errors > 0
+}
+
+// Error summarises the report's error diagnostics
+func (r *Report) Error() string {
+	for _, d := range r.Diagnostics {
+		if d.Severity == SeverityError {
+			if r.Errors == 1 {
+				return fmt.Sprintf("%s: %s", d.Code, d.Message)
+			}
+			return fmt.Sprintf("%s: %s (and %d more errors)", d.Code, d.Message, r.Errors-1)
+		}
+	}
+	return "graph is valid"
+}
+
+// linter accumulates diagnostics for a graph
+type linter struct {
+	graph       *types.Graph
+	diagnostics []Diagnostic
+}
+
+func (l *linter) errorf(code string, loc Location, format string, args ...interface{}) {
+	l.diagnostics = append(l.diagnostics, Diagnostic{Severity: SeverityError, Code: code, Location: loc, Message: fmt.Sprintf(format, args...)})
+}
+
+func (l *linter) warnf(code string, loc Location, format string, args ...interface{}) {
+	l.diagnostics = append(l.diagnostics, Diagnostic{Severity: SeverityWarning, Code: code, Location: loc, Message: fmt.Sprintf(format, args...)})
+}
+
+// Lint runs every check against a graph and returns the diagnostics found
+func Lint(graph *types.Graph) *Report {
+	l := &linter{graph: graph}
+
+	l.checkNodes()
+	l.checkEdges()
+	l.checkStartNode()
+	l.checkReachability()
+	l.checkCycles()
+	l.checkCrossNodeReferences()
+	l.checkExpressions()
+	l.checkDependencies()
+	l.checkRuleGroups()
+	l.checkAdvancedRules()
+
+	report := &Report{Diagnostics: l.diagnostics}
+	if report.Diagnostics == nil {
+		report.Diagnostics = make([]Diagnostic, 0)
+	}
+	for _, d := range report.Diagnostics {
+		if d.Severity == SeverityError {
+			report.Errors++
+		} else {
+			report.Warnings++
+		}
+	}
+	report.Valid = report.Errors == 0
+
+	return report
+}
+
+// sortedNodeIDs returns node IDs in a stable order so reports are deterministic
+func (l *linter) sortedNodeIDs() []string {
+	ids := make([]string, 0, len(l.graph.Nodes))
+	for id := range l.graph.Nodes {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// sortedEdgeIDs returns edge IDs in a stable order
+func (l *linter) sortedEdgeIDs() []string {
+	ids := make([]string, 0, len(l.graph.Edges))
+	for id := range l.graph.Edges {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// checkNodes validates each node's fields and validation rules
+func (l *linter) checkNodes() {
+	if len(l.graph.Nodes) == 0 {
+		l.errorf("GRAPH_EMPTY", Location{}, "graph has no nodes")
+		return
+	}
+
+	hasEnd := false
+	for _, nodeID := range l.sortedNodeIDs() {
+		node := l.graph.Nodes[nodeID]
+		if node == nil {
+			l.errorf("NODE_NULL", Location{NodeID: nodeID}, "node %s is null", nodeID)
+			continue
+		}
+		if node.ID != nodeID {
+			l.errorf("NODE_ID_MISMATCH", Location{NodeID: nodeID}, "node is keyed as %s but has id %s", nodeID, node.ID)
+		}
+		if node.Type == types.NodeTypeEnd {
+			hasEnd = true
+		}
+
+		fields := make(map[string]bool)
+		for _, field := range node.Fields {
+			loc := Location{NodeID: nodeID, FieldID: field.ID}
+			if field.ID == "" {
+				l.errorf("FIELD_ID_MISSING", loc, "field %q in node %s has no id", field.Name, nodeID)
+				continue
+			}
+			if fields[field.ID] {
+				l.errorf("FIELD_DUPLICATE", loc, "field %s is declared more than once in node %s", field.ID, nodeID)
+			}
+			fields[field.ID] = true
+
+			if field.Validation.Pattern != "" {
+				if _, err := regexp.Compile(field.Validation.Pattern); err != nil {
+					l.errorf("FIELD_PATTERN_INVALID", loc, "field %s has an invalid pattern: %v", field.ID, err)
+				}
+			}
+			if field.Validation.MaxLength > 0 && field.Validation.MinLength > field.Validation.MaxLength {
+				l.errorf("FIELD_LENGTH_RANGE_INVALID", loc, "field %s has min_length %d greater than max_length %d", field.ID, field.Validation.MinLength, field.Validation.MaxLength)
+			}
+			if field.Validation.MinValue != nil && field.Validation.MaxValue != nil && *field.Validation.MinValue > *field.Validation.MaxValue {
+				l.errorf("FIELD_VALUE_RANGE_INVALID", loc, "field %s has min_value %d greater than max_value %d", field.ID, *field.Validation.MinValue, *field.Validation.MaxValue)
+			}
+		}
+
+		for _, fieldID := range node.Validation.RequiredFields {
+			if !fields[fieldID] {
+				l.errorf("REQUIRED_FIELD_NOT_DEFINED", Location{NodeID: nodeID, FieldID: fieldID}, "required field %s is not defined in node %s", fieldID, nodeID)
+			}
+		}
+		for _, condition := range node.Validation.Conditions {
+			if err := checkOperand(condition.Operator, condition.Value, false); err != nil {
+				l.errorf("VALIDATION_OPERATOR_UNSUPPORTED", Location{NodeID: nodeID, FieldID: condition.Field}, "validation condition on field %s of node %s is invalid: %v", condition.Field, nodeID, err)
+			}
+		}
+	}
+
+	if !hasEnd {
+		l.errorf("END_NODE_MISSING", Location{}, "graph has no end node")
+	}
+}
+
+// checkEdges validates that every edge connects existing nodes
+func (l *linter) checkEdges() {
+	fields := l.graphFields()
+
+	for _, edgeID := range l.sortedEdgeIDs() {
+		edge := l.graph.Edges[edgeID]
+		loc := Location{EdgeID: edgeID}
+		if edge == nil {
+			l.errorf("EDGE_NULL", loc, "edge %s is null", edgeID)
+			continue
+		}
+		if edge.ID != edgeID {
+			l.errorf("EDGE_ID_MISMATCH", loc, "edge is keyed as %s but has id %s", edgeID, edge.ID)
+		}
+		if _, exists := l.graph.Nodes[edge.FromNodeID]; !exists {
+			l.errorf("EDGE_SOURCE_NOT_FOUND", loc, "edge %s starts at unknown node %s", edgeID, edge.FromNodeID)
+		}
+		if _, exists := l.graph.Nodes[edge.ToNodeID]; !exists {
+			l.errorf("EDGE_TARGET_NOT_FOUND", loc, "edge %s points to unknown node %s", edgeID, edge.ToNodeID)
+		}
+		if edge.Condition.Type != "field_value" {
+			continue
+		}
+		if edge.Condition.Field != "" && !fields[edge.Condition.Field] {
+			l.warnf("EDGE_FIELD_NOT_DEFINED", Location{EdgeID: edgeID, FieldID: edge.Condition.Field}, "edge %s conditions on field %s which no node defines", edgeID, edge.Condition.Field)
+		}
+		if err := checkOperand(edge.Condition.Operator, edge.Condition.Value, false); err != nil {
+			l.errorf("EDGE_OPERATOR_UNSUPPORTED", Location{EdgeID: edgeID, FieldID: edge.Condition.Field}, "edge %s has an invalid condition: %v", edgeID, err)
+		}
+	}
+}
+
+// checkStartNode validates the graph's entry point
+func (l *linter) checkStartNode() {
+	if l.graph.StartNodeID == "" {
+		l.errorf("START_NODE_MISSING", Location{}, "graph has no start node")
+		return
+	}
+	if _, exists := l.graph.Nodes[l.graph.StartNodeID]; !exists {
+		l.errorf("START_NODE_NOT_FOUND", Location{NodeID: l.graph.StartNodeID}, "start node %s does not exist", l.graph.StartNodeID)
+	}
+}
+
+// successors returns the outgoing adjacency list over existing nodes
+func (l *linter) successors() map[string][]string {
+	adjacency := make(map[string][]string)
+	for _, edgeID := range l.sortedEdgeIDs() {
+		edge := l.graph.Edges[edgeID]
+		if edge == nil {
+			continue
+		}
+		_, fromExists := l.graph.Nodes[edge.FromNodeID]
+		_, toExists := l.graph.Nodes[edge.ToNodeID]
+		if fromExists && toExists {
+			adjacency[edge.FromNodeID] = append(adjacency[edge.FromNodeID], edge.ToNodeID)
+		}
+	}
+	return adjacency
+}
+
+// checkReachability warns about nodes that cannot be reached from the start node
+func (l *linter) checkReachability() {
+	if _, exists := l.graph.Nodes[l.graph.StartNodeID]; !exists {
+		return
+	}
+
+	adjacency := l.successors()
+	visited := map[string]bool{l.graph.StartNodeID: true}
+	queue := []string{l.graph.StartNodeID}
+	// Sessions may also start from any entry node
+	for _, entryNodeID := range l.graph.EntryNodes {
+		if _, exists := l.graph.Nodes[entryNodeID]; exists && !visited[entryNodeID] {
+			visited[entryNodeID] = true
+			queue = append(queue, entryNodeID)
+		}
+	}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, next := range adjacency[current] {
+			if !visited[next] {
+				visited[next] = true
+				queue = append(queue, next)
+			}
+		}
+	}
+
+	for _, nodeID := range l.sortedNodeIDs() {
+		if !visited[nodeID] {
+			l.warnf("NODE_UNREACHABLE", Location{NodeID: nodeID}, "node %s cannot be reached from the start node or an entry node", nodeID)
+		}
+	}
+}
+
+// checkCycles reports cycles that no edge leaves and that contain no end node
+func (l *linter) checkCycles() {
+	adjacency := l.successors()
+
+	for _, component := range stronglyConnectedComponents(l.sortedNodeIDs(), adjacency) {
+		members := make(map[string]bool, len(component))
+		for _, nodeID := range component {
+			members[nodeID] = true
+		}
+
+		isCycle := len(component) > 1
+		if !isCycle {
+			for _, next := range adjacency[component[0]] {
+				if next == component[0] {
+					isCycle = true
+				}
+			}
+		}
+		if !isCycle {
+			continue
+		}
+
+		hasExit := false
+		for _, nodeID := range component {
+			if node := l.graph.Nodes[nodeID]; node != nil && node.Type == types.NodeTypeEnd {
+				hasExit = true
+			}
+			for _, next := range adjacency[nodeID] {
+				if !members[next] {
+					hasExit = true
+				}
+			}
+		}
+
+		if !hasExit {
+			sort.Strings(component)
+			l.errorf("CYCLE_WITHOUT_EXIT", Location{NodeID: component[0]}, "nodes %v form a cycle with no exit", component)
+		}
+	}
+}
+
+// stronglyConnectedComponents implements Tarjan's algorithm
+func stronglyConnectedComponents(nodeIDs []string, adjacency map[string][]string) [][]string {
+	index := 0
+	indices := make(map[string]int)
+	lowlink := make(map[string]int)
+	onStack := make(map[string]bool)
+	stack := make([]string, 0)
+	components := make([][]string, 0)
+
+	var connect func(nodeID string)
+	connect = func(nodeID string) {
+		indices[nodeID] = index
+		lowlink[nodeID] = index
+		index++
+		stack = append(stack, nodeID)
+		onStack[nodeID] = true
+
+		for _, next := range adjacency[nodeID] {
+			if _, seen := indices[next]; !seen {
+				connect(next)
+				if lowlink[next] < lowlink[nodeID] {
+					lowlink[nodeID] = lowlink[next]
+				}
+			} else if onStack[next] && indices[next] < lowlink[nodeID] {
+				lowlink[nodeID] = indices[next]
+			}
+		}
+
+		if lowlink[nodeID] == indices[nodeID] {
+			component := make([]string, 0)
+			for {
+				top := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				onStack[top] = false
+				component = append(component, top)
+				if top == nodeID {
+					break
+				}
+			}
+			components = append(components, component)
+		}
+	}
+
+	for _, nodeID := range nodeIDs {
+		if _, seen := indices[nodeID]; !seen {
+			connect(nodeID)
+		}
+	}
+
+	return components
+}
+
+// checkCrossNodeReferences validates node/field pairs and aliases used by cross-node rules
+func (l *linter) checkCrossNodeReferences() {
+	for _, rule := range l.graph.CrossNodeValidation {
+		aliases := make(map[string]bool)
+		for _, ref := range rule.Fields {
+			loc := Location{NodeID: ref.NodeID, FieldID: ref.FieldID, RuleID: rule.ID}
+			aliases[ref.Alias] = true
+
+			node, exists := l.graph.Nodes[ref.NodeID]
+			if !exists || node == nil {
+				l.errorf("CROSS_NODE_NODE_NOT_FOUND", loc, "cross-node rule %s references unknown node %s", rule.ID, ref.NodeID)
+				continue
+			}
+			if !nodeHasField(node, ref.FieldID) {
+				l.errorf("CROSS_NODE_FIELD_NOT_FOUND", loc, "cross-node rule %s references field %s which node %s does not define", rule.ID, ref.FieldID, ref.NodeID)
+			}
+		}
+
+		for _, alias := range rule.Condition.Fields {
+			if !aliases[alias] {
+				l.errorf("CROSS_NODE_ALIAS_NOT_FOUND", Location{RuleID: rule.ID}, "cross-node rule %s condition uses undeclared alias %s", rule.ID, alias)
+			}
+		}
+	}
+}
+
+// graphFields returns every field ID declared by any node
+func (l *linter) graphFields() map[string]bool {
+	fields := make(map[string]bool)
+	for _, node := range l.graph.Nodes {
+		if node == nil {
+			continue
+		}
+		for _, field := range node.Fields {
+			fields[field.ID] = true
+		}
+	}
+	return fields
+}
+
+// nodeHasField reports whether a node declares a field
+func nodeHasField(node *types.Node, fieldID string) bool {
+	for _, field := range node.Fields {
+		if field.ID == fieldID {
+			return true
+		}
+	}
+	return false
+}
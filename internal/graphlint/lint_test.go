@@ -0,0 +1,265 @@
+package graphlint
+
+import (
+	"testing"
+
+	"onboarding-system/internal/types"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestGraph builds start -> details -> end with a business type field
+func newTestGraph() *types.Graph {
+	graph := types.NewGraph("Lint Test", "")
+
+	start := &types.Node{ID: "start", Type: types.NodeTypeStart, Name: "Start", Fields: []types.Field{
+		{ID: "business_type", Type: types.FieldTypeSelect, Options: []string{"individual", "llp"}},
+	}, Validation: types.ValidationRules{RequiredFields: []string{"business_type"}}}
+	details := &types.Node{ID: "details", Type: types.NodeTypeInput, Name: "Details", Fields: []types.Field{
+		{ID: "pan_number", Type: types.FieldTypeText, Validation: types.FieldValidation{Pattern: `^[A-Z]{5}[0-9]{4}[A-Z]$`}},
+	}}
+	end := &types.Node{ID: "end", Type: types.NodeTypeEnd, Name: "End"}
+
+	graph.Nodes = map[string]*types.Node{"start": start, "details": details, "end": end}
+	graph.Edges = map[string]*types.Edge{
+		"e1": {ID: "e1", FromNodeID: "start", ToNodeID: "details", Condition: types.EdgeCondition{Type: "always"}},
+		"e2": {ID: "e2", FromNodeID: "details", ToNodeID: "end", Condition: types.EdgeCondition{Type: "always"}},
+	}
+	graph.StartNodeID = "start"
+
+	return graph
+}
+
+func codes(report *Report) []string {
+	result := make([]string, 0, len(report.Diagnostics))
+	for _, d := range report.Diagnostics {
+		result = append(result, d.Code)
+	}
+	return result
+}
+
+func TestLintValidGraph(t *testing.T) {
+	report := Lint(newTestGraph())
+
+	assert.True(t, report.Valid)
+	assert.Empty(t, report.Diagnostics)
+}
+
+func TestLintDiagnostics(t *testing.T) {
+	tests := []struct {
+		name     string
+		mutate   func(g *types.Graph)
+		code     string
+		severity Severity
+	}{
+		{"dangling edge target", func(g *types.Graph) {
+			g.Edges["e3"] = &types.Edge{ID: "e3", FromNodeID: "details", ToNodeID: "missing"}
+		}, "EDGE_TARGET_NOT_FOUND", SeverityError},
+		{"dangling edge source", func(g *types.Graph) {
+			g.Edges["e3"] = &types.Edge{ID: "e3", FromNodeID: "missing", ToNodeID: "end"}
+		}, "EDGE_SOURCE_NOT_FOUND", SeverityError},
+		{"missing start node", func(g *types.Graph) {
+			g.StartNodeID = "nowhere"
+		}, "START_NODE_NOT_FOUND", SeverityError},
+		{"unreachable node", func(g *types.Graph) {
+			g.Nodes["orphan"] = &types.Node{ID: "orphan", Type: types.NodeTypeInput}
+			g.Edges["e3"] = &types.Edge{ID: "e3", FromNodeID: "orphan", ToNodeID: "end"}
+		}, "NODE_UNREACHABLE", SeverityWarning},
+		{"no end node", func(g *types.Graph) {
+			g.Nodes["end"].Type = types.NodeTypeInput
+		}, "END_NODE_MISSING", SeverityError},
+		{"cycle without exit", func(g *types.Graph) {
+			g.Nodes["loop_a"] = &types.Node{ID: "loop_a", Type: types.NodeTypeInput}
+			g.Nodes["loop_b"] = &types.Node{ID: "loop_b", Type: types.NodeTypeInput}
+			g.Edges["e3"] = &types.Edge{ID: "e3", FromNodeID: "details", ToNodeID: "loop_a"}
+			g.Edges["e4"] = &types.Edge{ID: "e4", FromNodeID: "loop_a", ToNodeID: "loop_b"}
+			g.Edges["e5"] = &types.Edge{ID: "e5", FromNodeID: "loop_b", ToNodeID: "loop_a"}
+		}, "CYCLE_WITHOUT_EXIT", SeverityError},
+		{"undefined required field", func(g *types.Graph) {
+			g.Nodes["details"].Validation.RequiredFields = []string{"gst_number"}
+		}, "REQUIRED_FIELD_NOT_DEFINED", SeverityError},
+		{"invalid pattern", func(g *types.Graph) {
+			g.Nodes["details"].Fields[0].Validation.Pattern = "[A-Z"
+		}, "FIELD_PATTERN_INVALID", SeverityError},
+		{"cross-node unknown field", func(g *types.Graph) {
+			g.CrossNodeValidation = []types.CrossNodeValidationRule{{
+				ID:        "pan_match",
+				Fields:    []types.CrossNodeFieldReference{{NodeID: "details", FieldID: "signatory_pan", Alias: "pan"}},
+				Condition: types.CrossNodeCondition{Type: "field_match", Fields: []string{"pan"}},
+			}}
+		}, "CROSS_NODE_FIELD_NOT_FOUND", SeverityError},
+		{"cross-node unknown node", func(g *types.Graph) {
+			g.CrossNodeValidation = []types.CrossNodeValidationRule{{
+				ID:     "pan_match",
+				Fields: []types.CrossNodeFieldReference{{NodeID: "signatory", FieldID: "pan_number", Alias: "pan"}},
+			}}
+		}, "CROSS_NODE_NODE_NOT_FOUND", SeverityError},
+		{"cross-node unknown logic", func(g *types.Graph) {
+			g.CrossNodeValidation = []types.CrossNodeValidationRule{{
+				ID:        "pan_match",
+				Fields:    []types.CrossNodeFieldReference{{NodeID: "details", FieldID: "pan_number", Alias: "pan"}},
+				Condition: types.CrossNodeCondition{Type: "custom_logic", Logic: "pan_matches_aadhaar"},
+			}}
+		}, "CUSTOM_RULE_UNKNOWN", SeverityError},
+		{"invalid expression", func(g *types.Graph) {
+			g.Nodes["details"].Validation.CustomRules = []string{`len(pan_numbr) == 10`}
+		}, "EXPRESSION_INVALID", SeverityError},
+		{"rule group unknown field", func(g *types.Graph) {
+			g.RuleGroups = []types.RuleGroup{{
+				ID:             "basic",
+				RequiredNodes:  []string{"details"},
+				RequiredFields: map[string][]string{"details": {"gst_number"}},
+			}}
+		}, "RULE_GROUP_FIELD_NOT_FOUND", SeverityError},
+		{"unknown named custom rule", func(g *types.Graph) {
+			g.Nodes["details"].Fields[0].Validation.CustomRules = []string{"pan_validaton"}
+		}, "CUSTOM_RULE_UNKNOWN", SeverityError},
+		{"rule group unknown business type", func(g *types.Graph) {
+			g.RuleGroups = []types.RuleGroup{{ID: "basic", BusinessTypes: []string{"trust"}}}
+		}, "RULE_GROUP_BUSINESS_TYPE_UNKNOWN", SeverityError},
+		{"dependency with two kinds", func(g *types.Graph) {
+			g.Nodes["details"].Dependencies = []types.NodeDependency{{Expression: &types.DependencyExpression{
+				All:   []types.DependencyExpression{{Field: "business_type", Operator: "eq", Value: "llp"}},
+				Field: "business_type",
+			}}}
+		}, "DEPENDENCY_EXPRESSION_INVALID", SeverityError},
+		{"dependency empty group", func(g *types.Graph) {
+			g.Nodes["details"].Dependencies = []types.NodeDependency{{Expression: &types.DependencyExpression{
+				Not: &types.DependencyExpression{Any: []types.DependencyExpression{}},
+			}}}
+		}, "DEPENDENCY_EXPRESSION_INVALID", SeverityError},
+		{"dependency unsupported operator", func(g *types.Graph) {
+			g.Nodes["details"].Dependencies = []types.NodeDependency{{Expression: &types.DependencyExpression{
+				Any: []types.DependencyExpression{{Field: "business_type", Operator: "equals", Value: "llp"}},
+			}}}
+		}, "DEPENDENCY_OPERATOR_UNSUPPORTED", SeverityError},
+		{"dependency unknown value field", func(g *types.Graph) {
+			g.Nodes["details"].Dependencies = []types.NodeDependency{{Expression: &types.DependencyExpression{
+				Field: "pan_number", Operator: "ne", ValueField: "signatory_pan",
+			}}}
+		}, "DEPENDENCY_FIELD_NOT_FOUND", SeverityWarning},
+		{"dependency malformed operand", func(g *types.Graph) {
+			g.Nodes["details"].Dependencies = []types.NodeDependency{{FieldID: "business_type", Operator: "in", Value: "llp"}}
+		}, "DEPENDENCY_OPERATOR_UNSUPPORTED", SeverityError},
+		{"edge unsupported operator", func(g *types.Graph) {
+			g.Edges["e2"].Condition = types.EdgeCondition{Type: "field_value", Field: "pan_number", Operator: "gt_ci", Value: "A"}
+		}, "EDGE_OPERATOR_UNSUPPORTED", SeverityError},
+		{"edge invalid pattern", func(g *types.Graph) {
+			g.Edges["e2"].Condition = types.EdgeCondition{Type: "field_value", Field: "pan_number", Operator: "matches", Value: "[A-Z"}
+		}, "EDGE_OPERATOR_UNSUPPORTED", SeverityError},
+		{"validation condition unsupported operator", func(g *types.Graph) {
+			g.Nodes["details"].Validation.Conditions = []types.ValidationCondition{{Field: "pan_number", Operator: "like", Value: "A%"}}
+		}, "VALIDATION_OPERATOR_UNSUPPORTED", SeverityError},
+		{"rule group between without bounds", func(g *types.Graph) {
+			g.RuleGroups = []types.RuleGroup{{ID: "basic", ConditionalFields: map[string]types.ConditionalFieldRule{
+				"pan": {NodeID: "details", FieldID: "pan_number", Condition: "business_type", Operator: "between", Value: "llp"},
+			}}}
+		}, "RULE_GROUP_OPERATOR_UNSUPPORTED", SeverityError},
+		{"unknown entry node", func(g *types.Graph) {
+			g.EntryNodes = []string{"phone"}
+		}, "ENTRY_NODE_NOT_FOUND", SeverityError},
+		{"node rule on unknown node", func(g *types.Graph) {
+			g.NodeRules = []types.NodeRule{{ID: "r1", NodeID: "phone"}}
+		}, "NODE_RULE_NODE_NOT_FOUND", SeverityError},
+		{"node rule disables unknown edge", func(g *types.Graph) {
+			g.NodeRules = []types.NodeRule{{ID: "r1", NodeID: "details", Actions: []types.RuleAction{
+				{Type: types.RuleActionDisableEdge, Target: "e9"},
+			}}}
+		}, "NODE_RULE_ACTION_INVALID", SeverityError},
+		{"node rule unknown action", func(g *types.Graph) {
+			g.NodeRules = []types.NodeRule{{ID: "r1", NodeID: "details", Actions: []types.RuleAction{
+				{Type: "skip_node", Target: "end"},
+			}}}
+		}, "NODE_RULE_ACTION_INVALID", SeverityError},
+		{"node rule unsupported operator", func(g *types.Graph) {
+			g.NodeRules = []types.NodeRule{{ID: "r1", NodeID: "details", Conditions: []types.ActivationCondition{
+				{Field: "pan_number", Operator: "equals", Value: "A"},
+			}}}
+		}, "NODE_RULE_OPERATOR_UNSUPPORTED", SeverityError},
+		{"activation rule unknown node", func(g *types.Graph) {
+			g.ActivationRules = []types.ActivationRule{{ID: "basic", RequiredNodes: []string{"kyc"}}}
+		}, "ACTIVATION_RULE_NODE_NOT_FOUND", SeverityError},
+		{"activation rule malformed operand", func(g *types.Graph) {
+			g.ActivationRules = []types.ActivationRule{{ID: "basic", Conditions: []types.ActivationCondition{
+				{Field: "business_type", Operator: "in", Value: "llp"},
+			}}}
+		}, "ACTIVATION_OPERATOR_UNSUPPORTED", SeverityError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			graph := newTestGraph()
+			tt.mutate(graph)
+
+			report := Lint(graph)
+
+			assert.Contains(t, codes(report), tt.code)
+			for _, d := range report.Diagnostics {
+				if d.Code == tt.code {
+					assert.Equal(t, tt.severity, d.Severity)
+				}
+			}
+			assert.Equal(t, tt.severity != SeverityError, report.Valid)
+		})
+	}
+}
+
+func TestLintCycleWithExit(t *testing.T) {
+	graph := newTestGraph()
+	graph.Edges["back"] = &types.Edge{ID: "back", FromNodeID: "details", ToNodeID: "start"}
+
+	report := Lint(graph)
+
+	assert.True(t, report.Valid)
+	assert.NotContains(t, codes(report), "CYCLE_WITHOUT_EXIT")
+}
+
+func TestLintDependencyExpression(t *testing.T) {
+	graph := newTestGraph()
+	graph.Nodes["details"].Dependencies = []types.NodeDependency{
+		{FieldID: "business_type", Operator: "eq", Value: "llp"},
+		{Expression: &types.DependencyExpression{All: []types.DependencyExpression{
+			{Field: "business_type", Operator: "in", Value: []interface{}{"individual", "llp"}},
+			{Not: &types.DependencyExpression{Field: "pan_number", Operator: "eq", ValueField: "business_type"}},
+			{Field: "pan_number", Operator: "matches_ci", Value: `^[a-z]{5}`},
+			{Field: "business_type", Operator: "not_empty"},
+		}}},
+	}
+
+	report := Lint(graph)
+
+	assert.True(t, report.Valid)
+	assert.Empty(t, report.Diagnostics)
+}
+
+func TestLintEntryNodeReachability(t *testing.T) {
+	graph := newTestGraph()
+	graph.Nodes["phone"] = &types.Node{ID: "phone", Type: types.NodeTypeInput, Name: "Phone"}
+	graph.Edges["e3"] = &types.Edge{ID: "e3", FromNodeID: "phone", ToNodeID: "details", Condition: types.EdgeCondition{Type: "always"}}
+
+	assert.Contains(t, codes(Lint(graph)), "NODE_UNREACHABLE")
+
+	graph.EntryNodes = []string{"phone"}
+	report := Lint(graph)
+	assert.True(t, report.Valid)
+	assert.Empty(t, report.Diagnostics)
+}
+
+func TestLintCrossNodeLogic(t *testing.T) {
+	graph := newTestGraph()
+	for _, logic := range []string{
+		types.CrossNodeLogicBusinessNameMatchesBankName,
+		types.CrossNodeLogicPanMatchesSignatoryPan,
+		types.CrossNodeLogicAddressConsistency,
+	} {
+		graph.CrossNodeValidation = append(graph.CrossNodeValidation, types.CrossNodeValidationRule{
+			ID:        logic,
+			Fields:    []types.CrossNodeFieldReference{{NodeID: "details", FieldID: "pan_number", Alias: "pan"}},
+			Condition: types.CrossNodeCondition{Type: "custom_logic", Logic: logic},
+		})
+	}
+
+	report := Lint(graph)
+	assert.True(t, report.Valid)
+	assert.Empty(t, report.Diagnostics)
+}
@@ -0,0 +1,349 @@
+package graphlint
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"onboarding-system/internal/expr"
+	"onboarding-system/internal/operators"
+	"onboarding-system/internal/types"
+	"onboarding-system/internal/validators"
+)
+
+// checkOperand reports an unsupported operator or a malformed operand. An operand taken from
+// another field at runtime cannot be checked, so only the operator is.
+func checkOperand(operator string, operand interface{}, fromField bool) error {
+	if fromField {
+		if !operators.Supported(operator) {
+			return fmt.Errorf("%w: %q", operators.ErrUnknownOperator, operator)
+		}
+		return nil
+	}
+	return operators.ValidateOperand(operator, operand)
+}
+
+// checkDependencies validates the dependencies declared on nodes and their expressions
+func (l *linter) checkDependencies() {
+	fields := l.graphFields()
+
+	for _, nodeID := range l.sortedNodeIDs() {
+		node := l.graph.Nodes[nodeID]
+		if node == nil {
+			continue
+		}
+		for _, dependency := range node.Dependencies {
+			if dependency.Expression != nil {
+				l.checkDependencyExpression(nodeID, *dependency.Expression, fields)
+				continue
+			}
+			l.checkDependencyExpression(nodeID, types.DependencyExpression{
+				Field:    dependency.FieldID,
+				Operator: dependency.Operator,
+				Value:    dependency.Value,
+			}, fields)
+		}
+	}
+}
+
+// checkDependencyExpression checks that an expression is a well-formed group or comparison
+func (l *linter) checkDependencyExpression(nodeID string, expression types.DependencyExpression, fields map[string]bool) {
+	loc := Location{NodeID: nodeID}
+
+	kinds := 0
+	if expression.All != nil {
+		kinds++
+	}
+	if expression.Any != nil {
+		kinds++
+	}
+	if expression.Not != nil {
+		kinds++
+	}
+	if expression.Field != "" || expression.Operator != "" {
+		kinds++
+	}
+	if kinds != 1 {
+		l.errorf("DEPENDENCY_EXPRESSION_INVALID", loc, "dependency of node %s must set exactly one of all, any, not or a field comparison", nodeID)
+		return
+	}
+
+	switch {
+	case expression.All != nil || expression.Any != nil:
+		children := expression.All
+		if expression.Any != nil {
+			children = expression.Any
+		}
+		if len(children) == 0 {
+			l.errorf("DEPENDENCY_EXPRESSION_INVALID", loc, "dependency of node %s has an empty group", nodeID)
+		}
+		for _, child := range children {
+			l.checkDependencyExpression(nodeID, child, fields)
+		}
+
+	case expression.Not != nil:
+		l.checkDependencyExpression(nodeID, *expression.Not, fields)
+
+	default:
+		loc.FieldID = expression.Field
+		if expression.Field == "" {
+			l.errorf("DEPENDENCY_EXPRESSION_INVALID", loc, "dependency of node %s compares no field", nodeID)
+			return
+		}
+		if expression.Operator != "custom" {
+			if err := checkOperand(expression.Operator, expression.Value, expression.ValueField != ""); err != nil {
+				l.errorf("DEPENDENCY_OPERATOR_UNSUPPORTED", loc, "dependency of node %s has an invalid comparison: %v", nodeID, err)
+			}
+		}
+		if expression.ValueField != "" && expression.Value != nil {
+			l.errorf("DEPENDENCY_EXPRESSION_INVALID", loc, "dependency of node %s sets both value and value_field", nodeID)
+		}
+		if !fields[expression.Field] {
+			l.warnf("DEPENDENCY_FIELD_NOT_FOUND", loc, "dependency of node %s references field %s which no node defines", nodeID, expression.Field)
+		}
+		if expression.ValueField != "" && !fields[expression.ValueField] {
+			l.warnf("DEPENDENCY_FIELD_NOT_FOUND", loc, "dependency of node %s references field %s which no node defines", nodeID, expression.ValueField)
+		}
+	}
+}
+
+// checkExpressions parses and type-checks every custom rule expression in the graph
+// and checks that named rules are registered
+func (l *linter) checkExpressions() {
+	env := graphRuleEnv(l.graph)
+
+	compile := func(loc Location, rule string, env expr.Env) {
+		if strings.TrimSpace(rule) == "" {
+			return
+		}
+		if expr.IsIdentifier(rule) {
+			if !validators.Default.Has(rule) {
+				l.errorf("CUSTOM_RULE_UNKNOWN", loc, "custom rule %s is not a registered validation rule", rule)
+			}
+			return
+		}
+		if _, err := expr.Compile(rule, env); err != nil {
+			l.errorf("EXPRESSION_INVALID", loc, "%v", err)
+		}
+	}
+
+	for _, nodeID := range l.sortedNodeIDs() {
+		node := l.graph.Nodes[nodeID]
+		if node == nil {
+			continue
+		}
+		for _, rule := range node.Validation.CustomRules {
+			compile(Location{NodeID: nodeID}, rule, env)
+		}
+
+		for _, field := range node.Fields {
+			fieldEnv := expr.Env{
+				field.ID: fieldRuleType(field.Type),
+				"value":  fieldRuleType(field.Type),
+			}
+			for _, rule := range field.Validation.CustomRules {
+				compile(Location{NodeID: nodeID, FieldID: field.ID}, rule, fieldEnv)
+			}
+		}
+	}
+
+	for _, edgeID := range l.sortedEdgeIDs() {
+		edge := l.graph.Edges[edgeID]
+		if edge != nil && edge.Condition.Type == "custom" {
+			compile(Location{EdgeID: edgeID}, edge.Condition.CustomRule, env)
+		}
+	}
+
+	for _, rule := range l.graph.CrossNodeValidation {
+		// Named logic the cross-node validation engine implements needs no registered rule
+		if rule.Condition.Type != "custom_logic" || types.IsCrossNodeLogic(rule.Condition.Logic) {
+			continue
+		}
+		aliasEnv := make(expr.Env)
+		for _, ref := range rule.Fields {
+			aliasEnv[ref.Alias] = env[ref.FieldID]
+		}
+		compile(Location{RuleID: rule.ID}, rule.Condition.Logic, aliasEnv)
+	}
+}
+
+// graphRuleEnv declares every field in the graph as an expression identifier
+func graphRuleEnv(graph *types.Graph) expr.Env {
+	env := make(expr.Env)
+	for _, node := range graph.Nodes {
+		if node == nil {
+			continue
+		}
+		for _, field := range node.Fields {
+			t := fieldRuleType(field.Type)
+			if existing, ok := env[field.ID]; ok && existing != t {
+				t = expr.Any
+			}
+			env[field.ID] = t
+		}
+	}
+	return env
+}
+
+// fieldRuleType maps a field type to its expression type
+func fieldRuleType(fieldType types.FieldType) expr.Type {
+	switch fieldType {
+	case types.FieldTypeNumber:
+		return expr.Number
+	case types.FieldTypeText, types.FieldTypeEmail, types.FieldTypeDate, types.FieldTypeRadio:
+		return expr.String
+	default:
+		return expr.Any
+	}
+}
+
+// checkRuleGroups validates that rule groups reference nodes and fields that exist in the graph
+func (l *linter) checkRuleGroups() {
+	seen := make(map[string]bool)
+	businessTypes := graphBusinessTypes(l.graph)
+
+	for _, ruleGroup := range l.graph.RuleGroups {
+		loc := Location{RuleID: ruleGroup.ID}
+		if ruleGroup.ID == "" {
+			l.errorf("RULE_GROUP_ID_MISSING", loc, "rule group %q has no id", ruleGroup.Name)
+			continue
+		}
+		if seen[ruleGroup.ID] {
+			l.errorf("RULE_GROUP_DUPLICATE", loc, "rule group %s is declared more than once", ruleGroup.ID)
+		}
+		seen[ruleGroup.ID] = true
+
+		for _, businessType := range ruleGroup.BusinessTypes {
+			if businessTypes != nil && !businessTypes[businessType] {
+				l.errorf("RULE_GROUP_BUSINESS_TYPE_UNKNOWN", loc, "rule group %s applies to unknown business type %s", ruleGroup.ID, businessType)
+			}
+		}
+
+		for _, nodeID := range ruleGroup.RequiredNodes {
+			if _, exists := l.graph.Nodes[nodeID]; !exists {
+				l.errorf("RULE_GROUP_NODE_NOT_FOUND", Location{NodeID: nodeID, RuleID: ruleGroup.ID}, "rule group %s requires unknown node %s", ruleGroup.ID, nodeID)
+			}
+		}
+
+		nodeIDs := make([]string, 0, len(ruleGroup.RequiredFields))
+		for nodeID := range ruleGroup.RequiredFields {
+			nodeIDs = append(nodeIDs, nodeID)
+		}
+		sort.Strings(nodeIDs)
+
+		for _, nodeID := range nodeIDs {
+			node, exists := l.graph.Nodes[nodeID]
+			if !exists || node == nil {
+				l.errorf("RULE_GROUP_NODE_NOT_FOUND", Location{NodeID: nodeID, RuleID: ruleGroup.ID}, "rule group %s requires fields of unknown node %s", ruleGroup.ID, nodeID)
+				continue
+			}
+			for _, fieldID := range ruleGroup.RequiredFields[nodeID] {
+				if !nodeHasField(node, fieldID) {
+					l.errorf("RULE_GROUP_FIELD_NOT_FOUND", Location{NodeID: nodeID, FieldID: fieldID, RuleID: ruleGroup.ID}, "rule group %s requires field %s which node %s does not define", ruleGroup.ID, fieldID, nodeID)
+				}
+			}
+		}
+
+		ruleIDs := make([]string, 0, len(ruleGroup.ConditionalFields))
+		for ruleID := range ruleGroup.ConditionalFields {
+			ruleIDs = append(ruleIDs, ruleID)
+		}
+		sort.Strings(ruleIDs)
+
+		for _, ruleID := range ruleIDs {
+			rule := ruleGroup.ConditionalFields[ruleID]
+			ruleLoc := Location{NodeID: rule.NodeID, FieldID: rule.FieldID, RuleID: ruleGroup.ID}
+
+			node, exists := l.graph.Nodes[rule.NodeID]
+			if !exists || node == nil {
+				l.errorf("RULE_GROUP_NODE_NOT_FOUND", ruleLoc, "conditional field %s of rule group %s references unknown node %s", ruleID, ruleGroup.ID, rule.NodeID)
+				continue
+			}
+			if !nodeHasField(node, rule.FieldID) {
+				l.errorf("RULE_GROUP_FIELD_NOT_FOUND", ruleLoc, "conditional field %s of rule group %s references field %s which node %s does not define", ruleID, ruleGroup.ID, rule.FieldID, rule.NodeID)
+			}
+			if rule.Condition == "" {
+				l.errorf("RULE_GROUP_CONDITION_MISSING", ruleLoc, "conditional field %s of rule group %s has no condition field", ruleID, ruleGroup.ID)
+			}
+			if err := checkOperand(rule.Operator, rule.Value, false); err != nil {
+				l.errorf("RULE_GROUP_OPERATOR_UNSUPPORTED", ruleLoc, "conditional field %s of rule group %s has an invalid comparison: %v", ruleID, ruleGroup.ID, err)
+			}
+		}
+	}
+}
+
+// graphBusinessTypes returns the options of the graph's business_type field, or nil if it has none
+func graphBusinessTypes(graph *types.Graph) map[string]bool {
+	for _, node := range graph.Nodes {
+		if node == nil {
+			continue
+		}
+		for _, field := range node.Fields {
+			if field.ID == "business_type" && len(field.Options) > 0 {
+				options := make(map[string]bool)
+				for _, option := range field.Options {
+					options[option] = true
+				}
+				return options
+			}
+		}
+	}
+	return nil
+}
+
+// checkAdvancedRules validates entry nodes, node rules and activation rules
+func (l *linter) checkAdvancedRules() {
+	for _, entryNodeID := range l.graph.EntryNodes {
+		if _, exists := l.graph.Nodes[entryNodeID]; !exists {
+			l.errorf("ENTRY_NODE_NOT_FOUND", Location{NodeID: entryNodeID}, "entry node %s does not exist", entryNodeID)
+		}
+	}
+
+	activationRules := make(map[string]bool, len(l.graph.ActivationRules))
+	for _, rule := range l.graph.ActivationRules {
+		activationRules[rule.ID] = true
+
+		for _, nodeID := range append(append([]string(nil), rule.RequiredNodes...), rule.ExcludedNodes...) {
+			if _, exists := l.graph.Nodes[nodeID]; !exists {
+				l.errorf("ACTIVATION_RULE_NODE_NOT_FOUND", Location{NodeID: nodeID, RuleID: rule.ID}, "activation rule %s references unknown node %s", rule.ID, nodeID)
+			}
+		}
+		for _, condition := range rule.Conditions {
+			if err := checkOperand(condition.Operator, condition.Value, false); err != nil {
+				l.errorf("ACTIVATION_OPERATOR_UNSUPPORTED", Location{FieldID: condition.Field, RuleID: rule.ID}, "activation rule %s has an invalid condition: %v", rule.ID, err)
+			}
+		}
+	}
+
+	for _, rule := range l.graph.NodeRules {
+		loc := Location{NodeID: rule.NodeID, RuleID: rule.ID}
+
+		if _, exists := l.graph.Nodes[rule.NodeID]; !exists {
+			l.errorf("NODE_RULE_NODE_NOT_FOUND", loc, "node rule %s applies to unknown node %s", rule.ID, rule.NodeID)
+		}
+		for _, condition := range rule.Conditions {
+			if err := checkOperand(condition.Operator, condition.Value, false); err != nil {
+				l.errorf("NODE_RULE_OPERATOR_UNSUPPORTED", Location{NodeID: rule.NodeID, FieldID: condition.Field, RuleID: rule.ID}, "node rule %s has an invalid condition: %v", rule.ID, err)
+			}
+		}
+
+		for _, action := range rule.Actions {
+			switch action.Type {
+			case types.RuleActionDisableEdge:
+				if _, exists := l.graph.Edges[action.Target]; !exists {
+					l.errorf("NODE_RULE_ACTION_INVALID", loc, "node rule %s disables unknown edge %s", rule.ID, action.Target)
+				}
+			case types.RuleActionRequireNode, types.RuleActionExcludeNode:
+				if _, exists := l.graph.Nodes[action.Target]; !exists {
+					l.errorf("NODE_RULE_ACTION_INVALID", loc, "node rule %s action %s targets unknown node %s", rule.ID, action.Type, action.Target)
+				}
+			case types.RuleActionCheckActivation:
+				if !activationRules[action.Target] {
+					l.errorf("NODE_RULE_ACTION_INVALID", loc, "node rule %s checks unknown activation rule %s", rule.ID, action.Target)
+				}
+			default:
+				l.errorf("NODE_RULE_ACTION_INVALID", loc, "node rule %s has unknown action type %q", rule.ID, action.Type)
+			}
+		}
+	}
+}
@@ -0,0 +1,221 @@
+// Package graphloader reads declarative onboarding graph definitions from YAML and JSON files.
+package graphloader
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"onboarding-system/internal/graphlint"
+	"onboarding-system/internal/types"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Metadata keys recorded on graphs loaded from definition files
+const (
+	MetadataSourceFile     = "source_file"
+	MetadataSourceChecksum = "source_checksum"
+)
+
+// Definition is a graph loaded from a definition file
+type Definition struct {
+	Path     string
+	Checksum string // SHA-256 of the file contents
+	Graph    *types.Graph
+	Report   *graphlint.Report
+}
+
+// IsDefinitionFile reports whether a path has a graph definition extension
+func IsDefinitionFile(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml", ".json":
+		return true
+	}
+	return false
+}
+
+// LoadDir loads and lints every definition file in a directory, in file name order.
+// Files that fail to load are reported in the returned error; the rest are still returned.
+func LoadDir(dir string) ([]*Definition, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read graph directory: %w", err)
+	}
+
+	definitions := make([]*Definition, 0, len(entries))
+	sources := make(map[string]string)
+	var errs []error
+	for _, entry := range entries {
+		if entry.IsDir() || !IsDefinitionFile(entry.Name()) {
+			continue
+		}
+
+		path := filepath.Join(dir, entry.Name())
+		definition, err := LoadFile(path)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		if other, exists := sources[definition.Graph.ID]; exists {
+			errs = append(errs, fmt.Errorf("%s: graph ID %q is already defined in %s", path, definition.Graph.ID, other))
+			continue
+		}
+		sources[definition.Graph.ID] = path
+		definitions = append(definitions, definition)
+	}
+
+	return definitions, errors.Join(errs...)
+}
+
+// LoadFile loads and lints a single definition file. Lint errors fail the load.
+func LoadFile(path string) (*Definition, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read graph definition: %w", err)
+	}
+
+	graph, err := Parse(data, filepath.Ext(path))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	sum := sha256.Sum256(data)
+	definition := &Definition{
+		Path:     path,
+		Checksum: hex.EncodeToString(sum[:]),
+		Graph:    graph,
+		Report:   graphlint.Lint(graph),
+	}
+	graph.Metadata[MetadataSourceFile] = filepath.Base(path)
+	graph.Metadata[MetadataSourceChecksum] = definition.Checksum
+
+	if definition.Report.HasErrors() {
+		return nil, fmt.Errorf("%s: %s", path, definition.Report.Error())
+	}
+
+	return definition, nil
+}
+
+// Parse decodes a graph definition. The extension selects YAML (.yaml, .yml) or JSON (.json);
+// both use the JSON field names of types.Graph and unknown fields are rejected.
+func Parse(data []byte, ext string) (*types.Graph, error) {
+	switch strings.ToLower(ext) {
+	case ".yaml", ".yml":
+		var document interface{}
+		if err := yaml.Unmarshal(data, &document); err != nil {
+			return nil, fmt.Errorf("failed to parse yaml: %w", err)
+		}
+		converted, err := json.Marshal(document)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert yaml: %w", err)
+		}
+		data = converted
+	case ".json":
+	default:
+		return nil, fmt.Errorf("unsupported graph definition format: %s", ext)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+
+	var graph types.Graph
+	if err := decoder.Decode(&graph); err != nil {
+		return nil, fmt.Errorf("failed to decode graph definition: %w", err)
+	}
+
+	if err := normalize(&graph); err != nil {
+		return nil, err
+	}
+	return &graph, nil
+}
+
+// normalize fills in what a definition file may leave out: IDs taken from map keys,
+// empty metadata maps, timestamps and edge tracking.
+func normalize(graph *types.Graph) error {
+	if graph.ID == "" {
+		return fmt.Errorf("graph definition must have a stable id")
+	}
+	if graph.Name == "" {
+		graph.Name = graph.ID
+	}
+	if graph.Metadata == nil {
+		graph.Metadata = make(map[string]interface{})
+	}
+	if graph.Nodes == nil {
+		graph.Nodes = make(map[string]*types.Node)
+	}
+	if graph.Edges == nil {
+		graph.Edges = make(map[string]*types.Edge)
+	}
+
+	now := time.Now()
+	graph.CreatedAt = now
+	graph.UpdatedAt = now
+
+	for key, node := range graph.Nodes {
+		if node == nil {
+			return fmt.Errorf("node %q is empty", key)
+		}
+		if node.ID == "" {
+			node.ID = key
+		} else if node.ID != key {
+			return fmt.Errorf("node %q has mismatched id %q", key, node.ID)
+		}
+		if node.Metadata == nil {
+			node.Metadata = make(map[string]interface{})
+		}
+		for i := range node.Fields {
+			if node.Fields[i].Metadata == nil {
+				node.Fields[i].Metadata = make(map[string]interface{})
+			}
+		}
+		node.IncomingEdges = nil
+		node.OutgoingEdges = nil
+		node.CreatedAt = now
+		node.UpdatedAt = now
+	}
+
+	edgeIDs := make([]string, 0, len(graph.Edges))
+	for key, edge := range graph.Edges {
+		if edge == nil {
+			return fmt.Errorf("edge %q is empty", key)
+		}
+		if edge.ID == "" {
+			edge.ID = key
+		} else if edge.ID != key {
+			return fmt.Errorf("edge %q has mismatched id %q", key, edge.ID)
+		}
+		if edge.Condition.Type == "" {
+			edge.Condition.Type = "always"
+		}
+		if edge.Metadata == nil {
+			edge.Metadata = make(map[string]interface{})
+		}
+		edge.CreatedAt = now
+		edgeIDs = append(edgeIDs, key)
+	}
+
+	// Edge tracking is derived rather than authored
+	sort.Strings(edgeIDs)
+	for _, edgeID := range edgeIDs {
+		edge := graph.Edges[edgeID]
+		if from, exists := graph.Nodes[edge.FromNodeID]; exists {
+			from.OutgoingEdges = append(from.OutgoingEdges, edgeID)
+		}
+		if to, exists := graph.Nodes[edge.ToNodeID]; exists {
+			to.IncomingEdges = append(to.IncomingEdges, edgeID)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,97 @@
+package graphloader
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testDefinition = `
+id: test_flow
+name: Test Flow
+start_node_id: start
+nodes:
+  start:
+    type: start
+    fields:
+      - id: business_type
+        type: select
+        options: [individual]
+  done:
+    type: end
+    metadata:
+      required_for: [individual]
+edges:
+  start_to_done:
+    from_node_id: start
+    to_node_id: done
+`
+
+func writeFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	return path
+}
+
+func TestParseYAML(t *testing.T) {
+	graph, err := Parse([]byte(testDefinition), ".yaml")
+	require.NoError(t, err)
+
+	assert.Equal(t, "test_flow", graph.ID)
+	assert.Equal(t, "start", graph.Nodes["start"].ID)
+	assert.Equal(t, "always", graph.Edges["start_to_done"].Condition.Type)
+	assert.Equal(t, []string{"start_to_done"}, graph.Nodes["start"].OutgoingEdges)
+	assert.Equal(t, []string{"start_to_done"}, graph.Nodes["done"].IncomingEdges)
+	assert.Equal(t, []interface{}{"individual"}, graph.Nodes["done"].Metadata["required_for"])
+	assert.NotNil(t, graph.Nodes["start"].Fields[0].Metadata)
+}
+
+func TestParseRejectsInvalidDefinitions(t *testing.T) {
+	_, err := Parse([]byte("id: x\nnodez: {}\n"), ".yaml")
+	assert.ErrorContains(t, err, "nodez")
+
+	_, err = Parse([]byte(`{"name": "no id"}`), ".json")
+	assert.ErrorContains(t, err, "stable id")
+
+	_, err = Parse([]byte("id: x\nnodes:\n  a:\n    id: b\n"), ".yml")
+	assert.ErrorContains(t, err, "mismatched id")
+
+	_, err = Parse([]byte("id: x"), ".toml")
+	assert.Error(t, err)
+}
+
+func TestLoadDir(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "a.yaml", testDefinition)
+	writeFile(t, dir, "b.yml", testDefinition)
+	writeFile(t, dir, "broken.json", `{"id": "broken", "start_node_id": "missing"}`)
+	writeFile(t, dir, "README.md", "not a graph")
+
+	definitions, err := LoadDir(dir)
+
+	require.Len(t, definitions, 1)
+	assert.Equal(t, "a.yaml", definitions[0].Graph.Metadata[MetadataSourceFile])
+	assert.Len(t, definitions[0].Checksum, 64)
+	assert.ErrorContains(t, err, "already defined")
+	assert.ErrorContains(t, err, "broken.json")
+}
+
+func TestWatcherScan(t *testing.T) {
+	dir := t.TempDir()
+	path := writeFile(t, dir, "a.yaml", testDefinition)
+
+	watcher := NewWatcher(dir, time.Second)
+	assert.Empty(t, watcher.scan())
+
+	later := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(path, later, later))
+	added := writeFile(t, dir, "b.yaml", testDefinition)
+
+	assert.Equal(t, []string{path, added}, watcher.scan())
+	assert.Empty(t, watcher.scan())
+}
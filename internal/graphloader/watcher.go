@@ -0,0 +1,73 @@
+package graphloader
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// Watcher polls a directory for added or modified definition files.
+// Removed files are ignored: their graphs stay published because sessions may be pinned to them.
+type Watcher struct {
+	dir      string
+	interval time.Duration
+	modTimes map[string]time.Time
+}
+
+// NewWatcher creates a watcher; files already in the directory are not reported as changed
+func NewWatcher(dir string, interval time.Duration) *Watcher {
+	w := &Watcher{
+		dir:      dir,
+		interval: interval,
+		modTimes: make(map[string]time.Time),
+	}
+	w.scan()
+	return w
+}
+
+// Run polls until the context is cancelled, calling onChange with the files changed since the last poll
+func (w *Watcher) Run(ctx context.Context, onChange func(paths []string)) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if changed := w.scan(); len(changed) > 0 {
+				onChange(changed)
+			}
+		}
+	}
+}
+
+// scan records the modification time of every definition file and returns those that changed
+func (w *Watcher) scan() []string {
+	entries, err := os.ReadDir(w.dir)
+	if err != nil {
+		return nil
+	}
+
+	changed := make([]string, 0)
+	for _, entry := range entries {
+		if entry.IsDir() || !IsDefinitionFile(entry.Name()) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+
+		path := filepath.Join(w.dir, entry.Name())
+		if previous, seen := w.modTimes[path]; !seen || !previous.Equal(info.ModTime()) {
+			w.modTimes[path] = info.ModTime()
+			changed = append(changed, path)
+		}
+	}
+
+	sort.Strings(changed)
+	return changed
+}
@@ -3,8 +3,10 @@ package onboarding
 import (
 	"context"
 	"fmt"
-	"strings"
+	"sort"
+	"time"
 
+	"onboarding-system/internal/operators"
 	"onboarding-system/internal/types"
 
 	"github.com/sirupsen/logrus"
@@ -12,78 +14,27 @@ import (
 
 // AdvancedEngine handles the sophisticated graph traversal with multiple entry points and activation rules
 type AdvancedEngine struct {
+	engine *Engine // Validates node data and evaluates custom rules
 	logger *logrus.Logger
 }
 
 // NewAdvancedEngine creates a new advanced graph engine
 func NewAdvancedEngine(logger *logrus.Logger) *AdvancedEngine {
 	return &AdvancedEngine{
+		engine: NewEngine(logger),
 		logger: logger,
 	}
 }
 
-// ActivationRule represents a rule that determines if a user can be activated
-type ActivationRule struct {
-	ID            string                 `json:"id"`
-	Name          string                 `json:"name"`
-	Description   string                 `json:"description"`
-	Conditions    []ActivationCondition  `json:"conditions"`
-	RequiredNodes []string               `json:"required_nodes"` // Nodes that must be visited
-	ExcludedNodes []string               `json:"excluded_nodes"` // Nodes that should not be visited
-	Metadata      map[string]interface{} `json:"metadata"`
-}
-
-// ActivationCondition represents a condition for activation
-type ActivationCondition struct {
-	Field    string      `json:"field"`
-	Operator string      `json:"operator"`
-	Value    interface{} `json:"value"`
-	Required bool        `json:"required"` // If true, this condition must be met
-}
-
-// NodeRule represents rules that apply when a node is visited
-type NodeRule struct {
-	ID         string                 `json:"id"`
-	NodeID     string                 `json:"node_id"`
-	RuleType   string                 `json:"rule_type"` // "validation", "path_limitation", "activation_check"
-	Conditions []ActivationCondition  `json:"conditions"`
-	Actions    []RuleAction           `json:"actions"`
-	Metadata   map[string]interface{} `json:"metadata"`
-}
-
-// RuleAction represents an action to take when a rule is triggered
-type RuleAction struct {
-	Type     string                 `json:"type"`   // "disable_edge", "require_node", "exclude_node", "check_activation"
-	Target   string                 `json:"target"` // Edge ID, Node ID, or Activation Rule ID
-	Metadata map[string]interface{} `json:"metadata"`
-}
-
-// AdvancedGraph extends the basic graph with activation rules and node rules
+// AdvancedGraph is a graph evaluated with its activation rules, node rules and entry nodes
 type AdvancedGraph struct {
 	*types.Graph
-	ActivationRules []ActivationRule `json:"activation_rules"`
-	NodeRules       []NodeRule       `json:"node_rules"`
-	EntryNodes      []string         `json:"entry_nodes"` // Nodes that can be starting points
 }
 
-// AdvancedSession extends the basic session with activation tracking
+// AdvancedSession is a session together with its activation tracking
 type AdvancedSession struct {
 	*types.Session
-	VisitedNodes     []string   `json:"visited_nodes"`
-	DisabledEdges    []string   `json:"disabled_edges"`
-	ExcludedNodes    []string   `json:"excluded_nodes"`
-	ActivationStatus string     `json:"activation_status"` // "pending", "activated", "failed"
-	ActivationRules  []string   `json:"activation_rules"`  // Rules that have been checked
-	PathHistory      []PathStep `json:"path_history"`
-}
-
-// PathStep represents a step in the user's path
-type PathStep struct {
-	NodeID        string                 `json:"node_id"`
-	Timestamp     int64                  `json:"timestamp"`
-	Data          map[string]interface{} `json:"data"`
-	RulesApplied  []string               `json:"rules_applied"`
-	EdgesDisabled []string               `json:"edges_disabled"`
+	*types.AdvancedSessionState
 }
 
 // EvaluateNodeRules evaluates all rules for a specific node when it's visited
@@ -94,6 +45,7 @@ func (e *AdvancedEngine) EvaluateNodeRules(ctx context.Context, graph *AdvancedG
 		RequiredNodes:    make([]string, 0),
 		ExcludedNodes:    make([]string, 0),
 		ActivationChecks: make([]string, 0),
+		AppliedRules:     make([]string, 0),
 		Metadata:         make(map[string]interface{}),
 	}
 
@@ -105,17 +57,20 @@ func (e *AdvancedEngine) EvaluateNodeRules(ctx context.Context, graph *AdvancedG
 				e.logger.WithError(err).WithField("rule_id", rule.ID).Error("Failed to evaluate rule")
 				continue
 			}
+			if len(ruleResult.TriggeredActions) > 0 {
+				result.AppliedRules = append(result.AppliedRules, rule.ID)
+			}
 
 			// Apply rule actions
 			for _, action := range ruleResult.TriggeredActions {
 				switch action.Type {
-				case "disable_edge":
+				case types.RuleActionDisableEdge:
 					result.DisabledEdges = append(result.DisabledEdges, action.Target)
-				case "require_node":
+				case types.RuleActionRequireNode:
 					result.RequiredNodes = append(result.RequiredNodes, action.Target)
-				case "exclude_node":
+				case types.RuleActionExcludeNode:
 					result.ExcludedNodes = append(result.ExcludedNodes, action.Target)
-				case "check_activation":
+				case types.RuleActionCheckActivation:
 					result.ActivationChecks = append(result.ActivationChecks, action.Target)
 				}
 			}
@@ -159,8 +114,15 @@ func (e *AdvancedEngine) CheckActivation(ctx context.Context, graph *AdvancedGra
 func (e *AdvancedEngine) GetAvailablePaths(ctx context.Context, graph *AdvancedGraph, currentNodeID string, session *AdvancedSession) ([]*types.Node, error) {
 	availableNodes := make([]*types.Node, 0)
 
-	// Get all edges from current node
-	for _, edge := range graph.Edges {
+	// Get all edges from current node, in edge ID order so the first path is stable
+	edgeIDs := make([]string, 0, len(graph.Edges))
+	for edgeID := range graph.Edges {
+		edgeIDs = append(edgeIDs, edgeID)
+	}
+	sort.Strings(edgeIDs)
+
+	for _, edgeID := range edgeIDs {
+		edge := graph.Edges[edgeID]
 		if edge.FromNodeID == currentNodeID {
 			// Check if edge is disabled
 			if e.isEdgeDisabled(edge.ID, session.DisabledEdges) {
@@ -184,11 +146,12 @@ func (e *AdvancedEngine) GetAvailablePaths(ctx context.Context, graph *AdvancedG
 	return availableNodes, nil
 }
 
-// GetEntryNodes returns all possible entry nodes for the graph
+// GetEntryNodes returns all possible entry nodes for the graph: the start node followed by the
+// graph's other entry nodes
 func (e *AdvancedEngine) GetEntryNodes(ctx context.Context, graph *AdvancedGraph) []*types.Node {
-	entryNodes := make([]*types.Node, 0)
+	entryNodes := make([]*types.Node, 0, len(graph.EntryNodes)+1)
 
-	for _, nodeID := range graph.EntryNodes {
+	for _, nodeID := range appendUnique([]string{graph.StartNodeID}, graph.EntryNodes...) {
 		if node, exists := graph.Nodes[nodeID]; exists {
 			entryNodes = append(entryNodes, node)
 		}
@@ -197,14 +160,25 @@ func (e *AdvancedEngine) GetEntryNodes(ctx context.Context, graph *AdvancedGraph
 	return entryNodes
 }
 
+// IsEntryNode reports whether a session may start from a node
+func (e *AdvancedEngine) IsEntryNode(ctx context.Context, graph *AdvancedGraph, nodeID string) bool {
+	for _, node := range e.GetEntryNodes(ctx, graph) {
+		if node.ID == nodeID {
+			return true
+		}
+	}
+	return false
+}
+
 // ProcessNodeVisit processes a node visit and applies all relevant rules
 func (e *AdvancedEngine) ProcessNodeVisit(ctx context.Context, graph *AdvancedGraph, session *AdvancedSession, nodeID string, data map[string]interface{}) error {
 	// Add node to visited nodes if not already visited
-	if !e.isNodeVisited(nodeID, session.VisitedNodes) {
-		session.VisitedNodes = append(session.VisitedNodes, nodeID)
-	}
+	session.VisitedNodes = appendUnique(session.VisitedNodes, nodeID)
 
 	// Update session data
+	if session.Data == nil {
+		session.Data = make(map[string]interface{})
+	}
 	for key, value := range data {
 		session.Data[key] = value
 	}
@@ -216,15 +190,16 @@ func (e *AdvancedEngine) ProcessNodeVisit(ctx context.Context, graph *AdvancedGr
 	}
 
 	// Apply rule results to session
-	session.DisabledEdges = append(session.DisabledEdges, ruleResult.DisabledEdges...)
-	session.ExcludedNodes = append(session.ExcludedNodes, ruleResult.ExcludedNodes...)
+	session.DisabledEdges = appendUnique(session.DisabledEdges, ruleResult.DisabledEdges...)
+	session.ExcludedNodes = appendUnique(session.ExcludedNodes, ruleResult.ExcludedNodes...)
+	session.RequiredNodes = appendUnique(session.RequiredNodes, ruleResult.RequiredNodes...)
 
 	// Add to path history
 	pathStep := PathStep{
 		NodeID:        nodeID,
 		Timestamp:     e.getCurrentTimestamp(),
 		Data:          data,
-		RulesApplied:  make([]string, 0), // TODO: Track which rules were applied
+		RulesApplied:  ruleResult.AppliedRules,
 		EdgesDisabled: ruleResult.DisabledEdges,
 	}
 	session.PathHistory = append(session.PathHistory, pathStep)
@@ -235,9 +210,11 @@ func (e *AdvancedEngine) ProcessNodeVisit(ctx context.Context, graph *AdvancedGr
 		return fmt.Errorf("failed to check activation: %w", err)
 	}
 
+	session.ActivationRules = activationResult.ActivatedRules
 	if activationResult.CanActivate {
-		session.ActivationStatus = "activated"
-		session.Status = types.SessionStatusCompleted
+		session.ActivationStatus = types.ActivationStatusActivated
+	} else {
+		session.ActivationStatus = types.ActivationStatusPending
 	}
 
 	return nil
@@ -251,6 +228,7 @@ type RuleEvaluationResult struct {
 	RequiredNodes    []string               `json:"required_nodes"`
 	ExcludedNodes    []string               `json:"excluded_nodes"`
 	ActivationChecks []string               `json:"activation_checks"`
+	AppliedRules     []string               `json:"applied_rules"` // Node rules whose actions were triggered
 	Metadata         map[string]interface{} `json:"metadata"`
 }
 
@@ -305,8 +283,9 @@ func (e *AdvancedEngine) evaluateActivationRule(ctx context.Context, rule Activa
 		Metadata:            make(map[string]interface{}),
 	}
 
-	// Check if all required nodes have been visited
-	for _, requiredNode := range rule.RequiredNodes {
+	// Check if all required nodes have been visited; nodes required by node rules apply to every rule
+	requiredNodes := appendUnique(append([]string(nil), rule.RequiredNodes...), session.RequiredNodes...)
+	for _, requiredNode := range requiredNodes {
 		if !e.isNodeVisited(requiredNode, session.VisitedNodes) {
 			result.MissingRequirements = append(result.MissingRequirements, fmt.Sprintf("Required node not visited: %s", requiredNode))
 		}
@@ -340,40 +319,12 @@ func (e *AdvancedEngine) evaluateActivationRule(ctx context.Context, rule Activa
 
 func (e *AdvancedEngine) evaluateCondition(condition ActivationCondition, data map[string]interface{}) bool {
 	fieldValue, exists := data[condition.Field]
-	if !exists {
-		return false
-	}
-
-	switch condition.Operator {
-	case "eq":
-		return fieldValue == condition.Value
-	case "ne":
-		return fieldValue != condition.Value
-	case "in":
-		if slice, ok := condition.Value.([]interface{}); ok {
-			for _, v := range slice {
-				if fieldValue == v {
-					return true
-				}
-			}
-		}
-		return false
-	case "not_in":
-		if slice, ok := condition.Value.([]interface{}); ok {
-			for _, v := range slice {
-				if fieldValue == v {
-					return false
-				}
-			}
-		}
+	met, err := operators.Evaluate(condition.Operator, fieldValue, exists, condition.Value)
+	if err != nil {
+		e.logger.WithError(err).WithField("operator", condition.Operator).Warn("Failed to evaluate activation condition")
 		return true
-	case "contains":
-		return strings.Contains(fmt.Sprintf("%v", fieldValue), fmt.Sprintf("%v", condition.Value))
-	case "not_contains":
-		return !strings.Contains(fmt.Sprintf("%v", fieldValue), fmt.Sprintf("%v", condition.Value))
 	}
-
-	return true
+	return met
 }
 
 func (e *AdvancedEngine) evaluateEdgeCondition(condition types.EdgeCondition, data map[string]interface{}) bool {
@@ -395,9 +346,7 @@ func (e *AdvancedEngine) evaluateEdgeCondition(condition types.EdgeCondition, da
 }
 
 func (e *AdvancedEngine) evaluateCustomRule(rule string, data map[string]interface{}) bool {
-	// Implement custom rule evaluation logic
-	// This could be extended to support a rule engine like OPA
-	return true
+	return e.engine.validateCustomRule(rule, data)
 }
 
 func (e *AdvancedEngine) isEdgeDisabled(edgeID string, disabledEdges []string) bool {
@@ -429,7 +378,24 @@ func (e *AdvancedEngine) isNodeVisited(nodeID string, visitedNodes []string) boo
 
 func (e *AdvancedEngine) getCurrentTimestamp() int64 {
 	// Return current timestamp in milliseconds
-	return 0 // TODO: Implement proper timestamp
+	return time.Now().UnixMilli()
+}
+
+// appendUnique appends the values not already in list
+func appendUnique(list []string, values ...string) []string {
+	for _, value := range values {
+		found := false
+		for _, existing := range list {
+			if existing == value {
+				found = true
+				break
+			}
+		}
+		if !found {
+			list = append(list, value)
+		}
+	}
+	return list
 }
 
 // GetStats returns statistics about the advanced engine
@@ -448,9 +414,7 @@ func (e *AdvancedEngine) GetStats() map[string]interface{} {
 
 // ValidateNode validates a node's data against its validation rules
 func (e *AdvancedEngine) ValidateNode(ctx context.Context, node *types.Node, data map[string]interface{}) *types.ValidationResult {
-	// Create a basic engine to reuse validation logic
-	basicEngine := NewEngine(e.logger)
-	return basicEngine.ValidateNode(ctx, node, data)
+	return e.engine.ValidateNode(ctx, node, data)
 }
 
 // CanGoBack checks if the user can go back from the current node
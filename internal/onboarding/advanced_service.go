@@ -0,0 +1,303 @@
+package onboarding
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"onboarding-system/internal/config"
+	"onboarding-system/internal/storage"
+	"onboarding-system/internal/types"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ErrEntryNodeNotAllowed is returned when a session is started from a node that is not an entry node
+var ErrEntryNodeNotAllowed = errors.New("node is not an entry node of the graph")
+
+// ErrNotAdvancedSession is returned when activation is queried for a session not started from an entry node
+var ErrNotAdvancedSession = errors.New("session has no activation tracking")
+
+// ActivationReport describes where an advanced session stands against its graph's activation rules
+type ActivationReport struct {
+	SessionID        string            `json:"session_id"`
+	ActivationStatus string            `json:"activation_status"`
+	EntryNodeID      string            `json:"entry_node_id"`
+	CurrentNodeID    string            `json:"current_node_id"`
+	VisitedNodes     []string          `json:"visited_nodes"`
+	DisabledEdges    []string          `json:"disabled_edges"`
+	ExcludedNodes    []string          `json:"excluded_nodes"`
+	RequiredNodes    []string          `json:"required_nodes"`
+	AvailablePaths   []string          `json:"available_paths"`
+	Activation       *ActivationResult `json:"activation"`
+}
+
+// AdvancedService extends the regular service with entry nodes, node rules and activation rules
+type AdvancedService struct {
+	*Service
+	advancedEngine *AdvancedEngine
+	logger         *logrus.Logger
+}
+
+// NewAdvancedService creates a new advanced service
+func NewAdvancedService(storage storage.Storage, config *config.Config, logger *logrus.Logger) *AdvancedService {
+	return &AdvancedService{
+		Service:        NewService(storage, config),
+		advancedEngine: NewAdvancedEngine(logger),
+		logger:         logger,
+	}
+}
+
+// GetEntryNodes returns the nodes a new session of a graph may start from
+func (as *AdvancedService) GetEntryNodes(ctx context.Context, graphID string) ([]*Node, error) {
+	graph, err := as.Service.GetGraph(ctx, graphID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get graph: %w", err)
+	}
+
+	return as.advancedEngine.GetEntryNodes(ctx, &AdvancedGraph{Graph: graph}), nil
+}
+
+// StartAdvancedSession starts a session at one of the graph's entry nodes, or at its start node
+// if entryNodeID is empty, and begins tracking activation
+func (as *AdvancedService) StartAdvancedSession(ctx context.Context, userID, graphID, entryNodeID string) (*Session, error) {
+	session, err := as.Service.startSession(ctx, userID, graphID, func(session *Session, graph *Graph) error {
+		if entryNodeID == "" {
+			entryNodeID = graph.StartNodeID
+		}
+		if !as.advancedEngine.IsEntryNode(ctx, &AdvancedGraph{Graph: graph}, entryNodeID) {
+			return fmt.Errorf("%w: %s", ErrEntryNodeNotAllowed, entryNodeID)
+		}
+
+		session.CurrentNodeID = entryNodeID
+		session.AdvancedState = &AdvancedSessionState{
+			EntryNodeID:      entryNodeID,
+			VisitedNodes:     make([]string, 0),
+			DisabledEdges:    make([]string, 0),
+			ExcludedNodes:    make([]string, 0),
+			RequiredNodes:    make([]string, 0),
+			ActivationStatus: types.ActivationStatusPending,
+			ActivationRules:  make([]string, 0),
+			PathHistory:      make([]PathStep, 0),
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	as.logger.WithFields(logrus.Fields{
+		"session_id":    session.ID,
+		"graph_id":      graphID,
+		"user_id":       userID,
+		"entry_node_id": entryNodeID,
+	}).Info("Started advanced session")
+
+	return session, nil
+}
+
+// SubmitAdvancedNodeData submits data for the current node, applies the node's rules, checks
+// activation and moves to the next available node
+func (as *AdvancedService) SubmitAdvancedNodeData(ctx context.Context, sessionID string, data map[string]interface{}) (*NextStepResult, error) {
+	session, err := as.Service.GetSession(ctx, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get session: %w", err)
+	}
+
+	if err := CheckSessionWritable(session); err != nil {
+		return nil, err
+	}
+
+	// Sessions not started from an entry node use the regular service
+	if session.AdvancedState == nil {
+		return as.Service.SubmitNodeData(ctx, sessionID, data)
+	}
+
+	graph, err := as.Service.GetSessionGraph(ctx, session)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get graph: %w", err)
+	}
+
+	currentNode, exists := graph.Nodes[session.CurrentNodeID]
+	if !exists {
+		return nil, fmt.Errorf("current node not found: %s", session.CurrentNodeID)
+	}
+
+	// Validate node data against the accumulated session data
+	validationData := make(map[string]interface{}, len(session.Data)+len(data))
+	for k, v := range session.Data {
+		validationData[k] = v
+	}
+	for k, v := range data {
+		validationData[k] = v
+	}
+	validationResult := as.advancedEngine.ValidateNode(ctx, currentNode, validationData)
+	if !validationResult.Valid {
+		return nil, &NodeValidationError{Errors: validationResult.Errors}
+	}
+
+	// Apply the node's rules and re-check activation
+	advancedGraph := &AdvancedGraph{Graph: graph}
+	advancedSession := &AdvancedSession{Session: session, AdvancedSessionState: session.AdvancedState}
+	if err := as.advancedEngine.ProcessNodeVisit(ctx, advancedGraph, advancedSession, currentNode.ID, data); err != nil {
+		return nil, err
+	}
+
+	step := SessionStep{
+		ID:        fmt.Sprintf("%s-%d", sessionID, len(session.History)),
+		NodeID:    currentNode.ID,
+		Data:      data,
+		Timestamp: time.Now(),
+		Action:    "forward",
+	}
+	session.History = append(session.History, step)
+
+	if session.AdvancedState.ActivationStatus == types.ActivationStatusActivated {
+		session.Status = SessionStatusCompleted
+		now := time.Now()
+		session.CompletedAt = &now
+	} else if nextNode := as.nextNode(ctx, advancedGraph, advancedSession); nextNode != nil {
+		session.CurrentNodeID = nextNode.ID
+	} else {
+		as.logger.WithFields(logrus.Fields{
+			"session_id":   sessionID,
+			"current_node": session.CurrentNodeID,
+		}).Warn("No available paths from node, staying on current node")
+	}
+	session.UpdatedAt = time.Now()
+
+	events := []*SessionEvent{newSessionEvent(SessionEventSubmitted, session, &step, map[string]interface{}{
+		"node_id": currentNode.ID,
+		"data":    data,
+	})}
+	if session.Status == SessionStatusCompleted {
+		events = append(events, newSessionEvent(SessionEventCompleted, session, nil, map[string]interface{}{
+			"activation_rules": session.AdvancedState.ActivationRules,
+		}))
+	}
+	if err := as.Service.saveSession(ctx, session, events...); err != nil {
+		return nil, fmt.Errorf("failed to save session: %w", err)
+	}
+
+	result := &NextStepResult{
+		NextNodeID:      session.CurrentNodeID,
+		AvailablePaths:  make([]string, 0),
+		CanGoBack:       as.advancedEngine.CanGoBack(ctx, graph, currentNode.ID),
+		SessionRevision: session.Revision,
+		Metadata: map[string]interface{}{
+			"validation_warnings": validationResult.Warnings,
+			"session_status":      session.Status,
+			"activation_status":   session.AdvancedState.ActivationStatus,
+			"activation_rules":    session.AdvancedState.ActivationRules,
+		},
+	}
+	if session.Status != SessionStatusCompleted {
+		result.AvailablePaths = as.availablePaths(ctx, advancedGraph, advancedSession)
+	}
+
+	as.logger.WithFields(logrus.Fields{
+		"session_id":        sessionID,
+		"current_node":      currentNode.ID,
+		"next_node":         session.CurrentNodeID,
+		"activation_status": session.AdvancedState.ActivationStatus,
+	}).Info("Submitted node data with activation check")
+
+	return result, nil
+}
+
+// CheckActivationStatus evaluates the graph's activation rules against a session
+func (as *AdvancedService) CheckActivationStatus(ctx context.Context, sessionID string) (*ActivationReport, error) {
+	session, err := as.Service.GetSession(ctx, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get session: %w", err)
+	}
+	if session.AdvancedState == nil {
+		return nil, ErrNotAdvancedSession
+	}
+
+	graph, err := as.Service.GetSessionGraph(ctx, session)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get graph: %w", err)
+	}
+
+	advancedGraph := &AdvancedGraph{Graph: graph}
+	advancedSession := &AdvancedSession{Session: session, AdvancedSessionState: session.AdvancedState}
+	activation, err := as.advancedEngine.CheckActivation(ctx, advancedGraph, advancedSession)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check activation: %w", err)
+	}
+
+	state := session.AdvancedState
+	report := &ActivationReport{
+		SessionID:        session.ID,
+		ActivationStatus: state.ActivationStatus,
+		EntryNodeID:      state.EntryNodeID,
+		CurrentNodeID:    session.CurrentNodeID,
+		VisitedNodes:     state.VisitedNodes,
+		DisabledEdges:    state.DisabledEdges,
+		ExcludedNodes:    state.ExcludedNodes,
+		RequiredNodes:    state.RequiredNodes,
+		AvailablePaths:   make([]string, 0),
+		Activation:       activation,
+	}
+	if session.Status != SessionStatusCompleted {
+		report.AvailablePaths = as.availablePaths(ctx, advancedGraph, advancedSession)
+	}
+
+	return report, nil
+}
+
+// nextNode returns the first available node the session has not visited yet, or the first
+// available node if all have been visited
+func (as *AdvancedService) nextNode(ctx context.Context, graph *AdvancedGraph, session *AdvancedSession) *Node {
+	nodes, err := as.advancedEngine.GetAvailablePaths(ctx, graph, session.CurrentNodeID, session)
+	if err != nil || len(nodes) == 0 {
+		return nil
+	}
+	for _, node := range nodes {
+		if !as.advancedEngine.isNodeVisited(node.ID, session.VisitedNodes) {
+			return node
+		}
+	}
+	return nodes[0]
+}
+
+// availablePaths returns the IDs of the nodes reachable from the session's current node
+func (as *AdvancedService) availablePaths(ctx context.Context, graph *AdvancedGraph, session *AdvancedSession) []string {
+	nodes, err := as.advancedEngine.GetAvailablePaths(ctx, graph, session.CurrentNodeID, session)
+	if err != nil {
+		return make([]string, 0)
+	}
+	paths := make([]string, len(nodes))
+	for i, node := range nodes {
+		paths[i] = node.ID
+	}
+	return paths
+}
+
+// pruneAdvancedState drops visited, excluded and required nodes and disabled edges that a graph
+// revision does not define
+func pruneAdvancedState(state *AdvancedSessionState, graph *Graph) {
+	keepNodes := func(nodeIDs []string) []string {
+		kept := make([]string, 0, len(nodeIDs))
+		for _, nodeID := range nodeIDs {
+			if _, exists := graph.Nodes[nodeID]; exists {
+				kept = append(kept, nodeID)
+			}
+		}
+		return kept
+	}
+
+	state.VisitedNodes = keepNodes(state.VisitedNodes)
+	state.ExcludedNodes = keepNodes(state.ExcludedNodes)
+	state.RequiredNodes = keepNodes(state.RequiredNodes)
+
+	disabledEdges := make([]string, 0, len(state.DisabledEdges))
+	for _, edgeID := range state.DisabledEdges {
+		if _, exists := graph.Edges[edgeID]; exists {
+			disabledEdges = append(disabledEdges, edgeID)
+		}
+	}
+	state.DisabledEdges = disabledEdges
+}
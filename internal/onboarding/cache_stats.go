@@ -0,0 +1,15 @@
+package onboarding
+
+import "onboarding-system/internal/storage"
+
+// CacheStats is a snapshot of the storage cache counters
+type CacheStats = storage.CacheStats
+
+// CacheStats returns the storage cache counters; ok is false when the storage has no cache
+func (s *Service) CacheStats() (stats CacheStats, ok bool) {
+	provider, ok := s.storage.(storage.CacheStatsProvider)
+	if !ok {
+		return CacheStats{}, false
+	}
+	return provider.CacheStats(), true
+}
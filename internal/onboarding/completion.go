@@ -0,0 +1,164 @@
+package onboarding
+
+import (
+	"context"
+	"fmt"
+	"sort"
+)
+
+// CompletionReport explains whether a session can be completed and, if not, what is missing
+type CompletionReport struct {
+	SessionID        string            `json:"session_id"`
+	Complete         bool              `json:"complete"`
+	BusinessType     string            `json:"business_type,omitempty"`
+	MissingNodes     []string          `json:"missing_nodes"`                // The steps an IncompleteSessionError lists
+	ClosestRuleGroup string            `json:"closest_rule_group,omitempty"` // The rule group with the fewest missing requirements
+	RuleGroups       []RuleGroupReport `json:"rule_groups"`
+}
+
+// RuleGroupReport breaks down how far a session is through one rule group
+type RuleGroupReport struct {
+	ID                  string                  `json:"id"`
+	Name                string                  `json:"name"`
+	Complete            bool                    `json:"complete"`
+	Satisfied           int                     `json:"satisfied"` // Required fields that are filled
+	Total               int                     `json:"total"`     // Required fields, including conditional fields whose rule fired
+	Nodes               []NodeRequirementReport `json:"nodes"`
+	ConditionalRules    []ConditionalRuleReport `json:"conditional_rules"`
+	MissingRequirements []string                `json:"missing_requirements"`
+}
+
+// NodeRequirementReport lists the filled and missing fields a rule group requires of a node
+type NodeRequirementReport struct {
+	NodeID          string   `json:"node_id"`
+	Satisfied       bool     `json:"satisfied"`
+	SatisfiedFields []string `json:"satisfied_fields"`
+	MissingFields   []string `json:"missing_fields"`
+}
+
+// ConditionalRuleReport explains whether a conditional field rule fired
+type ConditionalRuleReport struct {
+	RuleID      string `json:"rule_id"`
+	NodeID      string `json:"node_id"`
+	FieldID     string `json:"field_id"`
+	Description string `json:"description,omitempty"`
+	Fired       bool   `json:"fired"`     // The condition holds, so the field is required
+	Reason      string `json:"reason"`    // The facts that decided the condition, e.g. "payment_channel eq website"
+	Satisfied   bool   `json:"satisfied"` // The rule did not fire or its field is filled
+}
+
+// ExplainCompleteness reports whether session data completes a graph, with a breakdown of every
+// rule group that applies to the session's business type. The verdict is ValidatePathCompleteness's.
+func (e *Engine) ExplainCompleteness(ctx context.Context, graph *Graph, currentNodeID string, sessionData map[string]interface{}, sessionHistory []SessionStep) *CompletionReport {
+	complete, missingNodes := e.ValidatePathCompleteness(ctx, graph, currentNodeID, sessionData, sessionHistory)
+	report := &CompletionReport{
+		Complete:     complete,
+		MissingNodes: missingNodes,
+		RuleGroups:   make([]RuleGroupReport, 0),
+	}
+
+	businessType, hasBusinessType := sessionData["business_type"]
+	if !hasBusinessType {
+		return report
+	}
+	report.BusinessType = fmt.Sprintf("%v", businessType)
+
+	closestMissing := -1
+	for _, ruleGroup := range e.GetBusinessTypeRuleGroups(graph, report.BusinessType) {
+		groupReport := e.ExplainRuleGroup(ruleGroup, sessionData)
+		report.RuleGroups = append(report.RuleGroups, *groupReport)
+
+		if missing := groupReport.Total - groupReport.Satisfied; closestMissing < 0 || missing < closestMissing {
+			closestMissing = missing
+			report.ClosestRuleGroup = groupReport.ID
+		}
+	}
+
+	return report
+}
+
+// ExplainRuleGroup reports which of a rule group's required nodes and fields are filled and which
+// of its conditional rules fired
+func (e *Engine) ExplainRuleGroup(ruleGroup RuleGroup, sessionData map[string]interface{}) *RuleGroupReport {
+	report := &RuleGroupReport{
+		ID:                  ruleGroup.ID,
+		Name:                ruleGroup.Name,
+		Nodes:               make([]NodeRequirementReport, 0, len(ruleGroup.RequiredNodes)),
+		ConditionalRules:    make([]ConditionalRuleReport, 0),
+		MissingRequirements: make([]string, 0),
+	}
+
+	ruleIDs := make([]string, 0, len(ruleGroup.ConditionalFields))
+	for ruleID := range ruleGroup.ConditionalFields {
+		ruleIDs = append(ruleIDs, ruleID)
+	}
+	sort.Strings(ruleIDs)
+
+	for _, nodeID := range ruleGroup.RequiredNodes {
+		// Nodes the group lists no fields for cannot be checked against session data
+		requiredFields, hasRequiredFields := ruleGroup.RequiredFields[nodeID]
+		if !hasRequiredFields {
+			continue
+		}
+
+		nodeReport := NodeRequirementReport{
+			NodeID:          nodeID,
+			SatisfiedFields: make([]string, 0),
+			MissingFields:   make([]string, 0),
+		}
+		for _, fieldID := range requiredFields {
+			if fieldFilled(sessionData, fieldID) {
+				nodeReport.SatisfiedFields = append(nodeReport.SatisfiedFields, fieldID)
+			} else {
+				nodeReport.MissingFields = append(nodeReport.MissingFields, fieldID)
+				report.MissingRequirements = append(report.MissingRequirements, fmt.Sprintf("Node %s: field %s is required", nodeID, fieldID))
+			}
+		}
+
+		for _, ruleID := range ruleIDs {
+			conditionalRule := ruleGroup.ConditionalFields[ruleID]
+			if conditionalRule.NodeID != nodeID {
+				continue
+			}
+
+			_, reason := explainComparison(DependencyExpression{
+				Field:    conditionalRule.Condition,
+				Operator: conditionalRule.Operator,
+				Value:    conditionalRule.Value,
+			}, sessionData)
+			ruleReport := ConditionalRuleReport{
+				RuleID:      ruleID,
+				NodeID:      nodeID,
+				FieldID:     conditionalRule.FieldID,
+				Description: conditionalRule.Description,
+				Fired:       e.evaluateConditionalField(conditionalRule, sessionData),
+				Reason:      reason,
+				Satisfied:   true,
+			}
+			if ruleReport.Fired {
+				if fieldFilled(sessionData, conditionalRule.FieldID) {
+					nodeReport.SatisfiedFields = append(nodeReport.SatisfiedFields, conditionalRule.FieldID)
+				} else {
+					ruleReport.Satisfied = false
+					nodeReport.MissingFields = append(nodeReport.MissingFields, conditionalRule.FieldID)
+					report.MissingRequirements = append(report.MissingRequirements, fmt.Sprintf("Node %s: field %s is required (%s)", nodeID, conditionalRule.FieldID, conditionalRule.Description))
+				}
+			}
+			report.ConditionalRules = append(report.ConditionalRules, ruleReport)
+		}
+
+		nodeReport.Satisfied = len(nodeReport.MissingFields) == 0
+		report.Satisfied += len(nodeReport.SatisfiedFields)
+		report.Total += len(nodeReport.SatisfiedFields) + len(nodeReport.MissingFields)
+		report.Nodes = append(report.Nodes, nodeReport)
+	}
+
+	report.Complete = len(report.MissingRequirements) == 0
+	return report
+}
+
+// fieldFilled reports whether session data holds a value for a field
+func fieldFilled(sessionData map[string]interface{}, fieldID string) bool {
+	value, exists := sessionData[fieldID]
+	return exists && value != nil && value != ""
+}
@@ -3,9 +3,9 @@ package onboarding
 import (
 	"context"
 	"fmt"
-	"regexp"
 	"strings"
 
+	"onboarding-system/internal/operators"
 	"onboarding-system/internal/types"
 
 	"github.com/sirupsen/logrus"
@@ -218,17 +218,23 @@ func (cve *CrossNodeValidationEngine) validateFieldContains(condition types.Cros
 	return true
 }
 
-// validateCustomLogic validates using custom logic (for complex cases)
+// validateCustomLogic validates using a named logic pattern or an expression over field aliases
 func (cve *CrossNodeValidationEngine) validateCustomLogic(condition types.CrossNodeCondition, fieldValues map[string]interface{}) bool {
-	// For now, implement some common custom logic patterns
-	// In a real implementation, this could be extended with a scripting engine
+	if isRuleExpression(condition.Logic) {
+		passed, err := evaluateRuleExpression(condition.Logic, fieldValues)
+		if err != nil {
+			cve.logger.WithError(err).WithField("logic", condition.Logic).Error("Failed to evaluate custom logic")
+			return false
+		}
+		return passed
+	}
 
 	switch condition.Logic {
-	case "business_name_matches_bank_name":
+	case types.CrossNodeLogicBusinessNameMatchesBankName:
 		return cve.validateBusinessNameMatchesBankName(fieldValues)
-	case "pan_matches_signatory_pan":
+	case types.CrossNodeLogicPanMatchesSignatoryPan:
 		return cve.validatePanMatchesSignatoryPan(fieldValues)
-	case "address_consistency":
+	case types.CrossNodeLogicAddressConsistency:
 		return cve.validateAddressConsistency(fieldValues)
 	default:
 		cve.logger.WithField("logic", condition.Logic).Error("Unknown custom logic")
@@ -282,47 +288,42 @@ func (cve *CrossNodeValidationEngine) validateAddressConsistency(fieldValues map
 	return cityStr != "" && stateStr != "" && pincodeStr != ""
 }
 
-// compareValues compares two values using the specified operator
+// compareValues compares two values using the specified operator; comparisons ignore case and
+// surrounding whitespace
 func (cve *CrossNodeValidationEngine) compareValues(value1, value2 interface{}, operator string) bool {
-	str1 := strings.ToLower(strings.TrimSpace(fmt.Sprintf("%v", value1)))
-	str2 := strings.ToLower(strings.TrimSpace(fmt.Sprintf("%v", value2)))
-
 	switch operator {
-	case "eq", "equals":
-		return str1 == str2
-	case "ne", "not_equals":
-		return str1 != str2
-	case "contains":
-		return strings.Contains(str1, str2) || strings.Contains(str2, str1)
-	case "matches":
-		// Use regex matching
-		matched, err := regexp.MatchString(str2, str1)
-		return err == nil && matched
-	default:
-		cve.logger.WithField("operator", operator).Error("Unknown comparison operator")
-		return false
+	case "equals":
+		operator = operators.Eq
+	case "not_equals":
+		operator = operators.Ne
+	case operators.Contains:
+		// Either value may contain the other
+		return cve.evaluate(operator, value1, value2) || cve.evaluate(operator, value2, value1)
 	}
+	return cve.evaluate(operator, value1, value2)
 }
 
 // containsValue checks if a field value contains the specified value
 func (cve *CrossNodeValidationEngine) containsValue(fieldValue, containsValue interface{}, operator string) bool {
-	fieldStr := strings.ToLower(strings.TrimSpace(fmt.Sprintf("%v", fieldValue)))
-	containsStr := strings.ToLower(strings.TrimSpace(fmt.Sprintf("%v", containsValue)))
+	return cve.evaluate(operator, fieldValue, containsValue)
+}
 
-	switch operator {
-	case "contains":
-		return strings.Contains(fieldStr, containsStr)
-	case "starts_with":
-		return strings.HasPrefix(fieldStr, containsStr)
-	case "ends_with":
-		return strings.HasSuffix(fieldStr, containsStr)
-	case "matches":
-		matched, err := regexp.MatchString(containsStr, fieldStr)
-		return err == nil && matched
-	default:
-		cve.logger.WithField("operator", operator).Error("Unknown contains operator")
+// evaluate applies the case-insensitive variant of an operator to trimmed values
+func (cve *CrossNodeValidationEngine) evaluate(operator string, value, operand interface{}) bool {
+	met, err := operators.Evaluate(operators.CaseInsensitive(operator), trimValue(value), true, trimValue(operand))
+	if err != nil {
+		cve.logger.WithError(err).WithField("operator", operator).Error("Failed to compare cross-node values")
 		return false
 	}
+	return met
+}
+
+// trimValue trims surrounding whitespace from a string value
+func trimValue(value interface{}) interface{} {
+	if s, ok := value.(string); ok {
+		return strings.TrimSpace(s)
+	}
+	return value
 }
 
 // Helper functions for result analysis
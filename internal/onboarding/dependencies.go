@@ -0,0 +1,129 @@
+package onboarding
+
+import (
+	"fmt"
+	"strings"
+
+	"onboarding-system/internal/operators"
+)
+
+// explainDependency evaluates a dependency against session data. The reason describes the facts
+// that decided the result, e.g. "subcategory in [lending, crypto] and payment_channel ne no_code".
+func explainDependency(dependency NodeDependency, sessionData map[string]interface{}, nested bool) (bool, string) {
+	if dependency.Expression != nil {
+		return explainExpression(*dependency.Expression, sessionData, nested)
+	}
+	return explainExpression(DependencyExpression{
+		Field:    dependency.FieldID,
+		Operator: dependency.Operator,
+		Value:    dependency.Value,
+	}, sessionData, nested)
+}
+
+// explainExpression evaluates a dependency expression; groups nested in another expression are
+// parenthesized in the reason
+func explainExpression(expression DependencyExpression, sessionData map[string]interface{}, nested bool) (bool, string) {
+	switch {
+	case len(expression.All) > 0:
+		reasons := make([]string, 0, len(expression.All))
+		for _, child := range expression.All {
+			satisfied, reason := explainExpression(child, sessionData, true)
+			if !satisfied {
+				return false, reason
+			}
+			reasons = append(reasons, reason)
+		}
+		return true, joinReasons(reasons, nested)
+
+	case len(expression.Any) > 0:
+		reasons := make([]string, 0, len(expression.Any))
+		for _, child := range expression.Any {
+			satisfied, reason := explainExpression(child, sessionData, true)
+			if satisfied {
+				return true, reason
+			}
+			reasons = append(reasons, reason)
+		}
+		return false, joinReasons(reasons, nested)
+
+	case expression.Not != nil:
+		// The reason for the negated expression's result also explains the negation
+		satisfied, reason := explainExpression(*expression.Not, sessionData, nested)
+		return !satisfied, reason
+
+	default:
+		return explainComparison(expression, sessionData)
+	}
+}
+
+// explainComparison evaluates a single field comparison
+func explainComparison(expression DependencyExpression, sessionData map[string]interface{}) (bool, string) {
+	fieldValue, exists := sessionData[expression.Field]
+	if !exists && !operators.ChecksPresence(expression.Operator) {
+		return false, fmt.Sprintf("%s is not set", expression.Field)
+	}
+
+	operand := expression.Value
+	description := fmt.Sprintf("%s %s %s", expression.Field, expression.Operator, formatDependencyValue(operand))
+	if expression.ValueField != "" {
+		if operand, exists = sessionData[expression.ValueField]; !exists {
+			return false, fmt.Sprintf("%s is not set", expression.ValueField)
+		}
+		description = fmt.Sprintf("%s %s %s", expression.Field, expression.Operator, expression.ValueField)
+	}
+
+	if compareDependencyValue(fieldValue, exists, expression.Operator, operand) {
+		return true, description
+	}
+	return false, "not " + description
+}
+
+// compareDependencyValue applies a dependency operator to a field value and its operand
+func compareDependencyValue(fieldValue interface{}, exists bool, operator string, operand interface{}) bool {
+	if operator == "custom" {
+		// Custom rules are checked on submission; the dependency only needs the field to exist
+		return exists
+	}
+	met, err := operators.Evaluate(operator, fieldValue, exists, operand)
+	return err == nil && met
+}
+
+// dependencyValues returns the list an in or not_in operand holds
+func dependencyValues(operand interface{}) []interface{} {
+	switch v := operand.(type) {
+	case []interface{}:
+		return v
+	case []string:
+		values := make([]interface{}, len(v))
+		for i, s := range v {
+			values[i] = s
+		}
+		return values
+	default:
+		return nil
+	}
+}
+
+// formatDependencyValue formats an operand for a reason
+func formatDependencyValue(value interface{}) string {
+	if values := dependencyValues(value); values != nil {
+		parts := make([]string, len(values))
+		for i, v := range values {
+			parts[i] = fmt.Sprintf("%v", v)
+		}
+		return "[" + strings.Join(parts, ", ") + "]"
+	}
+	if value == "" {
+		return `""`
+	}
+	return fmt.Sprintf("%v", value)
+}
+
+// joinReasons combines the reasons of every expression in a group
+func joinReasons(reasons []string, nested bool) string {
+	joined := strings.Join(reasons, " and ")
+	if nested && len(reasons) > 1 {
+		return "(" + joined + ")"
+	}
+	return joined
+}
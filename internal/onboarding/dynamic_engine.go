@@ -2,6 +2,7 @@ package onboarding
 
 import (
 	"fmt"
+	"strings"
 	"sync"
 
 	"github.com/sirupsen/logrus"
@@ -20,11 +21,12 @@ const (
 
 // NodeDependency represents a dependency condition for a node
 type NodeDependency struct {
-	FieldID      string      `json:"field_id"`                // Field to check
-	Operator     string      `json:"operator"`                // eq, ne, in, not_in, etc.
-	Value        interface{} `json:"value"`                   // Value to compare against
-	Condition    string      `json:"condition"`               // Human readable condition
-	BusinessType string      `json:"business_type,omitempty"` // Business type specific dependency
+	FieldID      string                `json:"field_id"`                // Field to check
+	Operator     string                `json:"operator"`                // eq, ne, in, not_in, etc.
+	Value        interface{}           `json:"value"`                   // Value to compare against
+	Condition    string                `json:"condition"`               // Human readable condition
+	BusinessType string                `json:"business_type,omitempty"` // Business type specific dependency
+	Expression   *DependencyExpression `json:"expression,omitempty"`    // Replaces the field comparison when set
 }
 
 // DynamicNode represents a node with dynamic status tracking
@@ -32,6 +34,7 @@ type DynamicNode struct {
 	*Node
 	Status        NodeStatus       `json:"status"`
 	InitialStatus NodeStatus       `json:"initial_status"`
+	Reason        string           `json:"reason,omitempty"` // Why the node has its status
 	Dependencies  []NodeDependency `json:"dependencies"`
 	Observers     []NodeObserver   `json:"-"` // Not serialized
 	mu            sync.RWMutex     `json:"-"` // Not serialized
@@ -76,10 +79,12 @@ func (de *DynamicEngine) ConvertToDynamicGraph(graph *Graph, businessType string
 
 	// Convert each node to a dynamic node
 	for nodeID, node := range graph.Nodes {
+		status, reason := de.determineInitialStatus(node, businessType)
 		dynamicNode := &DynamicNode{
 			Node:          node,
-			Status:        de.determineInitialStatus(node, businessType),
-			InitialStatus: de.determineInitialStatus(node, businessType),
+			Status:        status,
+			InitialStatus: status,
+			Reason:        reason,
 			Dependencies:  de.extractDependencies(node, businessType),
 			Observers:     make([]NodeObserver, 0),
 		}
@@ -92,34 +97,72 @@ func (de *DynamicEngine) ConvertToDynamicGraph(graph *Graph, businessType string
 	return dynamicGraph
 }
 
-// determineInitialStatus determines the initial status of a node based on business type
-func (de *DynamicEngine) determineInitialStatus(node *Node, businessType string) NodeStatus {
+// clone returns a copy of the dynamic graph with its own node statuses, sharing the underlying
+// graph and nodes, which are never modified
+func (dg *DynamicGraph) clone() *DynamicGraph {
+	dg.mu.RLock()
+	defer dg.mu.RUnlock()
+
+	clone := &DynamicGraph{
+		Graph:        dg.Graph,
+		DynamicNodes: make(map[string]*DynamicNode, len(dg.DynamicNodes)),
+		Observers:    make([]NodeObserver, 0),
+	}
+	for nodeID, dynamicNode := range dg.DynamicNodes {
+		dynamicNode.mu.RLock()
+		clone.DynamicNodes[nodeID] = &DynamicNode{
+			Node:          dynamicNode.Node,
+			Status:        dynamicNode.Status,
+			InitialStatus: dynamicNode.InitialStatus,
+			Reason:        dynamicNode.Reason,
+			Dependencies:  append([]NodeDependency(nil), dynamicNode.Dependencies...),
+			Observers:     make([]NodeObserver, 0),
+		}
+		dynamicNode.mu.RUnlock()
+	}
+	clone.AddObserver(clone)
+
+	return clone
+}
+
+// determineInitialStatus determines the initial status of a node based on business type, and why
+func (de *DynamicEngine) determineInitialStatus(node *Node, businessType string) (NodeStatus, string) {
 	// Check if this is a start node
 	if node.Type == "start" {
-		return NodeStatusMandatory
+		return NodeStatusMandatory, "it is the start node"
 	}
 
 	// Check if this is an end node
 	if node.Type == "end" {
-		return NodeStatusOptional // End nodes are optional until all required nodes are completed
+		return NodeStatusOptional, "" // End nodes are optional until all required nodes are completed
 	}
 
 	// Check if node has business type specific requirements
 	if de.isNodeRequiredForBusinessType(node, businessType) {
-		return NodeStatusMandatory
+		return NodeStatusMandatory, fmt.Sprintf("it is required for business type %s", businessType)
 	}
 
 	// Check if node has dependencies
 	if de.hasDependencies(node) {
-		return NodeStatusDependent
+		return NodeStatusDependent, ""
 	}
 
 	// Default to optional
-	return NodeStatusOptional
+	return NodeStatusOptional, ""
 }
 
 // isNodeRequiredForBusinessType checks if a node is required for a specific business type
 func (de *DynamicEngine) isNodeRequiredForBusinessType(node *Node, businessType string) bool {
+	// Imported graphs declare the business types a node is required for
+	if requiredFor, ok := metadataStrings(node.Metadata["required_for"]); ok {
+		for _, required := range requiredFor {
+			if required == businessType {
+				return true
+			}
+		}
+		return false
+	}
+
 	// Define business type specific requirements
 	businessTypeRequirements := map[string][]string{
 		"individual": {
@@ -213,8 +256,28 @@ func (de *DynamicEngine) isNodeRequiredForBusinessType(node *Node, businessType
 	return false
 }
 
+// metadataStrings reads a string list from metadata, which is []interface{} after a JSON round trip
+func metadataStrings(value interface{}) ([]string, bool) {
+	switch v := value.(type) {
+	case []string:
+		return v, true
+	case []interface{}:
+		result := make([]string, 0, len(v))
+		for _, item := range v {
+			result = append(result, fmt.Sprintf("%v", item))
+		}
+		return result, true
+	default:
+		return nil, false
+	}
+}
+
 // hasDependencies checks if a node has dependencies
 func (de *DynamicEngine) hasDependencies(node *Node) bool {
+	if len(node.Dependencies) > 0 {
+		return true
+	}
+
 	// Check if node has validation conditions that create dependencies
 	if len(node.Validation.Conditions) > 0 {
 		return true
@@ -234,6 +297,21 @@ func (de *DynamicEngine) hasDependencies(node *Node) bool {
 func (de *DynamicEngine) extractDependencies(node *Node, businessType string) []NodeDependency {
 	dependencies := make([]NodeDependency, 0)
 
+	// Dependencies declared on the node, unless they are for another business type
+	for _, dependency := range node.Dependencies {
+		if dependency.BusinessType != "" && dependency.BusinessType != businessType {
+			continue
+		}
+		dependencies = append(dependencies, NodeDependency{
+			FieldID:      dependency.FieldID,
+			Operator:     dependency.Operator,
+			Value:        dependency.Value,
+			Condition:    dependency.Condition,
+			BusinessType: dependency.BusinessType,
+			Expression:   dependency.Expression,
+		})
+	}
+
 	// Extract from validation conditions
 	for _, condition := range node.Validation.Conditions {
 		dependency := NodeDependency{
@@ -287,6 +365,11 @@ func (dg *DynamicGraph) RemoveObserver(observer NodeObserver) {
 
 // UpdateNodeStatus updates the status of a node and notifies observers
 func (dg *DynamicGraph) UpdateNodeStatus(nodeID string, newStatus NodeStatus, sessionData map[string]interface{}) {
+	dg.updateNodeStatus(nodeID, newStatus, "", sessionData)
+}
+
+// updateNodeStatus updates the status of a node and the reason for it, and notifies observers
+func (dg *DynamicGraph) updateNodeStatus(nodeID string, newStatus NodeStatus, reason string, sessionData map[string]interface{}) {
 	dg.mu.Lock()
 	defer dg.mu.Unlock()
 
@@ -298,6 +381,7 @@ func (dg *DynamicGraph) UpdateNodeStatus(nodeID string, newStatus NodeStatus, se
 	dynamicNode.mu.Lock()
 	oldStatus := dynamicNode.Status
 	dynamicNode.Status = newStatus
+	dynamicNode.Reason = reason
 	dynamicNode.mu.Unlock()
 
 	// Notify observers
@@ -333,9 +417,11 @@ func (dg *DynamicGraph) OnNodeDataChanged(nodeID string, fieldID string, value i
 func (de *DynamicEngine) evaluateDependentNodes(dg *DynamicGraph, sessionData map[string]interface{}) {
 	for nodeID, dynamicNode := range dg.DynamicNodes {
 		if dynamicNode.Status == NodeStatusDependent {
-			newStatus := de.EvaluateNodeDependencies(dynamicNode, sessionData)
+			newStatus, reason := de.ExplainNodeDependencies(dynamicNode, sessionData)
 			if newStatus != dynamicNode.Status {
-				dg.UpdateNodeStatus(nodeID, newStatus, sessionData)
+				dg.updateNodeStatus(nodeID, newStatus, reason, sessionData)
+			} else {
+				dynamicNode.Reason = reason
 			}
 		}
 	}
@@ -343,58 +429,38 @@ func (de *DynamicEngine) evaluateDependentNodes(dg *DynamicGraph, sessionData ma
 
 // EvaluateNodeDependencies evaluates if a dependent node should become mandatory or optional
 func (de *DynamicEngine) EvaluateNodeDependencies(dynamicNode *DynamicNode, sessionData map[string]interface{}) NodeStatus {
-	// Check all dependencies
+	status, _ := de.ExplainNodeDependencies(dynamicNode, sessionData)
+	return status
+}
+
+// ExplainNodeDependencies evaluates a dependent node like EvaluateNodeDependencies and also returns
+// the reason for the status. A node becomes mandatory as soon as any one dependency is satisfied.
+func (de *DynamicEngine) ExplainNodeDependencies(dynamicNode *DynamicNode, sessionData map[string]interface{}) (NodeStatus, string) {
+	nested := len(dynamicNode.Dependencies) > 1
+	unmet := make([]string, 0, len(dynamicNode.Dependencies))
 	for _, dependency := range dynamicNode.Dependencies {
-		if de.isDependencySatisfied(dependency, sessionData) {
-			return NodeStatusMandatory
+		satisfied, reason := explainDependency(dependency, sessionData, nested)
+		if satisfied {
+			return NodeStatusMandatory, reason
 		}
+		unmet = append(unmet, reason)
 	}
 
 	// If no dependencies are satisfied, check if node should be optional
 	if de.shouldNodeBeOptional(dynamicNode, sessionData) {
-		return NodeStatusOptional
+		return NodeStatusOptional, ""
 	}
 
-	return NodeStatusDependent
+	return NodeStatusDependent, strings.Join(unmet, " and ")
 }
 
-// isDependencySatisfied checks if a dependency condition is satisfied
-func (de *DynamicEngine) isDependencySatisfied(dependency NodeDependency, sessionData map[string]interface{}) bool {
-	fieldValue, exists := sessionData[dependency.FieldID]
-	if !exists {
-		return false
-	}
-
-	switch dependency.Operator {
-	case "eq":
-		return fieldValue == dependency.Value
-	case "ne":
-		return fieldValue != dependency.Value
-	case "in":
-		if values, ok := dependency.Value.([]interface{}); ok {
-			for _, v := range values {
-				if fieldValue == v {
-					return true
-				}
-			}
-		}
-		return false
-	case "not_in":
-		if values, ok := dependency.Value.([]interface{}); ok {
-			for _, v := range values {
-				if fieldValue == v {
-					return false
-				}
-			}
-		}
-		return true
-	case "custom":
-		// Handle custom rules - for now, return true if the field exists
-		_, exists := sessionData[dependency.FieldID]
-		return exists
-	default:
-		return false
+// Explanation describes a node's status and the reason for it, e.g. "mandatory because
+// business_type eq llp"; it is empty when there is no reason to give
+func (dn *DynamicNode) Explanation() string {
+	if dn.Reason == "" {
+		return ""
 	}
+	return fmt.Sprintf("%s because %s", dn.Status, dn.Reason)
 }
 
 // shouldNodeBeOptional determines if a node should be optional based on current state
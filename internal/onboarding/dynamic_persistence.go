@@ -48,6 +48,7 @@ func (dpm *DynamicPersistenceManager) SaveDynamicState(session *types.Session, d
 			Status:        string(dynamicNode.Status),
 			InitialStatus: string(dynamicNode.InitialStatus),
 			Dependencies:  dpm.convertDependencies(dynamicNode.Dependencies),
+			Reason:        dynamicNode.Reason,
 			LastUpdatedAt: time.Now(),
 			Metadata: map[string]interface{}{
 				"node_name": dynamicNode.Name,
@@ -91,6 +92,7 @@ func (dpm *DynamicPersistenceManager) RestoreDynamicState(session *types.Session
 			// Restore status
 			dynamicNode.Status = NodeStatus(nodeStatusInfo.Status)
 			dynamicNode.InitialStatus = NodeStatus(nodeStatusInfo.InitialStatus)
+			dynamicNode.Reason = nodeStatusInfo.Reason
 
 			// Restore dependencies
 			dynamicNode.Dependencies = dpm.convertToNodeDependencies(nodeStatusInfo.Dependencies)
@@ -129,6 +131,7 @@ func (dpm *DynamicPersistenceManager) convertDependencies(dependencies []NodeDep
 			Value:        dep.Value,
 			Condition:    dep.Condition,
 			BusinessType: dep.BusinessType,
+			Expression:   dep.Expression,
 		}
 	}
 	return result
@@ -144,6 +147,7 @@ func (dpm *DynamicPersistenceManager) convertToNodeDependencies(dependencies []t
 			Value:        dep.Value,
 			Condition:    dep.Condition,
 			BusinessType: dep.BusinessType,
+			Expression:   dep.Expression,
 		}
 	}
 	return result
@@ -155,17 +159,19 @@ func (dpm *DynamicPersistenceManager) reEvaluateDependencies(dynamicGraph *Dynam
 
 	for nodeID, dynamicNode := range dynamicGraph.DynamicNodes {
 		if dynamicNode.Status == NodeStatusDependent {
-			newStatus := engine.EvaluateNodeDependencies(dynamicNode, sessionData)
+			newStatus, reason := engine.ExplainNodeDependencies(dynamicNode, sessionData)
 			if newStatus != dynamicNode.Status {
 				dpm.logger.WithFields(logrus.Fields{
 					"node_id":    nodeID,
 					"node_name":  dynamicNode.Name,
 					"old_status": dynamicNode.Status,
 					"new_status": newStatus,
+					"reason":     reason,
 				}).Info("Node status changed during re-evaluation")
 
 				dynamicNode.Status = newStatus
 			}
+			dynamicNode.Reason = reason
 		}
 	}
 }
@@ -269,3 +275,24 @@ func (dpm *DynamicPersistenceManager) ValidateDynamicState(session *types.Sessio
 
 	return issues
 }
+
+// sessionBusinessType returns the business type in session data, defaulting to individual
+func sessionBusinessType(data map[string]interface{}) string {
+	if bt, exists := data["business_type"]; exists {
+		return fmt.Sprintf("%v", bt)
+	}
+	return "individual"
+}
+
+// evaluateDynamicGraph rebuilds a graph's dynamic node statuses from session data and the
+// nodes completed in the session history
+func (s *Service) evaluateDynamicGraph(graph *Graph, businessType string, data map[string]interface{}, history []SessionStep) *DynamicGraph {
+	dynamicGraph := NewDynamicEngine(s.logger).ConvertToDynamicGraph(graph, businessType)
+	for _, step := range history {
+		if step.Action == "forward" {
+			dynamicGraph.UpdateNodeStatus(step.NodeID, NodeStatusCompleted, data)
+		}
+	}
+	NewDynamicPersistenceManager(s.logger).reEvaluateDependencies(dynamicGraph, data)
+	return dynamicGraph
+}
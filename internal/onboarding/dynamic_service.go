@@ -3,6 +3,7 @@ package onboarding
 import (
 	"context"
 	"fmt"
+	"sync"
 	"time"
 
 	"onboarding-system/internal/config"
@@ -11,11 +12,16 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
+// maxDynamicGraphs bounds the converted graphs a service keeps. Business types come from session
+// data, so without a bound every new value would add an entry.
+const maxDynamicGraphs = 1000
+
 // DynamicService extends the regular service with dynamic node management
 type DynamicService struct {
 	*Service
 	dynamicEngine      *DynamicEngine
-	dynamicGraphs      map[string]*DynamicGraph // graphID -> DynamicGraph
+	dynamicGraphs      map[string]*DynamicGraph // graphID@revision/businessType -> converted graph, never modified
+	dynamicGraphsMu    sync.RWMutex
 	persistenceManager *DynamicPersistenceManager
 	logger             *logrus.Logger
 }
@@ -39,33 +45,11 @@ func (ds *DynamicService) StartDynamicSession(ctx context.Context, graphID, user
 		return nil, err
 	}
 
-	// Get the graph using the base service
-	graph, err := ds.Service.GetGraph(ctx, graphID)
+	// Build the session's node statuses, restoring them if the session already existed
+	businessType := dynamicBusinessType(session)
+	dynamicGraph, err := ds.sessionDynamicGraph(ctx, session)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get graph: %w", err)
-	}
-
-	// Determine business type from session data or use default
-	businessType := "individual" // default
-	if session.Data != nil {
-		if bt, exists := session.Data["business_type"]; exists {
-			businessType = fmt.Sprintf("%v", bt)
-		}
-	}
-
-	// Convert to dynamic graph if not already done
-	if _, exists := ds.dynamicGraphs[graphID]; !exists {
-		ds.dynamicGraphs[graphID] = ds.dynamicEngine.ConvertToDynamicGraph(graph, businessType)
-	}
-
-	dynamicGraph := ds.dynamicGraphs[graphID]
-
-	// Restore dynamic state if it exists
-	if err := ds.persistenceManager.RestoreDynamicState(session, dynamicGraph); err != nil {
-		ds.logger.WithFields(logrus.Fields{
-			"session_id": session.ID,
-			"error":      err,
-		}).Warn("Failed to restore dynamic state, using initial state")
+		return nil, err
 	}
 
 	// Save initial dynamic state
@@ -97,23 +81,37 @@ func (ds *DynamicService) SubmitNodeDataDynamic(ctx context.Context, sessionID s
 		return nil, fmt.Errorf("failed to get session: %w", err)
 	}
 
-	// Get dynamic graph
-	dynamicGraph, exists := ds.dynamicGraphs[session.GraphID]
-	if !exists {
-		// Fallback to regular service
+	if err := CheckSessionWritable(session); err != nil {
+		return nil, err
+	}
+
+	// Sessions not started as dynamic sessions use the regular service
+	if session.DynamicState == nil {
 		return ds.Service.SubmitNodeData(ctx, sessionID, data)
 	}
 
+	dynamicGraph, err := ds.sessionDynamicGraph(ctx, session)
+	if err != nil {
+		return nil, err
+	}
+
 	// Get current node from the graph
 	currentNode, exists := dynamicGraph.Graph.Nodes[session.CurrentNodeID]
 	if !exists {
 		return nil, fmt.Errorf("current node not found: %s", session.CurrentNodeID)
 	}
 
-	// Validate node data using the base engine
-	validationResult := ds.dynamicEngine.ValidateNode(ctx, currentNode, data)
+	// Validate node data against the accumulated session data using the base engine
+	validationData := make(map[string]interface{}, len(session.Data)+len(data))
+	for k, v := range session.Data {
+		validationData[k] = v
+	}
+	for k, v := range data {
+		validationData[k] = v
+	}
+	validationResult := ds.dynamicEngine.ValidateNode(ctx, currentNode, validationData)
 	if !validationResult.Valid {
-		return nil, fmt.Errorf("validation failed: %v", validationResult.Errors)
+		return nil, &NodeValidationError{Errors: validationResult.Errors}
 	}
 
 	// Update session data
@@ -132,18 +130,11 @@ func (ds *DynamicService) SubmitNodeDataDynamic(ctx context.Context, sessionID s
 		dynamicGraph.OnNodeDataChanged(session.CurrentNodeID, fieldID, value, session.Data)
 	}
 
-	// Determine business type for persistence
-	businessType := "individual" // default
-	if session.Data != nil {
-		if bt, exists := session.Data["business_type"]; exists {
-			businessType = fmt.Sprintf("%v", bt)
-		}
-	}
-
 	// Save dynamic state after changes
-	ds.persistenceManager.SaveDynamicState(session, dynamicGraph, businessType)
+	ds.persistenceManager.SaveDynamicState(session, dynamicGraph, sessionBusinessType(session.Data))
 
 	// Determine next node
+	submittedNodeID := session.CurrentNodeID
 	nextNode := ds.determineNextNodeDynamic(dynamicGraph, session)
 	if nextNode != nil {
 		session.CurrentNodeID = nextNode.ID
@@ -164,15 +155,23 @@ func (ds *DynamicService) SubmitNodeDataDynamic(ctx context.Context, sessionID s
 	}
 
 	// Save session
-	if err := ds.Service.SaveSession(ctx, session); err != nil {
+	events := []*SessionEvent{newSessionEvent(SessionEventSubmitted, session, nil, map[string]interface{}{
+		"node_id": submittedNodeID,
+		"data":    data,
+	})}
+	if session.Status == SessionStatusCompleted {
+		events = append(events, newSessionEvent(SessionEventCompleted, session, nil, nil))
+	}
+	if err := ds.Service.saveSession(ctx, session, events...); err != nil {
 		return nil, fmt.Errorf("failed to save session: %w", err)
 	}
 
 	// Prepare result
 	result := &NextStepResult{
-		NextNodeID:     session.CurrentNodeID,
-		AvailablePaths: []string{session.CurrentNodeID},
-		CanGoBack:      len(session.History) > 0,
+		NextNodeID:      session.CurrentNodeID,
+		AvailablePaths:  []string{session.CurrentNodeID},
+		CanGoBack:       len(session.History) > 0,
+		SessionRevision: session.Revision,
 		Metadata: map[string]interface{}{
 			"session_status":      string(session.Status),
 			"validation_warnings": validationResult.Warnings,
@@ -198,18 +197,13 @@ func (ds *DynamicService) GetDynamicNodeStatus(ctx context.Context, sessionID st
 		return nil, fmt.Errorf("failed to get session: %w", err)
 	}
 
-	// Get dynamic graph
-	dynamicGraph, exists := ds.dynamicGraphs[session.GraphID]
-	if !exists {
-		return nil, fmt.Errorf("dynamic graph not found for session: %s", sessionID)
+	if session.DynamicState == nil {
+		return nil, fmt.Errorf("dynamic state not found for session: %s", sessionID)
 	}
 
-	// Restore dynamic state if needed
-	if err := ds.persistenceManager.RestoreDynamicState(session, dynamicGraph); err != nil {
-		ds.logger.WithFields(logrus.Fields{
-			"session_id": sessionID,
-			"error":      err,
-		}).Warn("Failed to restore dynamic state")
+	dynamicGraph, err := ds.sessionDynamicGraph(ctx, session)
+	if err != nil {
+		return nil, err
 	}
 
 	// Build response
@@ -231,6 +225,10 @@ func (ds *DynamicService) GetDynamicNodeStatus(ctx context.Context, sessionID st
 			"dependencies":   dynamicNode.Dependencies,
 			"type":           dynamicNode.Type,
 		}
+		if dynamicNode.Reason != "" {
+			nodeInfo["reason"] = dynamicNode.Reason
+			nodeInfo["explanation"] = dynamicNode.Explanation()
+		}
 		response["nodes"].(map[string]interface{})[nodeID] = nodeInfo
 	}
 
@@ -245,8 +243,12 @@ func (ds *DynamicService) UpdateBusinessTypeDynamic(ctx context.Context, session
 		return fmt.Errorf("failed to get session: %w", err)
 	}
 
+	if err := CheckSessionWritable(session); err != nil {
+		return err
+	}
+
 	// Get the graph
-	graph, err := ds.Service.GetGraph(ctx, session.GraphID)
+	graph, err := ds.Service.GetSessionGraph(ctx, session)
 	if err != nil {
 		return fmt.Errorf("failed to get graph: %w", err)
 	}
@@ -255,17 +257,22 @@ func (ds *DynamicService) UpdateBusinessTypeDynamic(ctx context.Context, session
 	if session.Data == nil {
 		session.Data = make(map[string]interface{})
 	}
+	previousBusinessType := session.Data["business_type"]
 	session.Data["business_type"] = businessType
+	session.UpdatedAt = time.Now()
 
-	// Create new dynamic graph with new business type
-	dynamicGraph := ds.dynamicEngine.ConvertToDynamicGraph(graph, businessType)
-	ds.dynamicGraphs[session.GraphID] = dynamicGraph
+	// Recalculate every node status from the initial statuses of the new business type
+	dynamicGraph := ds.convertedGraph(session, graph, businessType).clone()
 
 	// Save dynamic state
 	ds.persistenceManager.SaveDynamicState(session, dynamicGraph, businessType)
 
 	// Save session
-	if err := ds.Service.SaveSession(ctx, session); err != nil {
+	changed := newSessionEvent(SessionEventBusinessTypeChanged, session, nil, map[string]interface{}{
+		"business_type":          businessType,
+		"previous_business_type": previousBusinessType,
+	})
+	if err := ds.Service.saveSession(ctx, session, changed); err != nil {
 		return fmt.Errorf("failed to save session: %w", err)
 	}
 
@@ -285,10 +292,9 @@ func (ds *DynamicService) GetDynamicStateSummary(ctx context.Context, sessionID
 		return nil, fmt.Errorf("failed to get session: %w", err)
 	}
 
-	// Get dynamic graph
-	dynamicGraph, exists := ds.dynamicGraphs[session.GraphID]
-	if !exists {
-		return nil, fmt.Errorf("dynamic graph not found for session: %s", sessionID)
+	dynamicGraph, err := ds.sessionDynamicGraph(ctx, session)
+	if err != nil {
+		return nil, err
 	}
 
 	// Validate dynamic state
@@ -366,12 +372,16 @@ func (ds *DynamicService) GetEligibleNodesDynamic(ctx context.Context, sessionID
 		return nil, fmt.Errorf("failed to get session: %w", err)
 	}
 
-	dynamicGraph, exists := ds.dynamicGraphs[session.GraphID]
-	if !exists {
-		// Fallback to regular service
+	// Sessions not started as dynamic sessions use the regular service
+	if session.DynamicState == nil {
 		return ds.Service.GetEligibleNodes(ctx, sessionID)
 	}
 
+	dynamicGraph, err := ds.sessionDynamicGraph(ctx, session)
+	if err != nil {
+		return nil, err
+	}
+
 	eligibleNodes := make([]string, 0)
 
 	for nodeID, dynamicNode := range dynamicGraph.DynamicNodes {
@@ -393,3 +403,67 @@ func (ds *DynamicService) GetEligibleNodesDynamic(ctx context.Context, sessionID
 
 	return eligibleNodes, nil
 }
+
+// sessionDynamicGraph returns a dynamic graph holding the node statuses of a single session. The
+// converted graph is shared between sessions; the statuses are restored from the session's dynamic
+// state, so requests for different sessions never see each other's progress.
+func (ds *DynamicService) sessionDynamicGraph(ctx context.Context, session *Session) (*DynamicGraph, error) {
+	graph, err := ds.Service.GetSessionGraph(ctx, session)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get graph: %w", err)
+	}
+
+	dynamicGraph := ds.convertedGraph(session, graph, dynamicBusinessType(session)).clone()
+	if err := ds.persistenceManager.RestoreDynamicState(session, dynamicGraph); err != nil {
+		return nil, fmt.Errorf("failed to restore dynamic state: %w", err)
+	}
+
+	return dynamicGraph, nil
+}
+
+// convertedGraph returns the conversion of the graph revision a session is pinned to for a
+// business type. The result is shared and must be cloned before its statuses are changed.
+func (ds *DynamicService) convertedGraph(session *Session, graph *Graph, businessType string) *DynamicGraph {
+	// Sessions started before versioning follow the default graph, which changes on every publish
+	if session.GraphRevision == 0 {
+		return ds.dynamicEngine.ConvertToDynamicGraph(graph, businessType)
+	}
+	key := dynamicGraphKey(session, businessType)
+
+	ds.dynamicGraphsMu.RLock()
+	converted, exists := ds.dynamicGraphs[key]
+	ds.dynamicGraphsMu.RUnlock()
+	if exists {
+		return converted
+	}
+
+	ds.dynamicGraphsMu.Lock()
+	defer ds.dynamicGraphsMu.Unlock()
+	if converted, exists := ds.dynamicGraphs[key]; exists {
+		return converted
+	}
+	converted = ds.dynamicEngine.ConvertToDynamicGraph(graph, businessType)
+	if len(ds.dynamicGraphs) >= maxDynamicGraphs {
+		// Conversions are cheap to redo; drop an arbitrary entry
+		for existing := range ds.dynamicGraphs {
+			delete(ds.dynamicGraphs, existing)
+			break
+		}
+	}
+	ds.dynamicGraphs[key] = converted
+
+	return converted
+}
+
+// dynamicBusinessType returns the business type a session's node statuses were evaluated for
+func dynamicBusinessType(session *Session) string {
+	if session.DynamicState != nil && session.DynamicState.BusinessType != "" {
+		return session.DynamicState.BusinessType
+	}
+	return sessionBusinessType(session.Data)
+}
+
+// dynamicGraphKey identifies the conversion of the revision a session is pinned to for a business type
+func dynamicGraphKey(session *Session, businessType string) string {
+	return fmt.Sprintf("%s@%d/%s", session.GraphID, session.GraphRevision, businessType)
+}
@@ -0,0 +1,43 @@
+package onboarding
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConvertedGraphsAreBounded(t *testing.T) {
+	service, store := newTestService(t)
+	dynamicService := NewDynamicService(store, service.config, newTestLogger())
+	graph := newTestGraph()
+	session := &Session{GraphID: graph.ID, GraphRevision: 1}
+
+	// Every business type a user submits is a new key
+	for i := 0; i < maxDynamicGraphs+10; i++ {
+		dynamicService.convertedGraph(session, graph, fmt.Sprintf("type-%d", i))
+	}
+	assert.Len(t, dynamicService.dynamicGraphs, maxDynamicGraphs)
+
+	// The same revision and business type is served from the cache
+	first := dynamicService.convertedGraph(session, graph, "llp")
+	assert.Same(t, first, dynamicService.convertedGraph(session, graph, "llp"))
+}
+
+func TestConvertedGraphFollowsDefaultGraphForUnversionedSessions(t *testing.T) {
+	service, store := newTestService(t)
+	dynamicService := NewDynamicService(store, service.config, newTestLogger())
+	session := &Session{GraphID: "graph", GraphRevision: 0}
+
+	before := newTestGraph()
+	converted := dynamicService.convertedGraph(session, before, "llp")
+	require.Contains(t, converted.Nodes, "details")
+
+	// Publishing replaces the default graph, and the session picks up the new one
+	after := newTestGraph()
+	after.Nodes["details"].Name = "Company details"
+	converted = dynamicService.convertedGraph(session, after, "llp")
+	assert.Equal(t, "Company details", converted.Nodes["details"].Name)
+	assert.Empty(t, dynamicService.dynamicGraphs)
+}
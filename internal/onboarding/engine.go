@@ -2,34 +2,18 @@ package onboarding
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"regexp"
 	"strconv"
 	"strings"
 
+	"onboarding-system/internal/operators"
+	"onboarding-system/internal/validators"
+
 	"github.com/sirupsen/logrus"
 )
 
-// RuleGroup represents a group of rules that define a complete path to onboarding completion
-type RuleGroup struct {
-	ID                string                          `json:"id"`
-	Name              string                          `json:"name"`
-	Description       string                          `json:"description"`
-	RequiredNodes     []string                        `json:"required_nodes"`     // Node IDs that must be completed for this rule group
-	RequiredFields    map[string][]string             `json:"required_fields"`    // Node ID -> []Field IDs that must be filled
-	ConditionalFields map[string]ConditionalFieldRule `json:"conditional_fields"` // Conditional field requirements
-}
-
-// ConditionalFieldRule defines when a field is required based on other field values
-type ConditionalFieldRule struct {
-	NodeID      string `json:"node_id"`
-	FieldID     string `json:"field_id"`
-	Condition   string `json:"condition"` // Field to check
-	Operator    string `json:"operator"`  // eq, ne, etc.
-	Value       string `json:"value"`     // Value to compare against
-	Description string `json:"description"`
-}
-
 // Engine handles graph traversal and validation
 type Engine struct {
 	logger *logrus.Logger
@@ -92,7 +76,7 @@ func (e *Engine) ValidateNode(ctx context.Context, node *Node, data map[string]i
 				continue
 			}
 
-			fieldResult := e.validateField(field, value)
+			fieldResult := e.validateField(field, value, data)
 			if !fieldResult.Valid {
 				result.Valid = false
 				result.Errors = append(result.Errors, fieldResult.Errors...)
@@ -103,21 +87,17 @@ func (e *Engine) ValidateNode(ctx context.Context, node *Node, data map[string]i
 
 	// Validate custom rules
 	for _, rule := range node.Validation.CustomRules {
-		if !e.validateCustomRule(rule, data) {
+		if err := e.checkCustomRule(rule, data); err != nil {
 			result.Valid = false
-			result.Errors = append(result.Errors, ValidationError{
-				Field:   "",
-				Message: fmt.Sprintf("Custom validation rule failed: %s", rule),
-				Code:    "CUSTOM_RULE_FAILED",
-			})
+			result.Errors = append(result.Errors, customRuleError("", rule, err))
 		}
 	}
 
 	return result
 }
 
-// validateField validates a single field
-func (e *Engine) validateField(field Field, value interface{}) *ValidationResult {
+// validateField validates a single field; data is the rest of the submission, used by cross-field rules
+func (e *Engine) validateField(field Field, value interface{}, data map[string]interface{}) *ValidationResult {
 	result := &ValidationResult{
 		Valid:    true,
 		Errors:   make([]ValidationError, 0),
@@ -197,99 +177,83 @@ func (e *Engine) validateField(field Field, value interface{}) *ValidationResult
 
 	// Custom field rules
 	for _, rule := range field.Validation.CustomRules {
-		if !e.validateCustomRule(rule, map[string]interface{}{field.ID: value}) {
+		if err := e.checkFieldRule(rule, field, value, data); err != nil {
 			result.Valid = false
-			result.Errors = append(result.Errors, ValidationError{
-				Field:   field.ID,
-				Message: fmt.Sprintf("Custom validation rule failed for field %s: %s", field.ID, rule),
-				Code:    "CUSTOM_FIELD_RULE_FAILED",
-			})
+			result.Errors = append(result.Errors, customRuleError(field.ID, rule, err))
 		}
 	}
 
 	return result
 }
 
-// validateCustomRule validates a custom rule
+// validateCustomRule reports whether data satisfies a custom rule, either a named rule or an expression
 func (e *Engine) validateCustomRule(rule string, data map[string]interface{}) bool {
-	// This is a simplified implementation
-	// In a real system, you might use a rule engine like OPA or implement a DSL
-
-	switch rule {
-	case "pan_validation", "pan_format":
-		// Indian PAN format validation
-		if pan, exists := data["pan_number"]; exists {
-			panStr := fmt.Sprintf("%v", pan)
-			panRegex := regexp.MustCompile(`^[A-Z]{5}[0-9]{4}[A-Z]{1}$`)
-			return panRegex.MatchString(panStr)
-		}
-	case "aadhaar_validation", "aadhaar_format":
-		// Indian Aadhaar format validation
-		if aadhaar, exists := data["aadhaar_number"]; exists {
-			aadhaarStr := fmt.Sprintf("%v", aadhaar)
-			aadhaarRegex := regexp.MustCompile(`^[0-9]{12}$`)
-			return aadhaarRegex.MatchString(aadhaarStr)
-		}
-	case "gst_validation", "gst_format":
-		// Indian GST format validation
-		if gst, exists := data["gst_number"]; exists {
-			gstStr := fmt.Sprintf("%v", gst)
-			gstRegex := regexp.MustCompile(`^[0-9]{2}[A-Z]{5}[0-9]{4}[A-Z]{1}[1-9A-Z]{1}Z[0-9A-Z]{1}$`)
-			return gstRegex.MatchString(gstStr)
-		}
+	return e.checkCustomRule(rule, data) == nil
+}
+
+// checkCustomRule evaluates a node or edge custom rule against submitted data
+func (e *Engine) checkCustomRule(rule string, data map[string]interface{}) error {
+	if strings.TrimSpace(rule) == "" {
+		return nil
+	}
+	if isRuleExpression(rule) {
+		return e.checkRuleExpression(rule, data)
 	}
 
-	return true // Default to valid for unknown rules
+	err := validators.Default.ValidateData(rule, data)
+	if errors.Is(err, validators.ErrUnknownRule) {
+		e.logger.WithField("rule", rule).Error("Unknown custom rule")
+	}
+	return err
+}
+
+// checkFieldRule evaluates a field custom rule against the field's value.
+// Expressions see only the field; registered rules also see the rest of the submission.
+func (e *Engine) checkFieldRule(rule string, field Field, value interface{}, data map[string]interface{}) error {
+	if strings.TrimSpace(rule) == "" {
+		return nil
+	}
+	if isRuleExpression(rule) {
+		return e.checkRuleExpression(rule, map[string]interface{}{field.ID: value, "value": value})
+	}
+
+	err := validators.Default.Validate(rule, fmt.Sprintf("%v", value), data)
+	if errors.Is(err, validators.ErrUnknownRule) {
+		e.logger.WithFields(logrus.Fields{"rule": rule, "field": field.ID}).Error("Unknown custom rule")
+	}
+	return err
+}
+
+// checkRuleExpression evaluates a custom rule expression
+func (e *Engine) checkRuleExpression(rule string, data map[string]interface{}) error {
+	passed, err := evaluateRuleExpression(rule, data)
+	if err != nil {
+		e.logger.WithError(err).WithField("rule", rule).Error("Failed to evaluate custom rule")
+		return err
+	}
+	if !passed {
+		return errRuleNotSatisfied
+	}
+	return nil
 }
 
 // validateCondition validates a validation condition
 func (e *Engine) validateCondition(condition ValidationCondition, data map[string]interface{}) bool {
 	fieldValue, exists := data[condition.Field]
-	if !exists {
+	if !exists && !operators.ChecksPresence(condition.Operator) {
 		// If field doesn't exist, check if it's an optional field
 		// For optional fields that don't exist, we should skip the condition validation
 		// Only fail if it's a required field
 		return true // Skip validation for missing optional fields
 	}
 
-	switch condition.Operator {
-	case "eq":
-		return fieldValue == condition.Value
-	case "ne":
-		return fieldValue != condition.Value
-	case "gt":
-		return compareNumbers(fieldValue, condition.Value) > 0
-	case "lt":
-		return compareNumbers(fieldValue, condition.Value) < 0
-	case "gte":
-		return compareNumbers(fieldValue, condition.Value) >= 0
-	case "lte":
-		return compareNumbers(fieldValue, condition.Value) <= 0
-	case "contains":
-		return strings.Contains(fmt.Sprintf("%v", fieldValue), fmt.Sprintf("%v", condition.Value))
-	case "not_contains":
-		return !strings.Contains(fmt.Sprintf("%v", fieldValue), fmt.Sprintf("%v", condition.Value))
-	case "in":
-		if slice, ok := condition.Value.([]interface{}); ok {
-			for _, v := range slice {
-				if fieldValue == v {
-					return true
-				}
-			}
-		}
-		return false
-	case "not_in":
-		if slice, ok := condition.Value.([]interface{}); ok {
-			for _, v := range slice {
-				if fieldValue == v {
-					return false
-				}
-			}
-		}
+	passed, err := operators.Evaluate(condition.Operator, fieldValue, exists, condition.Value)
+	if err != nil {
+		// Conditions the engine cannot evaluate do not block the flow
+		e.logger.WithError(err).WithField("field", condition.Field).Warn("Failed to evaluate condition")
 		return true
 	}
-
-	return true
+	return passed
 }
 
 // GetNextNodes returns the possible next nodes from the current node
@@ -311,14 +275,13 @@ func (e *Engine) GetNextNodes(ctx context.Context, graph *Graph, currentNodeID s
 
 // ValidatePathCompleteness checks if all required nodes have meaningful data filled
 func (e *Engine) ValidatePathCompleteness(ctx context.Context, graph *Graph, currentNodeID string, sessionData map[string]interface{}, sessionHistory []SessionStep) (bool, []string) {
-	// Check if this is the production onboarding graph
+	// Graphs with rule groups are complete once any group for the business type passes
 	businessType, hasBusinessType := sessionData["business_type"]
-	if hasBusinessType {
-		// For production onboarding, validate business-type specific requirements
+	if hasBusinessType && len(graph.RuleGroups) > 0 {
 		return e.ValidateProductionOnboardingCompleteness(ctx, graph, currentNodeID, sessionData, businessType)
 	}
 
-	// Legacy logic for unified onboarding graph
+	// Graphs without rule groups, such as the unified onboarding graph, need every required node
 	// Get all nodes that are required for activation based on the current session data
 	requiredNodes := e.getRequiredNodesForActivation(ctx, graph, sessionData)
 
@@ -388,9 +351,11 @@ func (e *Engine) ValidatePathCompleteness(ctx context.Context, graph *Graph, cur
 func (e *Engine) getRequiredNodesForActivation(ctx context.Context, graph *Graph, sessionData map[string]interface{}) []string {
 	requiredNodes := make([]string, 0)
 
-	// Check if this is the production onboarding graph by looking for business_type
+	// Check if this is the production onboarding graph by looking for business_type; the unified
+	// graph also asks companies for a business type, but always sets user_type
 	_, hasBusinessType := sessionData["business_type"]
-	if hasBusinessType {
+	userType, hasUserType := sessionData["user_type"]
+	if hasBusinessType && !hasUserType {
 		// This is the production onboarding graph - all nodes are required in sequence
 		// except for conditional nodes that depend on business type
 		requiredNodes = []string{
@@ -423,7 +388,6 @@ func (e *Engine) getRequiredNodesForActivation(ctx context.Context, graph *Graph
 	}
 
 	// Legacy logic for unified onboarding graph
-	userType, hasUserType := sessionData["user_type"]
 	if !hasUserType {
 		// If no user type, we can't determine requirements
 		return requiredNodes
@@ -558,32 +522,6 @@ func (e *Engine) GetPreviousNodes(ctx context.Context, graph *Graph, currentNode
 	return previousNodes
 }
 
-// compareNumbers compares two values as numbers
-func compareNumbers(a, b interface{}) int {
-	aStr := fmt.Sprintf("%v", a)
-	bStr := fmt.Sprintf("%v", b)
-
-	aNum, errA := strconv.ParseFloat(aStr, 64)
-	bNum, errB := strconv.ParseFloat(bStr, 64)
-
-	if errA != nil || errB != nil {
-		// If either is not a number, do string comparison
-		if aStr < bStr {
-			return -1
-		} else if aStr > bStr {
-			return 1
-		}
-		return 0
-	}
-
-	if aNum < bNum {
-		return -1
-	} else if aNum > bNum {
-		return 1
-	}
-	return 0
-}
-
 // GetFirstMissingNode returns the ID of the first missing node that needs to be completed
 func (e *Engine) GetFirstMissingNode(ctx context.Context, graph *Graph, missingNodeNames []string, sessionData map[string]interface{}) string {
 	// Get user type to determine the correct order
@@ -645,39 +583,12 @@ func (e *Engine) GetFirstMissingNode(ctx context.Context, graph *Graph, missingN
 // evaluateCondition evaluates a validation condition against the provided data
 func (e *Engine) evaluateCondition(condition ValidationCondition, data map[string]interface{}) bool {
 	fieldValue, exists := data[condition.Field]
-	if !exists {
-		return false
-	}
-
-	switch condition.Operator {
-	case "eq":
-		return fmt.Sprintf("%v", fieldValue) == fmt.Sprintf("%v", condition.Value)
-	case "ne":
-		return fmt.Sprintf("%v", fieldValue) != fmt.Sprintf("%v", condition.Value)
-	case "in":
-		if values, ok := condition.Value.([]string); ok {
-			fieldStr := fmt.Sprintf("%v", fieldValue)
-			for _, v := range values {
-				if fieldStr == v {
-					return true
-				}
-			}
-		}
-		return false
-	case "not_in":
-		if values, ok := condition.Value.([]string); ok {
-			fieldStr := fmt.Sprintf("%v", fieldValue)
-			for _, v := range values {
-				if fieldStr == v {
-					return false
-				}
-			}
-		}
-		return true
-	default:
-		e.logger.WithField("operator", condition.Operator).Warn("Unknown condition operator")
+	met, err := operators.Evaluate(condition.Operator, fieldValue, exists, condition.Value)
+	if err != nil {
+		e.logger.WithError(err).WithField("operator", condition.Operator).Warn("Failed to evaluate condition")
 		return false
 	}
+	return met
 }
 
 // getRequiredFieldsForCondition returns the fields that should be required when a condition is met
@@ -707,8 +618,8 @@ func (e *Engine) ValidateProductionOnboardingCompleteness(ctx context.Context, g
 	// Get business type as string
 	businessTypeStr := fmt.Sprintf("%v", businessType)
 
-	// Get all rule groups for this business type
-	ruleGroups := e.GetBusinessTypeRuleGroups(businessTypeStr)
+	// Get the graph's rule groups for this business type
+	ruleGroups := e.GetBusinessTypeRuleGroups(graph, businessTypeStr)
 
 	// Check if ANY rule group passes (all its required nodes are filled)
 	for _, ruleGroup := range ruleGroups {
@@ -746,250 +657,13 @@ func (e *Engine) ValidateProductionOnboardingCompleteness(ctx context.Context, g
 	return false, allMissingRequirements
 }
 
-// GetBusinessTypeRuleGroups returns all rule groups for a business type
-func (e *Engine) GetBusinessTypeRuleGroups(businessType string) []RuleGroup {
-	allRuleGroups := e.getAllRuleGroups()
-	if ruleGroups, exists := allRuleGroups[businessType]; exists {
-		return ruleGroups
-	}
-
-	// Return empty rule groups if not found
-	return []RuleGroup{}
-}
-
-// getAllRuleGroups defines rule groups for each business type - multiple paths to completion
-func (e *Engine) getAllRuleGroups() map[string][]RuleGroup {
-	ruleGroups := make(map[string][]RuleGroup)
-
-	// Individual business type rule groups
-	ruleGroups["individual"] = []RuleGroup{
-		{
-			ID:          "individual_basic_path",
-			Name:        "Individual Basic Path",
-			Description: "Basic individual onboarding with essential fields only",
-			RequiredNodes: []string{
-				"business_type_selection",
-				"payment_channel",
-				"business_details",
-			},
-			RequiredFields: map[string][]string{
-				"business_type_selection": {"business_type"},
-				"payment_channel":         {"payment_channel"},
-				"business_details":        {"business_name", "brand_name", "business_address_line1", "business_city", "business_state", "business_pincode"},
-			},
-			ConditionalFields: map[string]ConditionalFieldRule{
-				"website_url_required": {
-					NodeID:      "payment_channel",
-					FieldID:     "website_url",
-					Condition:   "payment_channel",
-					Operator:    "eq",
-					Value:       "website",
-					Description: "Website URL is required when payment channel is website",
-				},
-				"app_urls_required": {
-					NodeID:      "payment_channel",
-					FieldID:     "android_url",
-					Condition:   "payment_channel",
-					Operator:    "eq",
-					Value:       "app",
-					Description: "Android and iOS URLs are required when payment channel is app",
-				},
-			},
-		},
-		{
-			ID:          "individual_complete_path",
-			Name:        "Individual Complete Path",
-			Description: "Complete individual onboarding with all fields including documents",
-			RequiredNodes: []string{
-				"business_type_selection",
-				"payment_channel",
-				"mcc_policy_verification",
-				"business_details",
-			},
-			RequiredFields: map[string][]string{
-				"business_type_selection": {"business_type"},
-				"payment_channel":         {"payment_channel"},
-				"mcc_policy_verification": {"subcategory"},
-				"business_details":        {"business_name", "brand_name", "business_address_line1", "business_city", "business_state", "business_pincode"},
-			},
-			ConditionalFields: map[string]ConditionalFieldRule{
-				"website_url_required": {
-					NodeID:      "payment_channel",
-					FieldID:     "website_url",
-					Condition:   "payment_channel",
-					Operator:    "eq",
-					Value:       "website",
-					Description: "Website URL is required when payment channel is website",
-				},
-				"app_urls_required": {
-					NodeID:      "payment_channel",
-					FieldID:     "android_url",
-					Condition:   "payment_channel",
-					Operator:    "eq",
-					Value:       "app",
-					Description: "Android and iOS URLs are required when payment channel is app",
-				},
-			},
-		},
-	}
-
-	// Proprietorship business type rule groups
-	ruleGroups["proprietorship"] = []RuleGroup{
-		{
-			ID:          "proprietorship_basic_path",
-			Name:        "Proprietorship Basic Path",
-			Description: "Basic proprietorship onboarding with essential fields only",
-			RequiredNodes: []string{
-				"business_type_selection",
-				"payment_channel",
-				"business_details",
-			},
-			RequiredFields: map[string][]string{
-				"business_type_selection": {"business_type"},
-				"payment_channel":         {"payment_channel"},
-				"business_details":        {"business_name", "brand_name", "business_address_line1", "business_city", "business_state", "business_pincode"},
-			},
-			ConditionalFields: map[string]ConditionalFieldRule{
-				"website_url_required": {
-					NodeID:      "payment_channel",
-					FieldID:     "website_url",
-					Condition:   "payment_channel",
-					Operator:    "eq",
-					Value:       "website",
-					Description: "Website URL is required when payment channel is website",
-				},
-				"app_urls_required": {
-					NodeID:      "payment_channel",
-					FieldID:     "android_url",
-					Condition:   "payment_channel",
-					Operator:    "eq",
-					Value:       "app",
-					Description: "Android and iOS URLs are required when payment channel is app",
-				},
-			},
-		},
-		{
-			ID:          "proprietorship_complete_path",
-			Name:        "Proprietorship Complete Path",
-			Description: "Complete proprietorship onboarding with all fields including documents",
-			RequiredNodes: []string{
-				"business_type_selection",
-				"payment_channel",
-				"mcc_policy_verification",
-				"business_details",
-			},
-			RequiredFields: map[string][]string{
-				"business_type_selection": {"business_type"},
-				"payment_channel":         {"payment_channel"},
-				"mcc_policy_verification": {"subcategory"},
-				"business_details":        {"business_name", "brand_name", "business_address_line1", "business_city", "business_state", "business_pincode"},
-			},
-			ConditionalFields: map[string]ConditionalFieldRule{
-				"website_url_required": {
-					NodeID:      "payment_channel",
-					FieldID:     "website_url",
-					Condition:   "payment_channel",
-					Operator:    "eq",
-					Value:       "website",
-					Description: "Website URL is required when payment channel is website",
-				},
-				"app_urls_required": {
-					NodeID:      "payment_channel",
-					FieldID:     "android_url",
-					Condition:   "payment_channel",
-					Operator:    "eq",
-					Value:       "app",
-					Description: "Android and iOS URLs are required when payment channel is app",
-				},
-			},
-		},
-	}
-
-	// Private Limited business type rule groups
-	ruleGroups["private_limited"] = []RuleGroup{
-		{
-			ID:          "private_limited_basic_path",
-			Name:        "Private Limited Basic Path",
-			Description: "Basic private limited onboarding with essential fields only",
-			RequiredNodes: []string{
-				"business_type_selection",
-				"payment_channel",
-				"business_details",
-			},
-			RequiredFields: map[string][]string{
-				"business_type_selection": {"business_type"},
-				"payment_channel":         {"payment_channel"},
-				"business_details":        {"business_name", "brand_name", "business_address_line1", "business_city", "business_state", "business_pincode"},
-			},
-			ConditionalFields: map[string]ConditionalFieldRule{
-				"website_url_required": {
-					NodeID:      "payment_channel",
-					FieldID:     "website_url",
-					Condition:   "payment_channel",
-					Operator:    "eq",
-					Value:       "website",
-					Description: "Website URL is required when payment channel is website",
-				},
-				"app_urls_required": {
-					NodeID:      "payment_channel",
-					FieldID:     "android_url",
-					Condition:   "payment_channel",
-					Operator:    "eq",
-					Value:       "app",
-					Description: "Android and iOS URLs are required when payment channel is app",
-				},
-			},
-		},
-		{
-			ID:          "private_limited_complete_path",
-			Name:        "Private Limited Complete Path",
-			Description: "Complete private limited onboarding with all fields including documents",
-			RequiredNodes: []string{
-				"business_type_selection",
-				"payment_channel",
-				"mcc_policy_verification",
-				"business_details",
-			},
-			RequiredFields: map[string][]string{
-				"business_type_selection": {"business_type"},
-				"payment_channel":         {"payment_channel"},
-				"mcc_policy_verification": {"subcategory"},
-				"business_details":        {"business_name", "brand_name", "business_address_line1", "business_city", "business_state", "business_pincode"},
-			},
-			ConditionalFields: map[string]ConditionalFieldRule{
-				"website_url_required": {
-					NodeID:      "payment_channel",
-					FieldID:     "website_url",
-					Condition:   "payment_channel",
-					Operator:    "eq",
-					Value:       "website",
-					Description: "Website URL is required when payment channel is website",
-				},
-				"app_urls_required": {
-					NodeID:      "payment_channel",
-					FieldID:     "android_url",
-					Condition:   "payment_channel",
-					Operator:    "eq",
-					Value:       "app",
-					Description: "Android and iOS URLs are required when payment channel is app",
-				},
-			},
-		},
-	}
-
-	// Add similar rule groups for other business types...
-	// For now, using the same structure for all types
-	for _, businessType := range []string{"public_limited", "partnership", "llp", "trust", "society", "huf"} {
-		baseRuleGroups := ruleGroups["private_limited"] // Copy the same structure
-		for i := range baseRuleGroups {
-			baseRuleGroups[i].ID = businessType + "_" + baseRuleGroups[i].ID
-			// Convert business type to title case
-			titleCase := strings.ReplaceAll(businessType, "_", " ")
-			titleCase = strings.Title(titleCase)
-			baseRuleGroups[i].Name = strings.Replace(baseRuleGroups[i].Name, "Private Limited", titleCase, 1)
-			baseRuleGroups[i].Description = strings.Replace(baseRuleGroups[i].Description, "private limited", businessType, 1)
+// GetBusinessTypeRuleGroups returns the graph's rule groups that apply to a business type
+func (e *Engine) GetBusinessTypeRuleGroups(graph *Graph, businessType string) []RuleGroup {
+	ruleGroups := make([]RuleGroup, 0)
+	for _, ruleGroup := range graph.RuleGroups {
+		if ruleGroup.AppliesTo(businessType) {
+			ruleGroups = append(ruleGroups, ruleGroup)
 		}
-		ruleGroups[businessType] = baseRuleGroups
 	}
 
 	return ruleGroups
@@ -997,56 +671,19 @@ func (e *Engine) getAllRuleGroups() map[string][]RuleGroup {
 
 // EvaluateRuleGroup checks if all required nodes and fields are completed according to the rule group
 func (e *Engine) EvaluateRuleGroup(ruleGroup RuleGroup, sessionData map[string]interface{}) (bool, []string) {
-	missingRequirements := make([]string, 0)
-
-	// Check if all required nodes have been visited (have data)
-	for _, nodeID := range ruleGroup.RequiredNodes {
-		// Check if this node has been completed by looking for its key fields in session data
-		requiredFields, hasRequiredFields := ruleGroup.RequiredFields[nodeID]
-		if !hasRequiredFields {
-			continue
-		}
-
-		for _, fieldID := range requiredFields {
-			if value, exists := sessionData[fieldID]; !exists || value == nil || value == "" {
-				missingRequirements = append(missingRequirements, fmt.Sprintf("Node %s: field %s is required", nodeID, fieldID))
-			}
-		}
-
-		// Check conditional fields for this node
-		for _, conditionalRule := range ruleGroup.ConditionalFields {
-			if conditionalRule.NodeID == nodeID {
-				if e.evaluateConditionalField(conditionalRule, sessionData) {
-					// Condition is met, so this field is required
-					if value, exists := sessionData[conditionalRule.FieldID]; !exists || value == nil || value == "" {
-						missingRequirements = append(missingRequirements, fmt.Sprintf("Node %s: field %s is required (%s)", nodeID, conditionalRule.FieldID, conditionalRule.Description))
-					}
-				}
-			}
-		}
-	}
-
-	// Rule group passes if no missing requirements
-	return len(missingRequirements) == 0, missingRequirements
+	report := e.ExplainRuleGroup(ruleGroup, sessionData)
+	return report.Complete, report.MissingRequirements
 }
 
 // evaluateConditionalField checks if a conditional field requirement is met
 func (e *Engine) evaluateConditionalField(rule ConditionalFieldRule, sessionData map[string]interface{}) bool {
 	conditionValue, exists := sessionData[rule.Condition]
-	if !exists {
-		return false
-	}
-
-	conditionStr := fmt.Sprintf("%v", conditionValue)
-
-	switch rule.Operator {
-	case "eq":
-		return conditionStr == rule.Value
-	case "ne":
-		return conditionStr != rule.Value
-	default:
+	met, err := operators.Evaluate(rule.Operator, conditionValue, exists, rule.Value)
+	if err != nil {
+		e.logger.WithError(err).WithField("operator", rule.Operator).Warn("Failed to evaluate conditional field")
 		return false
 	}
+	return met
 }
 
 // getBusinessTypeRequirements returns the specific requirements for each business type based on CSV rules
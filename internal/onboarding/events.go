@@ -0,0 +1,92 @@
+package onboarding
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// EventHandler receives session events. Handlers run synchronously after the change is saved,
+// so they should hand slow work off rather than block.
+type EventHandler func(ctx context.Context, event SessionEvent)
+
+// Subscribe registers a handler for the session events emitted by this service
+func (s *Service) Subscribe(handler EventHandler) {
+	s.eventsMutex.Lock()
+	defer s.eventsMutex.Unlock()
+
+	s.eventHandlers = append(s.eventHandlers, handler)
+}
+
+// emit logs a session event and passes it to the subscribed handlers
+func (s *Service) emit(ctx context.Context, event SessionEvent) {
+	s.logger.WithFields(logrus.Fields{
+		"event":      event.Type,
+		"session_id": event.SessionID,
+		"status":     event.Status,
+	}).Info("Session event")
+
+	s.eventsMutex.RLock()
+	handlers := s.eventHandlers
+	s.eventsMutex.RUnlock()
+
+	for _, handler := range handlers {
+		handler(ctx, event)
+	}
+}
+
+// saveSession saves a session together with the events recording its change, then passes the
+// events to subscribers
+func (s *Service) saveSession(ctx context.Context, session *Session, events ...*SessionEvent) error {
+	if err := s.storage.SaveSessionWithEvents(ctx, session, events); err != nil {
+		return err
+	}
+
+	for _, event := range events {
+		s.emit(ctx, *event)
+	}
+	return nil
+}
+
+// newSessionEvent returns an event of the given type for the session's current state. step is the
+// history step the change appended, if any.
+func newSessionEvent(eventType SessionEventType, session *Session, step *SessionStep, data map[string]interface{}) *SessionEvent {
+	return &SessionEvent{
+		ID:         uuid.New().String(),
+		Type:       eventType,
+		SessionID:  session.ID,
+		UserID:     session.UserID,
+		GraphID:    session.GraphID,
+		NodeID:     session.CurrentNodeID,
+		Status:     session.Status,
+		Data:       data,
+		Step:       step,
+		OccurredAt: time.Now(),
+	}
+}
+
+// newSessionUpdatedEvent returns an event recording a session saved whole. The change is not
+// known, so the event records the session's state rather than a step.
+func newSessionUpdatedEvent(session *Session) *SessionEvent {
+	data := map[string]interface{}{
+		"graph_revision": session.GraphRevision,
+		"retry_count":    session.RetryCount,
+		"data":           session.Data,
+		"history":        session.History,
+	}
+	if session.CompletedAt != nil {
+		data["completed_at"] = session.CompletedAt.UTC().Format(time.RFC3339Nano)
+	}
+	return newSessionEvent(SessionEventUpdated, session, nil, data)
+}
+
+// lastStep returns the session's most recent history step
+func lastStep(session *Session) *SessionStep {
+	if len(session.History) == 0 {
+		return nil
+	}
+	step := session.History[len(session.History)-1]
+	return &step
+}
@@ -0,0 +1,69 @@
+package onboarding
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"onboarding-system/internal/graphloader"
+
+	"github.com/sirupsen/logrus"
+)
+
+// LoadGraphDefinitions publishes the YAML and JSON graph definitions in a directory.
+// Graphs are upserted by their stable ID; unchanged files do not create a new revision.
+func (s *Service) LoadGraphDefinitions(ctx context.Context, dir string) error {
+	definitions, err := graphloader.LoadDir(dir)
+	for _, definition := range definitions {
+		if syncErr := s.syncGraphDefinition(ctx, definition); syncErr != nil {
+			err = errors.Join(err, syncErr)
+		}
+	}
+	return err
+}
+
+// WatchGraphDefinitions reloads the definition directory whenever a file changes, until the context is cancelled
+func (s *Service) WatchGraphDefinitions(ctx context.Context, dir string, interval time.Duration) {
+	watcher := graphloader.NewWatcher(dir, interval)
+	watcher.Run(ctx, func(paths []string) {
+		s.logger.WithField("files", paths).Info("Graph definitions changed, reloading")
+		if err := s.LoadGraphDefinitions(ctx, dir); err != nil {
+			s.logger.WithError(err).WithField("dir", dir).Error("Failed to reload graph definitions")
+		}
+	})
+}
+
+// syncGraphDefinition publishes a definition unless the default revision was published from the same file contents
+func (s *Service) syncGraphDefinition(ctx context.Context, definition *graphloader.Definition) error {
+	graph := definition.Graph
+
+	versions, err := s.storage.ListGraphVersions(ctx, graph.ID)
+	if err != nil {
+		return fmt.Errorf("failed to list graph versions: %w", err)
+	}
+
+	if len(versions) > 0 {
+		current, err := s.storage.GetGraph(ctx, graph.ID)
+		if err != nil {
+			return fmt.Errorf("failed to get graph: %w", err)
+		}
+		if current.Metadata[graphloader.MetadataSourceChecksum] == definition.Checksum {
+			return nil
+		}
+		graph.CreatedAt = current.CreatedAt
+	}
+
+	if err := s.publishGraph(ctx, graph); err != nil {
+		return fmt.Errorf("%s: %w", definition.Path, err)
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"graph_id": graph.ID,
+		"revision": graph.Revision,
+		"file":     definition.Path,
+		"warnings": definition.Report.Warnings,
+	}).Info("Published graph definition")
+
+	return nil
+}
@@ -0,0 +1,31 @@
+package onboarding
+
+import (
+	"context"
+	"io"
+
+	"onboarding-system/internal/graphimport"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ImportGraphCSV builds a graph from the requirements CSV format and publishes it
+func (s *Service) ImportGraphCSV(ctx context.Context, r io.Reader, opts graphimport.Options) (*graphimport.Result, error) {
+	result, err := graphimport.ImportCSV(r, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.publishGraph(ctx, result.Graph); err != nil {
+		return nil, err
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"graph_id": result.Graph.ID,
+		"revision": result.Graph.Revision,
+		"nodes":    len(result.Graph.Nodes),
+		"warnings": len(result.Warnings),
+	}).Info("Imported graph from csv")
+
+	return result, nil
+}
@@ -0,0 +1,242 @@
+package onboarding
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ErrSessionExpired is returned when changing a session that expired through inactivity
+var ErrSessionExpired = errors.New("session has expired")
+
+// ErrSessionPaused is returned when changing a paused session
+var ErrSessionPaused = errors.New("session is paused")
+
+// ErrSessionNotInactive is returned when reactivating a session that is neither paused nor expired
+var ErrSessionNotInactive = errors.New("session is not paused or expired")
+
+// ErrRetriesExhausted is returned when a session has used up its retries
+var ErrRetriesExhausted = errors.New("maximum retry count exceeded")
+
+// History actions recorded for lifecycle transitions
+const (
+	actionPaused      = "paused"
+	actionExpired     = "expired"
+	actionReactivated = "reactivated"
+)
+
+// CheckSessionWritable rejects changes to sessions that are paused or expired
+func CheckSessionWritable(session *Session) error {
+	switch session.Status {
+	case SessionStatusExpired:
+		return ErrSessionExpired
+	case SessionStatusPaused:
+		return ErrSessionPaused
+	}
+	return nil
+}
+
+// RunSessionLifecycle pauses and expires idle sessions every interval until ctx is done
+func (s *Service) RunSessionLifecycle(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, _, err := s.SweepIdleSessions(ctx, s.now()); err != nil && ctx.Err() == nil {
+				s.logger.WithError(err).Error("Failed to sweep idle sessions")
+			}
+		}
+	}
+}
+
+// SweepIdleSessions pauses active sessions idle for longer than the pause window and expires
+// active or paused sessions idle for longer than the session timeout. Sessions their user paused
+// are expired only once both the timeout and their resume token's lifetime have passed. It
+// returns the number of sessions paused and expired.
+func (s *Service) SweepIdleSessions(ctx context.Context, now time.Time) (int, int, error) {
+	timeout := s.config.Onboarding.SessionTimeout
+	pauseAfter := s.config.Onboarding.PauseAfter
+
+	// A user's own pause lasts at least as long as the resume token issued with it
+	userPauseTimeout := timeout
+	if timeout > 0 && s.config.Onboarding.ResumeTokenTTL > timeout {
+		userPauseTimeout = s.config.Onboarding.ResumeTokenTTL
+	}
+
+	// Only sessions idle for the shorter of the two windows can be due. Pausing saves a session,
+	// so one the sweeper paused may expire up to one pause window after its timeout.
+	window := timeout
+	if pauseAfter > 0 && (window <= 0 || pauseAfter < window) {
+		window = pauseAfter
+	}
+	if window <= 0 {
+		return 0, 0, nil
+	}
+
+	paused, expired := 0, 0
+	query := SessionQuery{
+		Statuses:  []SessionStatus{SessionStatusActive, SessionStatusPaused},
+		UpdatedTo: now.Add(-window),
+		Ascending: true,
+		Limit:     MaxSessionPageSize,
+	}
+	err := s.forEachSession(ctx, query, func(session *Session) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		lastActive := lastActiveAt(session)
+		idle := now.Sub(lastActive)
+
+		var status SessionStatus
+		switch {
+		case pausedByUser(session):
+			if userPauseTimeout <= 0 || idle < userPauseTimeout {
+				return nil
+			}
+			status = SessionStatusExpired
+		case timeout > 0 && idle >= timeout:
+			status = SessionStatusExpired
+		case session.Status == SessionStatusActive && pauseAfter > 0 && idle >= pauseAfter:
+			status = SessionStatusPaused
+		default:
+			return nil
+		}
+
+		if err := s.transitionIdleSession(ctx, session, status, lastActive, now); err != nil {
+			// A conflict means the user acted since the sessions were listed
+			if !errors.Is(err, ErrSessionConflict) {
+				s.logger.WithError(err).WithField("session_id", session.ID).Error("Failed to transition idle session")
+			}
+			return nil
+		}
+
+		if status == SessionStatusExpired {
+			expired++
+		} else {
+			paused++
+		}
+		return nil
+	})
+	if err != nil {
+		return paused, expired, err
+	}
+
+	if paused > 0 || expired > 0 {
+		s.logger.WithFields(logrus.Fields{
+			"paused":  paused,
+			"expired": expired,
+		}).Info("Swept idle sessions")
+	}
+
+	return paused, expired, nil
+}
+
+// transitionIdleSession moves an idle session to status and records why
+func (s *Service) transitionIdleSession(ctx context.Context, session *Session, status SessionStatus, lastActive, now time.Time) error {
+	previousStatus := session.Status
+	action, eventType := actionPaused, SessionEventPaused
+	if status == SessionStatusExpired {
+		action, eventType = actionExpired, SessionEventExpired
+	}
+
+	data := map[string]interface{}{
+		"reason":          "inactivity",
+		"previous_status": string(previousStatus),
+		"last_active_at":  lastActive.UTC().Format(time.RFC3339Nano),
+	}
+
+	session.Status = status
+	session.UpdatedAt = now
+	session.History = append(session.History, SessionStep{
+		ID:        fmt.Sprintf("%s-%s-%d", session.ID, action, len(session.History)),
+		NodeID:    session.CurrentNodeID,
+		Data:      data,
+		Timestamp: now,
+		Action:    action,
+	})
+
+	if err := s.saveSession(ctx, session, newSessionEvent(eventType, session, lastStep(session), data)); err != nil {
+		return fmt.Errorf("failed to save session: %w", err)
+	}
+	return nil
+}
+
+// lastActiveAt returns when a session was last used. Pausing saves the session without it
+// being used, so a paused session's last activity is the one recorded when it was paused.
+func lastActiveAt(session *Session) time.Time {
+	if len(session.History) > 0 {
+		step := session.History[len(session.History)-1]
+		if step.Action == actionPaused {
+			if value, ok := step.Data["last_active_at"].(string); ok {
+				if lastActive, err := time.Parse(time.RFC3339Nano, value); err == nil {
+					return lastActive
+				}
+			}
+		}
+	}
+	return session.UpdatedAt
+}
+
+// pausedByUser reports whether a paused session was paused by its user rather than for inactivity
+func pausedByUser(session *Session) bool {
+	if session.Status != SessionStatusPaused || len(session.History) == 0 {
+		return false
+	}
+	step := session.History[len(session.History)-1]
+	return step.Action == actionPaused && step.Data["reason"] == "user"
+}
+
+// ReactivateSession returns a paused or expired session to active. Reactivation counts as a retry,
+// so it fails once the session has used up its retries.
+func (s *Service) ReactivateSession(ctx context.Context, sessionID string) (*Session, error) {
+	session, err := s.GetSession(ctx, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get session: %w", err)
+	}
+
+	if session.Status != SessionStatusPaused && session.Status != SessionStatusExpired {
+		return nil, ErrSessionNotInactive
+	}
+
+	if session.RetryCount >= s.config.Onboarding.MaxRetries {
+		return nil, ErrRetriesExhausted
+	}
+
+	previousStatus := session.Status
+	now := s.now()
+	session.Status = SessionStatusActive
+	session.RetryCount++
+	session.UpdatedAt = now
+
+	data := map[string]interface{}{
+		"previous_status": string(previousStatus),
+		"retry_count":     session.RetryCount,
+	}
+	session.History = append(session.History, SessionStep{
+		ID:        fmt.Sprintf("%s-%s-%d", session.ID, actionReactivated, len(session.History)),
+		NodeID:    session.CurrentNodeID,
+		Data:      data,
+		Timestamp: now,
+		Action:    actionReactivated,
+	})
+
+	if err := s.saveSession(ctx, session, newSessionEvent(SessionEventReactivated, session, lastStep(session), data)); err != nil {
+		return nil, fmt.Errorf("failed to save session: %w", err)
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"session_id":      sessionID,
+		"previous_status": previousStatus,
+		"retry_count":     session.RetryCount,
+	}).Info("Session reactivated")
+
+	return session, nil
+}
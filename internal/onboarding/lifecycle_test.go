@@ -0,0 +1,203 @@
+package onboarding
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"onboarding-system/internal/config"
+	"onboarding-system/internal/storage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testClock is a settable clock for the service's lifecycle changes
+type testClock struct {
+	now time.Time
+}
+
+func (c *testClock) Now() time.Time {
+	return c.now
+}
+
+func (c *testClock) Advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+// newLifecycleTestService returns a test service that pauses after an hour, expires after a day
+// and allows one retry, with its clock
+func newLifecycleTestService(t *testing.T) (*Service, *testClock) {
+	t.Helper()
+
+	service, _ := newTestServiceWithConfig(t, &config.Config{Onboarding: config.OnboardingConfig{
+		MaxRetries:     1,
+		SessionTimeout: 24 * time.Hour,
+		PauseAfter:     time.Hour,
+	}})
+	clock := &testClock{now: time.Now()}
+	service.now = clock.Now
+	return service, clock
+}
+
+// lastHistoryStep returns a stored session's most recent history step
+func lastHistoryStep(t *testing.T, service *Service, sessionID string) SessionStep {
+	t.Helper()
+
+	session, err := service.GetSession(context.Background(), sessionID)
+	require.NoError(t, err)
+	require.NotEmpty(t, session.History)
+	return session.History[len(session.History)-1]
+}
+
+func TestSweepIdleSessions(t *testing.T) {
+	service, clock := newLifecycleTestService(t)
+	ctx := context.Background()
+	graph := createTestGraph(t, service)
+
+	session, err := service.StartSession(ctx, "user-1", graph.ID)
+	require.NoError(t, err)
+
+	var events []SessionEvent
+	service.Subscribe(func(ctx context.Context, event SessionEvent) {
+		events = append(events, event)
+	})
+
+	clock.Advance(30 * time.Minute)
+	paused, expired, err := service.SweepIdleSessions(ctx, clock.Now())
+	require.NoError(t, err)
+	assert.Equal(t, 0, paused)
+	assert.Equal(t, 0, expired)
+
+	clock.Advance(90 * time.Minute)
+	paused, expired, err = service.SweepIdleSessions(ctx, clock.Now())
+	require.NoError(t, err)
+	assert.Equal(t, 1, paused)
+	assert.Equal(t, 0, expired)
+
+	step := lastHistoryStep(t, service, session.ID)
+	assert.Equal(t, actionPaused, step.Action)
+	assert.Equal(t, "inactivity", step.Data["reason"])
+	assert.True(t, clock.Now().Equal(step.Timestamp), "step at %s, clock at %s", step.Timestamp, clock.Now())
+
+	// A sweeper pause is not activity, so the session expires a day after it was last used
+	clock.Advance(23 * time.Hour)
+	paused, expired, err = service.SweepIdleSessions(ctx, clock.Now())
+	require.NoError(t, err)
+	assert.Equal(t, 0, paused)
+	assert.Equal(t, 1, expired)
+
+	stored, err := service.GetSession(ctx, session.ID)
+	require.NoError(t, err)
+	assert.Equal(t, SessionStatusExpired, stored.Status)
+	assert.Equal(t, "paused", lastHistoryStep(t, service, session.ID).Data["previous_status"])
+
+	require.Len(t, events, 2)
+	assert.Equal(t, SessionEventPaused, events[0].Type)
+	assert.Equal(t, SessionEventExpired, events[1].Type)
+
+	// Expired sessions are not swept again
+	clock.Advance(24 * time.Hour)
+	paused, expired, err = service.SweepIdleSessions(ctx, clock.Now())
+	require.NoError(t, err)
+	assert.Equal(t, 0, paused+expired)
+}
+
+// recordingStorage records the session queries it is asked for
+type recordingStorage struct {
+	storage.Storage
+	queries []SessionQuery
+}
+
+func (s *recordingStorage) ListSessionsQuery(ctx context.Context, query SessionQuery) (*SessionPage, error) {
+	s.queries = append(s.queries, query)
+	return s.Storage.ListSessionsQuery(ctx, query)
+}
+
+func TestSweepIdleSessionsQueriesIdleSessionsOnly(t *testing.T) {
+	now := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name       string
+		timeout    time.Duration
+		pauseAfter time.Duration
+		updatedTo  time.Time // Zero when nothing is queried
+	}{
+		{"pause window is shorter", 24 * time.Hour, time.Hour, now.Add(-time.Hour)},
+		{"pausing disabled", 24 * time.Hour, 0, now.Add(-24 * time.Hour)},
+		{"expiry disabled", 0, time.Hour, now.Add(-time.Hour)},
+		{"timeout is shorter", time.Hour, 2 * time.Hour, now.Add(-time.Hour)},
+		{"both disabled", 0, 0, time.Time{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &recordingStorage{Storage: storage.NewMemoryStorage(newTestLogger())}
+			service := newTestServiceOn(store, &config.Config{Onboarding: config.OnboardingConfig{
+				SessionTimeout: tt.timeout,
+				PauseAfter:     tt.pauseAfter,
+			}})
+
+			_, _, err := service.SweepIdleSessions(context.Background(), now)
+			require.NoError(t, err)
+
+			if tt.updatedTo.IsZero() {
+				assert.Empty(t, store.queries)
+				return
+			}
+			require.Len(t, store.queries, 1)
+			assert.Equal(t, tt.updatedTo, store.queries[0].UpdatedTo)
+			assert.ElementsMatch(t, []SessionStatus{SessionStatusActive, SessionStatusPaused}, store.queries[0].Statuses)
+		})
+	}
+}
+
+func TestReactivateSession(t *testing.T) {
+	service, clock := newLifecycleTestService(t)
+	ctx := context.Background()
+	graph := createTestGraph(t, service)
+
+	session, err := service.StartSession(ctx, "user-1", graph.ID)
+	require.NoError(t, err)
+
+	_, err = service.ReactivateSession(ctx, session.ID)
+	assert.True(t, errors.Is(err, ErrSessionNotInactive), "expected ErrSessionNotInactive, got %v", err)
+
+	clock.Advance(25 * time.Hour)
+	_, expired, err := service.SweepIdleSessions(ctx, clock.Now())
+	require.NoError(t, err)
+	require.Equal(t, 1, expired)
+
+	_, err = service.SubmitNodeData(ctx, session.ID, map[string]interface{}{"business_type": "llp"})
+	assert.True(t, errors.Is(err, ErrSessionExpired), "expected ErrSessionExpired, got %v", err)
+
+	reactivated, err := service.ReactivateSession(ctx, session.ID)
+	require.NoError(t, err)
+	assert.Equal(t, SessionStatusActive, reactivated.Status)
+	assert.Equal(t, 1, reactivated.RetryCount)
+
+	step := lastHistoryStep(t, service, session.ID)
+	assert.Equal(t, actionReactivated, step.Action)
+	assert.Equal(t, "expired", step.Data["previous_status"])
+	assert.True(t, clock.Now().Equal(step.Timestamp), "step at %s, clock at %s", step.Timestamp, clock.Now())
+
+	events, err := service.GetSessionEvents(ctx, session.ID)
+	require.NoError(t, err)
+	assert.Equal(t, SessionEventReactivated, events[len(events)-1].Type)
+
+	// The reactivated session can be used again
+	_, err = service.SubmitNodeData(ctx, session.ID, map[string]interface{}{"business_type": "llp"})
+	require.NoError(t, err)
+
+	// Once expired again, the one retry has been used up
+	clock.Advance(25 * time.Hour)
+	_, expired, err = service.SweepIdleSessions(ctx, clock.Now())
+	require.NoError(t, err)
+	require.Equal(t, 1, expired)
+
+	_, err = service.ReactivateSession(ctx, session.ID)
+	assert.True(t, errors.Is(err, ErrRetriesExhausted), "expected ErrRetriesExhausted, got %v", err)
+
+	_, err = service.ReactivateSession(ctx, "missing")
+	assert.Error(t, err)
+}
@@ -0,0 +1,400 @@
+package onboarding
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// MigrationPlan declares how a session's nodes and data map onto a target graph revision.
+// Field operations are applied in order: splits, renames, drops, then defaults.
+type MigrationPlan struct {
+	TargetRevision int                    `json:"target_revision"`         // 0 migrates to the default revision
+	NodeMap        map[string]string      `json:"node_map,omitempty"`      // old node ID -> new node ID, "" drops the node
+	FieldRenames   map[string]string      `json:"field_renames,omitempty"` // old field ID -> new field ID
+	FieldSplits    []FieldSplit           `json:"field_splits,omitempty"`
+	DropFields     []string               `json:"drop_fields,omitempty"`
+	Defaults       map[string]interface{} `json:"defaults,omitempty"` // values for fields the session has not filled
+}
+
+// FieldSplit splits a string field into several fields
+type FieldSplit struct {
+	Field     string   `json:"field"`
+	Into      []string `json:"into"`
+	Separator string   `json:"separator,omitempty"` // defaults to a single space
+}
+
+// MigrationChange describes one change made to a session by a migration
+type MigrationChange struct {
+	Action string `json:"action"` // rename_field, split_field, drop_field, default_field, map_node, drop_node
+	From   string `json:"from,omitempty"`
+	To     string `json:"to,omitempty"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// MigrationReport is the outcome of migrating a single session
+type MigrationReport struct {
+	SessionID     string                 `json:"session_id"`
+	GraphID       string                 `json:"graph_id"`
+	FromRevision  int                    `json:"from_revision"`
+	ToRevision    int                    `json:"to_revision"`
+	FromNodeID    string                 `json:"from_node_id"`
+	ToNodeID      string                 `json:"to_node_id"`
+	DryRun        bool                   `json:"dry_run"`
+	Applied       bool                   `json:"applied"`
+	Skipped       bool                   `json:"skipped,omitempty"` // Left out of a bulk migration; Warnings says why
+	Changes       []MigrationChange      `json:"changes"`
+	PathComplete  bool                   `json:"path_complete"`
+	MissingNodes  []string               `json:"missing_nodes,omitempty"`
+	DynamicStatus map[string]interface{} `json:"dynamic_status,omitempty"`
+	Warnings      []string               `json:"warnings,omitempty"`
+	Errors        []string               `json:"errors,omitempty"`
+}
+
+// BulkMigrationReport is the outcome of migrating every active and paused session of a graph
+type BulkMigrationReport struct {
+	GraphID    string             `json:"graph_id"`
+	ToRevision int                `json:"to_revision"`
+	DryRun     bool               `json:"dry_run"`
+	Migrated   int                `json:"migrated"`
+	Failed     int                `json:"failed"`
+	Skipped    int                `json:"skipped"`
+	Sessions   []*MigrationReport `json:"sessions"`
+	Error      string             `json:"error,omitempty"` // Why the run stopped early; the sessions above were still processed
+}
+
+// MigrateSession maps a session onto a target graph revision.
+// With dryRun set the report is produced but the session is not saved.
+func (s *Service) MigrateSession(ctx context.Context, sessionID string, plan MigrationPlan, dryRun bool) (*MigrationReport, error) {
+	session, err := s.storage.GetSession(ctx, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get session: %w", err)
+	}
+
+	target, err := s.migrationTarget(ctx, session.GraphID, plan.TargetRevision)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.migrateSession(ctx, session, target, plan, dryRun)
+}
+
+// MigrateGraphSessions migrates every active session of a graph that is not on the target revision.
+// A session that fails to migrate is reported and the run carries on. Paused sessions are
+// reported as skipped: migrating one changes its revision, which invalidates its resume token.
+// If listing the sessions fails part way, the report so far is returned with the error.
+func (s *Service) MigrateGraphSessions(ctx context.Context, graphID string, plan MigrationPlan, dryRun bool) (*BulkMigrationReport, error) {
+	target, err := s.migrationTarget(ctx, graphID, plan.TargetRevision)
+	if err != nil {
+		return nil, err
+	}
+
+	bulk := &BulkMigrationReport{
+		GraphID:    graphID,
+		ToRevision: target.Revision,
+		DryRun:     dryRun,
+		Sessions:   make([]*MigrationReport, 0),
+	}
+
+	query := SessionQuery{
+		GraphID:   graphID,
+		Statuses:  []SessionStatus{SessionStatusActive, SessionStatusPaused},
+		Ascending: true,
+		Limit:     MaxSessionPageSize,
+	}
+	err = s.forEachSession(ctx, query, func(session *Session) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if session.GraphRevision == target.Revision {
+			return nil
+		}
+
+		if session.Status == SessionStatusPaused {
+			report := newMigrationReport(session, target, dryRun)
+			report.Skipped = true
+			report.Warnings = append(report.Warnings, "session is paused; migrating it would invalidate its resume token, so migrate it individually or once it is resumed")
+			bulk.Skipped++
+			bulk.Sessions = append(bulk.Sessions, report)
+			return nil
+		}
+
+		// A failed save leaves the session half migrated, so its failure report is made beforehand
+		failed := newMigrationReport(session, target, dryRun)
+		report, err := s.migrateSession(ctx, session, target, plan, dryRun)
+		if err != nil {
+			s.logger.WithError(err).WithField("session_id", session.ID).Warn("Failed to migrate session")
+			report = failed
+			report.Errors = append(report.Errors, err.Error())
+		}
+
+		if len(report.Errors) > 0 {
+			bulk.Failed++
+		} else {
+			bulk.Migrated++
+		}
+		bulk.Sessions = append(bulk.Sessions, report)
+		return nil
+	})
+	if err != nil {
+		bulk.Error = err.Error()
+		return bulk, fmt.Errorf("failed to list sessions: %w", err)
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"graph_id": graphID,
+		"revision": target.Revision,
+		"dry_run":  dryRun,
+		"migrated": bulk.Migrated,
+		"failed":   bulk.Failed,
+		"skipped":  bulk.Skipped,
+	}).Info("Bulk session migration finished")
+
+	return bulk, nil
+}
+
+// migrationTarget resolves the graph revision sessions are migrated to
+func (s *Service) migrationTarget(ctx context.Context, graphID string, revision int) (*Graph, error) {
+	if revision == 0 {
+		graph, err := s.storage.GetGraph(ctx, graphID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get graph: %w", err)
+		}
+		if graph.Revision == 0 {
+			return nil, fmt.Errorf("graph %s has no published revision", graphID)
+		}
+		revision = graph.Revision
+	}
+
+	target, err := s.storage.GetGraphVersion(ctx, graphID, revision)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get graph version: %w", err)
+	}
+	return target, nil
+}
+
+// newMigrationReport returns an empty report for migrating a session to the target revision
+func newMigrationReport(session *Session, target *Graph, dryRun bool) *MigrationReport {
+	return &MigrationReport{
+		SessionID:    session.ID,
+		GraphID:      session.GraphID,
+		FromRevision: session.GraphRevision,
+		ToRevision:   target.Revision,
+		FromNodeID:   session.CurrentNodeID,
+		DryRun:       dryRun,
+		Changes:      make([]MigrationChange, 0),
+	}
+}
+
+// migrateSession builds the migrated session, checks it against the target graph and saves it unless dryRun is set
+func (s *Service) migrateSession(ctx context.Context, session *Session, target *Graph, plan MigrationPlan, dryRun bool) (*MigrationReport, error) {
+	report := newMigrationReport(session, target, dryRun)
+
+	if session.GraphRevision == target.Revision {
+		report.Errors = append(report.Errors, fmt.Sprintf("session is already on revision %d", target.Revision))
+		return report, nil
+	}
+
+	// Map session data and history
+	data := migrateFields(session.Data, plan, report)
+	defaultFields := make([]string, 0, len(plan.Defaults))
+	for field := range plan.Defaults {
+		defaultFields = append(defaultFields, field)
+	}
+	sort.Strings(defaultFields)
+
+	for _, field := range defaultFields {
+		if current, exists := data[field]; !exists || current == nil || current == "" {
+			data[field] = plan.Defaults[field]
+			report.Changes = append(report.Changes, MigrationChange{Action: "default_field", To: field, Detail: fmt.Sprintf("%v", plan.Defaults[field])})
+		}
+	}
+
+	mappedNodes := make(map[string]bool)
+	mapNode := func(nodeID string) string {
+		newID, mapped := plan.NodeMap[nodeID]
+		if !mapped {
+			newID = nodeID
+		}
+		if _, exists := target.Nodes[newID]; !exists {
+			newID = ""
+		}
+		if !mappedNodes[nodeID] {
+			mappedNodes[nodeID] = true
+			if newID == "" {
+				report.Changes = append(report.Changes, MigrationChange{Action: "drop_node", From: nodeID})
+			} else if newID != nodeID {
+				report.Changes = append(report.Changes, MigrationChange{Action: "map_node", From: nodeID, To: newID})
+			}
+		}
+		return newID
+	}
+
+	report.ToNodeID = mapNode(session.CurrentNodeID)
+	if report.ToNodeID == "" {
+		report.Errors = append(report.Errors, fmt.Sprintf("current node %s has no counterpart in revision %d", session.CurrentNodeID, target.Revision))
+	}
+
+	history := make([]SessionStep, 0, len(session.History))
+	for _, step := range session.History {
+		nodeID := mapNode(step.NodeID)
+		if nodeID == "" {
+			continue
+		}
+		step.NodeID = nodeID
+		step.Data = migrateFields(step.Data, plan, nil)
+		history = append(history, step)
+	}
+
+	// Warn about data the target graph no longer collects
+	targetFields := make(map[string]bool)
+	for _, node := range target.Nodes {
+		for _, field := range node.Fields {
+			targetFields[field.ID] = true
+		}
+	}
+	unknown := make([]string, 0)
+	for field := range data {
+		if !targetFields[field] {
+			unknown = append(unknown, field)
+		}
+	}
+	sort.Strings(unknown)
+	for _, field := range unknown {
+		report.Warnings = append(report.Warnings, fmt.Sprintf("field %s is not defined in revision %d", field, target.Revision))
+	}
+
+	// Re-run completeness and dynamic evaluation against the target revision
+	if report.ToNodeID != "" {
+		report.PathComplete, report.MissingNodes = s.engine.ValidatePathCompleteness(ctx, target, report.ToNodeID, data, history)
+	}
+
+	businessType := sessionBusinessType(data)
+	persistenceManager := NewDynamicPersistenceManager(s.logger)
+	dynamicGraph := s.evaluateDynamicGraph(target, businessType, data, history)
+	report.DynamicStatus = dynamicGraph.GetCompletionStatus()
+
+	if session.Status == SessionStatusCompleted && !report.PathComplete {
+		report.Warnings = append(report.Warnings, "completed session no longer satisfies the required path")
+	}
+
+	if dryRun || len(report.Errors) > 0 {
+		return report, nil
+	}
+
+	session.GraphRevision = target.Revision
+	session.CurrentNodeID = report.ToNodeID
+	session.Data = data
+	session.History = history
+	if session.DynamicState != nil {
+		// Node IDs of the old revision are discarded rather than restored
+		session.DynamicState = nil
+		persistenceManager.SaveDynamicState(session, dynamicGraph, businessType)
+	}
+	if session.AdvancedState != nil {
+		pruneAdvancedState(session.AdvancedState, target)
+	}
+
+	// Migration rewrites data and history, so the event records the result rather than the plan
+	migrated := newSessionEvent(SessionEventMigrated, session, nil, map[string]interface{}{
+		"from_revision": report.FromRevision,
+		"to_revision":   report.ToRevision,
+		"data":          session.Data,
+		"history":       session.History,
+	})
+	if err := s.saveSession(ctx, session, migrated); err != nil {
+		return nil, fmt.Errorf("failed to save session: %w", err)
+	}
+	report.Applied = true
+
+	s.logger.WithFields(logrus.Fields{
+		"session_id":    session.ID,
+		"graph_id":      session.GraphID,
+		"from_revision": report.FromRevision,
+		"to_revision":   report.ToRevision,
+		"changes":       len(report.Changes),
+	}).Info("Migrated session to graph revision")
+
+	return report, nil
+}
+
+// migrateFields applies the plan's splits, renames and drops to a copy of data.
+// Changes are recorded on report when it is not nil.
+func migrateFields(data map[string]interface{}, plan MigrationPlan, report *MigrationReport) map[string]interface{} {
+	record := func(change MigrationChange) {
+		if report != nil {
+			report.Changes = append(report.Changes, change)
+		}
+	}
+	warn := func(format string, args ...interface{}) {
+		if report != nil {
+			report.Warnings = append(report.Warnings, fmt.Sprintf(format, args...))
+		}
+	}
+
+	result := make(map[string]interface{}, len(data))
+	for k, v := range data {
+		result[k] = v
+	}
+
+	for _, split := range plan.FieldSplits {
+		value, exists := result[split.Field]
+		if !exists || len(split.Into) == 0 {
+			continue
+		}
+		text, ok := value.(string)
+		if !ok {
+			warn("field %s is not a string and was not split", split.Field)
+			continue
+		}
+
+		separator := split.Separator
+		if separator == "" {
+			separator = " "
+		}
+		parts := strings.SplitN(text, separator, len(split.Into))
+
+		delete(result, split.Field)
+		for i, field := range split.Into {
+			if i < len(parts) {
+				result[field] = strings.TrimSpace(parts[i])
+			} else {
+				result[field] = ""
+			}
+		}
+		record(MigrationChange{Action: "split_field", From: split.Field, To: strings.Join(split.Into, ",")})
+	}
+
+	oldFields := make([]string, 0, len(plan.FieldRenames))
+	for oldField := range plan.FieldRenames {
+		oldFields = append(oldFields, oldField)
+	}
+	sort.Strings(oldFields)
+
+	for _, oldField := range oldFields {
+		newField := plan.FieldRenames[oldField]
+		value, exists := result[oldField]
+		if !exists {
+			continue
+		}
+		if _, taken := result[newField]; taken {
+			warn("field %s was not renamed because %s already has a value", oldField, newField)
+			continue
+		}
+		delete(result, oldField)
+		result[newField] = value
+		record(MigrationChange{Action: "rename_field", From: oldField, To: newField})
+	}
+
+	for _, field := range plan.DropFields {
+		if _, exists := result[field]; exists {
+			delete(result, field)
+			record(MigrationChange{Action: "drop_field", From: field})
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,451 @@
+package onboarding
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"onboarding-system/internal/config"
+	"onboarding-system/internal/storage"
+	"onboarding-system/internal/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMigrateFields(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     map[string]interface{}
+		plan     MigrationPlan
+		expected map[string]interface{}
+		changes  []MigrationChange
+		warnings int
+	}{
+		{
+			name:     "split on space",
+			data:     map[string]interface{}{"full_name": "Asha Rao"},
+			plan:     MigrationPlan{FieldSplits: []FieldSplit{{Field: "full_name", Into: []string{"first_name", "last_name"}}}},
+			expected: map[string]interface{}{"first_name": "Asha", "last_name": "Rao"},
+			changes:  []MigrationChange{{Action: "split_field", From: "full_name", To: "first_name,last_name"}},
+		},
+		{
+			name: "split keeps the remainder in the last field and blanks missing parts",
+			data: map[string]interface{}{"address": "1 Main Road, Pune, Maharashtra", "phone": "98200"},
+			plan: MigrationPlan{FieldSplits: []FieldSplit{
+				{Field: "address", Into: []string{"line1", "rest"}, Separator: ","},
+				{Field: "phone", Into: []string{"code", "number"}, Separator: "-"},
+			}},
+			expected: map[string]interface{}{"line1": "1 Main Road", "rest": "Pune, Maharashtra", "code": "98200", "number": ""},
+			changes: []MigrationChange{
+				{Action: "split_field", From: "address", To: "line1,rest"},
+				{Action: "split_field", From: "phone", To: "code,number"},
+			},
+		},
+		{
+			name:     "split skips values that are not strings",
+			data:     map[string]interface{}{"full_name": 42},
+			plan:     MigrationPlan{FieldSplits: []FieldSplit{{Field: "full_name", Into: []string{"first_name", "last_name"}}}},
+			expected: map[string]interface{}{"full_name": 42},
+			changes:  []MigrationChange{},
+			warnings: 1,
+		},
+		{
+			name:     "rename",
+			data:     map[string]interface{}{"pan_number": "ABCDE1234F", "gst": "27ABCDE1234F1Z5"},
+			plan:     MigrationPlan{FieldRenames: map[string]string{"pan_number": "pan", "gst": "gst_number"}},
+			expected: map[string]interface{}{"pan": "ABCDE1234F", "gst_number": "27ABCDE1234F1Z5"},
+			changes: []MigrationChange{
+				{Action: "rename_field", From: "gst", To: "gst_number"},
+				{Action: "rename_field", From: "pan_number", To: "pan"},
+			},
+		},
+		{
+			name:     "rename does not overwrite a filled field",
+			data:     map[string]interface{}{"pan_number": "ABCDE1234F", "pan": "FGHIJ5678K"},
+			plan:     MigrationPlan{FieldRenames: map[string]string{"pan_number": "pan"}},
+			expected: map[string]interface{}{"pan_number": "ABCDE1234F", "pan": "FGHIJ5678K"},
+			changes:  []MigrationChange{},
+			warnings: 1,
+		},
+		{
+			name:     "drop",
+			data:     map[string]interface{}{"website_url": "https://acme.example", "pan": "ABCDE1234F"},
+			plan:     MigrationPlan{DropFields: []string{"website_url", "fax"}},
+			expected: map[string]interface{}{"pan": "ABCDE1234F"},
+			changes:  []MigrationChange{{Action: "drop_field", From: "website_url"}},
+		},
+		{
+			name: "splits run before renames and drops",
+			data: map[string]interface{}{"full_name": "Asha Rao"},
+			plan: MigrationPlan{
+				FieldSplits:  []FieldSplit{{Field: "full_name", Into: []string{"first_name", "last_name"}}},
+				FieldRenames: map[string]string{"first_name": "given_name"},
+				DropFields:   []string{"last_name"},
+			},
+			expected: map[string]interface{}{"given_name": "Asha"},
+			changes: []MigrationChange{
+				{Action: "split_field", From: "full_name", To: "first_name,last_name"},
+				{Action: "rename_field", From: "first_name", To: "given_name"},
+				{Action: "drop_field", From: "last_name"},
+			},
+		},
+		{
+			// Defaults need the target graph, so migrateSession applies them after migrateFields
+			name:     "defaults are left to the session migration",
+			data:     map[string]interface{}{},
+			plan:     MigrationPlan{Defaults: map[string]interface{}{"city": "Pune"}},
+			expected: map[string]interface{}{},
+			changes:  []MigrationChange{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			original := make(map[string]interface{}, len(tt.data))
+			for k, v := range tt.data {
+				original[k] = v
+			}
+
+			report := &MigrationReport{Changes: make([]MigrationChange, 0)}
+			result := migrateFields(tt.data, tt.plan, report)
+
+			assert.Equal(t, tt.expected, result)
+			assert.Equal(t, tt.changes, report.Changes)
+			assert.Len(t, report.Warnings, tt.warnings)
+			assert.Equal(t, original, tt.data, "the input must not be changed")
+
+			// Without a report the same result is produced
+			assert.Equal(t, tt.expected, migrateFields(tt.data, tt.plan, nil))
+		})
+	}
+}
+
+// newMigrationTargetGraph is revision 2 of the test graph: details became identity, pan_number
+// became pan, the full name is split and the website is no longer collected
+func newMigrationTargetGraph(graphID string) *types.Graph {
+	graph := newTestGraph()
+	graph.ID = graphID
+
+	details := graph.Nodes["details"]
+	delete(graph.Nodes, "details")
+	details.ID = "identity"
+	details.Fields = []types.Field{
+		{ID: "pan", Name: "pan", Type: types.FieldTypeText, Required: true},
+		{ID: "first_name", Name: "first_name", Type: types.FieldTypeText},
+		{ID: "last_name", Name: "last_name", Type: types.FieldTypeText},
+		{ID: "city", Name: "city", Type: types.FieldTypeText},
+	}
+	details.Validation = types.ValidationRules{RequiredFields: []string{"pan"}}
+	graph.Nodes["identity"] = details
+
+	graph.Edges["e1"].ToNodeID = "identity"
+	graph.Edges["e2"].FromNodeID = "identity"
+	graph.RuleGroups[0].RequiredNodes = []string{"start", "identity"}
+	graph.RuleGroups[0].RequiredFields = map[string][]string{
+		"start":    {"business_type"},
+		"identity": {"pan"},
+	}
+	return graph
+}
+
+// migrationPlan maps sessions on the test graph onto newMigrationTargetGraph
+var migrationPlan = MigrationPlan{
+	TargetRevision: 2,
+	NodeMap:        map[string]string{"details": "identity"},
+	FieldRenames:   map[string]string{"pan_number": "pan"},
+	FieldSplits:    []FieldSplit{{Field: "full_name", Into: []string{"first_name", "last_name"}}},
+	DropFields:     []string{"website_url"},
+	Defaults:       map[string]interface{}{"city": "Pune", "last_name": "Unknown"},
+}
+
+// startMigrationSession starts a session on revision 1 that has reached the details node
+func startMigrationSession(t *testing.T, service *Service, graphID string) *Session {
+	t.Helper()
+	ctx := context.Background()
+
+	session, err := service.StartSession(ctx, "user-1", graphID)
+	require.NoError(t, err)
+	_, err = service.SubmitNodeData(ctx, session.ID, map[string]interface{}{"business_type": "llp"})
+	require.NoError(t, err)
+
+	session, err = service.GetSession(ctx, session.ID)
+	require.NoError(t, err)
+	require.Equal(t, "details", session.CurrentNodeID)
+
+	session.Data["pan_number"] = "ABCDE1234F"
+	session.Data["full_name"] = "Asha Rao"
+	session.Data["website_url"] = "https://acme.example"
+	require.NoError(t, service.UpdateSession(ctx, session))
+	return session
+}
+
+// publishMigrationTarget publishes revision 2 of the test graph
+func publishMigrationTarget(t *testing.T, service *Service, graphID string) {
+	t.Helper()
+	require.NoError(t, service.publishGraph(context.Background(), newMigrationTargetGraph(graphID)))
+}
+
+func TestMigrateSessionDryRunAndApply(t *testing.T) {
+	service, _ := newTestService(t)
+	ctx := context.Background()
+	graph := createTestGraph(t, service)
+	session := startMigrationSession(t, service, graph.ID)
+	publishMigrationTarget(t, service, graph.ID)
+
+	expectedChanges := []MigrationChange{
+		{Action: "split_field", From: "full_name", To: "first_name,last_name"},
+		{Action: "rename_field", From: "pan_number", To: "pan"},
+		{Action: "drop_field", From: "website_url"},
+		{Action: "default_field", To: "city", Detail: "Pune"},
+		{Action: "map_node", From: "details", To: "identity"},
+	}
+
+	report, err := service.MigrateSession(ctx, session.ID, migrationPlan, true)
+	require.NoError(t, err)
+	assert.True(t, report.DryRun)
+	assert.False(t, report.Applied)
+	assert.Equal(t, 1, report.FromRevision)
+	assert.Equal(t, 2, report.ToRevision)
+	assert.Equal(t, "identity", report.ToNodeID)
+	assert.Equal(t, expectedChanges, report.Changes)
+	assert.Empty(t, report.Errors)
+
+	// A dry run leaves the session as it was
+	stored, err := service.GetSession(ctx, session.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, stored.GraphRevision)
+	assert.Equal(t, session.Revision, stored.Revision)
+	assert.Equal(t, "Asha Rao", stored.Data["full_name"])
+
+	report, err = service.MigrateSession(ctx, session.ID, migrationPlan, false)
+	require.NoError(t, err)
+	assert.True(t, report.Applied)
+	assert.Equal(t, expectedChanges, report.Changes)
+
+	stored, err = service.GetSession(ctx, session.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 2, stored.GraphRevision)
+	assert.Equal(t, "identity", stored.CurrentNodeID)
+	assert.Equal(t, map[string]interface{}{
+		"business_type": "llp",
+		"pan":           "ABCDE1234F",
+		"first_name":    "Asha",
+		"last_name":     "Rao", // Defaults only fill fields the session left empty
+		"city":          "Pune",
+	}, stored.Data)
+	for _, step := range stored.History {
+		assert.NotEqual(t, "details", step.NodeID, "history must use the new node IDs")
+	}
+
+	events, err := service.GetSessionEvents(ctx, session.ID)
+	require.NoError(t, err)
+	assert.Equal(t, SessionEventMigrated, events[len(events)-1].Type)
+	assertReplayMatchesStored(t, service, session.ID)
+
+	// A session already on the target revision is reported rather than migrated again
+	report, err = service.MigrateSession(ctx, session.ID, migrationPlan, false)
+	require.NoError(t, err)
+	assert.False(t, report.Applied)
+	assert.NotEmpty(t, report.Errors)
+}
+
+func TestMigrateSessionReportsUnmappedCurrentNode(t *testing.T) {
+	service, _ := newTestService(t)
+	ctx := context.Background()
+	graph := createTestGraph(t, service)
+	session := startMigrationSession(t, service, graph.ID)
+	publishMigrationTarget(t, service, graph.ID)
+
+	// Without a node map the details node has no counterpart in revision 2
+	plan := migrationPlan
+	plan.NodeMap = nil
+	report, err := service.MigrateSession(ctx, session.ID, plan, false)
+	require.NoError(t, err)
+	assert.False(t, report.Applied)
+	assert.Empty(t, report.ToNodeID)
+	assert.Contains(t, report.Changes, MigrationChange{Action: "drop_node", From: "details"})
+	assert.NotEmpty(t, report.Errors)
+
+	stored, err := service.GetSession(ctx, session.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, stored.GraphRevision)
+}
+
+// concurrentWriterStorage changes a session each time it is loaded, as if another request saved
+// it between the load and the save
+type concurrentWriterStorage struct {
+	storage.Storage
+}
+
+func (s *concurrentWriterStorage) GetSession(ctx context.Context, sessionID string) (*types.Session, error) {
+	session, err := s.Storage.GetSession(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	concurrent, err := s.Storage.GetSession(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	concurrent.Data["website_url"] = "https://changed.example"
+	if err := s.Storage.UpdateSession(ctx, concurrent); err != nil {
+		return nil, err
+	}
+
+	return session, nil
+}
+
+func TestMigrateSessionRevisionConflict(t *testing.T) {
+	service, store := newTestService(t)
+	ctx := context.Background()
+	graph := createTestGraph(t, service)
+	session := startMigrationSession(t, service, graph.ID)
+	publishMigrationTarget(t, service, graph.ID)
+
+	racing := newTestServiceOn(&concurrentWriterStorage{Storage: store}, &config.Config{})
+	_, err := racing.MigrateSession(ctx, session.ID, migrationPlan, false)
+	assert.True(t, errors.Is(err, ErrSessionConflict), "expected ErrSessionConflict, got %v", err)
+
+	// The concurrent change is kept and the session stays on its revision
+	stored, err := service.GetSession(ctx, session.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, stored.GraphRevision)
+	assert.Equal(t, "https://changed.example", stored.Data["website_url"])
+
+	// A dry run saves nothing, so it cannot conflict
+	report, err := racing.MigrateSession(ctx, session.ID, migrationPlan, true)
+	require.NoError(t, err)
+	assert.Empty(t, report.Errors)
+}
+
+func TestMigrateGraphSessions(t *testing.T) {
+	service, _ := newTestService(t)
+	ctx := context.Background()
+	graph := createTestGraph(t, service)
+
+	first := startMigrationSession(t, service, graph.ID)
+	second := startMigrationSession(t, service, graph.ID)
+
+	// A session on a node revision 2 cannot place fails
+	stranded := startMigrationSession(t, service, graph.ID)
+	stranded.CurrentNodeID = "legacy_review"
+	require.NoError(t, service.UpdateSession(ctx, stranded))
+
+	// Completed sessions are not migrated
+	completed := startMigrationSession(t, service, graph.ID)
+	completed.Status = SessionStatusCompleted
+	require.NoError(t, service.UpdateSession(ctx, completed))
+
+	publishMigrationTarget(t, service, graph.ID)
+
+	// Sessions started on revision 2 are already migrated
+	current, err := service.StartSession(ctx, "user-2", graph.ID)
+	require.NoError(t, err)
+	require.Equal(t, 2, current.GraphRevision)
+
+	bulk, err := service.MigrateGraphSessions(ctx, graph.ID, migrationPlan, true)
+	require.NoError(t, err)
+	assert.True(t, bulk.DryRun)
+	assert.Equal(t, 2, bulk.ToRevision)
+	assert.Equal(t, 2, bulk.Migrated)
+	assert.Equal(t, 1, bulk.Failed)
+	assert.Len(t, bulk.Sessions, 3)
+	for _, report := range bulk.Sessions {
+		assert.False(t, report.Applied)
+	}
+
+	bulk, err = service.MigrateGraphSessions(ctx, graph.ID, migrationPlan, false)
+	require.NoError(t, err)
+	assert.Equal(t, 2, bulk.Migrated)
+	assert.Equal(t, 1, bulk.Failed)
+
+	revisions := map[string]int{first.ID: 2, second.ID: 2, stranded.ID: 1, completed.ID: 1, current.ID: 2}
+	for sessionID, revision := range revisions {
+		stored, err := service.GetSession(ctx, sessionID)
+		require.NoError(t, err)
+		assert.Equal(t, revision, stored.GraphRevision, "session %s", sessionID)
+	}
+
+	// Only the stranded session is left to migrate
+	bulk, err = service.MigrateGraphSessions(ctx, graph.ID, migrationPlan, true)
+	require.NoError(t, err)
+	assert.Equal(t, 0, bulk.Migrated)
+	assert.Equal(t, 1, bulk.Failed)
+	require.Len(t, bulk.Sessions, 1)
+	assert.Equal(t, stranded.ID, bulk.Sessions[0].SessionID)
+}
+
+// failingSaveStorage fails to save one session, and fails to list sessions once failList is set
+type failingSaveStorage struct {
+	storage.Storage
+	sessionID string
+	failList  bool
+}
+
+func (s *failingSaveStorage) SaveSessionWithEvents(ctx context.Context, session *types.Session, events []*types.SessionEvent) error {
+	if session.ID == s.sessionID {
+		return errors.New("disk full")
+	}
+	return s.Storage.SaveSessionWithEvents(ctx, session, events)
+}
+
+func (s *failingSaveStorage) ListSessionsQuery(ctx context.Context, query SessionQuery) (*SessionPage, error) {
+	if s.failList {
+		return nil, errors.New("connection reset")
+	}
+	return s.Storage.ListSessionsQuery(ctx, query)
+}
+
+func TestMigrateGraphSessionsReportsFailuresAndSkips(t *testing.T) {
+	service, store := newTestService(t)
+	ctx := context.Background()
+	graph := createTestGraph(t, service)
+
+	first := startMigrationSession(t, service, graph.ID)
+	broken := startMigrationSession(t, service, graph.ID)
+	last := startMigrationSession(t, service, graph.ID)
+	paused := startMigrationSession(t, service, graph.ID)
+	_, err := service.PauseSession(ctx, paused.ID)
+	require.NoError(t, err)
+
+	publishMigrationTarget(t, service, graph.ID)
+
+	failing := &failingSaveStorage{Storage: store, sessionID: broken.ID}
+	bulk, err := newTestServiceOn(failing, &config.Config{}).MigrateGraphSessions(ctx, graph.ID, migrationPlan, false)
+	require.NoError(t, err)
+	assert.Equal(t, 2, bulk.Migrated)
+	assert.Equal(t, 1, bulk.Failed)
+	assert.Equal(t, 1, bulk.Skipped)
+	require.Len(t, bulk.Sessions, 4)
+
+	reports := make(map[string]*MigrationReport)
+	for _, report := range bulk.Sessions {
+		reports[report.SessionID] = report
+	}
+
+	// The failed save is reported against the revision the session stayed on
+	assert.False(t, reports[broken.ID].Applied)
+	assert.Equal(t, 1, reports[broken.ID].FromRevision)
+	require.Len(t, reports[broken.ID].Errors, 1)
+	assert.Contains(t, reports[broken.ID].Errors[0], "disk full")
+
+	assert.True(t, reports[paused.ID].Skipped)
+	assert.False(t, reports[paused.ID].Applied)
+	assert.NotEmpty(t, reports[paused.ID].Warnings)
+
+	revisions := map[string]int{first.ID: 2, broken.ID: 1, last.ID: 2, paused.ID: 1}
+	for sessionID, revision := range revisions {
+		stored, err := service.GetSession(ctx, sessionID)
+		require.NoError(t, err)
+		assert.Equal(t, revision, stored.GraphRevision, "session %s", sessionID)
+	}
+
+	// A run that cannot list sessions returns what it has with the error
+	failing.failList = true
+	bulk, err = newTestServiceOn(failing, &config.Config{}).MigrateGraphSessions(ctx, graph.ID, migrationPlan, false)
+	require.Error(t, err)
+	require.NotNil(t, bulk)
+	assert.Contains(t, bulk.Error, "connection reset")
+	assert.Empty(t, bulk.Sessions)
+}
@@ -0,0 +1,79 @@
+package onboarding
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ErrOutboxMessageNotDead is returned when requeueing a message that is not in the dead-letter queue
+var ErrOutboxMessageNotDead = errors.New("outbox message is not in the dead-letter queue")
+
+// ListOutboxMessages returns up to limit outbox messages with the given status, oldest first
+func (s *Service) ListOutboxMessages(ctx context.Context, status OutboxStatus, limit int) ([]*OutboxMessage, error) {
+	messages, err := s.storage.ListOutboxMessages(ctx, status, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list outbox messages: %w", err)
+	}
+	return messages, nil
+}
+
+// RequeueOutboxMessage moves a dead message back to pending so it is delivered again with a fresh
+// set of attempts. Endpoints that already accepted it are not sent it again.
+func (s *Service) RequeueOutboxMessage(ctx context.Context, id string) (*OutboxMessage, error) {
+	message, err := s.storage.GetOutboxMessage(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get outbox message: %w", err)
+	}
+
+	if message.Status != OutboxStatusDead {
+		return nil, ErrOutboxMessageNotDead
+	}
+
+	if err := s.requeue(ctx, message); err != nil {
+		return nil, err
+	}
+	return message, nil
+}
+
+// RequeueDeadLetters requeues every message in the dead-letter queue and returns how many it requeued
+func (s *Service) RequeueDeadLetters(ctx context.Context) (int, error) {
+	requeued := 0
+	for {
+		messages, err := s.storage.ListOutboxMessages(ctx, OutboxStatusDead, 100)
+		if err != nil {
+			return requeued, fmt.Errorf("failed to list outbox messages: %w", err)
+		}
+		if len(messages) == 0 {
+			return requeued, nil
+		}
+
+		for _, message := range messages {
+			if err := s.requeue(ctx, message); err != nil {
+				return requeued, err
+			}
+			requeued++
+		}
+	}
+}
+
+// requeue saves a message as pending and due now
+func (s *Service) requeue(ctx context.Context, message *OutboxMessage) error {
+	message.Status = OutboxStatusPending
+	message.Attempts = 0
+	message.NextAttemptAt = time.Now()
+
+	if err := s.storage.UpdateOutboxMessage(ctx, message); err != nil {
+		return fmt.Errorf("failed to update outbox message: %w", err)
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"message_id": message.ID,
+		"event":      message.EventType,
+		"session_id": message.SessionID,
+	}).Info("Requeued outbox message")
+	return nil
+}
@@ -0,0 +1,215 @@
+package onboarding
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"onboarding-system/internal/resumetoken"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ErrSessionNotActive is returned when pausing a session that is not active
+var ErrSessionNotActive = errors.New("session is not active")
+
+// ErrSessionNotPaused is returned when resuming a session that is not paused
+var ErrSessionNotPaused = errors.New("session is not paused")
+
+// ErrResumeTokenInvalid is returned for resume tokens that are forged, expired or already used
+var ErrResumeTokenInvalid = errors.New("resume token is invalid or has expired")
+
+// History action recorded when a paused session is resumed
+const actionResumed = "resumed"
+
+// PauseResult carries the token a paused session can be resumed with
+type PauseResult struct {
+	Session        *Session  `json:"session"`
+	ResumeToken    string    `json:"resume_token"`
+	TokenExpiresAt time.Time `json:"resume_token_expires_at"`
+}
+
+// ResumeResult describes where a resumed session picks up
+type ResumeResult struct {
+	Session        *Session               `json:"session"`
+	CurrentNode    *Node                  `json:"current_node,omitempty"`
+	RemainingSteps []string               `json:"remaining_steps"` // Required steps not completed yet
+	MandatoryNodes []string               `json:"mandatory_nodes"` // Nodes the dynamic rules make mandatory that are not completed
+	DynamicStatus  map[string]interface{} `json:"dynamic_status"`
+}
+
+// resumeSigner returns the signer for resume tokens, creating it on first use
+func (s *Service) resumeSigner() (*resumetoken.Signer, error) {
+	s.resumeOnce.Do(func() {
+		secret := []byte(s.config.Onboarding.ResumeSecret)
+		if len(secret) == 0 {
+			secret, s.resumeErr = resumetoken.RandomSecret()
+			if s.resumeErr != nil {
+				return
+			}
+			s.logger.Warn("ONBOARDING_RESUME_TOKEN_SECRET is not set; resume tokens will not survive a restart")
+		}
+		s.resumeTokens = resumetoken.NewSigner(secret, s.config.Onboarding.ResumeTokenTTL)
+	})
+	return s.resumeTokens, s.resumeErr
+}
+
+// PauseSession pauses an active session and issues a token that resumes it. Pausing a paused
+// session issues another token for it.
+func (s *Service) PauseSession(ctx context.Context, sessionID string) (*PauseResult, error) {
+	signer, err := s.resumeSigner()
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := s.GetSession(ctx, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get session: %w", err)
+	}
+
+	switch session.Status {
+	case SessionStatusActive:
+		if err := s.pauseSession(ctx, session); err != nil {
+			return nil, err
+		}
+	case SessionStatusPaused:
+	case SessionStatusExpired:
+		return nil, ErrSessionExpired
+	default:
+		return nil, ErrSessionNotActive
+	}
+
+	// The token is bound to the paused revision, so it stops working once the session changes
+	token, expiresAt, err := signer.Issue(session.ID, session.Revision, s.now())
+	if err != nil {
+		return nil, err
+	}
+
+	return &PauseResult{Session: session, ResumeToken: token, TokenExpiresAt: expiresAt}, nil
+}
+
+// pauseSession saves an active session as paused by its user
+func (s *Service) pauseSession(ctx context.Context, session *Session) error {
+	now := s.now()
+	data := map[string]interface{}{
+		"reason":         "user",
+		"last_active_at": now.UTC().Format(time.RFC3339Nano),
+	}
+
+	session.Status = SessionStatusPaused
+	session.UpdatedAt = now
+	session.History = append(session.History, SessionStep{
+		ID:        fmt.Sprintf("%s-%s-%d", session.ID, actionPaused, len(session.History)),
+		NodeID:    session.CurrentNodeID,
+		Data:      data,
+		Timestamp: now,
+		Action:    actionPaused,
+	})
+
+	if err := s.saveSession(ctx, session, newSessionEvent(SessionEventPaused, session, lastStep(session), data)); err != nil {
+		return fmt.Errorf("failed to save session: %w", err)
+	}
+	return nil
+}
+
+// ResumeSessionWithToken resumes the paused session a resume token was issued for
+func (s *Service) ResumeSessionWithToken(ctx context.Context, token string) (*ResumeResult, error) {
+	signer, err := s.resumeSigner()
+	if err != nil {
+		return nil, err
+	}
+
+	claims, err := signer.Verify(token, s.now())
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrResumeTokenInvalid, err)
+	}
+
+	session, err := s.GetSession(ctx, claims.SessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get session: %w", err)
+	}
+
+	if session.Revision != claims.Revision {
+		return nil, fmt.Errorf("%w: session has changed since it was paused", ErrResumeTokenInvalid)
+	}
+
+	return s.resumeSession(ctx, session)
+}
+
+// ResumeSession resumes a paused session
+func (s *Service) ResumeSession(ctx context.Context, sessionID string) (*ResumeResult, error) {
+	session, err := s.GetSession(ctx, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get session: %w", err)
+	}
+
+	return s.resumeSession(ctx, session)
+}
+
+// resumeSession reactivates a paused session and re-evaluates what it still has to complete
+func (s *Service) resumeSession(ctx context.Context, session *Session) (*ResumeResult, error) {
+	switch session.Status {
+	case SessionStatusPaused:
+	case SessionStatusExpired:
+		return nil, ErrSessionExpired
+	default:
+		return nil, ErrSessionNotPaused
+	}
+
+	graph, err := s.GetSessionGraph(ctx, session)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get graph: %w", err)
+	}
+
+	// Graph rules or session data may have changed dependent node statuses while paused
+	businessType := sessionBusinessType(session.Data)
+	dynamicGraph := s.evaluateDynamicGraph(graph, businessType, session.Data, session.History)
+	if session.DynamicState != nil {
+		persistenceManager := NewDynamicPersistenceManager(s.logger)
+		if err := persistenceManager.RestoreDynamicState(session, dynamicGraph); err != nil {
+			return nil, fmt.Errorf("failed to restore dynamic state: %w", err)
+		}
+		persistenceManager.SaveDynamicState(session, dynamicGraph, businessType)
+	}
+
+	now := s.now()
+	session.Status = SessionStatusActive
+	session.UpdatedAt = now
+	session.History = append(session.History, SessionStep{
+		ID:        fmt.Sprintf("%s-%s-%d", session.ID, actionResumed, len(session.History)),
+		NodeID:    session.CurrentNodeID,
+		Data:      map[string]interface{}{},
+		Timestamp: now,
+		Action:    actionResumed,
+	})
+
+	if err := s.saveSession(ctx, session, newSessionEvent(SessionEventResumed, session, lastStep(session), nil)); err != nil {
+		return nil, fmt.Errorf("failed to save session: %w", err)
+	}
+
+	_, remainingSteps := s.engine.ValidatePathCompleteness(ctx, graph, session.CurrentNodeID, session.Data, session.History)
+	result := &ResumeResult{
+		Session:        session,
+		CurrentNode:    graph.Nodes[session.CurrentNodeID],
+		RemainingSteps: remainingSteps,
+		MandatoryNodes: make([]string, 0),
+		DynamicStatus:  dynamicGraph.GetCompletionStatus(),
+	}
+	for nodeID, dynamicNode := range dynamicGraph.DynamicNodes {
+		if dynamicNode.Status == NodeStatusMandatory {
+			result.MandatoryNodes = append(result.MandatoryNodes, nodeID)
+		}
+	}
+	sort.Strings(result.MandatoryNodes)
+
+	s.logger.WithFields(logrus.Fields{
+		"session_id":      session.ID,
+		"current_node":    session.CurrentNodeID,
+		"remaining_steps": len(result.RemainingSteps),
+		"mandatory_nodes": len(result.MandatoryNodes),
+	}).Info("Session resumed")
+
+	return result, nil
+}
@@ -0,0 +1,227 @@
+package onboarding
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"onboarding-system/internal/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newPauseTestService returns a test service whose resume tokens live for tokenTTL
+func newPauseTestService(t *testing.T, tokenTTL time.Duration) *Service {
+	t.Helper()
+
+	service, _ := newTestServiceWithConfig(t, &config.Config{Onboarding: config.OnboardingConfig{
+		SessionTimeout: 24 * time.Hour,
+		ResumeSecret:   "pause-test-secret",
+		ResumeTokenTTL: tokenTTL,
+	}})
+	return service
+}
+
+func TestPauseAndResumeSession(t *testing.T) {
+	service := newPauseTestService(t, time.Hour)
+	ctx := context.Background()
+	graph := createTestGraph(t, service)
+
+	session, err := service.StartSession(ctx, "user-1", graph.ID)
+	require.NoError(t, err)
+
+	paused, err := service.PauseSession(ctx, session.ID)
+	require.NoError(t, err)
+	assert.Equal(t, SessionStatusPaused, paused.Session.Status)
+	assert.NotEmpty(t, paused.ResumeToken)
+	assert.True(t, paused.TokenExpiresAt.After(time.Now()))
+
+	// A paused session cannot be changed until it is resumed
+	_, err = service.SubmitNodeData(ctx, session.ID, map[string]interface{}{"business_type": "llp"})
+	assert.True(t, errors.Is(err, ErrSessionPaused), "expected ErrSessionPaused, got %v", err)
+
+	// Pausing again issues another token for the same revision
+	again, err := service.PauseSession(ctx, session.ID)
+	require.NoError(t, err)
+	assert.Equal(t, paused.Session.Revision, again.Session.Revision)
+
+	resumed, err := service.ResumeSessionWithToken(ctx, paused.ResumeToken)
+	require.NoError(t, err)
+	assert.Equal(t, SessionStatusActive, resumed.Session.Status)
+	assert.Equal(t, graph.StartNodeID, resumed.CurrentNode.ID)
+	assert.Equal(t, actionResumed, resumed.Session.History[len(resumed.Session.History)-1].Action)
+
+	// Either token is spent once the session has moved on from the paused revision
+	_, err = service.ResumeSessionWithToken(ctx, again.ResumeToken)
+	assert.True(t, errors.Is(err, ErrResumeTokenInvalid), "expected ErrResumeTokenInvalid, got %v", err)
+
+	_, err = service.ResumeSession(ctx, session.ID)
+	assert.True(t, errors.Is(err, ErrSessionNotPaused), "expected ErrSessionNotPaused, got %v", err)
+}
+
+func TestResumeSessionWithTokenRejectsStaleRevision(t *testing.T) {
+	service := newPauseTestService(t, time.Hour)
+	ctx := context.Background()
+	graph := createTestGraph(t, service)
+
+	session, err := service.StartSession(ctx, "user-1", graph.ID)
+	require.NoError(t, err)
+
+	first, err := service.PauseSession(ctx, session.ID)
+	require.NoError(t, err)
+	_, err = service.ResumeSession(ctx, session.ID)
+	require.NoError(t, err)
+
+	// The session is paused again, but at a later revision than the first token names
+	second, err := service.PauseSession(ctx, session.ID)
+	require.NoError(t, err)
+	require.NotEqual(t, first.Session.Revision, second.Session.Revision)
+
+	_, err = service.ResumeSessionWithToken(ctx, first.ResumeToken)
+	assert.True(t, errors.Is(err, ErrResumeTokenInvalid), "expected ErrResumeTokenInvalid, got %v", err)
+
+	stored, err := service.GetSession(ctx, session.ID)
+	require.NoError(t, err)
+	assert.Equal(t, SessionStatusPaused, stored.Status)
+
+	_, err = service.ResumeSessionWithToken(ctx, second.ResumeToken)
+	assert.NoError(t, err)
+}
+
+func TestResumeSessionWithTokenRejectsExpiredToken(t *testing.T) {
+	// Tokens that expire as soon as they are issued
+	service := newPauseTestService(t, 0)
+	ctx := context.Background()
+	graph := createTestGraph(t, service)
+
+	session, err := service.StartSession(ctx, "user-1", graph.ID)
+	require.NoError(t, err)
+
+	paused, err := service.PauseSession(ctx, session.ID)
+	require.NoError(t, err)
+
+	_, err = service.ResumeSessionWithToken(ctx, paused.ResumeToken)
+	assert.True(t, errors.Is(err, ErrResumeTokenInvalid), "expected ErrResumeTokenInvalid, got %v", err)
+
+	stored, err := service.GetSession(ctx, session.ID)
+	require.NoError(t, err)
+	assert.Equal(t, SessionStatusPaused, stored.Status)
+}
+
+func TestResumeSessionWithTokenRejectsTamperedToken(t *testing.T) {
+	service := newPauseTestService(t, time.Hour)
+	ctx := context.Background()
+	graph := createTestGraph(t, service)
+
+	session, err := service.StartSession(ctx, "user-1", graph.ID)
+	require.NoError(t, err)
+
+	paused, err := service.PauseSession(ctx, session.ID)
+	require.NoError(t, err)
+
+	// A token signed with another secret, for the same session and revision
+	other := newPauseTestService(t, time.Hour)
+	other.config.Onboarding.ResumeSecret = "another-secret"
+	signer, err := other.resumeSigner()
+	require.NoError(t, err)
+	forged, _, err := signer.Issue(session.ID, paused.Session.Revision, time.Now())
+	require.NoError(t, err)
+
+	token := []byte(paused.ResumeToken)
+	token[0] ^= 1
+	for name, tampered := range map[string]string{
+		"payload changed": string(token),
+		"signature cut":   paused.ResumeToken[:len(paused.ResumeToken)-4],
+		"other secret":    forged,
+		"empty":           "",
+	} {
+		_, err := service.ResumeSessionWithToken(ctx, tampered)
+		assert.True(t, errors.Is(err, ErrResumeTokenInvalid), "%s: expected ErrResumeTokenInvalid, got %v", name, err)
+	}
+
+	_, err = service.ResumeSessionWithToken(ctx, paused.ResumeToken)
+	assert.NoError(t, err)
+}
+
+func TestSweepKeepsUserPausedSessions(t *testing.T) {
+	service := newPauseTestService(t, 7*24*time.Hour)
+	ctx := context.Background()
+	graph := createTestGraph(t, service)
+
+	userPaused, err := service.StartSession(ctx, "user-1", graph.ID)
+	require.NoError(t, err)
+	paused, err := service.PauseSession(ctx, userPaused.ID)
+	require.NoError(t, err)
+
+	idle, err := service.StartSession(ctx, "user-2", graph.ID)
+	require.NoError(t, err)
+
+	// Two days on, past the session timeout but well within the token's lifetime
+	_, expired, err := service.SweepIdleSessions(ctx, time.Now().Add(48*time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, 1, expired)
+
+	stored, err := service.GetSession(ctx, idle.ID)
+	require.NoError(t, err)
+	assert.Equal(t, SessionStatusExpired, stored.Status)
+
+	stored, err = service.GetSession(ctx, userPaused.ID)
+	require.NoError(t, err)
+	assert.Equal(t, SessionStatusPaused, stored.Status)
+
+	resumed, err := service.ResumeSessionWithToken(ctx, paused.ResumeToken)
+	require.NoError(t, err)
+	assert.Equal(t, SessionStatusActive, resumed.Session.Status)
+}
+
+func TestSweepExpiresUserPausedSessionsOnceTokenAndTimeoutPass(t *testing.T) {
+	tests := []struct {
+		name     string
+		tokenTTL time.Duration
+		keptAt   time.Duration // Time after pausing at which the pause is still kept
+		expireAt time.Duration // Time after pausing at which it expires
+	}{
+		{"token outlives the timeout", 7 * 24 * time.Hour, 6 * 24 * time.Hour, 7*24*time.Hour + time.Minute},
+		{"timeout outlives the token", time.Hour, 23 * time.Hour, 24*time.Hour + time.Minute},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := newPauseTestService(t, tt.tokenTTL)
+			clock := &testClock{now: time.Now()}
+			service.now = clock.Now
+			ctx := context.Background()
+			graph := createTestGraph(t, service)
+
+			session, err := service.StartSession(ctx, "user-1", graph.ID)
+			require.NoError(t, err)
+			paused, err := service.PauseSession(ctx, session.ID)
+			require.NoError(t, err)
+			pausedAt := clock.Now()
+
+			_, expired, err := service.SweepIdleSessions(ctx, pausedAt.Add(tt.keptAt))
+			require.NoError(t, err)
+			assert.Equal(t, 0, expired)
+
+			clock.now = pausedAt.Add(tt.expireAt)
+			_, expired, err = service.SweepIdleSessions(ctx, clock.Now())
+			require.NoError(t, err)
+			assert.Equal(t, 1, expired)
+
+			stored, err := service.GetSession(ctx, session.ID)
+			require.NoError(t, err)
+			assert.Equal(t, SessionStatusExpired, stored.Status)
+			assert.Equal(t, "paused", lastHistoryStep(t, service, session.ID).Data["previous_status"])
+
+			_, err = service.ResumeSessionWithToken(ctx, paused.ResumeToken)
+			assert.True(t, errors.Is(err, ErrResumeTokenInvalid), "expected ErrResumeTokenInvalid, got %v", err)
+
+			// Expired sessions are not listed by later sweeps
+			_, expired, err = service.SweepIdleSessions(ctx, clock.Now().Add(24*time.Hour))
+			require.NoError(t, err)
+			assert.Equal(t, 0, expired)
+		})
+	}
+}
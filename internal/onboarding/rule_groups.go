@@ -0,0 +1,110 @@
+package onboarding
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ErrRuleGroupNotFound is returned when a rule group does not exist on a graph
+var ErrRuleGroupNotFound = errors.New("rule group not found")
+
+// ListRuleGroups returns the rule groups of a graph
+func (s *Service) ListRuleGroups(ctx context.Context, graphID string) ([]RuleGroup, error) {
+	graph, err := s.storage.GetGraph(ctx, graphID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get graph: %w", err)
+	}
+
+	if graph.RuleGroups == nil {
+		return []RuleGroup{}, nil
+	}
+	return graph.RuleGroups, nil
+}
+
+// GetRuleGroup returns a single rule group of a graph
+func (s *Service) GetRuleGroup(ctx context.Context, graphID, ruleGroupID string) (*RuleGroup, error) {
+	ruleGroups, err := s.ListRuleGroups(ctx, graphID)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range ruleGroups {
+		if ruleGroups[i].ID == ruleGroupID {
+			return &ruleGroups[i], nil
+		}
+	}
+	return nil, ErrRuleGroupNotFound
+}
+
+// CreateRuleGroup adds a rule group to a graph
+func (s *Service) CreateRuleGroup(ctx context.Context, graphID string, ruleGroup RuleGroup) error {
+	return s.modifyRuleGroups(ctx, graphID, func(ruleGroups []RuleGroup) ([]RuleGroup, error) {
+		for _, existing := range ruleGroups {
+			if existing.ID == ruleGroup.ID {
+				return nil, fmt.Errorf("%w: rule group %s already exists", ErrInvalidGraph, ruleGroup.ID)
+			}
+		}
+		return append(ruleGroups, ruleGroup), nil
+	})
+}
+
+// UpdateRuleGroup replaces a rule group on a graph
+func (s *Service) UpdateRuleGroup(ctx context.Context, graphID string, ruleGroup RuleGroup) error {
+	return s.modifyRuleGroups(ctx, graphID, func(ruleGroups []RuleGroup) ([]RuleGroup, error) {
+		for i := range ruleGroups {
+			if ruleGroups[i].ID == ruleGroup.ID {
+				ruleGroups[i] = ruleGroup
+				return ruleGroups, nil
+			}
+		}
+		return nil, ErrRuleGroupNotFound
+	})
+}
+
+// DeleteRuleGroup removes a rule group from a graph
+func (s *Service) DeleteRuleGroup(ctx context.Context, graphID, ruleGroupID string) error {
+	return s.modifyRuleGroups(ctx, graphID, func(ruleGroups []RuleGroup) ([]RuleGroup, error) {
+		for i := range ruleGroups {
+			if ruleGroups[i].ID == ruleGroupID {
+				return append(ruleGroups[:i], ruleGroups[i+1:]...), nil
+			}
+		}
+		return nil, ErrRuleGroupNotFound
+	})
+}
+
+// modifyRuleGroups applies a change to a copy of the graph's rule groups and publishes the result
+func (s *Service) modifyRuleGroups(ctx context.Context, graphID string, modify func([]RuleGroup) ([]RuleGroup, error)) error {
+	graph, err := s.storage.GetGraph(ctx, graphID)
+	if err != nil {
+		return fmt.Errorf("failed to get graph: %w", err)
+	}
+
+	ruleGroups := make([]RuleGroup, len(graph.RuleGroups))
+	copy(ruleGroups, graph.RuleGroups)
+
+	ruleGroups, err = modify(ruleGroups)
+	if err != nil {
+		return err
+	}
+
+	updated := *graph
+	updated.RuleGroups = ruleGroups
+	updated.UpdatedAt = time.Now()
+
+	if err := s.publishGraph(ctx, &updated); err != nil {
+		return err
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"graph_id":    graphID,
+		"rule_groups": len(ruleGroups),
+		"revision":    updated.Revision,
+	}).Info("Updated graph rule groups")
+
+	return nil
+}
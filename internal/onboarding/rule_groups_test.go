@@ -0,0 +1,102 @@
+package onboarding
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"onboarding-system/internal/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRuleGroupCRUD(t *testing.T) {
+	service, _ := newTestService(t)
+	ctx := context.Background()
+	graph := createTestGraph(t, service)
+
+	ruleGroups, err := service.ListRuleGroups(ctx, graph.ID)
+	require.NoError(t, err)
+	require.Len(t, ruleGroups, 1)
+
+	documents := types.RuleGroup{
+		ID:             "documents",
+		Name:           "Documents",
+		BusinessTypes:  []string{"llp"},
+		RequiredNodes:  []string{"details"},
+		RequiredFields: map[string][]string{"details": {"pan_number"}},
+	}
+	require.NoError(t, service.CreateRuleGroup(ctx, graph.ID, documents))
+
+	created, err := service.GetRuleGroup(ctx, graph.ID, "documents")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"llp"}, created.BusinessTypes)
+
+	// Every change publishes a new graph revision
+	stored, err := service.GetGraph(ctx, graph.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 2, stored.Revision)
+
+	err = service.CreateRuleGroup(ctx, graph.ID, documents)
+	assert.True(t, errors.Is(err, ErrInvalidGraph), "duplicate rule group: %v", err)
+
+	documents.Name = "Company Documents"
+	require.NoError(t, service.UpdateRuleGroup(ctx, graph.ID, documents))
+	updated, err := service.GetRuleGroup(ctx, graph.ID, "documents")
+	require.NoError(t, err)
+	assert.Equal(t, "Company Documents", updated.Name)
+
+	require.NoError(t, service.DeleteRuleGroup(ctx, graph.ID, "documents"))
+	_, err = service.GetRuleGroup(ctx, graph.ID, "documents")
+	assert.True(t, errors.Is(err, ErrRuleGroupNotFound))
+
+	assert.True(t, errors.Is(service.UpdateRuleGroup(ctx, graph.ID, documents), ErrRuleGroupNotFound))
+	assert.True(t, errors.Is(service.DeleteRuleGroup(ctx, graph.ID, "documents"), ErrRuleGroupNotFound))
+
+	ruleGroups, err = service.ListRuleGroups(ctx, graph.ID)
+	require.NoError(t, err)
+	assert.Len(t, ruleGroups, 1)
+}
+
+func TestRuleGroupValidation(t *testing.T) {
+	tests := []struct {
+		name      string
+		ruleGroup types.RuleGroup
+		code      string
+	}{
+		{"unknown node", types.RuleGroup{
+			ID: "bad", RequiredNodes: []string{"kyc"}, RequiredFields: map[string][]string{"kyc": {"pan_number"}},
+		}, "RULE_GROUP_NODE_NOT_FOUND"},
+		{"unknown field", types.RuleGroup{
+			ID: "bad", RequiredNodes: []string{"details"}, RequiredFields: map[string][]string{"details": {"gst_number"}},
+		}, "RULE_GROUP_FIELD_NOT_FOUND"},
+		{"unknown business type", types.RuleGroup{
+			ID: "bad", BusinessTypes: []string{"trust"}, RequiredNodes: []string{"details"},
+		}, "RULE_GROUP_BUSINESS_TYPE_UNKNOWN"},
+		{"missing id", types.RuleGroup{Name: "Unnamed"}, "RULE_GROUP_ID_MISSING"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, _ := newTestService(t)
+			ctx := context.Background()
+			graph := createTestGraph(t, service)
+
+			err := service.CreateRuleGroup(ctx, graph.ID, tt.ruleGroup)
+			var validationErr *GraphValidationError
+			require.True(t, errors.As(err, &validationErr), "expected a validation error, got %v", err)
+			codes := make([]string, 0, len(validationErr.Report.Diagnostics))
+			for _, diagnostic := range validationErr.Report.Diagnostics {
+				codes = append(codes, diagnostic.Code)
+			}
+			assert.Contains(t, codes, tt.code)
+
+			// A rejected rule group leaves the graph unchanged
+			stored, err := service.GetGraph(ctx, graph.ID)
+			require.NoError(t, err)
+			assert.Equal(t, 1, stored.Revision)
+			assert.Len(t, stored.RuleGroups, 1)
+		})
+	}
+}
@@ -0,0 +1,103 @@
+package onboarding
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"onboarding-system/internal/expr"
+	"onboarding-system/internal/graphlint"
+	"onboarding-system/internal/validators"
+)
+
+// ErrInvalidGraph is returned when a graph fails validation on save
+var ErrInvalidGraph = errors.New("invalid graph")
+
+// ErrValidationFailed is returned when submitted node data fails validation
+var ErrValidationFailed = errors.New("validation failed")
+
+// errRuleNotSatisfied is returned when a custom rule expression evaluates to false
+var errRuleNotSatisfied = errors.New("custom rule not satisfied")
+
+// ruleCache holds compiled rule expressions shared by all engines
+var ruleCache = expr.NewCache()
+
+// isRuleExpression reports whether a custom rule is an expression rather than a named rule
+func isRuleExpression(rule string) bool {
+	return strings.TrimSpace(rule) != "" && !expr.IsIdentifier(rule)
+}
+
+// evaluateRuleExpression evaluates a custom rule expression against data
+func evaluateRuleExpression(rule string, data map[string]interface{}) (bool, error) {
+	program, err := ruleCache.Compile(rule)
+	if err != nil {
+		return false, err
+	}
+	return program.EvalBool(data)
+}
+
+// customRuleError converts a failed custom rule into a validation error.
+// Registered rules supply their own message and may supply their own code.
+func customRuleError(fieldID, rule string, err error) ValidationError {
+	validationError := ValidationError{Field: fieldID, Code: "CUSTOM_RULE_FAILED"}
+	if fieldID != "" {
+		validationError.Code = "CUSTOM_FIELD_RULE_FAILED"
+	}
+
+	var ruleErr *validators.Error
+	switch {
+	case errors.As(err, &ruleErr):
+		validationError.Message = ruleErr.Message
+		if ruleErr.Code != "" {
+			validationError.Code = ruleErr.Code
+		}
+	case errors.Is(err, validators.ErrUnknownRule):
+		validationError.Message = fmt.Sprintf("Unknown custom validation rule: %s", rule)
+		validationError.Code = "CUSTOM_RULE_UNKNOWN"
+	case fieldID != "":
+		validationError.Message = fmt.Sprintf("Custom validation rule failed for field %s: %s", fieldID, rule)
+	default:
+		validationError.Message = fmt.Sprintf("Custom validation rule failed: %s", rule)
+	}
+
+	return validationError
+}
+
+// GraphValidationError carries the lint report of a graph that failed validation
+type GraphValidationError struct {
+	Report *graphlint.Report
+}
+
+func (e *GraphValidationError) Error() string {
+	return fmt.Sprintf("%v: %s", ErrInvalidGraph, e.Report.Error())
+}
+
+func (e *GraphValidationError) Unwrap() error {
+	return ErrInvalidGraph
+}
+
+// NodeValidationError carries the validation errors of node data that was rejected
+type NodeValidationError struct {
+	Errors []ValidationError
+}
+
+func (e *NodeValidationError) Error() string {
+	messages := make([]string, len(e.Errors))
+	for i, validationError := range e.Errors {
+		messages[i] = validationError.Message
+	}
+	return fmt.Sprintf("%v: %s", ErrValidationFailed, strings.Join(messages, "; "))
+}
+
+func (e *NodeValidationError) Unwrap() error {
+	return ErrValidationFailed
+}
+
+// validateGraph rejects graphs whose lint report contains errors
+func validateGraph(graph *Graph) error {
+	report := graphlint.Lint(graph)
+	if report.HasErrors() {
+		return &GraphValidationError{Report: report}
+	}
+	return nil
+}
@@ -2,22 +2,45 @@ package onboarding
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
+	"sync"
 	"time"
 
 	"onboarding-system/internal/config"
+	"onboarding-system/internal/graphlint"
+	"onboarding-system/internal/resumetoken"
 	"onboarding-system/internal/storage"
 
 	"github.com/sirupsen/logrus"
 )
 
+// ErrNodeNotFound is returned when navigating to a node the session's graph does not have
+var ErrNodeNotFound = errors.New("node not found")
+
+// IncompleteSessionError lists the required steps a session has yet to complete
+type IncompleteSessionError struct {
+	MissingNodes []string
+	Report       *CompletionReport // Per rule group breakdown of what is missing
+}
+
+func (e *IncompleteSessionError) Error() string {
+	return fmt.Sprintf("you must complete the following steps first: %v", e.MissingNodes)
+}
+
 // Service handles onboarding operations
 type Service struct {
-	storage storage.Storage
-	engine  *Engine
-	config  *config.Config
-	logger  *logrus.Logger
+	storage       storage.Storage
+	engine        *Engine
+	config        *config.Config
+	logger        *logrus.Logger
+	eventHandlers []EventHandler
+	eventsMutex   sync.RWMutex
+	resumeTokens  *resumetoken.Signer
+	resumeErr     error
+	resumeOnce    sync.Once
+	now           func() time.Time // Clock for session lifecycle changes, replaced in tests
 }
 
 // NewService creates a new onboarding service
@@ -30,11 +53,18 @@ func NewService(storage storage.Storage, config *config.Config) *Service {
 		engine:  NewEngine(logger),
 		config:  config,
 		logger:  logger,
+		now:     time.Now,
 	}
 }
 
 // StartSession starts a new onboarding session
 func (s *Service) StartSession(ctx context.Context, userID, graphID string) (*Session, error) {
+	return s.startSession(ctx, userID, graphID, nil)
+}
+
+// startSession starts a session at the graph's start node; prepare, if set, can change the new
+// session before it is first saved
+func (s *Service) startSession(ctx context.Context, userID, graphID string, prepare func(session *Session, graph *Graph) error) (*Session, error) {
 	// Get the graph
 	graph, err := s.storage.GetGraph(ctx, graphID)
 	if err != nil {
@@ -43,10 +73,21 @@ func (s *Service) StartSession(ctx context.Context, userID, graphID string) (*Se
 
 	// Create new session
 	session := NewSession(userID, graphID)
+	session.GraphRevision = graph.Revision
 	session.CurrentNodeID = graph.StartNodeID
+	if prepare != nil {
+		if err := prepare(session, graph); err != nil {
+			return nil, err
+		}
+	}
 
 	// Save session
-	if err := s.storage.SaveSession(ctx, session); err != nil {
+	startedData := map[string]interface{}{"graph_revision": graph.Revision}
+	if session.AdvancedState != nil {
+		startedData["entry_node_id"] = session.AdvancedState.EntryNodeID
+	}
+	started := newSessionEvent(SessionEventStarted, session, nil, startedData)
+	if err := s.saveSession(ctx, session, started); err != nil {
 		return nil, fmt.Errorf("failed to save session: %w", err)
 	}
 
@@ -54,6 +95,7 @@ func (s *Service) StartSession(ctx context.Context, userID, graphID string) (*Se
 		"session_id": session.ID,
 		"user_id":    userID,
 		"graph_id":   graphID,
+		"revision":   graph.Revision,
 	}).Info("Started new onboarding session")
 
 	return session, nil
@@ -66,7 +108,7 @@ func (s *Service) GetCurrentNode(ctx context.Context, sessionID string) (*Node,
 		return nil, fmt.Errorf("failed to get session: %w", err)
 	}
 
-	graph, err := s.storage.GetGraph(ctx, session.GraphID)
+	graph, err := s.GetSessionGraph(ctx, session)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get graph: %w", err)
 	}
@@ -81,12 +123,16 @@ func (s *Service) GetCurrentNode(ctx context.Context, sessionID string) (*Node,
 
 // SubmitNodeData submits data for the current node and moves to the next node
 func (s *Service) SubmitNodeData(ctx context.Context, sessionID string, data map[string]interface{}) (*NextStepResult, error) {
-	session, err := s.storage.GetSession(ctx, sessionID)
+	session, err := s.GetSession(ctx, sessionID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get session: %w", err)
 	}
 
-	graph, err := s.storage.GetGraph(ctx, session.GraphID)
+	if err := CheckSessionWritable(session); err != nil {
+		return nil, err
+	}
+
+	graph, err := s.GetSessionGraph(ctx, session)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get graph: %w", err)
 	}
@@ -113,7 +159,7 @@ func (s *Service) SubmitNodeData(ctx context.Context, sessionID string, data map
 			"node_id":    session.CurrentNodeID,
 			"errors":     validationResult.Errors,
 		}).Warn("Node validation failed")
-		return nil, fmt.Errorf("validation failed: %v", validationResult.Errors)
+		return nil, &NodeValidationError{Errors: validationResult.Errors}
 	}
 
 	// Only validate path completeness if we're trying to reach the end node
@@ -247,15 +293,23 @@ func (s *Service) SubmitNodeData(ctx context.Context, sessionID string, data map
 	session.UpdatedAt = time.Now()
 
 	// Save updated session
-	if err := s.storage.SaveSession(ctx, session); err != nil {
+	events := []*SessionEvent{newSessionEvent(SessionEventSubmitted, session, &step, map[string]interface{}{
+		"node_id": currentNode.ID,
+		"data":    data,
+	})}
+	if session.Status == SessionStatusCompleted {
+		events = append(events, newSessionEvent(SessionEventCompleted, session, nil, nil))
+	}
+	if err := s.saveSession(ctx, session, events...); err != nil {
 		return nil, fmt.Errorf("failed to save session: %w", err)
 	}
 
 	// Prepare result
 	result := &NextStepResult{
-		NextNodeID:     session.CurrentNodeID,
-		AvailablePaths: make([]string, 0),
-		CanGoBack:      s.engine.CanGoBack(ctx, graph, currentNode.ID),
+		NextNodeID:      session.CurrentNodeID,
+		AvailablePaths:  make([]string, 0),
+		CanGoBack:       s.engine.CanGoBack(ctx, graph, currentNode.ID),
+		SessionRevision: session.Revision,
 		Metadata: map[string]interface{}{
 			"validation_warnings": validationResult.Warnings,
 			"session_status":      session.Status,
@@ -284,12 +338,16 @@ func (s *Service) SubmitNodeData(ctx context.Context, sessionID string, data map
 
 // GoBack moves the session back to the previous node
 func (s *Service) GoBack(ctx context.Context, sessionID string) (*Node, error) {
-	session, err := s.storage.GetSession(ctx, sessionID)
+	session, err := s.GetSession(ctx, sessionID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get session: %w", err)
 	}
 
-	graph, err := s.storage.GetGraph(ctx, session.GraphID)
+	if err := CheckSessionWritable(session); err != nil {
+		return nil, err
+	}
+
+	graph, err := s.GetSessionGraph(ctx, session)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get graph: %w", err)
 	}
@@ -305,6 +363,7 @@ func (s *Service) GoBack(ctx context.Context, sessionID string) (*Node, error) {
 	previousNode := previousNodes[0]
 
 	// Update session
+	fromNodeID := session.CurrentNodeID
 	session.CurrentNodeID = previousNode.ID
 	session.UpdatedAt = time.Now()
 
@@ -319,7 +378,8 @@ func (s *Service) GoBack(ctx context.Context, sessionID string) (*Node, error) {
 	session.History = append(session.History, step)
 
 	// Save updated session
-	if err := s.storage.SaveSession(ctx, session); err != nil {
+	wentBack := newSessionEvent(SessionEventWentBack, session, &step, map[string]interface{}{"from_node_id": fromNodeID})
+	if err := s.saveSession(ctx, session, wentBack); err != nil {
 		return nil, fmt.Errorf("failed to save session: %w", err)
 	}
 
@@ -331,13 +391,108 @@ func (s *Service) GoBack(ctx context.Context, sessionID string) (*Node, error) {
 	return previousNode, nil
 }
 
-// GetSession returns a session by ID
+// NavigateToNode moves a session to any node of its graph and records the navigation
+func (s *Service) NavigateToNode(ctx context.Context, session *Session, nodeID string) (*Node, error) {
+	graph, err := s.GetSessionGraph(ctx, session)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get graph: %w", err)
+	}
+
+	node, exists := graph.Nodes[nodeID]
+	if !exists {
+		return nil, ErrNodeNotFound
+	}
+
+	if err := CheckSessionWritable(session); err != nil {
+		return nil, err
+	}
+
+	fromNodeID := session.CurrentNodeID
+	session.CurrentNodeID = nodeID
+	session.UpdatedAt = time.Now()
+
+	navigated := newSessionEvent(SessionEventNavigated, session, nil, map[string]interface{}{"from_node_id": fromNodeID})
+	if err := s.saveSession(ctx, session, navigated); err != nil {
+		return nil, fmt.Errorf("failed to save session: %w", err)
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"session_id": session.ID,
+		"from_node":  fromNodeID,
+		"node_id":    nodeID,
+	}).Info("Navigated to node")
+
+	return node, nil
+}
+
+// CompleteSession marks a session completed once every required step is done. It returns an
+// *IncompleteSessionError listing the missing steps otherwise.
+func (s *Service) CompleteSession(ctx context.Context, session *Session) error {
+	graph, err := s.GetSessionGraph(ctx, session)
+	if err != nil {
+		return fmt.Errorf("failed to get graph: %w", err)
+	}
+
+	if err := CheckSessionWritable(session); err != nil {
+		return err
+	}
+
+	report := s.engine.ExplainCompleteness(ctx, graph, session.CurrentNodeID, session.Data, session.History)
+	if !report.Complete {
+		report.SessionID = session.ID
+		return &IncompleteSessionError{MissingNodes: report.MissingNodes, Report: report}
+	}
+
+	now := time.Now()
+	session.Status = SessionStatusCompleted
+	session.CompletedAt = &now
+	session.CurrentNodeID = ""
+	session.UpdatedAt = now
+
+	if err := s.saveSession(ctx, session, newSessionEvent(SessionEventCompleted, session, nil, nil)); err != nil {
+		return fmt.Errorf("failed to save session: %w", err)
+	}
+
+	s.logger.WithField("session_id", session.ID).Info("Session completed successfully")
+	return nil
+}
+
+// GetCompletionReport explains whether a session can be completed and what each of its rule
+// groups still needs
+func (s *Service) GetCompletionReport(ctx context.Context, sessionID string) (*CompletionReport, error) {
+	session, err := s.storage.GetSession(ctx, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get session: %w", err)
+	}
+
+	graph, err := s.GetSessionGraph(ctx, session)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get graph: %w", err)
+	}
+
+	report := s.engine.ExplainCompleteness(ctx, graph, session.CurrentNodeID, session.Data, session.History)
+	report.SessionID = session.ID
+	return report, nil
+}
+
+// GetSession returns a session by ID, checking the revision expected by the context
 func (s *Service) GetSession(ctx context.Context, sessionID string) (*Session, error) {
-	return s.storage.GetSession(ctx, sessionID)
+	session, err := s.storage.GetSession(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkExpectedRevision(ctx, session); err != nil {
+		return nil, err
+	}
+
+	return session, nil
 }
 
+// UpdateSession saves a changed session; it fails with a *SessionConflictError if the session
+// was saved by someone else since it was loaded
 func (s *Service) UpdateSession(ctx context.Context, session *Session) error {
-	return s.storage.UpdateSession(ctx, session)
+	return s.saveSession(ctx, session, newSessionUpdatedEvent(session))
 }
 
 // GetSessionHistory returns the history of a session
@@ -350,14 +505,18 @@ func (s *Service) GetSessionHistory(ctx context.Context, sessionID string) ([]Se
 	return session.History, nil
 }
 
-// SaveSession saves a session to storage
+// SaveSession saves a session to storage, recording it as started if it was never saved before
 func (s *Service) SaveSession(ctx context.Context, session *Session) error {
-	return s.storage.SaveSession(ctx, session)
+	if session.Revision == 0 {
+		started := newSessionEvent(SessionEventStarted, session, nil, map[string]interface{}{"graph_revision": session.GraphRevision})
+		return s.saveSession(ctx, session, started, newSessionUpdatedEvent(session))
+	}
+	return s.saveSession(ctx, session, newSessionUpdatedEvent(session))
 }
 
 // RetrySession retries a failed session
 func (s *Service) RetrySession(ctx context.Context, sessionID string) error {
-	session, err := s.storage.GetSession(ctx, sessionID)
+	session, err := s.GetSession(ctx, sessionID)
 	if err != nil {
 		return fmt.Errorf("failed to get session: %w", err)
 	}
@@ -367,7 +526,7 @@ func (s *Service) RetrySession(ctx context.Context, sessionID string) error {
 	}
 
 	if session.RetryCount >= s.config.Onboarding.MaxRetries {
-		return fmt.Errorf("maximum retry count exceeded")
+		return ErrRetriesExhausted
 	}
 
 	// Reset session status
@@ -376,7 +535,8 @@ func (s *Service) RetrySession(ctx context.Context, sessionID string) error {
 	session.UpdatedAt = time.Now()
 
 	// Save updated session
-	if err := s.storage.SaveSession(ctx, session); err != nil {
+	retried := newSessionEvent(SessionEventRetried, session, nil, map[string]interface{}{"retry_count": session.RetryCount})
+	if err := s.saveSession(ctx, session, retried); err != nil {
 		return fmt.Errorf("failed to save session: %w", err)
 	}
 
@@ -388,10 +548,10 @@ func (s *Service) RetrySession(ctx context.Context, sessionID string) error {
 	return nil
 }
 
-// CreateGraph creates a new onboarding graph
+// CreateGraph publishes a graph as a new immutable revision
 func (s *Service) CreateGraph(ctx context.Context, graph *Graph) error {
-	if err := s.storage.SaveGraph(ctx, graph); err != nil {
-		return fmt.Errorf("failed to save graph: %w", err)
+	if err := s.publishGraph(ctx, graph); err != nil {
+		return err
 	}
 
 	s.logger.WithFields(logrus.Fields{
@@ -402,6 +562,11 @@ func (s *Service) CreateGraph(ctx context.Context, graph *Graph) error {
 	return nil
 }
 
+// ValidateGraph lints a graph without saving it
+func (s *Service) ValidateGraph(ctx context.Context, graph *Graph) *graphlint.Report {
+	return graphlint.Lint(graph)
+}
+
 // GetGraph returns a graph by ID
 func (s *Service) GetGraph(ctx context.Context, graphID string) (*Graph, error) {
 	return s.storage.GetGraph(ctx, graphID)
@@ -412,11 +577,6 @@ func (s *Service) ListGraphs(ctx context.Context) ([]*Graph, error) {
 	return s.storage.ListGraphs(ctx)
 }
 
-// ListAllSessions returns all sessions for admin dashboard
-func (s *Service) ListAllSessions(ctx context.Context) ([]*Session, error) {
-	return s.storage.ListAllSessions(ctx)
-}
-
 // ValidatePathCompleteness checks if all required nodes have been completed
 func (s *Service) ValidatePathCompleteness(ctx context.Context, graph *Graph, currentNodeID string, sessionData map[string]interface{}, sessionHistory []SessionStep) (bool, []string) {
 	return s.engine.ValidatePathCompleteness(ctx, graph, currentNodeID, sessionData, sessionHistory)
@@ -455,7 +615,7 @@ func (s *Service) GetEligibleNodes(ctx context.Context, sessionID string) ([]str
 		return nil, fmt.Errorf("failed to get session: %w", err)
 	}
 
-	graph, err := s.storage.GetGraph(ctx, session.GraphID)
+	graph, err := s.GetSessionGraph(ctx, session)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get graph: %w", err)
 	}
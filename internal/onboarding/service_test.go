@@ -0,0 +1,102 @@
+package onboarding
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"onboarding-system/internal/config"
+	"onboarding-system/internal/storage"
+	"onboarding-system/internal/types"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestService returns a service over in-memory storage with quiet logging
+func newTestService(t *testing.T) (*Service, storage.Storage) {
+	t.Helper()
+	return newTestServiceWithConfig(t, &config.Config{})
+}
+
+// newTestServiceWithConfig returns a test service using cfg
+func newTestServiceWithConfig(t *testing.T, cfg *config.Config) (*Service, storage.Storage) {
+	t.Helper()
+
+	store := storage.NewMemoryStorage(newTestLogger())
+	return newTestServiceOn(store, cfg), store
+}
+
+// newTestServiceOn returns a test service over store
+func newTestServiceOn(store storage.Storage, cfg *config.Config) *Service {
+	service := NewService(store, cfg)
+	service.logger = newTestLogger()
+	service.engine = NewEngine(service.logger)
+	return service
+}
+
+// newTestLogger returns a logger that only reports errors
+func newTestLogger() *logrus.Logger {
+	logger := logrus.New()
+	logger.SetLevel(logrus.ErrorLevel)
+	return logger
+}
+
+// newTestGraph builds start -> details -> end with a rule group requiring both nodes
+func newTestGraph() *types.Graph {
+	graph := types.NewGraph("Service Test", "")
+
+	start := &types.Node{ID: "start", Type: types.NodeTypeStart, Name: "Start", Fields: []types.Field{
+		{ID: "business_type", Name: "business_type", Type: types.FieldTypeSelect, Required: true, Options: []string{"individual", "llp"}},
+	}, Validation: types.ValidationRules{RequiredFields: []string{"business_type"}}}
+	details := &types.Node{ID: "details", Type: types.NodeTypeInput, Name: "Details", Fields: []types.Field{
+		{ID: "pan_number", Name: "pan_number", Type: types.FieldTypeText, Required: true},
+		{ID: "website_url", Name: "website_url", Type: types.FieldTypeText},
+	}, Validation: types.ValidationRules{RequiredFields: []string{"pan_number"}}}
+	end := &types.Node{ID: "end", Type: types.NodeTypeEnd, Name: "End"}
+
+	graph.Nodes = map[string]*types.Node{"start": start, "details": details, "end": end}
+	graph.Edges = map[string]*types.Edge{
+		"e1": {ID: "e1", FromNodeID: "start", ToNodeID: "details", Condition: types.EdgeCondition{Type: "always"}},
+		"e2": {ID: "e2", FromNodeID: "details", ToNodeID: "end", Condition: types.EdgeCondition{Type: "always"}},
+	}
+	graph.StartNodeID = "start"
+	graph.RuleGroups = []types.RuleGroup{{
+		ID:            "basic",
+		Name:          "Basic",
+		RequiredNodes: []string{"start", "details"},
+		RequiredFields: map[string][]string{
+			"start":   {"business_type"},
+			"details": {"pan_number"},
+		},
+	}}
+
+	return graph
+}
+
+// createTestGraph publishes the test graph and returns it
+func createTestGraph(t *testing.T, service *Service) *types.Graph {
+	t.Helper()
+
+	graph := newTestGraph()
+	require.NoError(t, service.CreateGraph(context.Background(), graph))
+	return graph
+}
+
+func TestSubmitNodeDataReturnsValidationErrors(t *testing.T) {
+	ctx := context.Background()
+	service, _ := newTestService(t)
+	graph := createTestGraph(t, service)
+
+	session, err := service.StartSession(ctx, "user-1", graph.ID)
+	require.NoError(t, err)
+
+	_, err = service.SubmitNodeData(ctx, session.ID, map[string]interface{}{})
+	require.ErrorIs(t, err, ErrValidationFailed)
+
+	var validationErr *NodeValidationError
+	require.True(t, errors.As(err, &validationErr))
+	require.NotEmpty(t, validationErr.Errors)
+	require.Equal(t, "business_type", validationErr.Errors[0].Field)
+	require.Equal(t, "REQUIRED_FIELD_MISSING", validationErr.Errors[0].Code)
+}
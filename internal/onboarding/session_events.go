@@ -0,0 +1,159 @@
+package onboarding
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrNoSessionEvents is returned when a session has no recorded events to replay, either because it
+// did not exist yet or because it predates the event log
+var ErrNoSessionEvents = errors.New("no events recorded for session")
+
+// GetSessionEvents returns a session's events in the order they happened
+func (s *Service) GetSessionEvents(ctx context.Context, sessionID string) ([]*SessionEvent, error) {
+	events, err := s.storage.ListSessionEvents(ctx, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list session events: %w", err)
+	}
+	return events, nil
+}
+
+// GetSessionAsOf rebuilds a session as it was at the given time by replaying its events up to then
+func (s *Service) GetSessionAsOf(ctx context.Context, sessionID string, at time.Time) (*Session, error) {
+	events, err := s.GetSessionEvents(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	replayed := make([]*SessionEvent, 0, len(events))
+	for _, event := range events {
+		if event.OccurredAt.After(at) {
+			break
+		}
+		replayed = append(replayed, event)
+	}
+
+	return ReplaySessionEvents(replayed)
+}
+
+// ReplaySessionEvents rebuilds a session from its events. Dynamic state is derived from the graph
+// rather than recorded, so the replayed session has none.
+func ReplaySessionEvents(events []*SessionEvent) (*Session, error) {
+	if len(events) == 0 {
+		return nil, ErrNoSessionEvents
+	}
+
+	first := events[0]
+	if first.Type != SessionEventStarted {
+		return nil, fmt.Errorf("event log starts with %s instead of %s", first.Type, SessionEventStarted)
+	}
+
+	session := &Session{
+		ID:            first.SessionID,
+		UserID:        first.UserID,
+		GraphID:       first.GraphID,
+		GraphRevision: eventInt(first.Data["graph_revision"]),
+		Data:          make(map[string]interface{}),
+		History:       make([]SessionStep, 0),
+		CreatedAt:     first.OccurredAt,
+	}
+
+	for _, event := range events {
+		if err := applySessionEvent(session, event); err != nil {
+			return nil, fmt.Errorf("failed to apply event %d (%s): %w", event.Sequence, event.Type, err)
+		}
+	}
+
+	return session, nil
+}
+
+// applySessionEvent applies one event to a replayed session
+func applySessionEvent(session *Session, event *SessionEvent) error {
+	switch event.Type {
+	case SessionEventSubmitted:
+		var data map[string]interface{}
+		if err := decodeEventValue(event.Data["data"], &data); err != nil {
+			return err
+		}
+		for key, value := range data {
+			session.Data[key] = value
+		}
+	case SessionEventBusinessTypeChanged:
+		session.Data["business_type"] = event.Data["business_type"]
+	case SessionEventCompleted:
+		completedAt := event.OccurredAt
+		session.CompletedAt = &completedAt
+	case SessionEventReactivated, SessionEventRetried:
+		session.RetryCount = eventInt(event.Data["retry_count"])
+	case SessionEventUpdated:
+		session.GraphRevision = eventInt(event.Data["graph_revision"])
+		session.RetryCount = eventInt(event.Data["retry_count"])
+		session.Data = make(map[string]interface{})
+		if err := decodeEventValue(event.Data["data"], &session.Data); err != nil {
+			return err
+		}
+		session.History = make([]SessionStep, 0)
+		if err := decodeEventValue(event.Data["history"], &session.History); err != nil {
+			return err
+		}
+		session.CompletedAt = nil
+		if value, ok := event.Data["completed_at"].(string); ok {
+			completedAt, err := time.Parse(time.RFC3339Nano, value)
+			if err != nil {
+				return fmt.Errorf("failed to parse completed_at: %w", err)
+			}
+			session.CompletedAt = &completedAt
+		}
+	case SessionEventMigrated:
+		session.GraphRevision = eventInt(event.Data["to_revision"])
+		session.Data = make(map[string]interface{})
+		if err := decodeEventValue(event.Data["data"], &session.Data); err != nil {
+			return err
+		}
+		session.History = make([]SessionStep, 0)
+		if err := decodeEventValue(event.Data["history"], &session.History); err != nil {
+			return err
+		}
+	}
+
+	if event.Step != nil {
+		session.History = append(session.History, *event.Step)
+	}
+
+	session.CurrentNodeID = event.NodeID
+	session.Status = event.Status
+	session.Revision = event.Revision
+	session.UpdatedAt = event.OccurredAt
+	return nil
+}
+
+// decodeEventValue converts an event data value into target. Values read back from storage are
+// generic JSON, so they are converted through their JSON encoding.
+func decodeEventValue(value interface{}, target interface{}) error {
+	if value == nil {
+		return nil
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("failed to encode event data: %w", err)
+	}
+	if err := json.Unmarshal(data, target); err != nil {
+		return fmt.Errorf("failed to decode event data: %w", err)
+	}
+	return nil
+}
+
+// eventInt reads an integer from event data, which holds float64 once read back from storage
+func eventInt(value interface{}) int {
+	switch v := value.(type) {
+	case int:
+		return v
+	case float64:
+		return int(v)
+	}
+	return 0
+}
@@ -0,0 +1,120 @@
+package onboarding
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// assertReplayMatchesStored checks that replaying a session's events rebuilds the stored session
+func assertReplayMatchesStored(t *testing.T, service *Service, sessionID string) {
+	t.Helper()
+	ctx := context.Background()
+
+	stored, err := service.GetSession(ctx, sessionID)
+	require.NoError(t, err)
+	events, err := service.GetSessionEvents(ctx, sessionID)
+	require.NoError(t, err)
+
+	replayed, err := ReplaySessionEvents(events)
+	require.NoError(t, err)
+	asOf, err := service.GetSessionAsOf(ctx, sessionID, time.Now())
+	require.NoError(t, err)
+
+	for name, session := range map[string]*Session{"replayed": replayed, "as of now": asOf} {
+		assert.Equal(t, stored.ID, session.ID, name)
+		assert.Equal(t, stored.UserID, session.UserID, name)
+		assert.Equal(t, stored.GraphID, session.GraphID, name)
+		assert.Equal(t, stored.GraphRevision, session.GraphRevision, name)
+		assert.Equal(t, stored.CurrentNodeID, session.CurrentNodeID, name)
+		assert.Equal(t, stored.Status, session.Status, name)
+		assert.Equal(t, stored.Revision, session.Revision, name)
+		assert.Equal(t, stored.RetryCount, session.RetryCount, name)
+		assert.Equal(t, stored.CompletedAt != nil, session.CompletedAt != nil, name)
+		assert.JSONEq(t, jsonString(t, stored.Data), jsonString(t, session.Data), name)
+		assert.JSONEq(t, jsonString(t, stored.History), jsonString(t, session.History), name)
+	}
+}
+
+// jsonString encodes a value for comparison regardless of the Go types JSON decoding produced
+func jsonString(t *testing.T, value interface{}) string {
+	t.Helper()
+	data, err := json.Marshal(value)
+	require.NoError(t, err)
+	return string(data)
+}
+
+func TestUpdateSessionRecordsEvent(t *testing.T) {
+	service, _ := newTestService(t)
+	ctx := context.Background()
+	graph := createTestGraph(t, service)
+
+	session, err := service.StartSession(ctx, "user-1", graph.ID)
+	require.NoError(t, err)
+	_, err = service.SubmitNodeData(ctx, session.ID, map[string]interface{}{"business_type": "llp"})
+	require.NoError(t, err)
+
+	session, err = service.GetSession(ctx, session.ID)
+	require.NoError(t, err)
+	session.Data["pan_number"] = "ABCDE1234F"
+	delete(session.Data, "business_type")
+	session.RetryCount = 2
+	require.NoError(t, service.UpdateSession(ctx, session))
+
+	events, err := service.GetSessionEvents(ctx, session.ID)
+	require.NoError(t, err)
+	last := events[len(events)-1]
+	assert.Equal(t, SessionEventUpdated, last.Type)
+	assert.Equal(t, session.Revision, last.Revision)
+
+	assertReplayMatchesStored(t, service, session.ID)
+}
+
+func TestSaveSessionRecordsEvents(t *testing.T) {
+	service, _ := newTestService(t)
+	ctx := context.Background()
+	graph := createTestGraph(t, service)
+
+	// A session saved for the first time is recorded as started
+	session := NewSession("user-1", graph.ID)
+	session.GraphRevision = graph.Revision
+	session.CurrentNodeID = "details"
+	session.Data["business_type"] = "individual"
+	require.NoError(t, service.SaveSession(ctx, session))
+
+	events, err := service.GetSessionEvents(ctx, session.ID)
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+	assert.Equal(t, SessionEventStarted, events[0].Type)
+	assert.Equal(t, SessionEventUpdated, events[1].Type)
+	assertReplayMatchesStored(t, service, session.ID)
+
+	completedAt := time.Now()
+	session.Status = SessionStatusCompleted
+	session.CompletedAt = &completedAt
+	require.NoError(t, service.SaveSession(ctx, session))
+	assertReplayMatchesStored(t, service, session.ID)
+}
+
+func TestStartDynamicSessionReplays(t *testing.T) {
+	service, store := newTestService(t)
+	dynamicService := NewDynamicService(store, service.config, newTestLogger())
+	ctx := context.Background()
+	graph := createTestGraph(t, dynamicService.Service)
+
+	// Starting a dynamic session saves it a second time with its dynamic state
+	session, err := dynamicService.StartDynamicSession(ctx, graph.ID, "user-1")
+	require.NoError(t, err)
+	require.NotNil(t, session.DynamicState)
+
+	events, err := service.GetSessionEvents(ctx, session.ID)
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+	assert.Equal(t, SessionEventUpdated, events[1].Type)
+
+	assertReplayMatchesStored(t, service, session.ID)
+}
@@ -0,0 +1,56 @@
+package onboarding
+
+import (
+	"context"
+	"fmt"
+
+	"onboarding-system/internal/storage"
+)
+
+// Session listing types, re-exported from the storage package
+type (
+	SessionQuery     = storage.SessionQuery
+	SessionPage      = storage.SessionPage
+	SessionSortField = storage.SessionSortField
+)
+
+const (
+	SessionSortCreatedAt = storage.SessionSortCreatedAt
+	SessionSortUpdatedAt = storage.SessionSortUpdatedAt
+
+	MaxSessionPageSize = storage.MaxSessionPageSize
+)
+
+// ErrInvalidCursor is returned for a cursor that is malformed or was issued for a different sort
+var ErrInvalidCursor = storage.ErrInvalidCursor
+
+// ListSessionsQuery returns one page of the sessions matching query
+func (s *Service) ListSessionsQuery(ctx context.Context, query SessionQuery) (*SessionPage, error) {
+	page, err := s.storage.ListSessionsQuery(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+	return page, nil
+}
+
+// forEachSession calls fn with every session matching query, loading them a page at a time.
+// Pages continue from the sort position of the last session, so fn may change the sessions.
+func (s *Service) forEachSession(ctx context.Context, query SessionQuery, fn func(*Session) error) error {
+	for {
+		page, err := s.ListSessionsQuery(ctx, query)
+		if err != nil {
+			return err
+		}
+
+		for _, session := range page.Sessions {
+			if err := fn(session); err != nil {
+				return err
+			}
+		}
+
+		if page.NextCursor == "" {
+			return nil
+		}
+		query.Cursor = page.NextCursor
+	}
+}
@@ -0,0 +1,31 @@
+package onboarding
+
+import (
+	"context"
+
+	"onboarding-system/internal/storage"
+)
+
+// ErrSessionConflict is returned when a session changed since the caller loaded it
+var ErrSessionConflict = storage.ErrSessionConflict
+
+// SessionConflictError carries the current session when a change was based on an older revision
+type SessionConflictError = storage.SessionConflictError
+
+type expectedRevisionKey struct{}
+
+// WithExpectedRevision returns a context under which sessions load only at the given revision.
+// Changes made under it fail with a *SessionConflictError if the session has moved on, e.g.
+// because a client's If-Match header is stale.
+func WithExpectedRevision(ctx context.Context, revision int) context.Context {
+	return context.WithValue(ctx, expectedRevisionKey{}, revision)
+}
+
+// checkExpectedRevision rejects a loaded session that is not at the revision the context expects
+func checkExpectedRevision(ctx context.Context, session *Session) error {
+	revision, ok := ctx.Value(expectedRevisionKey{}).(int)
+	if !ok || revision == session.Revision {
+		return nil
+	}
+	return &SessionConflictError{SessionID: session.ID, Revision: revision, Current: session}
+}
@@ -22,6 +22,20 @@ type ValidationRules = types.ValidationRules
 type NodeType = types.NodeType
 type FieldType = types.FieldType
 type NextStepResult = types.NextStepResult
+type RuleGroup = types.RuleGroup
+type ConditionalFieldRule = types.ConditionalFieldRule
+type GraphVersion = types.GraphVersion
+type SessionEvent = types.SessionEvent
+type SessionEventType = types.SessionEventType
+type OutboxMessage = types.OutboxMessage
+type OutboxStatus = types.OutboxStatus
+type DependencyExpression = types.DependencyExpression
+type ActivationRule = types.ActivationRule
+type ActivationCondition = types.ActivationCondition
+type NodeRule = types.NodeRule
+type RuleAction = types.RuleAction
+type AdvancedSessionState = types.AdvancedSessionState
+type PathStep = types.PathStep
 
 // Re-export functions
 var NewSession = types.NewSession
@@ -52,3 +66,25 @@ const (
 	SessionStatusFailed    = types.SessionStatusFailed
 	SessionStatusExpired   = types.SessionStatusExpired
 )
+
+const (
+	SessionEventStarted             = types.SessionEventStarted
+	SessionEventSubmitted           = types.SessionEventSubmitted
+	SessionEventNavigated           = types.SessionEventNavigated
+	SessionEventWentBack            = types.SessionEventWentBack
+	SessionEventBusinessTypeChanged = types.SessionEventBusinessTypeChanged
+	SessionEventCompleted           = types.SessionEventCompleted
+	SessionEventPaused              = types.SessionEventPaused
+	SessionEventResumed             = types.SessionEventResumed
+	SessionEventExpired             = types.SessionEventExpired
+	SessionEventReactivated         = types.SessionEventReactivated
+	SessionEventRetried             = types.SessionEventRetried
+	SessionEventMigrated            = types.SessionEventMigrated
+	SessionEventUpdated             = types.SessionEventUpdated
+)
+
+const (
+	OutboxStatusPending   = types.OutboxStatusPending
+	OutboxStatusDelivered = types.OutboxStatusDelivered
+	OutboxStatusDead      = types.OutboxStatusDead
+)
@@ -0,0 +1,127 @@
+package onboarding
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"onboarding-system/internal/graphdiff"
+	"onboarding-system/internal/storage"
+
+	"github.com/sirupsen/logrus"
+)
+
+// maxPublishAttempts bounds how often publishing retries after a concurrent publish took its revision
+const maxPublishAttempts = 5
+
+// publishGraph validates a graph and stores it as a new immutable revision.
+// The published revision also becomes the default graph for new sessions.
+func (s *Service) publishGraph(ctx context.Context, graph *Graph) error {
+	if err := validateGraph(graph); err != nil {
+		return err
+	}
+
+	graph.UpdatedAt = time.Now()
+	for attempt := 1; ; attempt++ {
+		versions, err := s.storage.ListGraphVersions(ctx, graph.ID)
+		if err != nil {
+			return fmt.Errorf("failed to list graph versions: %w", err)
+		}
+
+		graph.Revision = 1
+		if len(versions) > 0 {
+			graph.Revision = versions[len(versions)-1].Revision + 1
+		}
+
+		// Revisions are unique per graph, so of two publishes that picked the same revision
+		// only one is saved; the other retries with the next revision
+		err = s.storage.PublishGraphVersion(ctx, graph)
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, storage.ErrGraphVersionExists) || attempt == maxPublishAttempts {
+			return fmt.Errorf("failed to publish graph version: %w", err)
+		}
+
+		s.logger.WithFields(logrus.Fields{
+			"graph_id": graph.ID,
+			"revision": graph.Revision,
+		}).Debug("Graph revision was published concurrently, retrying")
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"graph_id": graph.ID,
+		"revision": graph.Revision,
+	}).Info("Published graph revision")
+
+	return nil
+}
+
+// GetSessionGraph returns the graph revision a session was started on
+func (s *Service) GetSessionGraph(ctx context.Context, session *Session) (*Graph, error) {
+	// Sessions started before versioning follow the default graph
+	if session.GraphRevision == 0 {
+		return s.storage.GetGraph(ctx, session.GraphID)
+	}
+	return s.storage.GetGraphVersion(ctx, session.GraphID, session.GraphRevision)
+}
+
+// ListGraphVersions returns the published revisions of a graph, oldest first
+func (s *Service) ListGraphVersions(ctx context.Context, graphID string) ([]*GraphVersion, error) {
+	graph, err := s.storage.GetGraph(ctx, graphID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get graph: %w", err)
+	}
+
+	versions, err := s.storage.ListGraphVersions(ctx, graphID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list graph versions: %w", err)
+	}
+
+	for _, version := range versions {
+		version.IsDefault = version.Revision == graph.Revision
+	}
+
+	return versions, nil
+}
+
+// GetGraphVersion returns a specific revision of a graph
+func (s *Service) GetGraphVersion(ctx context.Context, graphID string, revision int) (*Graph, error) {
+	return s.storage.GetGraphVersion(ctx, graphID, revision)
+}
+
+// SetDefaultGraphVersion makes a published revision the graph used by new sessions
+func (s *Service) SetDefaultGraphVersion(ctx context.Context, graphID string, revision int) (*Graph, error) {
+	graph, err := s.storage.GetGraphVersion(ctx, graphID, revision)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get graph version: %w", err)
+	}
+
+	graph.UpdatedAt = time.Now()
+	if err := s.storage.SaveGraph(ctx, graph); err != nil {
+		return nil, fmt.Errorf("failed to save graph: %w", err)
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"graph_id": graphID,
+		"revision": revision,
+	}).Info("Set default graph revision")
+
+	return graph, nil
+}
+
+// DiffGraphVersions compares two published revisions of a graph
+func (s *Service) DiffGraphVersions(ctx context.Context, graphID string, fromRevision, toRevision int) (*graphdiff.Diff, error) {
+	from, err := s.storage.GetGraphVersion(ctx, graphID, fromRevision)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get graph revision %d: %w", fromRevision, err)
+	}
+
+	to, err := s.storage.GetGraphVersion(ctx, graphID, toRevision)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get graph revision %d: %w", toRevision, err)
+	}
+
+	return graphdiff.Compare(from, to), nil
+}
@@ -0,0 +1,184 @@
+package onboarding
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"onboarding-system/internal/config"
+	"onboarding-system/internal/graphdiff"
+	"onboarding-system/internal/storage"
+	"onboarding-system/internal/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGraphVersioning(t *testing.T) {
+	service, _ := newTestService(t)
+	ctx := context.Background()
+	graph := createTestGraph(t, service)
+	assert.Equal(t, 1, graph.Revision)
+
+	first, err := service.StartSession(ctx, "user-1", graph.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, first.GraphRevision)
+
+	// Publishing a change makes it the default for new sessions only
+	changed := newTestGraph()
+	changed.ID = graph.ID
+	changed.Nodes["details"].Fields = append(changed.Nodes["details"].Fields, types.Field{ID: "gst_number", Name: "gst_number", Type: types.FieldTypeText})
+	require.NoError(t, service.publishGraph(ctx, changed))
+	assert.Equal(t, 2, changed.Revision)
+
+	second, err := service.StartSession(ctx, "user-2", graph.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 2, second.GraphRevision)
+
+	sessionGraph, err := service.GetSessionGraph(ctx, first)
+	require.NoError(t, err)
+	assert.Equal(t, 1, sessionGraph.Revision)
+	assert.Len(t, sessionGraph.Nodes["details"].Fields, 2)
+
+	versions, err := service.ListGraphVersions(ctx, graph.ID)
+	require.NoError(t, err)
+	require.Len(t, versions, 2)
+	assert.False(t, versions[0].IsDefault)
+	assert.True(t, versions[1].IsDefault)
+
+	diff, err := service.DiffGraphVersions(ctx, graph.ID, 1, 2)
+	require.NoError(t, err)
+	assert.Equal(t, []graphdiff.Change{{Type: graphdiff.ChangeAdded, Kind: graphdiff.KindField, ID: "gst_number", NodeID: "details", Detail: "text"}}, diff.Changes)
+
+	// Rolling back to revision 1 does not publish a new revision
+	rolledBack, err := service.SetDefaultGraphVersion(ctx, graph.ID, 1)
+	require.NoError(t, err)
+	assert.Equal(t, 1, rolledBack.Revision)
+
+	third, err := service.StartSession(ctx, "user-3", graph.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, third.GraphRevision)
+
+	versions, err = service.ListGraphVersions(ctx, graph.ID)
+	require.NoError(t, err)
+	require.Len(t, versions, 2)
+	assert.True(t, versions[0].IsDefault)
+
+	_, err = service.SetDefaultGraphVersion(ctx, graph.ID, 3)
+	assert.Error(t, err)
+	_, err = service.DiffGraphVersions(ctx, graph.ID, 1, 3)
+	assert.Error(t, err)
+
+	// A graph that fails validation is not published
+	invalid := newTestGraph()
+	invalid.ID = graph.ID
+	invalid.StartNodeID = "missing"
+	assert.True(t, errors.Is(service.publishGraph(ctx, invalid), ErrInvalidGraph))
+	versions, err = service.ListGraphVersions(ctx, graph.ID)
+	require.NoError(t, err)
+	assert.Len(t, versions, 2)
+}
+
+// racingPublishStorage publishes a revision of its own the first time graph versions are listed,
+// as if another publish ran between listing the versions and saving the new one
+type racingPublishStorage struct {
+	storage.Storage
+	raced bool
+}
+
+func (s *racingPublishStorage) ListGraphVersions(ctx context.Context, graphID string) ([]*types.GraphVersion, error) {
+	versions, err := s.Storage.ListGraphVersions(ctx, graphID)
+	if err != nil || s.raced || len(versions) == 0 {
+		return versions, err
+	}
+	s.raced = true
+
+	concurrent := newTestGraph()
+	concurrent.ID = graphID
+	concurrent.Description = "Published concurrently"
+	concurrent.Revision = versions[len(versions)-1].Revision + 1
+	if err := s.Storage.PublishGraphVersion(ctx, concurrent); err != nil {
+		return nil, err
+	}
+	return versions, nil
+}
+
+func TestPublishGraphRetriesTakenRevision(t *testing.T) {
+	service, store := newTestService(t)
+	ctx := context.Background()
+	graph := createTestGraph(t, service)
+
+	racing := newTestServiceOn(&racingPublishStorage{Storage: store}, &config.Config{})
+	update := newTestGraph()
+	update.ID = graph.ID
+	update.Description = "Published second"
+	require.NoError(t, racing.publishGraph(ctx, update))
+	assert.Equal(t, 3, update.Revision)
+
+	// Both publishes are kept, and the later revision is the default
+	concurrent, err := service.GetGraphVersion(ctx, graph.ID, 2)
+	require.NoError(t, err)
+	assert.Equal(t, "Published concurrently", concurrent.Description)
+
+	stored, err := service.GetGraph(ctx, graph.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 3, stored.Revision)
+	assert.Equal(t, "Published second", stored.Description)
+}
+
+// takenRevisionStorage rejects every publish as already taken
+type takenRevisionStorage struct {
+	storage.Storage
+	attempts int
+}
+
+func (s *takenRevisionStorage) PublishGraphVersion(ctx context.Context, graph *types.Graph) error {
+	s.attempts++
+	return &storage.GraphVersionExistsError{GraphID: graph.ID, Revision: graph.Revision}
+}
+
+func TestPublishGraphGivesUpAfterRepeatedConflicts(t *testing.T) {
+	store := &takenRevisionStorage{Storage: storage.NewMemoryStorage(newTestLogger())}
+	service := newTestServiceOn(store, &config.Config{})
+
+	err := service.CreateGraph(context.Background(), newTestGraph())
+	assert.True(t, errors.Is(err, storage.ErrGraphVersionExists), "expected ErrGraphVersionExists, got %v", err)
+	assert.Equal(t, maxPublishAttempts, store.attempts)
+}
+
+func TestConcurrentPublishesGetDistinctRevisions(t *testing.T) {
+	service, _ := newTestService(t)
+	ctx := context.Background()
+	graph := createTestGraph(t, service)
+
+	// Fewer publishers than attempts, so each one succeeds however often it loses a race
+	const publishers = maxPublishAttempts - 1
+	revisions := make(chan int, publishers)
+	var wg sync.WaitGroup
+	for i := 0; i < publishers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			update := newTestGraph()
+			update.ID = graph.ID
+			if assert.NoError(t, service.publishGraph(ctx, update)) {
+				revisions <- update.Revision
+			}
+		}()
+	}
+	wg.Wait()
+	close(revisions)
+
+	expected, published := make([]int, 0, publishers), make([]int, 0, publishers)
+	for revision := range revisions {
+		published = append(published, revision)
+		expected = append(expected, len(expected)+2)
+	}
+	assert.ElementsMatch(t, expected, published)
+
+	versions, err := service.ListGraphVersions(ctx, graph.ID)
+	require.NoError(t, err)
+	assert.Len(t, versions, publishers+1)
+}
@@ -0,0 +1,238 @@
+package operators
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// dateLayouts are the string forms accepted as dates
+var dateLayouts = []string{time.RFC3339Nano, "2006-01-02"}
+
+// Equal reports whether two values are equal after coercing them to a common type
+func Equal(a, b interface{}) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+
+	listA, okA := toList(a)
+	listB, okB := toList(b)
+	if okA || okB {
+		if !okA || !okB || len(listA) != len(listB) {
+			return false
+		}
+		for i := range listA {
+			if !Equal(listA[i], listB[i]) {
+				return false
+			}
+		}
+		return true
+	}
+
+	if isBool(a) || isBool(b) {
+		boolA, okA := toBool(a)
+		boolB, okB := toBool(b)
+		return okA && okB && boolA == boolB
+	}
+	if isNumber(a) || isNumber(b) {
+		numberA, okA := toNumber(a)
+		numberB, okB := toNumber(b)
+		return okA && okB && numberA == numberB
+	}
+	if isTime(a) || isTime(b) {
+		timeA, okA := toTime(a)
+		timeB, okB := toTime(b)
+		return okA && okB && timeA.Equal(timeB)
+	}
+
+	return ToString(a) == ToString(b)
+}
+
+// Compare orders two values: as numbers when both are numeric, as dates when both are dates and as
+// strings otherwise. ok is false when the values cannot be ordered.
+func Compare(a, b interface{}) (int, bool) {
+	if a == nil || b == nil {
+		return 0, false
+	}
+	if _, isList := toList(a); isList {
+		return 0, false
+	}
+	if _, isList := toList(b); isList {
+		return 0, false
+	}
+
+	if numberA, okA := toNumber(a); okA {
+		if numberB, okB := toNumber(b); okB {
+			switch {
+			case numberA < numberB:
+				return -1, true
+			case numberA > numberB:
+				return 1, true
+			default:
+				return 0, true
+			}
+		}
+	}
+	if timeA, okA := toTime(a); okA {
+		if timeB, okB := toTime(b); okB {
+			return timeA.Compare(timeB), true
+		}
+	}
+	if isBool(a) || isBool(b) {
+		return 0, false
+	}
+
+	return strings.Compare(ToString(a), ToString(b)), true
+}
+
+// ToString formats a value the way string operators see it
+func ToString(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
+
+// isBool reports whether a value is a bool
+func isBool(value interface{}) bool {
+	_, ok := value.(bool)
+	return ok
+}
+
+// toBool coerces a bool or a "true"/"false" string
+func toBool(value interface{}) (bool, bool) {
+	switch v := value.(type) {
+	case bool:
+		return v, true
+	case string:
+		b, err := strconv.ParseBool(strings.TrimSpace(v))
+		return b, err == nil
+	default:
+		return false, false
+	}
+}
+
+// isNumber reports whether a value has a numeric type
+func isNumber(value interface{}) bool {
+	switch value.(type) {
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64, json.Number:
+		return true
+	default:
+		return false
+	}
+}
+
+// toNumber coerces a number or a numeric string
+func toNumber(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		return f, err == nil
+	case json.Number:
+		f, err := v.Float64()
+		return f, err == nil
+	}
+
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(rv.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), true
+	default:
+		return 0, false
+	}
+}
+
+// isTime reports whether a value is a time
+func isTime(value interface{}) bool {
+	_, ok := value.(time.Time)
+	return ok
+}
+
+// toTime coerces a time or a date string
+func toTime(value interface{}) (time.Time, bool) {
+	switch v := value.(type) {
+	case time.Time:
+		return v, true
+	case string:
+		for _, layout := range dateLayouts {
+			if t, err := time.Parse(layout, strings.TrimSpace(v)); err == nil {
+				return t, true
+			}
+		}
+	}
+	return time.Time{}, false
+}
+
+// toList returns the elements of a slice or array value
+func toList(value interface{}) ([]interface{}, bool) {
+	switch v := value.(type) {
+	case []interface{}:
+		return v, true
+	case []string:
+		list := make([]interface{}, len(v))
+		for i, s := range v {
+			list[i] = s
+		}
+		return list, true
+	case nil, string, []byte:
+		return nil, false
+	}
+
+	rv := reflect.ValueOf(value)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return nil, false
+	}
+	list := make([]interface{}, rv.Len())
+	for i := range list {
+		list[i] = rv.Index(i).Interface()
+	}
+	return list, true
+}
+
+// isEmpty reports whether a value is null, a blank string or an empty list or map
+func isEmpty(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case string:
+		return strings.TrimSpace(v) == ""
+	}
+
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return rv.Len() == 0
+	default:
+		return false
+	}
+}
+
+// foldCase lower-cases strings, including the strings in a list
+func foldCase(value interface{}) interface{} {
+	if s, ok := value.(string); ok {
+		return strings.ToLower(s)
+	}
+	if list, ok := toList(value); ok {
+		folded := make([]interface{}, len(list))
+		for i, element := range list {
+			folded[i] = foldCase(element)
+		}
+		return folded
+	}
+	return value
+}
@@ -0,0 +1,286 @@
+// Package operators implements the comparison operators used by graph conditions: validation and
+// edge conditions, node dependencies, activation rules, rule group conditional fields and
+// cross-node field checks.
+//
+// Values are coerced to a common type before they are compared, so a JSON number matches an
+// integer constant and a form value "10" matches the number 10:
+//
+//   - a bool on either side compares as bool ("true" and "false" are accepted)
+//   - a number on either side compares as a number (numeric strings are accepted)
+//   - a time on either side compares as a time (RFC 3339 and 2006-01-02 strings are accepted)
+//   - lists compare element by element; anything else compares as a string
+//
+// gt, gte, lt, lte and between order values as numbers when both sides are numeric, as dates when
+// both are dates and as strings otherwise. Every string operator has a case-insensitive variant
+// with an _ci suffix, e.g. eq_ci or starts_with_ci.
+package operators
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Operator names
+const (
+	Eq          = "eq"
+	Ne          = "ne"
+	Gt          = "gt"
+	Gte         = "gte"
+	Lt          = "lt"
+	Lte         = "lte"
+	Between     = "between" // Operand is [min, max], both inclusive
+	In          = "in"
+	NotIn       = "not_in"
+	Contains    = "contains" // Substring of a string or element of a list
+	NotContains = "not_contains"
+	StartsWith  = "starts_with"
+	EndsWith    = "ends_with"
+	Matches     = "matches" // Operand is a regular expression
+	NotMatches  = "not_matches"
+	Exists      = "exists" // The field is set, to anything but null
+	NotExists   = "not_exists"
+	Empty       = "empty" // The field is missing, null, blank or an empty list
+	NotEmpty    = "not_empty"
+)
+
+// CaseInsensitiveSuffix turns a string operator into its case-insensitive variant
+const CaseInsensitiveSuffix = "_ci"
+
+// ErrUnknownOperator is returned for an operator this package does not implement
+var ErrUnknownOperator = errors.New("unknown operator")
+
+// operatorFunc applies an operator to a value that is set and an operand
+type operatorFunc func(value, operand interface{}) (bool, error)
+
+// operators maps every operator that compares a value that is set
+var operators = map[string]operatorFunc{
+	Eq:  func(value, operand interface{}) (bool, error) { return Equal(value, operand), nil },
+	Ne:  func(value, operand interface{}) (bool, error) { return !Equal(value, operand), nil },
+	Gt:  ordered(func(c int) bool { return c > 0 }),
+	Gte: ordered(func(c int) bool { return c >= 0 }),
+	Lt:  ordered(func(c int) bool { return c < 0 }),
+	Lte: ordered(func(c int) bool { return c <= 0 }),
+	Between: func(value, operand interface{}) (bool, error) {
+		bounds, ok := toList(operand)
+		if !ok || len(bounds) != 2 {
+			return false, fmt.Errorf("%s needs a [min, max] operand", Between)
+		}
+		low, okLow := Compare(value, bounds[0])
+		high, okHigh := Compare(value, bounds[1])
+		return okLow && okHigh && low >= 0 && high <= 0, nil
+	},
+	In: func(value, operand interface{}) (bool, error) { return in(value, operand) },
+	NotIn: func(value, operand interface{}) (bool, error) {
+		found, err := in(value, operand)
+		return !found, err
+	},
+	Contains:    func(value, operand interface{}) (bool, error) { return contains(value, operand), nil },
+	NotContains: func(value, operand interface{}) (bool, error) { return !contains(value, operand), nil },
+	StartsWith: func(value, operand interface{}) (bool, error) {
+		return strings.HasPrefix(ToString(value), ToString(operand)), nil
+	},
+	EndsWith: func(value, operand interface{}) (bool, error) {
+		return strings.HasSuffix(ToString(value), ToString(operand)), nil
+	},
+	Matches: func(value, operand interface{}) (bool, error) { return matches(value, operand, false) },
+	NotMatches: func(value, operand interface{}) (bool, error) {
+		matched, err := matches(value, operand, false)
+		return !matched, err
+	},
+}
+
+// presenceOperators check whether a value is set rather than compare it
+var presenceOperators = map[string]func(value interface{}, exists bool) bool{
+	Exists:    func(value interface{}, exists bool) bool { return exists && value != nil },
+	NotExists: func(value interface{}, exists bool) bool { return !exists || value == nil },
+	Empty:     func(value interface{}, exists bool) bool { return !exists || isEmpty(value) },
+	NotEmpty:  func(value interface{}, exists bool) bool { return exists && !isEmpty(value) },
+}
+
+// caseInsensitive are the operators with an _ci variant
+var caseInsensitive = map[string]bool{
+	Eq:          true,
+	Ne:          true,
+	In:          true,
+	NotIn:       true,
+	Contains:    true,
+	NotContains: true,
+	StartsWith:  true,
+	EndsWith:    true,
+	Matches:     true,
+	NotMatches:  true,
+}
+
+// Evaluate applies an operator to a field value and an operand. exists reports whether the field
+// is set at all; a missing field satisfies only not_exists and empty.
+func Evaluate(operator string, value interface{}, exists bool, operand interface{}) (bool, error) {
+	if presence, ok := presenceOperators[operator]; ok {
+		return presence(value, exists), nil
+	}
+
+	base, fold := baseOperator(operator)
+	apply, ok := operators[base]
+	if !ok {
+		return false, fmt.Errorf("%w: %q", ErrUnknownOperator, operator)
+	}
+	if !exists {
+		return false, nil
+	}
+
+	if fold {
+		if base == Matches || base == NotMatches {
+			matched, err := matches(value, operand, true)
+			return matched == (base == Matches), err
+		}
+		value, operand = foldCase(value), foldCase(operand)
+	}
+	return apply(value, operand)
+}
+
+// Supported reports whether an operator is implemented
+func Supported(operator string) bool {
+	if _, ok := presenceOperators[operator]; ok {
+		return true
+	}
+	base, _ := baseOperator(operator)
+	_, ok := operators[base]
+	return ok
+}
+
+// ChecksPresence reports whether an operator tests whether a field is set, so applies to missing fields
+func ChecksPresence(operator string) bool {
+	_, ok := presenceOperators[operator]
+	return ok
+}
+
+// CaseInsensitive returns the case-insensitive variant of an operator, or the operator itself when
+// it has none
+func CaseInsensitive(operator string) string {
+	if caseInsensitive[operator] {
+		return operator + CaseInsensitiveSuffix
+	}
+	return operator
+}
+
+// Names returns every supported operator, sorted
+func Names() []string {
+	names := make([]string, 0, len(operators)+len(presenceOperators)+len(caseInsensitive))
+	for name := range operators {
+		names = append(names, name)
+	}
+	for name := range presenceOperators {
+		names = append(names, name)
+	}
+	for name := range caseInsensitive {
+		names = append(names, name+CaseInsensitiveSuffix)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ValidateOperand checks that an operator is supported and that its operand has the form it needs,
+// so a graph can be rejected before the condition is ever evaluated
+func ValidateOperand(operator string, operand interface{}) error {
+	if !Supported(operator) {
+		return fmt.Errorf("%w: %q", ErrUnknownOperator, operator)
+	}
+
+	base, fold := baseOperator(operator)
+	switch base {
+	case In, NotIn:
+		if _, ok := toList(operand); !ok {
+			return fmt.Errorf("%s needs a list operand", operator)
+		}
+	case Between:
+		if bounds, ok := toList(operand); !ok || len(bounds) != 2 {
+			return fmt.Errorf("%s needs a [min, max] operand", operator)
+		}
+	case Matches, NotMatches:
+		if _, err := compilePattern(ToString(operand), fold); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// baseOperator strips the case-insensitive suffix from an operator
+func baseOperator(operator string) (string, bool) {
+	if base := strings.TrimSuffix(operator, CaseInsensitiveSuffix); base != operator && caseInsensitive[base] {
+		return base, true
+	}
+	return operator, false
+}
+
+// ordered builds an ordering operator from a test on the result of Compare
+func ordered(test func(c int) bool) operatorFunc {
+	return func(value, operand interface{}) (bool, error) {
+		c, ok := Compare(value, operand)
+		return ok && test(c), nil
+	}
+}
+
+// in reports whether value equals any element of the operand list
+func in(value, operand interface{}) (bool, error) {
+	list, ok := toList(operand)
+	if !ok {
+		return false, fmt.Errorf("%s needs a list operand", In)
+	}
+	for _, element := range list {
+		if Equal(value, element) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// contains reports whether a list value has an element equal to the operand, or a string value
+// has the operand as a substring
+func contains(value, operand interface{}) bool {
+	if list, ok := toList(value); ok {
+		for _, element := range list {
+			if Equal(element, operand) {
+				return true
+			}
+		}
+		return false
+	}
+	return strings.Contains(ToString(value), ToString(operand))
+}
+
+// matches reports whether the string form of value matches the operand pattern
+func matches(value, operand interface{}, fold bool) (bool, error) {
+	re, err := compilePattern(ToString(operand), fold)
+	if err != nil {
+		return false, err
+	}
+	return re.MatchString(ToString(value)), nil
+}
+
+// patternCache avoids recompiling regular expressions on every evaluation
+var (
+	patternCache = make(map[string]*regexp.Regexp)
+	patternMutex sync.Mutex
+)
+
+func compilePattern(pattern string, fold bool) (*regexp.Regexp, error) {
+	if fold {
+		pattern = "(?i)" + pattern
+	}
+
+	patternMutex.Lock()
+	defer patternMutex.Unlock()
+
+	if re, ok := patternCache[pattern]; ok {
+		return re, nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+	}
+	patternCache[pattern] = re
+	return re, nil
+}
@@ -0,0 +1,167 @@
+package operators
+
+import (
+	"encoding/json"
+	"errors"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEvaluate(t *testing.T) {
+	date := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		operator string
+		value    interface{}
+		operand  interface{}
+		want     bool
+	}{
+		// Equality coerces to a common type
+		{"eq strings", Eq, "llp", "llp", true},
+		{"eq is case sensitive", Eq, "LLP", "llp", false},
+		{"eq json number and int", Eq, float64(10), 10, true},
+		{"eq numeric string and int", Eq, "10", 10, true},
+		{"eq json.Number", Eq, json.Number("2.5"), 2.5, true},
+		{"eq numeric strings compare as strings", Eq, "10", "10.0", false},
+		{"eq non-numeric string and number", Eq, "ten", 10, false},
+		{"eq bool and string", Eq, true, "true", true},
+		{"eq bool and other string", Eq, false, "no", false},
+		{"eq time and date string", Eq, date, "2024-03-01", true},
+		{"eq lists", Eq, []interface{}{"a", float64(1)}, []string{"a", "1"}, true},
+		{"eq lists of different length", Eq, []interface{}{"a"}, []interface{}{"a", "b"}, false},
+		{"eq list and scalar", Eq, []interface{}{"a"}, "a", false},
+		{"eq null", Eq, nil, nil, true},
+		{"eq null and empty string", Eq, nil, "", false},
+		{"ne", Ne, "website", "no_code", true},
+		{"ne coerced", Ne, float64(3), 3, false},
+		{"eq_ci", Eq + CaseInsensitiveSuffix, "LLP", "llp", true},
+		{"ne_ci", Ne + CaseInsensitiveSuffix, "LLP", "llp", false},
+
+		// Ordering compares numbers, then dates, then strings
+		{"gt numbers", Gt, float64(10), 9, true},
+		{"gt numeric strings", Gt, "10", "9", true},
+		{"gt non-numeric strings", Gt, "b", "a", true},
+		{"gte equal", Gte, 5, "5", true},
+		{"lt dates", Lt, "2024-01-31", "2024-02-01", true},
+		{"lt mixed date forms", Lt, "2024-02-01T10:00:00Z", date, true},
+		{"lte", Lte, 4.5, 4.5, true},
+		{"gt bool cannot be ordered", Gt, true, false, false},
+		{"gt null cannot be ordered", Gt, nil, 1, false},
+		{"between inclusive low", Between, 18, []interface{}{18, 60}, true},
+		{"between inclusive high", Between, "60", []interface{}{18, 60}, true},
+		{"between outside", Between, float64(61), []int{18, 60}, false},
+		{"between dates", Between, "2024-03-15", []string{"2024-03-01", "2024-03-31"}, true},
+
+		// Lists
+		{"in", In, "llp", []interface{}{"llp", "trust"}, true},
+		{"in coerces elements", In, float64(2), []int{1, 2, 3}, true},
+		{"in string list", In, "trust", []string{"llp", "trust"}, true},
+		{"in missing element", In, "huf", []string{"llp", "trust"}, false},
+		{"not_in", NotIn, "huf", []string{"llp", "trust"}, true},
+		{"in_ci", In + CaseInsensitiveSuffix, "LLP", []string{"llp", "Trust"}, true},
+		{"not_in_ci", NotIn + CaseInsensitiveSuffix, "TRUST", []string{"llp", "Trust"}, false},
+
+		// Strings
+		{"contains substring", Contains, "Acme Private Limited", "Private", true},
+		{"contains list element", Contains, []interface{}{"upi", "cards"}, "cards", true},
+		{"contains list coerces", Contains, []interface{}{float64(1), float64(2)}, 2, true},
+		{"contains_ci", Contains + CaseInsensitiveSuffix, "Acme Private Limited", "private", true},
+		{"not_contains", NotContains, "Acme", "Ltd", true},
+		{"starts_with", StartsWith, "https://acme.in", "https://", true},
+		{"starts_with number", StartsWith, float64(411001), "41", true},
+		{"starts_with_ci", StartsWith + CaseInsensitiveSuffix, "HTTPS://acme.in", "https://", true},
+		{"ends_with", EndsWith, "acme.in", ".com", false},
+		{"ends_with_ci", EndsWith + CaseInsensitiveSuffix, "ACME.IN", ".in", true},
+		{"matches", Matches, "ABCDE1234F", `^[A-Z]{5}[0-9]{4}[A-Z]$`, true},
+		{"matches is case sensitive", Matches, "abcde1234f", `^[A-Z]{5}[0-9]{4}[A-Z]$`, false},
+		{"matches_ci", Matches + CaseInsensitiveSuffix, "abcde1234f", `^[A-Z]{5}[0-9]{4}[A-Z]$`, true},
+		{"not_matches", NotMatches, "ABC", `^[0-9]+$`, true},
+		{"not_matches_ci", NotMatches + CaseInsensitiveSuffix, "abc", `^[A-Z]+$`, false},
+
+		// Presence
+		{"exists", Exists, "", nil, true},
+		{"exists null", Exists, nil, nil, false},
+		{"not_exists null", NotExists, nil, nil, true},
+		{"empty blank", Empty, "  ", nil, true},
+		{"empty list", Empty, []interface{}{}, nil, true},
+		{"empty zero", Empty, float64(0), nil, false},
+		{"not_empty", NotEmpty, "x", nil, true},
+		{"not_empty map", NotEmpty, map[string]interface{}{}, nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Evaluate(tt.operator, tt.value, true, tt.operand)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestEvaluateMissingValue(t *testing.T) {
+	for _, operator := range Names() {
+		t.Run(operator, func(t *testing.T) {
+			got, err := Evaluate(operator, nil, false, []interface{}{"a", "b"})
+			require.NoError(t, err)
+			// Only operators asking whether a field is unset hold for a missing field
+			assert.Equal(t, operator == NotExists || operator == Empty, got)
+		})
+	}
+}
+
+func TestEvaluateErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		operator string
+		operand  interface{}
+	}{
+		{"unknown operator", "equals", "x"},
+		{"suffix on an operator without a variant", Gt + CaseInsensitiveSuffix, 1},
+		{"in without a list", In, "llp"},
+		{"between without two bounds", Between, []interface{}{1}},
+		{"invalid pattern", Matches, "[A-Z"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Evaluate(tt.operator, "x", true, tt.operand)
+			assert.Error(t, err)
+			assert.Error(t, ValidateOperand(tt.operator, tt.operand))
+		})
+	}
+
+	_, err := Evaluate("equals", "x", true, "x")
+	assert.True(t, errors.Is(err, ErrUnknownOperator))
+}
+
+func TestValidateOperand(t *testing.T) {
+	assert.NoError(t, ValidateOperand(In, []string{"llp"}))
+	assert.NoError(t, ValidateOperand(Between, []interface{}{"2024-01-01", "2024-12-31"}))
+	assert.NoError(t, ValidateOperand(Matches+CaseInsensitiveSuffix, `^[a-z]+$`))
+	assert.NoError(t, ValidateOperand(Exists, nil))
+}
+
+func TestNames(t *testing.T) {
+	names := Names()
+
+	assert.True(t, sort.StringsAreSorted(names))
+	for _, name := range names {
+		assert.True(t, Supported(name), name)
+	}
+	assert.Contains(t, names, "starts_with_ci")
+	assert.NotContains(t, names, "gt_ci")
+	assert.False(t, Supported("custom"))
+}
+
+func TestCaseInsensitive(t *testing.T) {
+	assert.Equal(t, "eq_ci", CaseInsensitive(Eq))
+	assert.Equal(t, "matches_ci", CaseInsensitive(Matches))
+	assert.Equal(t, "gt", CaseInsensitive(Gt))
+	assert.True(t, ChecksPresence(Empty))
+	assert.False(t, ChecksPresence(Eq))
+}
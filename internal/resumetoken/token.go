@@ -0,0 +1,93 @@
+// Package resumetoken issues and verifies signed, expiring tokens that let a user resume a
+// paused onboarding session, e.g. on another device, without knowing the session ID.
+//
+// A token is the base64url encoded JSON claims and their HMAC-SHA256 signature, joined by a dot.
+package resumetoken
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ErrInvalid is returned for tokens that are malformed or were not signed with the signer's secret
+var ErrInvalid = errors.New("invalid resume token")
+
+// ErrExpired is returned for correctly signed tokens past their expiry
+var ErrExpired = errors.New("resume token has expired")
+
+// Claims identify the paused session a token resumes
+type Claims struct {
+	SessionID string `json:"sid"`
+	Revision  int    `json:"rev"` // Session revision when it was paused; later changes invalidate the token
+	ExpiresAt int64  `json:"exp"` // Unix seconds
+}
+
+// Signer issues and verifies resume tokens
+type Signer struct {
+	secret []byte
+	ttl    time.Duration
+}
+
+// NewSigner creates a signer whose tokens are valid for ttl
+func NewSigner(secret []byte, ttl time.Duration) *Signer {
+	return &Signer{secret: secret, ttl: ttl}
+}
+
+// RandomSecret returns a secret for signers whose tokens need not outlive the process
+func RandomSecret() ([]byte, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, fmt.Errorf("failed to generate resume token secret: %w", err)
+	}
+	return secret, nil
+}
+
+// Issue returns a token resuming the session at revision, and when it expires
+func (s *Signer) Issue(sessionID string, revision int, now time.Time) (string, time.Time, error) {
+	expiresAt := now.Add(s.ttl).Truncate(time.Second)
+	payload, err := json.Marshal(Claims{SessionID: sessionID, Revision: revision, ExpiresAt: expiresAt.Unix()})
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to encode resume token: %w", err)
+	}
+
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + s.sign(encoded), expiresAt, nil
+}
+
+// Verify checks a token's signature and expiry and returns its claims
+func (s *Signer) Verify(token string, now time.Time) (*Claims, error) {
+	encoded, signature, found := strings.Cut(strings.TrimSpace(token), ".")
+	if !found || !hmac.Equal([]byte(signature), []byte(s.sign(encoded))) {
+		return nil, ErrInvalid
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, ErrInvalid
+	}
+
+	var claims Claims
+	if err := json.Unmarshal(payload, &claims); err != nil || claims.SessionID == "" {
+		return nil, ErrInvalid
+	}
+
+	if !now.Before(time.Unix(claims.ExpiresAt, 0)) {
+		return nil, ErrExpired
+	}
+
+	return &claims, nil
+}
+
+// sign returns the base64url encoded signature of an encoded payload
+func (s *Signer) sign(encoded string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(encoded))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
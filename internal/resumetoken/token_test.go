@@ -0,0 +1,52 @@
+package resumetoken
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIssueAndVerify(t *testing.T) {
+	signer := NewSigner([]byte("secret"), time.Hour)
+	now := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	token, expiresAt, err := signer.Issue("session-1", 4, now)
+	require.NoError(t, err)
+	assert.Equal(t, now.Add(time.Hour), expiresAt)
+	assert.NotContains(t, token, "session-1", "the token is encoded, not a plain session ID")
+
+	claims, err := signer.Verify(token, now.Add(59*time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, &Claims{SessionID: "session-1", Revision: 4, ExpiresAt: expiresAt.Unix()}, claims)
+
+	_, err = signer.Verify(token, expiresAt)
+	assert.ErrorIs(t, err, ErrExpired)
+}
+
+func TestVerifyRejectsForgedTokens(t *testing.T) {
+	signer := NewSigner([]byte("secret"), time.Hour)
+	now := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	token, _, err := signer.Issue("session-1", 4, now)
+	require.NoError(t, err)
+
+	// A token for another session, signed with a different secret, spliced onto the real signature
+	forged, _, err := NewSigner([]byte("other"), time.Hour).Issue("session-2", 4, now)
+	require.NoError(t, err)
+	payload, _, _ := strings.Cut(forged, ".")
+	_, signature, _ := strings.Cut(token, ".")
+
+	tests := map[string]string{
+		"empty":           "",
+		"no signature":    payload,
+		"other secret":    forged,
+		"spliced payload": payload + "." + signature,
+		"bad encoding":    "!!!." + signature,
+	}
+	for name, candidate := range tests {
+		_, err := signer.Verify(candidate, now)
+		assert.ErrorIs(t, err, ErrInvalid, name)
+	}
+}
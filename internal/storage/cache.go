@@ -0,0 +1,377 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"onboarding-system/internal/config"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+)
+
+// cacheKeyVersion is part of every cache key. Bump it when a cached type changes shape so
+// instances running new code never read entries written by old code.
+const cacheKeyVersion = 1
+
+// cacheInvalidationChannel is the pub/sub channel instances announce invalidations on
+const cacheInvalidationChannel = "onboarding:cache:invalidate"
+
+// maxLocalCacheEntries bounds the entries an instance keeps locally
+const maxLocalCacheEntries = 10000
+
+// cacheKind is the type of entity a cache entry holds
+type cacheKind string
+
+const (
+	cacheKindSession cacheKind = "session"
+	cacheKindGraph   cacheKind = "graph"
+)
+
+// CacheStats is a snapshot of the cache counters since startup
+type CacheStats struct {
+	SessionHits   uint64 `json:"session_hits"`
+	SessionMisses uint64 `json:"session_misses"`
+	GraphHits     uint64 `json:"graph_hits"`
+	GraphMisses   uint64 `json:"graph_misses"`
+	LocalHits     uint64 `json:"local_hits"`    // Hits served from the instance's own copy, included in the hits above
+	Invalidations uint64 `json:"invalidations"` // Entries invalidated by this instance
+	RemoteEvicts  uint64 `json:"remote_evicts"` // Local copies dropped because another instance invalidated them
+	Errors        uint64 `json:"errors"`        // Failed cache reads, writes and invalidations
+}
+
+// CacheStatsProvider is implemented by storage backends with a cache
+type CacheStatsProvider interface {
+	CacheStats() CacheStats
+}
+
+// cacheCounters holds the live counters behind CacheStats
+type cacheCounters struct {
+	sessionHits, sessionMisses, graphHits, graphMisses atomic.Uint64
+	localHits, invalidations, remoteEvicts, errors     atomic.Uint64
+}
+
+// localEntry is an instance's copy of a cache entry. An entry without data marks a generation as
+// invalidated, so a slower read of an older generation cannot put stale data back.
+type localEntry struct {
+	generation int64
+	data       []byte
+	expiresAt  time.Time
+}
+
+// cacheInvalidation is the message published when an entry is invalidated
+type cacheInvalidation struct {
+	Key        string `json:"key"`
+	Generation int64  `json:"generation"`
+	Origin     string `json:"origin"`
+}
+
+// redisCache caches sessions and graphs in Redis, with an optional short-lived local copy per instance.
+//
+// Each entry has a generation counter, and its data is stored under a key that includes the
+// generation. Mutations never write data; they increment the generation after the database
+// commit, which orphans whatever was cached. A read that raced the mutation can only write the
+// generation it started with, which nobody reads any more, so stale data is never served from
+// Redis. Invalidations are published so other instances drop their local copies.
+type redisCache struct {
+	client     *redis.Client
+	ttls       map[cacheKind]time.Duration
+	localTTL   time.Duration
+	instanceID string
+	logger     *logrus.Logger
+	counters   cacheCounters
+
+	mu    sync.Mutex
+	local map[string]*localEntry
+
+	pubsub *redis.PubSub
+	done   chan struct{}
+}
+
+// newRedisCache creates a cache and, when local copies are enabled, subscribes to invalidations
+func newRedisCache(client *redis.Client, cfg config.RedisConfig, logger *logrus.Logger) *redisCache {
+	c := &redisCache{
+		client: client,
+		ttls: map[cacheKind]time.Duration{
+			cacheKindSession: cfg.SessionTTL,
+			cacheKindGraph:   cfg.GraphTTL,
+		},
+		localTTL:   cfg.LocalTTL,
+		instanceID: uuid.New().String(),
+		logger:     logger,
+		local:      make(map[string]*localEntry),
+		done:       make(chan struct{}),
+	}
+
+	if c.localTTL > 0 {
+		c.pubsub = client.Subscribe(context.Background(), cacheInvalidationChannel)
+		go c.listen()
+	} else {
+		close(c.done)
+	}
+
+	return c
+}
+
+// entryKey identifies an entry independent of its generation
+func entryKey(kind cacheKind, id string) string {
+	return fmt.Sprintf("onboarding:v%d:%s:%s", cacheKeyVersion, kind, id)
+}
+
+// generationKey holds the current generation of an entry
+func generationKey(key string) string {
+	return key + ":gen"
+}
+
+// dataKey holds an entry's data for one generation
+func dataKey(key string, generation int64) string {
+	return key + ":" + strconv.FormatInt(generation, 10)
+}
+
+// get loads an entry into target. On a miss it returns the generation to pass to set once the
+// entry has been read from the database.
+func (c *redisCache) get(ctx context.Context, kind cacheKind, id string, target interface{}) (hit bool, generation int64) {
+	if c.ttls[kind] <= 0 {
+		return false, -1
+	}
+	key := entryKey(kind, id)
+
+	if data := c.getLocal(key); data != nil {
+		if err := json.Unmarshal(data, target); err == nil {
+			c.counters.localHits.Add(1)
+			c.recordLookup(kind, true)
+			return true, 0
+		}
+	}
+
+	generation, err := c.client.Get(ctx, generationKey(key)).Int64()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		c.recordError(err, "Failed to read cache generation", key)
+		c.recordLookup(kind, false)
+		return false, -1
+	}
+
+	data, err := c.client.Get(ctx, dataKey(key, generation)).Bytes()
+	if err != nil {
+		if !errors.Is(err, redis.Nil) {
+			c.recordError(err, "Failed to read cache entry", key)
+		}
+		c.recordLookup(kind, false)
+		return false, generation
+	}
+
+	if err := json.Unmarshal(data, target); err != nil {
+		c.recordError(err, "Failed to unmarshal cache entry", key)
+		c.recordLookup(kind, false)
+		return false, generation
+	}
+
+	c.setLocal(key, generation, data)
+	c.recordLookup(kind, true)
+	return true, generation
+}
+
+// set caches an entry read from the database under the generation get returned
+func (c *redisCache) set(ctx context.Context, kind cacheKind, id string, generation int64, value interface{}) {
+	if c.ttls[kind] <= 0 || generation < 0 {
+		return
+	}
+	key := entryKey(kind, id)
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		c.recordError(err, "Failed to marshal cache entry", key)
+		return
+	}
+
+	if err := c.client.Set(ctx, dataKey(key, generation), data, c.ttls[kind]).Err(); err != nil {
+		c.recordError(err, "Failed to write cache entry", key)
+		return
+	}
+
+	c.setLocal(key, generation, data)
+}
+
+// invalidate orphans the cached entry after it changed in the database and tells other instances.
+// Call it after the change is committed.
+func (c *redisCache) invalidate(ctx context.Context, kind cacheKind, id string) {
+	if c.ttls[kind] <= 0 {
+		return
+	}
+	key := entryKey(kind, id)
+	c.counters.invalidations.Add(1)
+
+	// The generation never expires. If it did, it would restart at 0 and count up through
+	// generations whose data may still be cached. Persist also clears the expiry that keys written
+	// by earlier versions carry.
+	var incr *redis.IntCmd
+	_, err := c.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		incr = pipe.Incr(ctx, generationKey(key))
+		pipe.Persist(ctx, generationKey(key))
+		return nil
+	})
+	if err != nil {
+		// Without a new generation the entry may be stale until it expires
+		c.recordError(err, "Failed to invalidate cache entry", key)
+		c.evictLocal(key, 0)
+		return
+	}
+	generation := incr.Val()
+
+	// The previous generation can no longer be read, so free it now rather than at expiry
+	if err := c.client.Del(ctx, dataKey(key, generation-1)).Err(); err != nil {
+		c.recordError(err, "Failed to delete cache entry", key)
+	}
+
+	c.evictLocal(key, generation)
+	c.publish(ctx, key, generation)
+}
+
+// publish announces an invalidation to the other instances
+func (c *redisCache) publish(ctx context.Context, key string, generation int64) {
+	if c.localTTL <= 0 {
+		return
+	}
+
+	message, err := json.Marshal(cacheInvalidation{Key: key, Generation: generation, Origin: c.instanceID})
+	if err != nil {
+		c.recordError(err, "Failed to marshal cache invalidation", key)
+		return
+	}
+	if err := c.client.Publish(ctx, cacheInvalidationChannel, message).Err(); err != nil {
+		c.recordError(err, "Failed to publish cache invalidation", key)
+	}
+}
+
+// listen drops local copies invalidated by other instances until the cache is closed
+func (c *redisCache) listen() {
+	defer close(c.done)
+
+	for message := range c.pubsub.Channel() {
+		var invalidation cacheInvalidation
+		if err := json.Unmarshal([]byte(message.Payload), &invalidation); err != nil {
+			c.recordError(err, "Failed to unmarshal cache invalidation", message.Payload)
+			continue
+		}
+		if invalidation.Origin == c.instanceID {
+			continue
+		}
+
+		c.evictLocal(invalidation.Key, invalidation.Generation)
+		c.counters.remoteEvicts.Add(1)
+	}
+}
+
+// getLocal returns the instance's copy of an entry, if it has a live one
+func (c *redisCache) getLocal(key string) []byte {
+	if c.localTTL <= 0 {
+		return nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.local[key]
+	if !ok || time.Now().After(entry.expiresAt) {
+		return nil
+	}
+	return entry.data
+}
+
+// setLocal keeps a copy of an entry unless a newer generation has been seen
+func (c *redisCache) setLocal(key string, generation int64, data []byte) {
+	if c.localTTL <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if entry, ok := c.local[key]; ok && entry.generation > generation && time.Now().Before(entry.expiresAt) {
+		return
+	}
+	c.storeLocal(key, &localEntry{generation: generation, data: data, expiresAt: time.Now().Add(c.localTTL)})
+}
+
+// evictLocal drops the instance's copy of an entry and remembers the generation that replaced it
+func (c *redisCache) evictLocal(key string, generation int64) {
+	if c.localTTL <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.storeLocal(key, &localEntry{generation: generation, expiresAt: time.Now().Add(c.localTTL)})
+}
+
+// storeLocal saves a local entry, making room first if the cache is full. Callers hold mu.
+func (c *redisCache) storeLocal(key string, entry *localEntry) {
+	if _, exists := c.local[key]; !exists && len(c.local) >= maxLocalCacheEntries {
+		now := time.Now()
+		for existing, candidate := range c.local {
+			if now.After(candidate.expiresAt) {
+				delete(c.local, existing)
+			}
+		}
+		// Nothing has expired; drop an arbitrary entry
+		if len(c.local) >= maxLocalCacheEntries {
+			for existing := range c.local {
+				delete(c.local, existing)
+				break
+			}
+		}
+	}
+	c.local[key] = entry
+}
+
+// recordLookup counts a hit or miss
+func (c *redisCache) recordLookup(kind cacheKind, hit bool) {
+	switch {
+	case kind == cacheKindSession && hit:
+		c.counters.sessionHits.Add(1)
+	case kind == cacheKindSession:
+		c.counters.sessionMisses.Add(1)
+	case hit:
+		c.counters.graphHits.Add(1)
+	default:
+		c.counters.graphMisses.Add(1)
+	}
+}
+
+// recordError counts and logs a cache failure; the cache is never required for correctness
+func (c *redisCache) recordError(err error, message, key string) {
+	c.counters.errors.Add(1)
+	c.logger.WithError(err).WithField("key", key).Warn(message)
+}
+
+// stats returns a snapshot of the counters
+func (c *redisCache) stats() CacheStats {
+	return CacheStats{
+		SessionHits:   c.counters.sessionHits.Load(),
+		SessionMisses: c.counters.sessionMisses.Load(),
+		GraphHits:     c.counters.graphHits.Load(),
+		GraphMisses:   c.counters.graphMisses.Load(),
+		LocalHits:     c.counters.localHits.Load(),
+		Invalidations: c.counters.invalidations.Load(),
+		RemoteEvicts:  c.counters.remoteEvicts.Load(),
+		Errors:        c.counters.errors.Load(),
+	}
+}
+
+// close stops listening for invalidations
+func (c *redisCache) close() error {
+	if c.pubsub == nil {
+		return nil
+	}
+	err := c.pubsub.Close()
+	<-c.done
+	return err
+}
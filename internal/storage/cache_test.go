@@ -0,0 +1,162 @@
+package storage
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"onboarding-system/internal/config"
+	"onboarding-system/internal/types"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestCache(t *testing.T, server *miniredis.Miniredis, cfg config.RedisConfig) *redisCache {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	cache := newRedisCache(client, cfg, logger)
+	t.Cleanup(func() {
+		cache.close()
+		client.Close()
+	})
+	return cache
+}
+
+func testCacheConfig(localTTL time.Duration) config.RedisConfig {
+	return config.RedisConfig{SessionTTL: time.Hour, GraphTTL: time.Hour, LocalTTL: localTTL}
+}
+
+func TestCacheHitMissAndInvalidate(t *testing.T) {
+	ctx := context.Background()
+	server := miniredis.RunT(t)
+	cache := newTestCache(t, server, testCacheConfig(0))
+
+	var session types.Session
+	hit, generation := cache.get(ctx, cacheKindSession, "session-1", &session)
+	assert.False(t, hit)
+	assert.Equal(t, int64(0), generation)
+
+	cache.set(ctx, cacheKindSession, "session-1", generation, &types.Session{ID: "session-1", Revision: 1})
+	assert.Equal(t, time.Hour, server.TTL("onboarding:v1:session:session-1:0"), "keys carry the key version and generation")
+
+	hit, _ = cache.get(ctx, cacheKindSession, "session-1", &session)
+	require.True(t, hit)
+	assert.Equal(t, 1, session.Revision)
+
+	cache.invalidate(ctx, cacheKindSession, "session-1")
+	assert.False(t, server.Exists("onboarding:v1:session:session-1:0"), "the invalidated generation is deleted")
+
+	hit, generation = cache.get(ctx, cacheKindSession, "session-1", &types.Session{})
+	assert.False(t, hit)
+	assert.Equal(t, int64(1), generation)
+
+	// Graphs are counted separately
+	hit, _ = cache.get(ctx, cacheKindGraph, "graph-1", &types.Graph{})
+	assert.False(t, hit)
+
+	assert.Equal(t, CacheStats{SessionHits: 1, SessionMisses: 2, GraphMisses: 1, Invalidations: 1}, cache.stats())
+}
+
+func TestCacheDropsReadsThatRacedAnInvalidation(t *testing.T) {
+	ctx := context.Background()
+	server := miniredis.RunT(t)
+	cache := newTestCache(t, server, testCacheConfig(time.Minute))
+
+	// A reader misses and loads revision 1 from the database...
+	hit, generation := cache.get(ctx, cacheKindSession, "session-1", &types.Session{})
+	require.False(t, hit)
+
+	// ...a writer commits revision 2 and invalidates...
+	cache.invalidate(ctx, cacheKindSession, "session-1")
+
+	// ...and the reader caches what it loaded, which must not be served
+	cache.set(ctx, cacheKindSession, "session-1", generation, &types.Session{ID: "session-1", Revision: 1})
+
+	hit, _ = cache.get(ctx, cacheKindSession, "session-1", &types.Session{})
+	assert.False(t, hit)
+}
+
+func TestCacheGenerationsNeverRepeat(t *testing.T) {
+	ctx := context.Background()
+	server := miniredis.RunT(t)
+	cache := newTestCache(t, server, testCacheConfig(0))
+
+	// A generation key written by an earlier version still carries an expiry
+	require.NoError(t, server.Set("onboarding:v1:session:session-1:gen", "0"))
+	server.SetTTL("onboarding:v1:session:session-1:gen", 2*time.Hour)
+
+	cache.invalidate(ctx, cacheKindSession, "session-1")
+	assert.Zero(t, server.TTL("onboarding:v1:session:session-1:gen"), "invalidating clears the expiry")
+
+	_, generation := cache.get(ctx, cacheKindSession, "session-1", &types.Session{})
+	cache.set(ctx, cacheKindSession, "session-1", generation, &types.Session{ID: "session-1", Revision: 2})
+	cache.invalidate(ctx, cacheKindSession, "session-1")
+
+	// Long after every entry would have expired, an older generation is still never current
+	server.FastForward(3 * time.Hour)
+	hit, generation := cache.get(ctx, cacheKindSession, "session-1", &types.Session{})
+	assert.False(t, hit)
+	assert.Equal(t, int64(2), generation)
+}
+
+func TestCacheInvalidatesOtherInstances(t *testing.T) {
+	ctx := context.Background()
+	server := miniredis.RunT(t)
+	first := newTestCache(t, server, testCacheConfig(time.Minute))
+	second := newTestCache(t, server, testCacheConfig(time.Minute))
+
+	_, generation := first.get(ctx, cacheKindGraph, "graph-1", &types.Graph{})
+	first.set(ctx, cacheKindGraph, "graph-1", generation, &types.Graph{ID: "graph-1", Name: "v1"})
+
+	// The first instance now serves its own copy without Redis
+	server.Del("onboarding:v1:graph:graph-1:0")
+	var graph types.Graph
+	hit, _ := first.get(ctx, cacheKindGraph, "graph-1", &graph)
+	require.True(t, hit)
+	assert.Equal(t, "v1", graph.Name)
+	assert.Equal(t, uint64(1), first.stats().LocalHits)
+
+	second.invalidate(ctx, cacheKindGraph, "graph-1")
+
+	require.Eventually(t, func() bool { return first.stats().RemoteEvicts == 1 }, time.Second, 10*time.Millisecond)
+	hit, _ = first.get(ctx, cacheKindGraph, "graph-1", &types.Graph{})
+	assert.False(t, hit, "the local copy is dropped when another instance invalidates it")
+	assert.Equal(t, uint64(0), second.stats().RemoteEvicts, "instances ignore their own invalidations")
+}
+
+func TestCacheDisabledByZeroTTL(t *testing.T) {
+	ctx := context.Background()
+	server := miniredis.RunT(t)
+	cache := newTestCache(t, server, config.RedisConfig{GraphTTL: time.Hour})
+
+	hit, generation := cache.get(ctx, cacheKindSession, "session-1", &types.Session{})
+	assert.False(t, hit)
+	cache.set(ctx, cacheKindSession, "session-1", generation, &types.Session{ID: "session-1"})
+	cache.invalidate(ctx, cacheKindSession, "session-1")
+
+	assert.Empty(t, server.Keys())
+	assert.Equal(t, CacheStats{}, cache.stats(), "disabled lookups are not counted")
+}
+
+func TestCacheErrorsFallBackToMisses(t *testing.T) {
+	ctx := context.Background()
+	server := miniredis.RunT(t)
+	cache := newTestCache(t, server, testCacheConfig(0))
+	server.Close()
+
+	hit, generation := cache.get(ctx, cacheKindSession, "session-1", &types.Session{})
+	assert.False(t, hit)
+	cache.set(ctx, cacheKindSession, "session-1", generation, &types.Session{ID: "session-1"})
+	cache.invalidate(ctx, cacheKindSession, "session-1")
+
+	stats := cache.stats()
+	assert.Equal(t, uint64(1), stats.SessionMisses)
+	assert.Equal(t, uint64(2), stats.Errors, "the failed read and the failed invalidation are counted")
+}
@@ -0,0 +1,43 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+
+	"onboarding-system/internal/types"
+)
+
+// ErrSessionConflict is returned when a session is saved from a revision that is no longer current
+var ErrSessionConflict = errors.New("session was modified concurrently")
+
+// SessionConflictError carries the stored session a save conflicted with, so callers can reconcile
+type SessionConflictError struct {
+	SessionID string
+	Revision  int            // Revision the caller saved from
+	Current   *types.Session // Session as currently stored
+}
+
+func (e *SessionConflictError) Error() string {
+	return fmt.Sprintf("%v: session %s is at revision %d, not %d", ErrSessionConflict, e.SessionID, e.Current.Revision, e.Revision)
+}
+
+func (e *SessionConflictError) Unwrap() error {
+	return ErrSessionConflict
+}
+
+// ErrGraphVersionExists is returned when saving a graph revision that is already published
+var ErrGraphVersionExists = errors.New("graph version already exists")
+
+// GraphVersionExistsError names the revision that was published first
+type GraphVersionExistsError struct {
+	GraphID  string
+	Revision int
+}
+
+func (e *GraphVersionExistsError) Error() string {
+	return fmt.Sprintf("graph version %s@%d already exists", e.GraphID, e.Revision)
+}
+
+func (e *GraphVersionExistsError) Unwrap() error {
+	return ErrGraphVersionExists
+}
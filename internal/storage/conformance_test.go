@@ -0,0 +1,54 @@
+package storage_test
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"onboarding-system/internal/config"
+	"onboarding-system/internal/storage"
+	"onboarding-system/internal/storage/storagetest"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+)
+
+func testLogger() *logrus.Logger {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	return logger
+}
+
+func TestMemoryStorageConformance(t *testing.T) {
+	storagetest.Run(t, func(t *testing.T) storage.Storage {
+		return storage.NewMemoryStorage(testLogger())
+	})
+}
+
+func TestSQLiteStorageConformance(t *testing.T) {
+	storagetest.Run(t, func(t *testing.T) storage.Storage {
+		store, err := storage.NewSQLiteStorage(filepath.Join(t.TempDir(), "onboarding.db"), testLogger())
+		require.NoError(t, err)
+		t.Cleanup(func() { store.Close() })
+		return store
+	})
+}
+
+// TestPostgresStorageConformance runs against the PostgreSQL database named by the DB_* variables,
+// for example one started with docker-compose. Redis is optional; without it every read is a cache miss.
+func TestPostgresStorageConformance(t *testing.T) {
+	if os.Getenv("DB_HOST") == "" {
+		t.Skip("DB_HOST not set; start PostgreSQL and set DB_HOST, DB_USER, DB_PASSWORD and DB_NAME to run")
+	}
+
+	cfg, err := config.Load()
+	require.NoError(t, err)
+
+	storagetest.Run(t, func(t *testing.T) storage.Storage {
+		store, err := storage.NewPostgresRedisStorage(cfg, testLogger())
+		require.NoError(t, err)
+		t.Cleanup(func() { store.Close() })
+		return store
+	})
+}
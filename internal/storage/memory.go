@@ -2,7 +2,9 @@ package storage
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"sort"
 	"sync"
 	"time"
 
@@ -11,30 +13,92 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
+// graphVersion is an immutable graph snapshot and the time it was saved
+type graphVersion struct {
+	graph     *types.Graph
+	createdAt time.Time
+}
+
 // MemoryStorage implements Storage using in-memory data structures
 type MemoryStorage struct {
 	graphs   map[string]*types.Graph
+	versions map[string]map[int]*graphVersion
 	sessions map[string]*types.Session
+	events   map[string][]*types.SessionEvent
+	outbox   map[string]*types.OutboxMessage
 	mutex    sync.RWMutex
 	logger   *logrus.Logger
+
+	outboxDisabled bool
 }
 
 // NewMemoryStorage creates a new in-memory storage instance
 func NewMemoryStorage(logger *logrus.Logger) *MemoryStorage {
 	return &MemoryStorage{
 		graphs:   make(map[string]*types.Graph),
+		versions: make(map[string]map[int]*graphVersion),
 		sessions: make(map[string]*types.Session),
+		events:   make(map[string][]*types.SessionEvent),
+		outbox:   make(map[string]*types.OutboxMessage),
 		logger:   logger,
 	}
 }
 
-// SaveSession saves a session to memory
+// SaveSession saves a copy of a session to memory
 func (m *MemoryStorage) SaveSession(ctx context.Context, session *types.Session) error {
+	return m.SaveSessionWithEvents(ctx, session, nil)
+}
+
+// SaveSessionWithEvents saves a copy of a session and appends copies of events to its log
+func (m *MemoryStorage) SaveSessionWithEvents(ctx context.Context, session *types.Session, events []*types.SessionEvent) error {
 	m.mutex.Lock()
 	defer m.mutex.Unlock()
 
-	session.UpdatedAt = time.Now()
-	m.sessions[session.ID] = session
+	if existing, exists := m.sessions[session.ID]; exists && existing.Revision != session.Revision {
+		current, err := copySession(existing)
+		if err != nil {
+			return err
+		}
+		return &SessionConflictError{SessionID: session.ID, Revision: session.Revision, Current: current}
+	}
+
+	stored, err := copySession(session)
+	if err != nil {
+		return err
+	}
+	stored.Revision++
+
+	// Copy the events before storing anything so a failed copy leaves the session unchanged
+	now := time.Now()
+	eventLog := m.events[session.ID]
+	appended := make([]*types.SessionEvent, 0, len(events))
+	messages := make([]*types.OutboxMessage, 0, len(events))
+	for i, event := range events {
+		event.SessionID = session.ID
+		event.Sequence = len(eventLog) + i + 1
+		event.Revision = stored.Revision
+		eventCopy, err := copySessionEvent(event)
+		if err != nil {
+			return err
+		}
+		appended = append(appended, eventCopy)
+
+		if m.outboxDisabled {
+			continue
+		}
+		outboxEvent, err := copySessionEvent(event)
+		if err != nil {
+			return err
+		}
+		messages = append(messages, newOutboxMessage(outboxEvent, now))
+	}
+
+	m.sessions[session.ID] = stored
+	m.events[session.ID] = append(eventLog, appended...)
+	for _, message := range messages {
+		m.outbox[message.ID] = message
+	}
+	session.Revision = stored.Revision
 
 	m.logger.WithFields(logrus.Fields{
 		"session_id": session.ID,
@@ -55,9 +119,8 @@ func (m *MemoryStorage) GetSession(ctx context.Context, sessionID string) (*type
 		return nil, fmt.Errorf("session not found")
 	}
 
-	// Return a copy to avoid race conditions
-	sessionCopy := *session
-	return &sessionCopy, nil
+	// Return a copy so callers cannot change the stored session without saving it
+	return copySession(session)
 }
 
 // UpdateSession updates a session in memory
@@ -71,6 +134,7 @@ func (m *MemoryStorage) DeleteSession(ctx context.Context, sessionID string) err
 	defer m.mutex.Unlock()
 
 	delete(m.sessions, sessionID)
+	delete(m.events, sessionID)
 
 	m.logger.WithField("session_id", sessionID).Debug("Session deleted from memory")
 	return nil
@@ -84,9 +148,11 @@ func (m *MemoryStorage) ListSessions(ctx context.Context, userID string) ([]*typ
 	var userSessions []*types.Session
 	for _, session := range m.sessions {
 		if session.UserID == userID {
-			// Return a copy to avoid race conditions
-			sessionCopy := *session
-			userSessions = append(userSessions, &sessionCopy)
+			sessionCopy, err := copySession(session)
+			if err != nil {
+				return nil, err
+			}
+			userSessions = append(userSessions, sessionCopy)
 		}
 	}
 
@@ -100,21 +166,200 @@ func (m *MemoryStorage) ListAllSessions(ctx context.Context) ([]*types.Session,
 
 	var allSessions []*types.Session
 	for _, session := range m.sessions {
-		// Return a copy to avoid race conditions
-		sessionCopy := *session
-		allSessions = append(allSessions, &sessionCopy)
+		sessionCopy, err := copySession(session)
+		if err != nil {
+			return nil, err
+		}
+		allSessions = append(allSessions, sessionCopy)
 	}
 
 	return allSessions, nil
 }
 
-// SaveGraph saves a graph to memory
+// ListSessionsQuery returns copies of one page of the sessions matching query
+func (m *MemoryStorage) ListSessionsQuery(ctx context.Context, query SessionQuery) (*SessionPage, error) {
+	query, cursor, err := query.normalize()
+	if err != nil {
+		return nil, err
+	}
+
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	var matched []*types.Session
+	for _, session := range m.sessions {
+		if query.matches(session) && (cursor == nil || query.after(session, cursor)) {
+			matched = append(matched, session)
+		}
+	}
+
+	query.sortSessions(matched)
+	if len(matched) > query.Limit+1 {
+		matched = matched[:query.Limit+1]
+	}
+
+	sessions := make([]*types.Session, 0, len(matched))
+	for _, session := range matched {
+		sessionCopy, err := copySession(session)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, sessionCopy)
+	}
+
+	return query.page(sessions)
+}
+
+// ListSessionEvents returns copies of a session's events in the order they were saved
+func (m *MemoryStorage) ListSessionEvents(ctx context.Context, sessionID string) ([]*types.SessionEvent, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	events := make([]*types.SessionEvent, 0, len(m.events[sessionID]))
+	for _, event := range m.events[sessionID] {
+		eventCopy, err := copySessionEvent(event)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, eventCopy)
+	}
+
+	return events, nil
+}
+
+// ClaimOutboxMessages returns copies of the oldest pending messages that are due and defers them by lease
+func (m *MemoryStorage) ClaimOutboxMessages(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*types.OutboxMessage, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	due := make([]*types.OutboxMessage, 0)
+	for _, message := range m.outbox {
+		if message.Status == types.OutboxStatusPending && !message.NextAttemptAt.After(now) {
+			due = append(due, message)
+		}
+	}
+	sortOutboxMessages(due)
+	if len(due) > limit {
+		due = due[:limit]
+	}
+
+	claimed := make([]*types.OutboxMessage, 0, len(due))
+	for _, message := range due {
+		message.NextAttemptAt = now.Add(lease)
+		messageCopy, err := copyOutboxMessage(message)
+		if err != nil {
+			return nil, err
+		}
+		claimed = append(claimed, messageCopy)
+	}
+
+	return claimed, nil
+}
+
+// UpdateOutboxMessage saves a copy of an outbox message's delivery state
+func (m *MemoryStorage) UpdateOutboxMessage(ctx context.Context, message *types.OutboxMessage) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if _, exists := m.outbox[message.ID]; !exists {
+		return fmt.Errorf("outbox message not found")
+	}
+
+	message.UpdatedAt = time.Now()
+	stored, err := copyOutboxMessage(message)
+	if err != nil {
+		return err
+	}
+	m.outbox[message.ID] = stored
+	return nil
+}
+
+// GetOutboxMessage retrieves a copy of an outbox message
+func (m *MemoryStorage) GetOutboxMessage(ctx context.Context, id string) (*types.OutboxMessage, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	message, exists := m.outbox[id]
+	if !exists {
+		return nil, fmt.Errorf("outbox message not found")
+	}
+	return copyOutboxMessage(message)
+}
+
+// ListOutboxMessages returns copies of the oldest outbox messages with the given status
+func (m *MemoryStorage) ListOutboxMessages(ctx context.Context, status types.OutboxStatus, limit int) ([]*types.OutboxMessage, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	matching := make([]*types.OutboxMessage, 0)
+	for _, message := range m.outbox {
+		if message.Status == status {
+			matching = append(matching, message)
+		}
+	}
+	sortOutboxMessages(matching)
+	if len(matching) > limit {
+		matching = matching[:limit]
+	}
+
+	messages := make([]*types.OutboxMessage, 0, len(matching))
+	for _, message := range matching {
+		messageCopy, err := copyOutboxMessage(message)
+		if err != nil {
+			return nil, err
+		}
+		messages = append(messages, messageCopy)
+	}
+
+	return messages, nil
+}
+
+// PruneOutboxMessages deletes the messages with status last updated before the cutoff
+func (m *MemoryStorage) PruneOutboxMessages(ctx context.Context, status types.OutboxStatus, before time.Time) (int, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	pruned := 0
+	for id, message := range m.outbox {
+		if message.Status == status && message.UpdatedAt.Before(before) {
+			delete(m.outbox, id)
+			pruned++
+		}
+	}
+	return pruned, nil
+}
+
+// DisableOutbox stops adding saved events to the outbox
+func (m *MemoryStorage) DisableOutbox() {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.outboxDisabled = true
+}
+
+// sortOutboxMessages orders messages oldest first
+func sortOutboxMessages(messages []*types.OutboxMessage) {
+	sort.Slice(messages, func(i, j int) bool {
+		if !messages[i].CreatedAt.Equal(messages[j].CreatedAt) {
+			return messages[i].CreatedAt.Before(messages[j].CreatedAt)
+		}
+		if messages[i].SessionID != messages[j].SessionID {
+			return messages[i].SessionID < messages[j].SessionID
+		}
+		return messages[i].Event.Sequence < messages[j].Event.Sequence
+	})
+}
+
+// SaveGraph saves a copy of a graph to memory
 func (m *MemoryStorage) SaveGraph(ctx context.Context, graph *types.Graph) error {
 	m.mutex.Lock()
 	defer m.mutex.Unlock()
 
-	graph.UpdatedAt = time.Now()
-	m.graphs[graph.ID] = graph
+	stored, err := copyGraph(graph)
+	if err != nil {
+		return err
+	}
+	m.graphs[graph.ID] = stored
 
 	m.logger.WithFields(logrus.Fields{
 		"graph_id": graph.ID,
@@ -134,9 +379,8 @@ func (m *MemoryStorage) GetGraph(ctx context.Context, graphID string) (*types.Gr
 		return nil, fmt.Errorf("graph not found")
 	}
 
-	// Return a copy to avoid race conditions
-	graphCopy := *graph
-	return &graphCopy, nil
+	// Return a copy so callers cannot change the stored graph without saving it
+	return copyGraph(graph)
 }
 
 // UpdateGraph updates a graph in memory
@@ -150,6 +394,7 @@ func (m *MemoryStorage) DeleteGraph(ctx context.Context, graphID string) error {
 	defer m.mutex.Unlock()
 
 	delete(m.graphs, graphID)
+	delete(m.versions, graphID)
 
 	m.logger.WithField("graph_id", graphID).Debug("Graph deleted from memory")
 	return nil
@@ -162,14 +407,171 @@ func (m *MemoryStorage) ListGraphs(ctx context.Context) ([]*types.Graph, error)
 
 	var graphs []*types.Graph
 	for _, graph := range m.graphs {
-		// Return a copy to avoid race conditions
-		graphCopy := *graph
-		graphs = append(graphs, &graphCopy)
+		graphCopy, err := copyGraph(graph)
+		if err != nil {
+			return nil, err
+		}
+		graphs = append(graphs, graphCopy)
 	}
 
 	return graphs, nil
 }
 
+// SaveGraphVersion stores an immutable snapshot of a graph revision
+func (m *MemoryStorage) SaveGraphVersion(ctx context.Context, graph *types.Graph) error {
+	snapshot, err := copyGraph(graph)
+	if err != nil {
+		return err
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if err := m.saveGraphVersion(snapshot); err != nil {
+		return err
+	}
+
+	m.logger.WithFields(logrus.Fields{
+		"graph_id": graph.ID,
+		"revision": graph.Revision,
+	}).Debug("Graph version saved to memory")
+
+	return nil
+}
+
+// PublishGraphVersion saves a graph revision and makes it the default graph in one step
+func (m *MemoryStorage) PublishGraphVersion(ctx context.Context, graph *types.Graph) error {
+	snapshot, err := copyGraph(graph)
+	if err != nil {
+		return err
+	}
+	stored, err := copyGraph(graph)
+	if err != nil {
+		return err
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if err := m.saveGraphVersion(snapshot); err != nil {
+		return err
+	}
+	m.graphs[graph.ID] = stored
+
+	m.logger.WithFields(logrus.Fields{
+		"graph_id": graph.ID,
+		"revision": graph.Revision,
+	}).Debug("Graph version published to memory")
+
+	return nil
+}
+
+// saveGraphVersion stores a snapshot unless its revision exists; the caller holds the lock
+func (m *MemoryStorage) saveGraphVersion(snapshot *types.Graph) error {
+	revisions, exists := m.versions[snapshot.ID]
+	if !exists {
+		revisions = make(map[int]*graphVersion)
+		m.versions[snapshot.ID] = revisions
+	}
+	if _, exists := revisions[snapshot.Revision]; exists {
+		return &GraphVersionExistsError{GraphID: snapshot.ID, Revision: snapshot.Revision}
+	}
+	revisions[snapshot.Revision] = &graphVersion{graph: snapshot, createdAt: time.Now()}
+	return nil
+}
+
+// GetGraphVersion retrieves a specific revision of a graph
+func (m *MemoryStorage) GetGraphVersion(ctx context.Context, graphID string, revision int) (*types.Graph, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	version, exists := m.versions[graphID][revision]
+	if !exists {
+		return nil, fmt.Errorf("graph version not found")
+	}
+
+	// Versions are immutable, so callers get their own copy
+	return copyGraph(version.graph)
+}
+
+// ListGraphVersions lists all revisions of a graph, oldest first
+func (m *MemoryStorage) ListGraphVersions(ctx context.Context, graphID string) ([]*types.GraphVersion, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	versions := make([]*types.GraphVersion, 0, len(m.versions[graphID]))
+	for _, version := range m.versions[graphID] {
+		versions = append(versions, &types.GraphVersion{
+			GraphID:   version.graph.ID,
+			Revision:  version.graph.Revision,
+			Name:      version.graph.Name,
+			Version:   version.graph.Version,
+			CreatedAt: version.createdAt,
+		})
+	}
+	sort.Slice(versions, func(i, j int) bool {
+		return versions[i].Revision < versions[j].Revision
+	})
+
+	return versions, nil
+}
+
+// copyGraph returns a deep copy of a graph
+func copyGraph(graph *types.Graph) (*types.Graph, error) {
+	data, err := json.Marshal(graph)
+	if err != nil {
+		return nil, fmt.Errorf("failed to copy graph: %w", err)
+	}
+
+	var graphCopy types.Graph
+	if err := json.Unmarshal(data, &graphCopy); err != nil {
+		return nil, fmt.Errorf("failed to copy graph: %w", err)
+	}
+	return &graphCopy, nil
+}
+
+// copySession returns a deep copy of a session
+func copySession(session *types.Session) (*types.Session, error) {
+	data, err := json.Marshal(session)
+	if err != nil {
+		return nil, fmt.Errorf("failed to copy session: %w", err)
+	}
+
+	var sessionCopy types.Session
+	if err := json.Unmarshal(data, &sessionCopy); err != nil {
+		return nil, fmt.Errorf("failed to copy session: %w", err)
+	}
+	return &sessionCopy, nil
+}
+
+// copySessionEvent returns a deep copy of a session event
+func copySessionEvent(event *types.SessionEvent) (*types.SessionEvent, error) {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return nil, fmt.Errorf("failed to copy session event: %w", err)
+	}
+
+	var eventCopy types.SessionEvent
+	if err := json.Unmarshal(data, &eventCopy); err != nil {
+		return nil, fmt.Errorf("failed to copy session event: %w", err)
+	}
+	return &eventCopy, nil
+}
+
+// copyOutboxMessage returns a deep copy of an outbox message
+func copyOutboxMessage(message *types.OutboxMessage) (*types.OutboxMessage, error) {
+	data, err := json.Marshal(message)
+	if err != nil {
+		return nil, fmt.Errorf("failed to copy outbox message: %w", err)
+	}
+
+	var messageCopy types.OutboxMessage
+	if err := json.Unmarshal(data, &messageCopy); err != nil {
+		return nil, fmt.Errorf("failed to copy outbox message: %w", err)
+	}
+	return &messageCopy, nil
+}
+
 // Close closes the memory storage (no-op for in-memory)
 func (m *MemoryStorage) Close() error {
 	m.logger.Info("Memory storage closed")
@@ -194,7 +596,10 @@ func (m *MemoryStorage) ClearAll() {
 	defer m.mutex.Unlock()
 
 	m.graphs = make(map[string]*types.Graph)
+	m.versions = make(map[string]map[int]*graphVersion)
 	m.sessions = make(map[string]*types.Session)
+	m.events = make(map[string][]*types.SessionEvent)
+	m.outbox = make(map[string]*types.OutboxMessage)
 
 	m.logger.Info("All data cleared from memory storage")
 }
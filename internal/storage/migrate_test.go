@@ -0,0 +1,52 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMigrate(t *testing.T) {
+	ctx := context.Background()
+	db, err := sql.Open("sqlite", "file::memory:")
+	require.NoError(t, err)
+	db.SetMaxOpenConns(1)
+	defer db.Close()
+
+	migrations := []migration{
+		{version: 1, name: "create", statements: []string{`CREATE TABLE items (id TEXT PRIMARY KEY)`}},
+		{version: 2, name: "broken", statements: []string{`ALTER TABLE items ADD COLUMN name TEXT`, `ALTER TABLE missing ADD COLUMN name TEXT`}},
+	}
+
+	applied, err := migrate(ctx, db, migrations)
+	assert.Equal(t, 1, applied)
+	assert.ErrorContains(t, err, "migration 2 (broken) failed")
+
+	// The failed migration was rolled back as a whole and is retried on the next run
+	_, err = db.ExecContext(ctx, `SELECT name FROM items`)
+	assert.Error(t, err, "column from the failed migration was rolled back")
+
+	migrations[1].statements = migrations[1].statements[:1]
+	applied, err = migrate(ctx, db, migrations)
+	require.NoError(t, err)
+	assert.Equal(t, 1, applied)
+
+	applied, err = migrate(ctx, db, migrations)
+	require.NoError(t, err)
+	assert.Zero(t, applied)
+
+	_, err = migrate(ctx, db, []migration{{version: 2, name: "b"}, {version: 1, name: "a"}})
+	assert.ErrorContains(t, err, "out of order")
+}
+
+func TestMigrationsAreOrdered(t *testing.T) {
+	for name, migrations := range map[string][]migration{"postgres": postgresMigrations, "sqlite": sqliteMigrations} {
+		for i, m := range migrations {
+			assert.Equal(t, i+1, m.version, "%s migration %q", name, m.name)
+			assert.NotEmpty(t, m.statements, "%s migration %q", name, m.name)
+		}
+	}
+}
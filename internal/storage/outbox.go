@@ -0,0 +1,63 @@
+package storage
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"onboarding-system/internal/types"
+)
+
+// newOutboxMessage returns the pending outbox message that delivers a saved event
+func newOutboxMessage(event *types.SessionEvent, now time.Time) *types.OutboxMessage {
+	return &types.OutboxMessage{
+		ID:            event.ID,
+		SessionID:     event.SessionID,
+		EventType:     event.Type,
+		Event:         event,
+		Status:        types.OutboxStatusPending,
+		NextAttemptAt: now,
+		DeliveredTo:   make([]string, 0),
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+}
+
+// outboxColumns lists the outbox columns in the order scanOutboxMessage expects
+const outboxColumns = `id, session_id, event_type, event, status, attempts, next_attempt_at, last_error, delivered_to, created_at, updated_at`
+
+// scanOutboxMessage scans a row selected with outboxColumns
+func scanOutboxMessage(row rowScanner) (*types.OutboxMessage, error) {
+	var message types.OutboxMessage
+	var eventJSON, deliveredToJSON []byte
+
+	err := row.Scan(
+		&message.ID, &message.SessionID, &message.EventType, &eventJSON, &message.Status, &message.Attempts,
+		&message.NextAttemptAt, &message.LastError, &deliveredToJSON, &message.CreatedAt, &message.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(eventJSON, &message.Event); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal outbox event: %w", err)
+	}
+	if err := json.Unmarshal(deliveredToJSON, &message.DeliveredTo); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal outbox deliveries: %w", err)
+	}
+
+	return &message, nil
+}
+
+// scanOutboxMessages reads rows selected with outboxColumns
+func scanOutboxMessages(rows *sql.Rows) ([]*types.OutboxMessage, error) {
+	messages := make([]*types.OutboxMessage, 0)
+	for rows.Next() {
+		message, err := scanOutboxMessage(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan outbox message: %w", err)
+		}
+		messages = append(messages, message)
+	}
+	return messages, rows.Err()
+}
@@ -0,0 +1,261 @@
+package storage
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"onboarding-system/internal/types"
+)
+
+// SessionSortField is a timestamp sessions can be listed by
+type SessionSortField string
+
+const (
+	SessionSortCreatedAt SessionSortField = "created_at"
+	SessionSortUpdatedAt SessionSortField = "updated_at"
+)
+
+// Page sizes for ListSessionsQuery
+const (
+	DefaultSessionPageSize = 50
+	MaxSessionPageSize     = 500
+)
+
+// ErrInvalidCursor is returned for a cursor that is malformed or was issued for a different sort
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// SessionQuery selects one page of sessions. Empty fields do not filter; time ranges include
+// their start and exclude their end.
+type SessionQuery struct {
+	UserID        string
+	GraphID       string
+	Statuses      []types.SessionStatus // Matches any of the statuses
+	BusinessType  string                // Matches the business_type in the session data
+	CurrentNodeID string
+	CreatedFrom   time.Time
+	CreatedTo     time.Time
+	UpdatedFrom   time.Time
+	UpdatedTo     time.Time
+	SortBy        SessionSortField // Defaults to created_at
+	Ascending     bool             // Newest first unless set
+	Limit         int              // Defaults to DefaultSessionPageSize, capped at MaxSessionPageSize
+	Cursor        string           // NextCursor of the previous page
+}
+
+// SessionPage is one page of a session listing
+type SessionPage struct {
+	Sessions   []*types.Session `json:"sessions"`
+	NextCursor string           `json:"next_cursor,omitempty"` // Empty on the last page
+}
+
+// sessionCursor is the sort position of the last session on a page
+type sessionCursor struct {
+	SortBy    SessionSortField `json:"s"`
+	Ascending bool             `json:"a"`
+	Time      time.Time        `json:"t"`
+	ID        string           `json:"i"`
+}
+
+// normalize applies the query defaults and decodes its cursor
+func (q SessionQuery) normalize() (SessionQuery, *sessionCursor, error) {
+	switch q.SortBy {
+	case "":
+		q.SortBy = SessionSortCreatedAt
+	case SessionSortCreatedAt, SessionSortUpdatedAt:
+	default:
+		return q, nil, fmt.Errorf("invalid sort field %q", q.SortBy)
+	}
+
+	if q.Limit <= 0 {
+		q.Limit = DefaultSessionPageSize
+	}
+	if q.Limit > MaxSessionPageSize {
+		q.Limit = MaxSessionPageSize
+	}
+
+	if q.Cursor == "" {
+		return q, nil, nil
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(q.Cursor)
+	if err != nil {
+		return q, nil, ErrInvalidCursor
+	}
+	var cursor sessionCursor
+	if err := json.Unmarshal(data, &cursor); err != nil || cursor.ID == "" {
+		return q, nil, ErrInvalidCursor
+	}
+	if cursor.SortBy != q.SortBy || cursor.Ascending != q.Ascending {
+		return q, nil, ErrInvalidCursor
+	}
+
+	return q, &cursor, nil
+}
+
+// sortTime returns the timestamp a session is sorted by
+func (q SessionQuery) sortTime(session *types.Session) time.Time {
+	if q.SortBy == SessionSortUpdatedAt {
+		return session.UpdatedAt
+	}
+	return session.CreatedAt
+}
+
+// page trims sessions fetched with one extra row to the query limit and sets the next cursor
+func (q SessionQuery) page(sessions []*types.Session) (*SessionPage, error) {
+	page := &SessionPage{Sessions: sessions}
+	if page.Sessions == nil {
+		page.Sessions = make([]*types.Session, 0)
+	}
+	if len(sessions) <= q.Limit {
+		return page, nil
+	}
+
+	page.Sessions = sessions[:q.Limit]
+	last := page.Sessions[q.Limit-1]
+	data, err := json.Marshal(sessionCursor{SortBy: q.SortBy, Ascending: q.Ascending, Time: q.sortTime(last), ID: last.ID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode cursor: %w", err)
+	}
+	page.NextCursor = base64.RawURLEncoding.EncodeToString(data)
+
+	return page, nil
+}
+
+// matches reports whether a session passes the query filters
+func (q SessionQuery) matches(session *types.Session) bool {
+	if q.UserID != "" && session.UserID != q.UserID {
+		return false
+	}
+	if q.GraphID != "" && session.GraphID != q.GraphID {
+		return false
+	}
+	if q.CurrentNodeID != "" && session.CurrentNodeID != q.CurrentNodeID {
+		return false
+	}
+	if q.BusinessType != "" {
+		if businessType, _ := session.Data["business_type"].(string); businessType != q.BusinessType {
+			return false
+		}
+	}
+	if len(q.Statuses) > 0 {
+		found := false
+		for _, status := range q.Statuses {
+			if session.Status == status {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return inRange(session.CreatedAt, q.CreatedFrom, q.CreatedTo) && inRange(session.UpdatedAt, q.UpdatedFrom, q.UpdatedTo)
+}
+
+// inRange reports whether t is in [from, to), where a zero bound is open
+func inRange(t, from, to time.Time) bool {
+	return (from.IsZero() || !t.Before(from)) && (to.IsZero() || t.Before(to))
+}
+
+// sortSessions orders sessions by the query sort, breaking ties by ID
+func (q SessionQuery) sortSessions(sessions []*types.Session) {
+	sort.Slice(sessions, func(i, j int) bool {
+		return q.before(sessions[i], q.sortTime(sessions[j]), sessions[j].ID)
+	})
+}
+
+// before reports whether a session sorts before the position (t, id)
+func (q SessionQuery) before(session *types.Session, t time.Time, id string) bool {
+	sessionTime := q.sortTime(session)
+	if !sessionTime.Equal(t) {
+		return sessionTime.Before(t) == q.Ascending
+	}
+	return session.ID != id && (session.ID < id) == q.Ascending
+}
+
+// after reports whether a session sorts after the cursor, so belongs on a later page
+func (q SessionQuery) after(session *types.Session, cursor *sessionCursor) bool {
+	sessionTime := q.sortTime(session)
+	if !sessionTime.Equal(cursor.Time) {
+		return sessionTime.After(cursor.Time) == q.Ascending
+	}
+	return session.ID != cursor.ID && (session.ID > cursor.ID) == q.Ascending
+}
+
+// sessionQueryDialect is what differs between the SQL backends in a session listing
+type sessionQueryDialect struct {
+	placeholder  func(n int) string
+	businessType string                      // Expression for the business type in the session data
+	filterTime   func(t time.Time) time.Time // Converts a filter bound to the form times are stored in
+}
+
+// buildSessionQuery returns the SQL and arguments selecting one page of sessions, plus one row to
+// tell whether there is another page. Paging continues from the cursor's sort position, which the
+// (sort column, id) indexes serve directly.
+func buildSessionQuery(columns string, q SessionQuery, cursor *sessionCursor, dialect sessionQueryDialect) (string, []interface{}) {
+	var conditions []string
+	var args []interface{}
+	arg := func(value interface{}) string {
+		args = append(args, value)
+		return dialect.placeholder(len(args))
+	}
+
+	if q.UserID != "" {
+		conditions = append(conditions, "user_id = "+arg(q.UserID))
+	}
+	if q.GraphID != "" {
+		conditions = append(conditions, "graph_id = "+arg(q.GraphID))
+	}
+	if q.CurrentNodeID != "" {
+		conditions = append(conditions, "current_node_id = "+arg(q.CurrentNodeID))
+	}
+	if q.BusinessType != "" {
+		conditions = append(conditions, dialect.businessType+" = "+arg(q.BusinessType))
+	}
+	if len(q.Statuses) > 0 {
+		placeholders := make([]string, 0, len(q.Statuses))
+		for _, status := range q.Statuses {
+			placeholders = append(placeholders, arg(status))
+		}
+		conditions = append(conditions, "status IN ("+strings.Join(placeholders, ", ")+")")
+	}
+
+	timeRanges := []struct {
+		column   string
+		from, to time.Time
+	}{
+		{"created_at", q.CreatedFrom, q.CreatedTo},
+		{"updated_at", q.UpdatedFrom, q.UpdatedTo},
+	}
+	for _, r := range timeRanges {
+		if !r.from.IsZero() {
+			conditions = append(conditions, r.column+" >= "+arg(dialect.filterTime(r.from)))
+		}
+		if !r.to.IsZero() {
+			conditions = append(conditions, r.column+" < "+arg(dialect.filterTime(r.to)))
+		}
+	}
+
+	column := string(q.SortBy)
+	direction, comparison := "DESC", "<"
+	if q.Ascending {
+		direction, comparison = "ASC", ">"
+	}
+	if cursor != nil {
+		// The cursor holds a time read back from the database, so it is passed unconverted
+		conditions = append(conditions, fmt.Sprintf("(%s, id) %s (%s, %s)", column, comparison, arg(cursor.Time), arg(cursor.ID)))
+	}
+
+	query := `SELECT ` + columns + ` FROM sessions`
+	if len(conditions) > 0 {
+		query += ` WHERE ` + strings.Join(conditions, " AND ")
+	}
+	query += fmt.Sprintf(` ORDER BY %s %s, id %s LIMIT %s`, column, direction, direction, arg(q.Limit+1))
+
+	return query, args
+}
@@ -0,0 +1,1096 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"onboarding-system/internal/types"
+
+	"github.com/sirupsen/logrus"
+	_ "modernc.org/sqlite"
+)
+
+// sqliteMigrations is the SQLite schema. Append new migrations; never edit applied ones.
+var sqliteMigrations = []migration{
+	{
+		version: 1,
+		name:    "create graphs, nodes, edges and graph versions",
+		statements: []string{
+			`CREATE TABLE graphs (
+				id TEXT PRIMARY KEY,
+				name TEXT NOT NULL,
+				description TEXT NOT NULL DEFAULT '',
+				version TEXT NOT NULL DEFAULT '',
+				revision INTEGER NOT NULL DEFAULT 0,
+				start_node_id TEXT NOT NULL DEFAULT '',
+				metadata TEXT,
+				rule_groups TEXT,
+				cross_node_validation TEXT,
+				created_at TIMESTAMP NOT NULL,
+				updated_at TIMESTAMP NOT NULL
+			)`,
+			`CREATE TABLE nodes (
+				graph_id TEXT NOT NULL REFERENCES graphs(id) ON DELETE CASCADE,
+				id TEXT NOT NULL,
+				type TEXT NOT NULL,
+				name TEXT NOT NULL,
+				description TEXT NOT NULL DEFAULT '',
+				fields TEXT,
+				validation TEXT,
+				incoming_edges TEXT,
+				outgoing_edges TEXT,
+				is_independent INTEGER NOT NULL DEFAULT 0,
+				is_dependent INTEGER NOT NULL DEFAULT 0,
+				dependencies TEXT,
+				metadata TEXT,
+				created_at TIMESTAMP NOT NULL,
+				updated_at TIMESTAMP NOT NULL,
+				PRIMARY KEY (graph_id, id)
+			)`,
+			`CREATE TABLE edges (
+				graph_id TEXT NOT NULL REFERENCES graphs(id) ON DELETE CASCADE,
+				id TEXT NOT NULL,
+				from_node_id TEXT NOT NULL,
+				to_node_id TEXT NOT NULL,
+				condition TEXT,
+				metadata TEXT,
+				created_at TIMESTAMP NOT NULL,
+				PRIMARY KEY (graph_id, id)
+			)`,
+			`CREATE TABLE graph_versions (
+				graph_id TEXT NOT NULL,
+				revision INTEGER NOT NULL,
+				name TEXT NOT NULL,
+				version TEXT NOT NULL DEFAULT '',
+				definition TEXT NOT NULL,
+				created_at TIMESTAMP NOT NULL,
+				PRIMARY KEY (graph_id, revision)
+			)`,
+		},
+	},
+	{
+		version: 2,
+		name:    "create sessions and session history",
+		statements: []string{
+			`CREATE TABLE sessions (
+				id TEXT PRIMARY KEY,
+				user_id TEXT NOT NULL,
+				graph_id TEXT NOT NULL,
+				graph_revision INTEGER NOT NULL DEFAULT 0,
+				current_node_id TEXT NOT NULL DEFAULT '',
+				data TEXT,
+				status TEXT NOT NULL,
+				retry_count INTEGER NOT NULL DEFAULT 0,
+				dynamic_state TEXT,
+				created_at TIMESTAMP NOT NULL,
+				updated_at TIMESTAMP NOT NULL,
+				completed_at TIMESTAMP
+			)`,
+			`CREATE TABLE session_history (
+				session_id TEXT NOT NULL REFERENCES sessions(id) ON DELETE CASCADE,
+				position INTEGER NOT NULL,
+				id TEXT NOT NULL,
+				node_id TEXT NOT NULL,
+				action TEXT NOT NULL,
+				data TEXT,
+				timestamp TIMESTAMP NOT NULL,
+				PRIMARY KEY (session_id, position)
+			)`,
+			`CREATE INDEX idx_sessions_user_id ON sessions(user_id)`,
+			`CREATE INDEX idx_sessions_status ON sessions(status)`,
+		},
+	},
+	{
+		version: 3,
+		name:    "add session revisions",
+		statements: []string{
+			`ALTER TABLE sessions ADD COLUMN revision INTEGER NOT NULL DEFAULT 0`,
+		},
+	},
+	{
+		version: 4,
+		name:    "create session events",
+		statements: []string{
+			`CREATE TABLE session_events (
+				session_id TEXT NOT NULL REFERENCES sessions(id) ON DELETE CASCADE,
+				sequence INTEGER NOT NULL,
+				id TEXT NOT NULL,
+				type TEXT NOT NULL,
+				event TEXT NOT NULL,
+				occurred_at TIMESTAMP NOT NULL,
+				PRIMARY KEY (session_id, sequence)
+			)`,
+		},
+	},
+	{
+		version: 5,
+		name:    "create webhook outbox",
+		statements: []string{
+			`CREATE TABLE outbox (
+				position INTEGER PRIMARY KEY AUTOINCREMENT,
+				id TEXT NOT NULL UNIQUE,
+				session_id TEXT NOT NULL,
+				event_type TEXT NOT NULL,
+				event TEXT NOT NULL,
+				status TEXT NOT NULL,
+				attempts INTEGER NOT NULL DEFAULT 0,
+				next_attempt_at TIMESTAMP NOT NULL,
+				last_error TEXT NOT NULL DEFAULT '',
+				delivered_to TEXT NOT NULL,
+				created_at TIMESTAMP NOT NULL,
+				updated_at TIMESTAMP NOT NULL
+			)`,
+			`CREATE INDEX idx_outbox_status_next_attempt ON outbox(status, next_attempt_at)`,
+		},
+	},
+	{
+		version: 6,
+		name:    "index session listing filters and sort orders",
+		statements: []string{
+			`CREATE INDEX idx_sessions_created_at_id ON sessions(created_at, id)`,
+			`CREATE INDEX idx_sessions_updated_at_id ON sessions(updated_at, id)`,
+			`CREATE INDEX idx_sessions_user_id_created_at ON sessions(user_id, created_at, id)`,
+			`CREATE INDEX idx_sessions_status_created_at ON sessions(status, created_at, id)`,
+			`CREATE INDEX idx_sessions_graph_id_created_at ON sessions(graph_id, created_at, id)`,
+			`CREATE INDEX idx_sessions_current_node_id ON sessions(current_node_id)`,
+			`CREATE INDEX idx_sessions_business_type ON sessions(json_extract(data, '$.business_type'))`,
+			`DROP INDEX idx_sessions_user_id`,
+			`DROP INDEX idx_sessions_status`,
+		},
+	},
+	{
+		version: 7,
+		name:    "persist activation rules, node rules, entry nodes and advanced session state",
+		statements: []string{
+			`ALTER TABLE graphs ADD COLUMN activation_rules TEXT`,
+			`ALTER TABLE graphs ADD COLUMN node_rules TEXT`,
+			`ALTER TABLE graphs ADD COLUMN entry_nodes TEXT`,
+			`ALTER TABLE sessions ADD COLUMN advanced_state TEXT`,
+		},
+	},
+	{
+		version: 8,
+		name:    "index outbox messages by status and update time for pruning",
+		statements: []string{
+			`CREATE INDEX idx_outbox_status_updated_at ON outbox(status, updated_at)`,
+		},
+	},
+}
+
+// SQLiteStorage implements Storage using a SQLite database file
+type SQLiteStorage struct {
+	db             *sql.DB
+	logger         *logrus.Logger
+	outboxDisabled bool
+}
+
+// NewSQLiteStorage opens the SQLite database at path, creating it if needed, and migrates its schema
+func NewSQLiteStorage(path string, logger *logrus.Logger) (*SQLiteStorage, error) {
+	if path != ":memory:" {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return nil, fmt.Errorf("failed to create database directory: %w", err)
+		}
+	}
+
+	dsn := fmt.Sprintf("file:%s?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)", path)
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open sqlite database: %w", err)
+	}
+
+	// SQLite allows a single writer; one connection serializes writes instead of failing them
+	// with SQLITE_BUSY, and keeps an in-memory database shared
+	db.SetMaxOpenConns(1)
+
+	applied, err := migrate(context.Background(), db, sqliteMigrations)
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to migrate sqlite database: %w", err)
+	}
+
+	logger.WithFields(logrus.Fields{
+		"path":               path,
+		"migrations_applied": applied,
+	}).Info("Using SQLite storage")
+
+	return &SQLiteStorage{db: db, logger: logger}, nil
+}
+
+// SaveSession saves a session and its history
+func (s *SQLiteStorage) SaveSession(ctx context.Context, session *types.Session) error {
+	return s.SaveSessionWithEvents(ctx, session, nil)
+}
+
+// SaveSessionWithEvents saves a session and its history and appends events to its log in one transaction
+func (s *SQLiteStorage) SaveSessionWithEvents(ctx context.Context, session *types.Session, events []*types.SessionEvent) error {
+	dataJSON, err := json.Marshal(session.Data)
+	if err != nil {
+		return fmt.Errorf("failed to marshal session data: %w", err)
+	}
+
+	// A nil dynamic state is stored as NULL rather than the JSON null
+	var dynamicStateJSON interface{}
+	if session.DynamicState != nil {
+		if dynamicStateJSON, err = json.Marshal(session.DynamicState); err != nil {
+			return fmt.Errorf("failed to marshal session dynamic state: %w", err)
+		}
+	}
+	var advancedStateJSON interface{}
+	if session.AdvancedState != nil {
+		if advancedStateJSON, err = json.Marshal(session.AdvancedState); err != nil {
+			return fmt.Errorf("failed to marshal session advanced state: %w", err)
+		}
+	}
+
+	var completedAt interface{}
+	if session.CompletedAt != nil {
+		completedAt = session.CompletedAt.UTC()
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// The update only applies if the stored revision is the one the session was loaded at
+	query := `INSERT INTO sessions (id, user_id, graph_id, graph_revision, current_node_id, data, status, retry_count, dynamic_state, advanced_state, revision, created_at, updated_at, completed_at)
+			  VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			  ON CONFLICT (id) DO UPDATE SET
+			  graph_revision = excluded.graph_revision,
+			  current_node_id = excluded.current_node_id,
+			  data = excluded.data,
+			  status = excluded.status,
+			  retry_count = excluded.retry_count,
+			  dynamic_state = excluded.dynamic_state,
+			  advanced_state = excluded.advanced_state,
+			  revision = excluded.revision,
+			  updated_at = excluded.updated_at,
+			  completed_at = excluded.completed_at
+			  WHERE sessions.revision = ?
+			  RETURNING revision`
+
+	var revision int
+	err = tx.QueryRowContext(ctx, query,
+		session.ID, session.UserID, session.GraphID, session.GraphRevision, session.CurrentNodeID,
+		dataJSON, session.Status, session.RetryCount, dynamicStateJSON, advancedStateJSON, session.Revision+1,
+		session.CreatedAt.UTC(), session.UpdatedAt.UTC(), completedAt, session.Revision).Scan(&revision)
+	if err == sql.ErrNoRows {
+		// Release the connection before reading the current session
+		tx.Rollback()
+		return s.sessionConflict(ctx, session)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to save session: %w", err)
+	}
+
+	// Replace the history so steps removed by the caller do not linger
+	if _, err := tx.ExecContext(ctx, `DELETE FROM session_history WHERE session_id = ?`, session.ID); err != nil {
+		return fmt.Errorf("failed to clear session history: %w", err)
+	}
+
+	for i, step := range session.History {
+		stepDataJSON, err := json.Marshal(step.Data)
+		if err != nil {
+			return fmt.Errorf("failed to marshal session step data: %w", err)
+		}
+
+		_, err = tx.ExecContext(ctx,
+			`INSERT INTO session_history (session_id, position, id, node_id, action, data, timestamp) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+			session.ID, i, step.ID, step.NodeID, step.Action, stepDataJSON, step.Timestamp.UTC())
+		if err != nil {
+			return fmt.Errorf("failed to save session step: %w", err)
+		}
+	}
+
+	if err := s.appendEvents(ctx, tx, session.ID, revision, events); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	session.Revision = revision
+	return nil
+}
+
+// appendEvents adds events to the end of a session's log
+func (s *SQLiteStorage) appendEvents(ctx context.Context, tx *sql.Tx, sessionID string, revision int, events []*types.SessionEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	var last int
+	err := tx.QueryRowContext(ctx, `SELECT COALESCE(MAX(sequence), 0) FROM session_events WHERE session_id = ?`, sessionID).Scan(&last)
+	if err != nil {
+		return fmt.Errorf("failed to get last session event: %w", err)
+	}
+
+	for i, event := range events {
+		event.SessionID = sessionID
+		event.Sequence = last + i + 1
+		event.Revision = revision
+
+		eventJSON, err := json.Marshal(event)
+		if err != nil {
+			return fmt.Errorf("failed to marshal session event: %w", err)
+		}
+
+		_, err = tx.ExecContext(ctx,
+			`INSERT INTO session_events (session_id, sequence, id, type, event, occurred_at) VALUES (?, ?, ?, ?, ?, ?)`,
+			sessionID, event.Sequence, event.ID, event.Type, eventJSON, event.OccurredAt.UTC())
+		if err != nil {
+			return fmt.Errorf("failed to save session event: %w", err)
+		}
+
+		if s.outboxDisabled {
+			continue
+		}
+		message := newOutboxMessage(event, time.Now().UTC())
+		_, err = tx.ExecContext(ctx,
+			`INSERT INTO outbox (`+outboxColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			message.ID, message.SessionID, message.EventType, eventJSON, message.Status, message.Attempts,
+			message.NextAttemptAt, message.LastError, "[]", message.CreatedAt, message.UpdatedAt)
+		if err != nil {
+			return fmt.Errorf("failed to save outbox message: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// ListSessionEvents returns a session's events in the order they were saved
+func (s *SQLiteStorage) ListSessionEvents(ctx context.Context, sessionID string) ([]*types.SessionEvent, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT event FROM session_events WHERE session_id = ? ORDER BY sequence`, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query session events: %w", err)
+	}
+	defer rows.Close()
+
+	return scanSessionEvents(rows)
+}
+
+// ClaimOutboxMessages returns the oldest pending messages that are due and defers them by lease
+func (s *SQLiteStorage) ClaimOutboxMessages(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*types.OutboxMessage, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx,
+		`SELECT `+outboxColumns+` FROM outbox WHERE status = ? AND next_attempt_at <= ? ORDER BY position LIMIT ?`,
+		types.OutboxStatusPending, now.UTC(), limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query outbox: %w", err)
+	}
+	messages, err := scanOutboxMessages(rows)
+	rows.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	leaseUntil := now.Add(lease).UTC()
+	for _, message := range messages {
+		if _, err := tx.ExecContext(ctx, `UPDATE outbox SET next_attempt_at = ? WHERE id = ?`, leaseUntil, message.ID); err != nil {
+			return nil, fmt.Errorf("failed to claim outbox message: %w", err)
+		}
+		message.NextAttemptAt = leaseUntil
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return messages, nil
+}
+
+// UpdateOutboxMessage saves an outbox message's delivery state
+func (s *SQLiteStorage) UpdateOutboxMessage(ctx context.Context, message *types.OutboxMessage) error {
+	deliveredToJSON, err := json.Marshal(message.DeliveredTo)
+	if err != nil {
+		return fmt.Errorf("failed to marshal outbox deliveries: %w", err)
+	}
+
+	message.UpdatedAt = time.Now()
+	result, err := s.db.ExecContext(ctx,
+		`UPDATE outbox SET status = ?, attempts = ?, next_attempt_at = ?, last_error = ?, delivered_to = ?, updated_at = ? WHERE id = ?`,
+		message.Status, message.Attempts, message.NextAttemptAt.UTC(), message.LastError, deliveredToJSON, message.UpdatedAt.UTC(), message.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update outbox message: %w", err)
+	}
+
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		return fmt.Errorf("outbox message not found")
+	}
+	return nil
+}
+
+// GetOutboxMessage retrieves an outbox message
+func (s *SQLiteStorage) GetOutboxMessage(ctx context.Context, id string) (*types.OutboxMessage, error) {
+	message, err := scanOutboxMessage(s.db.QueryRowContext(ctx, `SELECT `+outboxColumns+` FROM outbox WHERE id = ?`, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("outbox message not found")
+		}
+		return nil, fmt.Errorf("failed to get outbox message: %w", err)
+	}
+	return message, nil
+}
+
+// ListOutboxMessages returns the oldest outbox messages with the given status
+func (s *SQLiteStorage) ListOutboxMessages(ctx context.Context, status types.OutboxStatus, limit int) ([]*types.OutboxMessage, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT `+outboxColumns+` FROM outbox WHERE status = ? ORDER BY position LIMIT ?`, status, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query outbox: %w", err)
+	}
+	defer rows.Close()
+
+	return scanOutboxMessages(rows)
+}
+
+// PruneOutboxMessages deletes the messages with status last updated before the cutoff
+func (s *SQLiteStorage) PruneOutboxMessages(ctx context.Context, status types.OutboxStatus, before time.Time) (int, error) {
+	result, err := s.db.ExecContext(ctx, `DELETE FROM outbox WHERE status = ? AND updated_at < ?`, status, before.UTC())
+	if err != nil {
+		return 0, fmt.Errorf("failed to prune outbox: %w", err)
+	}
+	pruned, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to prune outbox: %w", err)
+	}
+	return int(pruned), nil
+}
+
+// DisableOutbox stops adding saved events to the outbox
+func (s *SQLiteStorage) DisableOutbox() {
+	s.outboxDisabled = true
+}
+
+// sessionConflict returns the conflict error for a save that did not match the stored revision
+func (s *SQLiteStorage) sessionConflict(ctx context.Context, session *types.Session) error {
+	current, err := s.GetSession(ctx, session.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get conflicting session: %w", err)
+	}
+	return &SessionConflictError{SessionID: session.ID, Revision: session.Revision, Current: current}
+}
+
+// GetSession retrieves a session and its history
+func (s *SQLiteStorage) GetSession(ctx context.Context, sessionID string) (*types.Session, error) {
+	query := `SELECT ` + sqliteSessionColumns + ` FROM sessions WHERE id = ?`
+
+	session, err := scanSQLiteSession(s.db.QueryRowContext(ctx, query, sessionID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("session not found")
+		}
+		return nil, fmt.Errorf("failed to get session: %w", err)
+	}
+
+	if err := s.loadHistory(ctx, session); err != nil {
+		return nil, err
+	}
+
+	return session, nil
+}
+
+// sqliteSessionColumns lists the session columns in the order scanSQLiteSession expects
+const sqliteSessionColumns = `id, user_id, graph_id, graph_revision, current_node_id, data, status, retry_count, dynamic_state, advanced_state, revision, created_at, updated_at, completed_at`
+
+// scanSQLiteSession scans a row selected with sqliteSessionColumns; history is loaded separately
+func scanSQLiteSession(row rowScanner) (*types.Session, error) {
+	var session types.Session
+	var dataJSON, dynamicStateJSON, advancedStateJSON []byte
+	var completedAt sql.NullTime
+
+	err := row.Scan(
+		&session.ID, &session.UserID, &session.GraphID, &session.GraphRevision, &session.CurrentNodeID,
+		&dataJSON, &session.Status, &session.RetryCount, &dynamicStateJSON, &advancedStateJSON, &session.Revision,
+		&session.CreatedAt, &session.UpdatedAt, &completedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(dataJSON, &session.Data); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal session data: %w", err)
+	}
+
+	if len(dynamicStateJSON) > 0 {
+		if err := json.Unmarshal(dynamicStateJSON, &session.DynamicState); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal session dynamic state: %w", err)
+		}
+	}
+
+	if len(advancedStateJSON) > 0 {
+		if err := json.Unmarshal(advancedStateJSON, &session.AdvancedState); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal session advanced state: %w", err)
+		}
+	}
+
+	if completedAt.Valid {
+		session.CompletedAt = &completedAt.Time
+	}
+
+	return &session, nil
+}
+
+// loadHistory reads a session's steps in the order they were taken
+func (s *SQLiteStorage) loadHistory(ctx context.Context, session *types.Session) error {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT id, node_id, action, data, timestamp FROM session_history WHERE session_id = ? ORDER BY position`, session.ID)
+	if err != nil {
+		return fmt.Errorf("failed to query session history: %w", err)
+	}
+	defer rows.Close()
+
+	session.History = make([]types.SessionStep, 0)
+	for rows.Next() {
+		var step types.SessionStep
+		var dataJSON []byte
+
+		if err := rows.Scan(&step.ID, &step.NodeID, &step.Action, &dataJSON, &step.Timestamp); err != nil {
+			return fmt.Errorf("failed to scan session step: %w", err)
+		}
+
+		if err := json.Unmarshal(dataJSON, &step.Data); err != nil {
+			return fmt.Errorf("failed to unmarshal session step data: %w", err)
+		}
+
+		session.History = append(session.History, step)
+	}
+
+	return rows.Err()
+}
+
+// UpdateSession updates a session
+func (s *SQLiteStorage) UpdateSession(ctx context.Context, session *types.Session) error {
+	return s.SaveSession(ctx, session)
+}
+
+// DeleteSession deletes a session; its history and events are removed by the foreign key cascade
+func (s *SQLiteStorage) DeleteSession(ctx context.Context, sessionID string) error {
+	if _, err := s.db.ExecContext(ctx, `DELETE FROM sessions WHERE id = ?`, sessionID); err != nil {
+		return fmt.Errorf("failed to delete session: %w", err)
+	}
+	return nil
+}
+
+// ListSessions lists sessions for a user
+func (s *SQLiteStorage) ListSessions(ctx context.Context, userID string) ([]*types.Session, error) {
+	query := `SELECT ` + sqliteSessionColumns + ` FROM sessions WHERE user_id = ? ORDER BY created_at DESC`
+	return s.listSessions(ctx, query, userID)
+}
+
+// ListAllSessions lists all sessions for admin dashboard
+func (s *SQLiteStorage) ListAllSessions(ctx context.Context) ([]*types.Session, error) {
+	query := `SELECT ` + sqliteSessionColumns + ` FROM sessions ORDER BY created_at DESC`
+	return s.listSessions(ctx, query)
+}
+
+// ListSessionsQuery returns one page of the sessions matching query
+func (s *SQLiteStorage) ListSessionsQuery(ctx context.Context, query SessionQuery) (*SessionPage, error) {
+	query, cursor, err := query.normalize()
+	if err != nil {
+		return nil, err
+	}
+
+	statement, args := buildSessionQuery(sqliteSessionColumns, query, cursor, sessionQueryDialect{
+		placeholder:  func(int) string { return "?" },
+		businessType: "json_extract(data, '$.business_type')",
+		// Times are stored as UTC text, which sorts chronologically
+		filterTime: func(t time.Time) time.Time { return t.UTC() },
+	})
+
+	sessions, err := s.listSessions(ctx, statement, args...)
+	if err != nil {
+		return nil, err
+	}
+	return query.page(sessions)
+}
+
+// listSessions runs a session query and loads each session's history
+func (s *SQLiteStorage) listSessions(ctx context.Context, query string, args ...interface{}) ([]*types.Session, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+
+	var sessions []*types.Session
+	for rows.Next() {
+		session, err := scanSQLiteSession(rows)
+		if err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan session: %w", err)
+		}
+		sessions = append(sessions, session)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+
+	// With a single connection, history can only be read once the session rows are closed
+	for _, session := range sessions {
+		if err := s.loadHistory(ctx, session); err != nil {
+			return nil, err
+		}
+	}
+
+	return sessions, nil
+}
+
+// SaveGraph saves a graph with its nodes and edges
+func (s *SQLiteStorage) SaveGraph(ctx context.Context, graph *types.Graph) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := s.saveGraph(ctx, tx, graph); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// saveGraph writes a graph with its nodes and edges within tx
+func (s *SQLiteStorage) saveGraph(ctx context.Context, tx *sql.Tx, graph *types.Graph) error {
+	metadataJSON, err := json.Marshal(graph.Metadata)
+	if err != nil {
+		return fmt.Errorf("failed to marshal graph metadata: %w", err)
+	}
+
+	ruleGroupsJSON, err := json.Marshal(graph.RuleGroups)
+	if err != nil {
+		return fmt.Errorf("failed to marshal graph rule groups: %w", err)
+	}
+
+	crossNodeJSON, err := json.Marshal(graph.CrossNodeValidation)
+	if err != nil {
+		return fmt.Errorf("failed to marshal graph cross node validation: %w", err)
+	}
+
+	activationRulesJSON, nodeRulesJSON, entryNodesJSON, err := marshalAdvancedRules(graph)
+	if err != nil {
+		return err
+	}
+
+	graphQuery := `INSERT INTO graphs (id, name, description, version, revision, start_node_id, metadata, rule_groups, cross_node_validation, activation_rules, node_rules, entry_nodes, created_at, updated_at)
+				   VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+				   ON CONFLICT (id) DO UPDATE SET
+				   name = excluded.name,
+				   description = excluded.description,
+				   version = excluded.version,
+				   revision = excluded.revision,
+				   start_node_id = excluded.start_node_id,
+				   metadata = excluded.metadata,
+				   rule_groups = excluded.rule_groups,
+				   cross_node_validation = excluded.cross_node_validation,
+				   activation_rules = excluded.activation_rules,
+				   node_rules = excluded.node_rules,
+				   entry_nodes = excluded.entry_nodes,
+				   updated_at = excluded.updated_at`
+
+	_, err = tx.ExecContext(ctx, graphQuery,
+		graph.ID, graph.Name, graph.Description, graph.Version, graph.Revision, graph.StartNodeID,
+		metadataJSON, ruleGroupsJSON, crossNodeJSON, activationRulesJSON, nodeRulesJSON, entryNodesJSON,
+		graph.CreatedAt.UTC(), graph.UpdatedAt.UTC())
+	if err != nil {
+		return fmt.Errorf("failed to save graph: %w", err)
+	}
+
+	// Replace nodes and edges so ones removed from the graph do not linger
+	if _, err := tx.ExecContext(ctx, `DELETE FROM edges WHERE graph_id = ?`, graph.ID); err != nil {
+		return fmt.Errorf("failed to clear graph edges: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM nodes WHERE graph_id = ?`, graph.ID); err != nil {
+		return fmt.Errorf("failed to clear graph nodes: %w", err)
+	}
+
+	for _, node := range graph.Nodes {
+		if err := s.saveNode(ctx, tx, graph.ID, node); err != nil {
+			return fmt.Errorf("failed to save node: %w", err)
+		}
+	}
+
+	for _, edge := range graph.Edges {
+		if err := s.saveEdge(ctx, tx, graph.ID, edge); err != nil {
+			return fmt.Errorf("failed to save edge: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// saveNode saves a node, including its edge tracking and dependencies
+func (s *SQLiteStorage) saveNode(ctx context.Context, tx *sql.Tx, graphID string, node *types.Node) error {
+	columns := map[string]interface{}{
+		"fields":         node.Fields,
+		"validation":     node.Validation,
+		"incoming_edges": node.IncomingEdges,
+		"outgoing_edges": node.OutgoingEdges,
+		"dependencies":   node.Dependencies,
+		"metadata":       node.Metadata,
+	}
+	encoded := make(map[string][]byte, len(columns))
+	for column, value := range columns {
+		data, err := json.Marshal(value)
+		if err != nil {
+			return fmt.Errorf("failed to marshal node %s: %w", column, err)
+		}
+		encoded[column] = data
+	}
+
+	query := `INSERT INTO nodes (graph_id, id, type, name, description, fields, validation, incoming_edges, outgoing_edges,
+			  is_independent, is_dependent, dependencies, metadata, created_at, updated_at)
+			  VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+	_, err := tx.ExecContext(ctx, query,
+		graphID, node.ID, node.Type, node.Name, node.Description,
+		encoded["fields"], encoded["validation"], encoded["incoming_edges"], encoded["outgoing_edges"],
+		node.IsIndependent, node.IsDependent, encoded["dependencies"], encoded["metadata"],
+		node.CreatedAt.UTC(), node.UpdatedAt.UTC())
+
+	return err
+}
+
+// saveEdge saves an edge
+func (s *SQLiteStorage) saveEdge(ctx context.Context, tx *sql.Tx, graphID string, edge *types.Edge) error {
+	conditionJSON, err := json.Marshal(edge.Condition)
+	if err != nil {
+		return fmt.Errorf("failed to marshal edge condition: %w", err)
+	}
+
+	metadataJSON, err := json.Marshal(edge.Metadata)
+	if err != nil {
+		return fmt.Errorf("failed to marshal edge metadata: %w", err)
+	}
+
+	query := `INSERT INTO edges (graph_id, id, from_node_id, to_node_id, condition, metadata, created_at)
+			  VALUES (?, ?, ?, ?, ?, ?, ?)`
+
+	_, err = tx.ExecContext(ctx, query,
+		graphID, edge.ID, edge.FromNodeID, edge.ToNodeID, conditionJSON, metadataJSON, edge.CreatedAt.UTC())
+
+	return err
+}
+
+// GetGraph retrieves a graph with its nodes and edges
+func (s *SQLiteStorage) GetGraph(ctx context.Context, graphID string) (*types.Graph, error) {
+	graphQuery := `SELECT id, name, description, version, revision, start_node_id, metadata, rule_groups, cross_node_validation, activation_rules, node_rules, entry_nodes, created_at, updated_at
+				   FROM graphs WHERE id = ?`
+
+	var graph types.Graph
+	var metadataJSON, ruleGroupsJSON, crossNodeJSON, activationRulesJSON, nodeRulesJSON, entryNodesJSON []byte
+
+	err := s.db.QueryRowContext(ctx, graphQuery, graphID).Scan(
+		&graph.ID, &graph.Name, &graph.Description, &graph.Version, &graph.Revision, &graph.StartNodeID,
+		&metadataJSON, &ruleGroupsJSON, &crossNodeJSON, &activationRulesJSON, &nodeRulesJSON, &entryNodesJSON,
+		&graph.CreatedAt, &graph.UpdatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("graph not found")
+		}
+		return nil, fmt.Errorf("failed to get graph: %w", err)
+	}
+
+	if err := json.Unmarshal(metadataJSON, &graph.Metadata); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal graph metadata: %w", err)
+	}
+
+	if err := json.Unmarshal(ruleGroupsJSON, &graph.RuleGroups); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal graph rule groups: %w", err)
+	}
+
+	if err := json.Unmarshal(crossNodeJSON, &graph.CrossNodeValidation); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal graph cross node validation: %w", err)
+	}
+
+	if err := unmarshalAdvancedRules(&graph, activationRulesJSON, nodeRulesJSON, entryNodesJSON); err != nil {
+		return nil, err
+	}
+
+	nodes, err := s.getGraphNodes(ctx, graphID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get graph nodes: %w", err)
+	}
+	graph.Nodes = nodes
+
+	edges, err := s.getGraphEdges(ctx, graphID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get graph edges: %w", err)
+	}
+	graph.Edges = edges
+
+	return &graph, nil
+}
+
+// getGraphNodes retrieves all nodes for a graph
+func (s *SQLiteStorage) getGraphNodes(ctx context.Context, graphID string) (map[string]*types.Node, error) {
+	query := `SELECT id, type, name, description, fields, validation, incoming_edges, outgoing_edges,
+			  is_independent, is_dependent, dependencies, metadata, created_at, updated_at
+			  FROM nodes WHERE graph_id = ?`
+
+	rows, err := s.db.QueryContext(ctx, query, graphID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query nodes: %w", err)
+	}
+	defer rows.Close()
+
+	nodes := make(map[string]*types.Node)
+	for rows.Next() {
+		var node types.Node
+		var fieldsJSON, validationJSON, incomingJSON, outgoingJSON, dependenciesJSON, metadataJSON []byte
+
+		err := rows.Scan(
+			&node.ID, &node.Type, &node.Name, &node.Description,
+			&fieldsJSON, &validationJSON, &incomingJSON, &outgoingJSON,
+			&node.IsIndependent, &node.IsDependent, &dependenciesJSON, &metadataJSON,
+			&node.CreatedAt, &node.UpdatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan node: %w", err)
+		}
+
+		columns := []struct {
+			name   string
+			data   []byte
+			target interface{}
+		}{
+			{"fields", fieldsJSON, &node.Fields},
+			{"validation", validationJSON, &node.Validation},
+			{"incoming_edges", incomingJSON, &node.IncomingEdges},
+			{"outgoing_edges", outgoingJSON, &node.OutgoingEdges},
+			{"dependencies", dependenciesJSON, &node.Dependencies},
+			{"metadata", metadataJSON, &node.Metadata},
+		}
+		for _, column := range columns {
+			if err := json.Unmarshal(column.data, column.target); err != nil {
+				return nil, fmt.Errorf("failed to unmarshal node %s: %w", column.name, err)
+			}
+		}
+
+		nodes[node.ID] = &node
+	}
+
+	return nodes, rows.Err()
+}
+
+// getGraphEdges retrieves all edges for a graph
+func (s *SQLiteStorage) getGraphEdges(ctx context.Context, graphID string) (map[string]*types.Edge, error) {
+	query := `SELECT id, from_node_id, to_node_id, condition, metadata, created_at FROM edges WHERE graph_id = ?`
+
+	rows, err := s.db.QueryContext(ctx, query, graphID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query edges: %w", err)
+	}
+	defer rows.Close()
+
+	edges := make(map[string]*types.Edge)
+	for rows.Next() {
+		var edge types.Edge
+		var conditionJSON, metadataJSON []byte
+
+		if err := rows.Scan(&edge.ID, &edge.FromNodeID, &edge.ToNodeID, &conditionJSON, &metadataJSON, &edge.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan edge: %w", err)
+		}
+
+		if err := json.Unmarshal(conditionJSON, &edge.Condition); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal edge condition: %w", err)
+		}
+
+		if err := json.Unmarshal(metadataJSON, &edge.Metadata); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal edge metadata: %w", err)
+		}
+
+		edges[edge.ID] = &edge
+	}
+
+	return edges, rows.Err()
+}
+
+// UpdateGraph updates a graph
+func (s *SQLiteStorage) UpdateGraph(ctx context.Context, graph *types.Graph) error {
+	return s.SaveGraph(ctx, graph)
+}
+
+// DeleteGraph deletes a graph and its versions; nodes and edges are removed by the foreign key cascade
+func (s *SQLiteStorage) DeleteGraph(ctx context.Context, graphID string) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM graph_versions WHERE graph_id = ?`, graphID); err != nil {
+		return fmt.Errorf("failed to delete graph versions: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM graphs WHERE id = ?`, graphID); err != nil {
+		return fmt.Errorf("failed to delete graph: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// ListGraphs lists all graphs with their nodes and edges
+func (s *SQLiteStorage) ListGraphs(ctx context.Context) ([]*types.Graph, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT id FROM graphs ORDER BY created_at DESC`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list graphs: %w", err)
+	}
+
+	var graphIDs []string
+	for rows.Next() {
+		var graphID string
+		if err := rows.Scan(&graphID); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan graph: %w", err)
+		}
+		graphIDs = append(graphIDs, graphID)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, fmt.Errorf("failed to list graphs: %w", err)
+	}
+
+	var graphs []*types.Graph
+	for _, graphID := range graphIDs {
+		graph, err := s.GetGraph(ctx, graphID)
+		if err != nil {
+			return nil, err
+		}
+		graphs = append(graphs, graph)
+	}
+
+	return graphs, nil
+}
+
+// SaveGraphVersion stores an immutable snapshot of a graph revision
+func (s *SQLiteStorage) SaveGraphVersion(ctx context.Context, graph *types.Graph) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := s.saveGraphVersion(ctx, tx, graph); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// PublishGraphVersion saves a graph revision and makes it the default graph in one transaction
+func (s *SQLiteStorage) PublishGraphVersion(ctx context.Context, graph *types.Graph) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// The version insert fails on an existing revision before the default graph is touched
+	if err := s.saveGraphVersion(ctx, tx, graph); err != nil {
+		return err
+	}
+	if err := s.saveGraph(ctx, tx, graph); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// saveGraphVersion inserts a graph revision snapshot within tx
+func (s *SQLiteStorage) saveGraphVersion(ctx context.Context, tx *sql.Tx, graph *types.Graph) error {
+	definitionJSON, err := json.Marshal(graph)
+	if err != nil {
+		return fmt.Errorf("failed to marshal graph version: %w", err)
+	}
+
+	query := `INSERT INTO graph_versions (graph_id, revision, name, version, definition, created_at)
+			  VALUES (?, ?, ?, ?, ?, ?)
+			  ON CONFLICT (graph_id, revision) DO NOTHING`
+
+	result, err := tx.ExecContext(ctx, query,
+		graph.ID, graph.Revision, graph.Name, graph.Version, definitionJSON, time.Now().UTC())
+	if err != nil {
+		return fmt.Errorf("failed to save graph version: %w", err)
+	}
+
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		return &GraphVersionExistsError{GraphID: graph.ID, Revision: graph.Revision}
+	}
+
+	return nil
+}
+
+// GetGraphVersion retrieves a specific revision of a graph
+func (s *SQLiteStorage) GetGraphVersion(ctx context.Context, graphID string, revision int) (*types.Graph, error) {
+	var definitionJSON []byte
+	err := s.db.QueryRowContext(ctx, `SELECT definition FROM graph_versions WHERE graph_id = ? AND revision = ?`,
+		graphID, revision).Scan(&definitionJSON)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("graph version not found")
+		}
+		return nil, fmt.Errorf("failed to get graph version: %w", err)
+	}
+
+	var graph types.Graph
+	if err := json.Unmarshal(definitionJSON, &graph); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal graph version: %w", err)
+	}
+
+	return &graph, nil
+}
+
+// ListGraphVersions lists all revisions of a graph, oldest first
+func (s *SQLiteStorage) ListGraphVersions(ctx context.Context, graphID string) ([]*types.GraphVersion, error) {
+	query := `SELECT revision, name, version, created_at FROM graph_versions WHERE graph_id = ? ORDER BY revision`
+
+	rows, err := s.db.QueryContext(ctx, query, graphID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list graph versions: %w", err)
+	}
+	defer rows.Close()
+
+	versions := make([]*types.GraphVersion, 0)
+	for rows.Next() {
+		version := &types.GraphVersion{GraphID: graphID}
+		if err := rows.Scan(&version.Revision, &version.Name, &version.Version, &version.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan graph version: %w", err)
+		}
+		versions = append(versions, version)
+	}
+
+	return versions, rows.Err()
+}
+
+// Close closes the database
+func (s *SQLiteStorage) Close() error {
+	if err := s.db.Close(); err != nil {
+		return fmt.Errorf("failed to close database: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,189 @@
+package storage
+
+import (
+	"context"
+	"io"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"onboarding-system/internal/config"
+	"onboarding-system/internal/types"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testLogger() *logrus.Logger {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	return logger
+}
+
+func newTestSQLite(t *testing.T, path string) *SQLiteStorage {
+	t.Helper()
+	store, err := NewSQLiteStorage(path, testLogger())
+	require.NoError(t, err)
+	t.Cleanup(func() { store.Close() })
+	return store
+}
+
+func testGraph() *types.Graph {
+	now := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	return &types.Graph{
+		ID:          "kyc",
+		Name:        "KYC",
+		Description: "Know your customer",
+		Version:     "1.0.0",
+		Revision:    2,
+		StartNodeID: "start",
+		Nodes: map[string]*types.Node{
+			"start": {
+				ID:            "start",
+				Type:          types.NodeTypeStart,
+				Name:          "Start",
+				Fields:        []types.Field{{ID: "business_type", Name: "Business Type", Type: types.FieldTypeSelect, Required: true, Options: []string{"individual", "llp"}, Metadata: map[string]interface{}{}}},
+				Validation:    types.ValidationRules{RequiredFields: []string{"business_type"}},
+				OutgoingEdges: []string{"start_to_pan"},
+				IsIndependent: true,
+				Metadata:      map[string]interface{}{"step": float64(1)},
+				CreatedAt:     now,
+				UpdatedAt:     now,
+			},
+			"pan": {
+				ID:            "pan",
+				Type:          types.NodeTypeEnd,
+				Name:          "PAN",
+				IncomingEdges: []string{"start_to_pan"},
+				IsDependent:   true,
+				Dependencies:  []types.NodeDependency{{FieldID: "business_type", Operator: "eq", Value: "llp", Condition: "business_type eq llp"}},
+				Metadata:      map[string]interface{}{},
+				CreatedAt:     now,
+				UpdatedAt:     now,
+			},
+		},
+		Edges: map[string]*types.Edge{
+			"start_to_pan": {
+				ID:         "start_to_pan",
+				FromNodeID: "start",
+				ToNodeID:   "pan",
+				Condition:  types.EdgeCondition{Type: "field_value", Field: "business_type", Operator: "eq", Value: "llp"},
+				Metadata:   map[string]interface{}{},
+				CreatedAt:  now,
+			},
+		},
+		CrossNodeValidation: []types.CrossNodeValidationRule{{
+			ID:        "name_match",
+			Fields:    []types.CrossNodeFieldReference{{NodeID: "start", FieldID: "business_type", Alias: "type"}},
+			Condition: types.CrossNodeCondition{Type: "field_match", Operator: "eq", Fields: []string{"type"}},
+			Severity:  types.ValidationSeverityError,
+			Enabled:   true,
+		}},
+		RuleGroups: []types.RuleGroup{{ID: "llp", Name: "LLP", BusinessTypes: []string{"llp"}, RequiredNodes: []string{"pan"}}},
+		Metadata:   map[string]interface{}{"owner": "risk"},
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+}
+
+func testSession(id, userID string, createdAt time.Time) *types.Session {
+	completedAt := createdAt.Add(time.Hour)
+	return &types.Session{
+		ID:            id,
+		UserID:        userID,
+		GraphID:       "kyc",
+		GraphRevision: 2,
+		CurrentNodeID: "pan",
+		Data:          map[string]interface{}{"business_type": "llp", "turnover": float64(1200)},
+		History: []types.SessionStep{
+			{ID: "step-1", NodeID: "start", Data: map[string]interface{}{"business_type": "llp"}, Timestamp: createdAt, Action: "forward"},
+			{ID: "step-2", NodeID: "pan", Data: map[string]interface{}{}, Timestamp: createdAt.Add(time.Minute), Action: "backward"},
+		},
+		Status:      types.SessionStatusCompleted,
+		RetryCount:  1,
+		CreatedAt:   createdAt,
+		UpdatedAt:   createdAt,
+		CompletedAt: &completedAt,
+		DynamicState: &types.DynamicSessionState{
+			BusinessType: "llp",
+			NodeStatuses: map[string]types.NodeStatusInfo{
+				"pan": {Status: "completed", InitialStatus: "blocked", Dependencies: []types.DependencyInfo{{FieldID: "business_type", Operator: "eq", Value: "llp"}}, LastUpdatedAt: createdAt},
+			},
+			LastEvaluatedAt:  createdAt,
+			CompletionStatus: map[string]interface{}{"percent": float64(100)},
+		},
+	}
+}
+
+func TestSQLitePersistsAcrossReopen(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "nested", "onboarding.db")
+	createdAt := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	store, err := NewSQLiteStorage(path, testLogger())
+	require.NoError(t, err)
+	require.NoError(t, store.SaveGraph(ctx, testGraph()))
+	saved := testSession("s1", "alice", createdAt)
+	require.NoError(t, store.SaveSession(ctx, saved))
+	require.NoError(t, store.Close())
+
+	reopened := newTestSQLite(t, path)
+	graph, err := reopened.GetGraph(ctx, "kyc")
+	require.NoError(t, err)
+	assert.Equal(t, testGraph(), graph)
+
+	session, err := reopened.GetSession(ctx, "s1")
+	require.NoError(t, err)
+	assert.Equal(t, saved, session)
+}
+
+func TestSQLiteMigrations(t *testing.T) {
+	ctx := context.Background()
+	store := newTestSQLite(t, ":memory:")
+
+	applied, err := migrate(ctx, store.db, sqliteMigrations)
+	require.NoError(t, err)
+	assert.Zero(t, applied, "migrations are only applied once")
+
+	var count int
+	require.NoError(t, store.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM schema_migrations`).Scan(&count))
+	assert.Equal(t, len(sqliteMigrations), count)
+}
+
+func TestNewFailsWhenSelectedDriverCannotStart(t *testing.T) {
+	// A directory cannot be opened as a database file
+	cfg := &config.Config{Database: config.DatabaseConfig{Driver: "sqlite", SQLitePath: t.TempDir()}}
+	store, err := New(cfg)
+	assert.Error(t, err)
+	assert.Nil(t, store, "a selected driver never falls back to memory")
+
+	cfg = &config.Config{Database: config.DatabaseConfig{Driver: "postgres"}}
+	_, err = New(cfg)
+	assert.Error(t, err)
+
+	cfg = &config.Config{Database: config.DatabaseConfig{Driver: "sqlite", SQLitePath: filepath.Join(t.TempDir(), "onboarding.db")}}
+	store, err = New(cfg)
+	require.NoError(t, err)
+	defer store.Close()
+	assert.IsType(t, &SQLiteStorage{}, store)
+}
+
+func TestNewDisablesOutboxWithoutWebhooks(t *testing.T) {
+	ctx := context.Background()
+
+	for _, webhooks := range []config.WebhookConfig{
+		{},
+		{URLs: []string{"https://crm.example.com/hooks"}, PollInterval: time.Second},
+	} {
+		store, err := New(&config.Config{Database: config.DatabaseConfig{Driver: "memory"}, Webhooks: webhooks})
+		require.NoError(t, err)
+
+		session := &types.Session{ID: "session-1", UserID: "user", GraphID: "graph", Data: map[string]interface{}{}}
+		event := &types.SessionEvent{ID: "event-1", Type: types.SessionEventStarted, OccurredAt: time.Now()}
+		require.NoError(t, store.SaveSessionWithEvents(ctx, session, []*types.SessionEvent{event}))
+
+		_, err = store.GetOutboxMessage(ctx, event.ID)
+		assert.Equal(t, webhooks.Enabled(), err == nil, "outbox written with webhooks %+v", webhooks)
+	}
+}
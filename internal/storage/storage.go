@@ -17,7 +17,8 @@ import (
 
 // Storage interface defines the storage operations
 type Storage interface {
-	// Session operations
+	// Session operations. Saves fail with a *SessionConflictError unless session.Revision matches
+	// the stored revision, and increment session.Revision on success.
 	SaveSession(ctx context.Context, session *types.Session) error
 	GetSession(ctx context.Context, sessionID string) (*types.Session, error)
 	UpdateSession(ctx context.Context, session *types.Session) error
@@ -25,6 +26,29 @@ type Storage interface {
 	ListSessions(ctx context.Context, userID string) ([]*types.Session, error)
 	ListAllSessions(ctx context.Context) ([]*types.Session, error)
 
+	// ListSessionsQuery returns one page of the sessions matching query, in the query's sort order
+	ListSessionsQuery(ctx context.Context, query SessionQuery) (*SessionPage, error)
+
+	// Session event operations. SaveSessionWithEvents saves the session like SaveSession and appends
+	// the events to its log in the same write, assigning their sequence and revision. Nothing is
+	// appended if the save fails.
+	SaveSessionWithEvents(ctx context.Context, session *types.Session, events []*types.SessionEvent) error
+	ListSessionEvents(ctx context.Context, sessionID string) ([]*types.SessionEvent, error)
+
+	// Outbox operations. Every event saved with SaveSessionWithEvents is added to the outbox in the
+	// same write. ClaimOutboxMessages returns up to limit pending messages due at now and hides
+	// them from other claims until now+lease, so several dispatchers can share the outbox.
+	ClaimOutboxMessages(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*types.OutboxMessage, error)
+	UpdateOutboxMessage(ctx context.Context, message *types.OutboxMessage) error
+	GetOutboxMessage(ctx context.Context, id string) (*types.OutboxMessage, error)
+	ListOutboxMessages(ctx context.Context, status types.OutboxStatus, limit int) ([]*types.OutboxMessage, error)
+	// PruneOutboxMessages deletes the messages with status last updated before the cutoff and
+	// returns how many were deleted
+	PruneOutboxMessages(ctx context.Context, status types.OutboxStatus, before time.Time) (int, error)
+	// DisableOutbox stops adding saved events to the outbox, for deployments that deliver no
+	// webhooks. Events are still appended to the session log.
+	DisableOutbox()
+
 	// Graph operations
 	SaveGraph(ctx context.Context, graph *types.Graph) error
 	GetGraph(ctx context.Context, graphID string) (*types.Graph, error)
@@ -32,51 +56,106 @@ type Storage interface {
 	DeleteGraph(ctx context.Context, graphID string) error
 	ListGraphs(ctx context.Context) ([]*types.Graph, error)
 
+	// Graph version operations; versions are immutable once saved
+	SaveGraphVersion(ctx context.Context, graph *types.Graph) error
+	// PublishGraphVersion saves a graph revision and the graph itself in one write, so the
+	// default graph never names an unsaved revision. It fails with ErrGraphVersionExists if the
+	// revision was already published.
+	PublishGraphVersion(ctx context.Context, graph *types.Graph) error
+	GetGraphVersion(ctx context.Context, graphID string, revision int) (*types.Graph, error)
+	ListGraphVersions(ctx context.Context, graphID string) ([]*types.GraphVersion, error)
+
 	// Close closes the storage connections
 	Close() error
 }
 
 // PostgresRedisStorage implements Storage using PostgreSQL and Redis
 type PostgresRedisStorage struct {
-	db     *sql.DB
-	redis  *redis.Client
-	logger *logrus.Logger
+	db             *sql.DB
+	redis          *redis.Client
+	cache          *redisCache
+	logger         *logrus.Logger
+	outboxDisabled bool
 }
 
-// New creates a new storage instance
+// New creates a new storage instance. A driver selected with DB_DRIVER must start, so the
+// service never silently runs on memory when it was configured to persist. Without a driver,
+// PostgreSQL + Redis is used when configured, falling back to memory.
 func New(config *config.Config) (Storage, error) {
 	logger := logrus.New()
 	logger.SetLevel(logrus.InfoLevel)
 
-	// Check if database configuration is provided
-	if !isDatabaseConfigured(config.Database) {
-		logger.Info("No database configuration provided, using in-memory storage")
+	storage, err := openStorage(config, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	// Nothing would deliver or prune outbox messages, and they hold the submitted data
+	if !config.Webhooks.Enabled() {
+		storage.DisableOutbox()
+	}
+	return storage, nil
+}
+
+// openStorage opens the backend the configuration selects
+func openStorage(config *config.Config, logger *logrus.Logger) (Storage, error) {
+	switch config.Database.Driver {
+	case "":
+		// Check if database configuration is provided
+		if !isDatabaseConfigured(config.Database) {
+			logger.Info("No database configuration provided, using in-memory storage")
+			return NewMemoryStorage(logger), nil
+		}
+	case "postgres":
+		if !isDatabaseConfigured(config.Database) {
+			return nil, fmt.Errorf("database driver postgres requires DB_HOST, DB_USER, DB_PASSWORD and DB_NAME")
+		}
+	case "memory":
+		logger.Info("Using in-memory storage")
 		return NewMemoryStorage(logger), nil
+	case "sqlite":
+		storage, err := NewSQLiteStorage(config.Database.SQLitePath, logger)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open SQLite database %s: %w", config.Database.SQLitePath, err)
+		}
+		return storage, nil
+	default:
+		return nil, fmt.Errorf("unknown database driver %q", config.Database.Driver)
 	}
 
-	// Connect to PostgreSQL
-	db, err := connectPostgres(config.Database)
+	storage, err := NewPostgresRedisStorage(config, logger)
 	if err != nil {
-		logger.WithError(err).Warn("Failed to connect to PostgreSQL, falling back to in-memory storage")
+		if config.Database.Driver != "" {
+			return nil, fmt.Errorf("failed to initialize PostgreSQL storage: %w", err)
+		}
+		logger.WithError(err).Warn("Failed to initialize PostgreSQL storage, falling back to in-memory storage")
 		return NewMemoryStorage(logger), nil
 	}
 
-	// Connect to Redis
-	redisClient := connectRedis(config.Redis)
+	logger.Info("Using PostgreSQL + Redis storage")
+	return storage, nil
+}
+
+// NewPostgresRedisStorage connects to PostgreSQL and Redis and initializes the database schema
+func NewPostgresRedisStorage(config *config.Config, logger *logrus.Logger) (*PostgresRedisStorage, error) {
+	db, err := connectPostgres(config.Database)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to PostgreSQL: %w", err)
+	}
 
+	client := connectRedis(config.Redis)
 	storage := &PostgresRedisStorage{
 		db:     db,
-		redis:  redisClient,
+		redis:  client,
+		cache:  newRedisCache(client, config.Redis, logger),
 		logger: logger,
 	}
 
-	// Initialize database schema
 	if err := storage.initSchema(); err != nil {
-		logger.WithError(err).Warn("Failed to initialize database schema, falling back to in-memory storage")
-		return NewMemoryStorage(logger), nil
+		storage.Close()
+		return nil, fmt.Errorf("failed to initialize database schema: %w", err)
 	}
 
-	logger.Info("Using PostgreSQL + Redis storage")
 	return storage, nil
 }
 
@@ -116,63 +195,213 @@ func connectRedis(config config.RedisConfig) *redis.Client {
 	})
 }
 
-// initSchema initializes the database schema
+// postgresMigrations is the PostgreSQL schema. Migration 1 is the schema created before migrations
+// were recorded; its statements are idempotent so existing databases adopt it unchanged.
+// Append new migrations; never edit applied ones.
+var postgresMigrations = []migration{
+	{
+		version: 1,
+		name:    "create graphs, nodes, edges, graph versions and sessions",
+		statements: []string{
+			`CREATE TABLE IF NOT EXISTS graphs (
+				id VARCHAR(36) PRIMARY KEY,
+				name VARCHAR(255) NOT NULL,
+				description TEXT,
+				version VARCHAR(50),
+				revision INTEGER DEFAULT 0,
+				start_node_id VARCHAR(36),
+				metadata JSONB,
+				rule_groups JSONB,
+				created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+				updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+			)`,
+			`ALTER TABLE graphs ADD COLUMN IF NOT EXISTS rule_groups JSONB`,
+			`ALTER TABLE graphs ADD COLUMN IF NOT EXISTS revision INTEGER DEFAULT 0`,
+			`CREATE TABLE IF NOT EXISTS graph_versions (
+				graph_id VARCHAR(36) NOT NULL,
+				revision INTEGER NOT NULL,
+				definition JSONB NOT NULL,
+				created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+				PRIMARY KEY (graph_id, revision)
+			)`,
+			`CREATE TABLE IF NOT EXISTS nodes (
+				id VARCHAR(36) PRIMARY KEY,
+				graph_id VARCHAR(36) REFERENCES graphs(id) ON DELETE CASCADE,
+				type VARCHAR(50) NOT NULL,
+				name VARCHAR(255) NOT NULL,
+				description TEXT,
+				fields JSONB,
+				validation JSONB,
+				metadata JSONB,
+				created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+				updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+			)`,
+			`CREATE TABLE IF NOT EXISTS edges (
+				id VARCHAR(36) PRIMARY KEY,
+				graph_id VARCHAR(36) REFERENCES graphs(id) ON DELETE CASCADE,
+				from_node_id VARCHAR(36) REFERENCES nodes(id) ON DELETE CASCADE,
+				to_node_id VARCHAR(36) REFERENCES nodes(id) ON DELETE CASCADE,
+				condition JSONB,
+				metadata JSONB,
+				created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+			)`,
+			`CREATE TABLE IF NOT EXISTS sessions (
+				id VARCHAR(36) PRIMARY KEY,
+				user_id VARCHAR(255) NOT NULL,
+				graph_id VARCHAR(36) REFERENCES graphs(id),
+				graph_revision INTEGER DEFAULT 0,
+				current_node_id VARCHAR(36),
+				data JSONB,
+				history JSONB,
+				status VARCHAR(50) NOT NULL,
+				retry_count INTEGER DEFAULT 0,
+				created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+				updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+				completed_at TIMESTAMP
+			)`,
+			`ALTER TABLE sessions ADD COLUMN IF NOT EXISTS graph_revision INTEGER DEFAULT 0`,
+			`CREATE INDEX IF NOT EXISTS idx_sessions_user_id ON sessions(user_id)`,
+			`CREATE INDEX IF NOT EXISTS idx_sessions_status ON sessions(status)`,
+			`CREATE INDEX IF NOT EXISTS idx_nodes_graph_id ON nodes(graph_id)`,
+			`CREATE INDEX IF NOT EXISTS idx_edges_graph_id ON edges(graph_id)`,
+		},
+	},
+	{
+		version: 2,
+		name:    "persist dynamic session state, cross-node validation and node dependencies",
+		statements: []string{
+			`ALTER TABLE sessions ADD COLUMN IF NOT EXISTS dynamic_state JSONB`,
+			`ALTER TABLE graphs ADD COLUMN IF NOT EXISTS cross_node_validation JSONB`,
+			`ALTER TABLE nodes ADD COLUMN IF NOT EXISTS incoming_edges JSONB`,
+			`ALTER TABLE nodes ADD COLUMN IF NOT EXISTS outgoing_edges JSONB`,
+			`ALTER TABLE nodes ADD COLUMN IF NOT EXISTS is_independent BOOLEAN NOT NULL DEFAULT FALSE`,
+			`ALTER TABLE nodes ADD COLUMN IF NOT EXISTS is_dependent BOOLEAN NOT NULL DEFAULT FALSE`,
+			`ALTER TABLE nodes ADD COLUMN IF NOT EXISTS dependencies JSONB`,
+		},
+	},
+	{
+		version: 3,
+		name:    "scope node and edge ids to their graph",
+		statements: []string{
+			`ALTER TABLE edges DROP CONSTRAINT IF EXISTS edges_from_node_id_fkey`,
+			`ALTER TABLE edges DROP CONSTRAINT IF EXISTS edges_to_node_id_fkey`,
+			`ALTER TABLE edges DROP CONSTRAINT IF EXISTS edges_pkey`,
+			`ALTER TABLE edges ADD PRIMARY KEY (graph_id, id)`,
+			`ALTER TABLE nodes DROP CONSTRAINT IF EXISTS nodes_pkey`,
+			`ALTER TABLE nodes ADD PRIMARY KEY (graph_id, id)`,
+		},
+	},
+	{
+		version: 4,
+		name:    "add session revisions",
+		statements: []string{
+			`ALTER TABLE sessions ADD COLUMN IF NOT EXISTS revision INTEGER NOT NULL DEFAULT 0`,
+		},
+	},
+	{
+		version: 5,
+		name:    "create session events",
+		statements: []string{
+			`CREATE TABLE IF NOT EXISTS session_events (
+				session_id VARCHAR(36) NOT NULL REFERENCES sessions(id) ON DELETE CASCADE,
+				sequence INTEGER NOT NULL,
+				id VARCHAR(36) NOT NULL,
+				type VARCHAR(100) NOT NULL,
+				event JSONB NOT NULL,
+				occurred_at TIMESTAMP NOT NULL,
+				PRIMARY KEY (session_id, sequence)
+			)`,
+		},
+	},
+	{
+		version: 6,
+		name:    "create webhook outbox",
+		statements: []string{
+			`CREATE TABLE IF NOT EXISTS outbox (
+				position BIGSERIAL PRIMARY KEY,
+				id VARCHAR(36) NOT NULL UNIQUE,
+				session_id VARCHAR(36) NOT NULL,
+				event_type VARCHAR(100) NOT NULL,
+				event JSONB NOT NULL,
+				status VARCHAR(20) NOT NULL,
+				attempts INTEGER NOT NULL DEFAULT 0,
+				next_attempt_at TIMESTAMP NOT NULL,
+				last_error TEXT NOT NULL DEFAULT '',
+				delivered_to JSONB NOT NULL,
+				created_at TIMESTAMP NOT NULL,
+				updated_at TIMESTAMP NOT NULL
+			)`,
+			`CREATE INDEX IF NOT EXISTS idx_outbox_status_next_attempt ON outbox(status, next_attempt_at)`,
+		},
+	},
+	{
+		version: 7,
+		name:    "index session listing filters and sort orders",
+		statements: []string{
+			`CREATE INDEX IF NOT EXISTS idx_sessions_created_at_id ON sessions(created_at, id)`,
+			`CREATE INDEX IF NOT EXISTS idx_sessions_updated_at_id ON sessions(updated_at, id)`,
+			`CREATE INDEX IF NOT EXISTS idx_sessions_user_id_created_at ON sessions(user_id, created_at, id)`,
+			`CREATE INDEX IF NOT EXISTS idx_sessions_status_created_at ON sessions(status, created_at, id)`,
+			`CREATE INDEX IF NOT EXISTS idx_sessions_graph_id_created_at ON sessions(graph_id, created_at, id)`,
+			`CREATE INDEX IF NOT EXISTS idx_sessions_current_node_id ON sessions(current_node_id)`,
+			`CREATE INDEX IF NOT EXISTS idx_sessions_business_type ON sessions((data->>'business_type'))`,
+			// Superseded by the composite indexes above
+			`DROP INDEX IF EXISTS idx_sessions_user_id`,
+			`DROP INDEX IF EXISTS idx_sessions_status`,
+		},
+	},
+	{
+		version: 8,
+		name:    "persist activation rules, node rules, entry nodes and advanced session state",
+		statements: []string{
+			`ALTER TABLE graphs ADD COLUMN IF NOT EXISTS activation_rules JSONB`,
+			`ALTER TABLE graphs ADD COLUMN IF NOT EXISTS node_rules JSONB`,
+			`ALTER TABLE graphs ADD COLUMN IF NOT EXISTS entry_nodes JSONB`,
+			`ALTER TABLE sessions ADD COLUMN IF NOT EXISTS advanced_state JSONB`,
+		},
+	},
+	{
+		version: 9,
+		name:    "index outbox messages by status and update time for pruning",
+		statements: []string{
+			`CREATE INDEX IF NOT EXISTS idx_outbox_status_updated_at ON outbox(status, updated_at)`,
+		},
+	},
+	{
+		version: 10,
+		name:    "let sessions outlive their graph",
+		statements: []string{
+			`ALTER TABLE sessions DROP CONSTRAINT IF EXISTS sessions_graph_id_fkey`,
+		},
+	},
+}
+
+// migrationLockID is the advisory lock that keeps instances starting together from migrating at once
+const migrationLockID = 7243911
+
+// initSchema brings the database schema up to date
 func (s *PostgresRedisStorage) initSchema() error {
-	queries := []string{
-		`CREATE TABLE IF NOT EXISTS graphs (
-			id VARCHAR(36) PRIMARY KEY,
-			name VARCHAR(255) NOT NULL,
-			description TEXT,
-			version VARCHAR(50),
-			start_node_id VARCHAR(36),
-			metadata JSONB,
-			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
-		)`,
-		`CREATE TABLE IF NOT EXISTS nodes (
-			id VARCHAR(36) PRIMARY KEY,
-			graph_id VARCHAR(36) REFERENCES graphs(id) ON DELETE CASCADE,
-			type VARCHAR(50) NOT NULL,
-			name VARCHAR(255) NOT NULL,
-			description TEXT,
-			fields JSONB,
-			validation JSONB,
-			metadata JSONB,
-			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
-		)`,
-		`CREATE TABLE IF NOT EXISTS edges (
-			id VARCHAR(36) PRIMARY KEY,
-			graph_id VARCHAR(36) REFERENCES graphs(id) ON DELETE CASCADE,
-			from_node_id VARCHAR(36) REFERENCES nodes(id) ON DELETE CASCADE,
-			to_node_id VARCHAR(36) REFERENCES nodes(id) ON DELETE CASCADE,
-			condition JSONB,
-			metadata JSONB,
-			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
-		)`,
-		`CREATE TABLE IF NOT EXISTS sessions (
-			id VARCHAR(36) PRIMARY KEY,
-			user_id VARCHAR(255) NOT NULL,
-			graph_id VARCHAR(36) REFERENCES graphs(id),
-			current_node_id VARCHAR(36),
-			data JSONB,
-			history JSONB,
-			status VARCHAR(50) NOT NULL,
-			retry_count INTEGER DEFAULT 0,
-			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-			completed_at TIMESTAMP
-		)`,
-		`CREATE INDEX IF NOT EXISTS idx_sessions_user_id ON sessions(user_id)`,
-		`CREATE INDEX IF NOT EXISTS idx_sessions_status ON sessions(status)`,
-		`CREATE INDEX IF NOT EXISTS idx_nodes_graph_id ON nodes(graph_id)`,
-		`CREATE INDEX IF NOT EXISTS idx_edges_graph_id ON edges(graph_id)`,
-	}
-
-	for _, query := range queries {
-		if _, err := s.db.Exec(query); err != nil {
-			return fmt.Errorf("failed to execute query: %w", err)
-		}
+	ctx := context.Background()
+
+	// Advisory locks belong to a session, so lock, migrate and unlock on one connection
+	conn, err := s.db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get connection: %w", err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, `SELECT pg_advisory_lock($1)`, migrationLockID); err != nil {
+		return fmt.Errorf("failed to acquire migration lock: %w", err)
+	}
+	defer conn.ExecContext(ctx, `SELECT pg_advisory_unlock($1)`, migrationLockID)
+
+	applied, err := migrate(ctx, conn, postgresMigrations)
+	if err != nil {
+		return err
+	}
+
+	if applied > 0 {
+		s.logger.WithField("migrations_applied", applied).Info("Migrated PostgreSQL schema")
 	}
 
 	return nil
@@ -180,6 +409,11 @@ func (s *PostgresRedisStorage) initSchema() error {
 
 // SaveSession saves a session to the database
 func (s *PostgresRedisStorage) SaveSession(ctx context.Context, session *types.Session) error {
+	return s.SaveSessionWithEvents(ctx, session, nil)
+}
+
+// SaveSessionWithEvents saves a session and appends events to its log in one transaction
+func (s *PostgresRedisStorage) SaveSessionWithEvents(ctx context.Context, session *types.Session, events []*types.SessionEvent) error {
 	dataJSON, err := json.Marshal(session.Data)
 	if err != nil {
 		return fmt.Errorf("failed to marshal session data: %w", err)
@@ -190,51 +424,274 @@ func (s *PostgresRedisStorage) SaveSession(ctx context.Context, session *types.S
 		return fmt.Errorf("failed to marshal session history: %w", err)
 	}
 
-	query := `INSERT INTO sessions (id, user_id, graph_id, current_node_id, data, history, status, retry_count, created_at, updated_at, completed_at)
-			  VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+	// A nil dynamic state is stored as NULL rather than the JSON null
+	var dynamicStateJSON interface{}
+	if session.DynamicState != nil {
+		if dynamicStateJSON, err = json.Marshal(session.DynamicState); err != nil {
+			return fmt.Errorf("failed to marshal session dynamic state: %w", err)
+		}
+	}
+	var advancedStateJSON interface{}
+	if session.AdvancedState != nil {
+		if advancedStateJSON, err = json.Marshal(session.AdvancedState); err != nil {
+			return fmt.Errorf("failed to marshal session advanced state: %w", err)
+		}
+	}
+
+	// The update only applies if the stored revision is the one the session was loaded at
+	query := `INSERT INTO sessions (id, user_id, graph_id, graph_revision, current_node_id, data, history, status, retry_count, dynamic_state, advanced_state, revision, created_at, updated_at, completed_at)
+			  VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12 + 1, $13, $14, $15)
 			  ON CONFLICT (id) DO UPDATE SET
+			  graph_revision = EXCLUDED.graph_revision,
 			  current_node_id = EXCLUDED.current_node_id,
 			  data = EXCLUDED.data,
 			  history = EXCLUDED.history,
 			  status = EXCLUDED.status,
 			  retry_count = EXCLUDED.retry_count,
+			  dynamic_state = EXCLUDED.dynamic_state,
+			  advanced_state = EXCLUDED.advanced_state,
+			  revision = EXCLUDED.revision,
 			  updated_at = EXCLUDED.updated_at,
-			  completed_at = EXCLUDED.completed_at`
+			  completed_at = EXCLUDED.completed_at
+			  WHERE sessions.revision = $12
+			  RETURNING revision`
 
-	_, err = s.db.ExecContext(ctx, query,
-		session.ID, session.UserID, session.GraphID, session.CurrentNodeID,
-		dataJSON, historyJSON, session.Status, session.RetryCount,
-		session.CreatedAt, session.UpdatedAt, session.CompletedAt)
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
 
+	var revision int
+	err = tx.QueryRowContext(ctx, query,
+		session.ID, session.UserID, session.GraphID, session.GraphRevision, session.CurrentNodeID,
+		dataJSON, historyJSON, session.Status, session.RetryCount, dynamicStateJSON, advancedStateJSON, session.Revision,
+		session.CreatedAt, session.UpdatedAt, session.CompletedAt).Scan(&revision)
+
+	if err == sql.ErrNoRows {
+		tx.Rollback()
+		return s.sessionConflict(ctx, session)
+	}
 	if err != nil {
 		return fmt.Errorf("failed to save session: %w", err)
 	}
 
-	// Cache in Redis
-	s.cacheSession(ctx, session)
+	// The session row stays locked until commit, so concurrent saves cannot interleave events
+	if err := s.appendEvents(ctx, tx, session.ID, revision, events); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	session.Revision = revision
+
+	s.cache.invalidate(ctx, cacheKindSession, session.ID)
+
+	return nil
+}
+
+// appendEvents adds events to the end of a session's log
+func (s *PostgresRedisStorage) appendEvents(ctx context.Context, tx *sql.Tx, sessionID string, revision int, events []*types.SessionEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	var last int
+	err := tx.QueryRowContext(ctx, `SELECT COALESCE(MAX(sequence), 0) FROM session_events WHERE session_id = $1`, sessionID).Scan(&last)
+	if err != nil {
+		return fmt.Errorf("failed to get last session event: %w", err)
+	}
+
+	for i, event := range events {
+		event.SessionID = sessionID
+		event.Sequence = last + i + 1
+		event.Revision = revision
+
+		eventJSON, err := json.Marshal(event)
+		if err != nil {
+			return fmt.Errorf("failed to marshal session event: %w", err)
+		}
+
+		_, err = tx.ExecContext(ctx,
+			`INSERT INTO session_events (session_id, sequence, id, type, event, occurred_at) VALUES ($1, $2, $3, $4, $5, $6)`,
+			sessionID, event.Sequence, event.ID, event.Type, eventJSON, event.OccurredAt)
+		if err != nil {
+			return fmt.Errorf("failed to save session event: %w", err)
+		}
+
+		if s.outboxDisabled {
+			continue
+		}
+		message := newOutboxMessage(event, time.Now())
+		_, err = tx.ExecContext(ctx,
+			`INSERT INTO outbox (`+outboxColumns+`) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`,
+			message.ID, message.SessionID, message.EventType, eventJSON, message.Status, message.Attempts,
+			message.NextAttemptAt, message.LastError, "[]", message.CreatedAt, message.UpdatedAt)
+		if err != nil {
+			return fmt.Errorf("failed to save outbox message: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// ListSessionEvents returns a session's events in the order they were saved
+func (s *PostgresRedisStorage) ListSessionEvents(ctx context.Context, sessionID string) ([]*types.SessionEvent, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT event FROM session_events WHERE session_id = $1 ORDER BY sequence`, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query session events: %w", err)
+	}
+	defer rows.Close()
+
+	return scanSessionEvents(rows)
+}
+
+// ClaimOutboxMessages returns the oldest pending messages that are due and defers them by lease.
+// Rows claimed by a concurrent dispatcher are skipped rather than waited for.
+func (s *PostgresRedisStorage) ClaimOutboxMessages(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*types.OutboxMessage, error) {
+	query := `WITH claimed AS (
+				UPDATE outbox SET next_attempt_at = $3
+				WHERE id IN (
+					SELECT id FROM outbox
+					WHERE status = $4 AND next_attempt_at <= $1
+					ORDER BY position
+					LIMIT $2
+					FOR UPDATE SKIP LOCKED
+				)
+				RETURNING position, ` + outboxColumns + `
+			  )
+			  SELECT ` + outboxColumns + ` FROM claimed ORDER BY position`
+
+	rows, err := s.db.QueryContext(ctx, query, now, limit, now.Add(lease), types.OutboxStatusPending)
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim outbox messages: %w", err)
+	}
+	defer rows.Close()
+
+	return scanOutboxMessages(rows)
+}
+
+// UpdateOutboxMessage saves an outbox message's delivery state
+func (s *PostgresRedisStorage) UpdateOutboxMessage(ctx context.Context, message *types.OutboxMessage) error {
+	deliveredToJSON, err := json.Marshal(message.DeliveredTo)
+	if err != nil {
+		return fmt.Errorf("failed to marshal outbox deliveries: %w", err)
+	}
+
+	message.UpdatedAt = time.Now()
+	result, err := s.db.ExecContext(ctx,
+		`UPDATE outbox SET status = $1, attempts = $2, next_attempt_at = $3, last_error = $4, delivered_to = $5, updated_at = $6 WHERE id = $7`,
+		message.Status, message.Attempts, message.NextAttemptAt, message.LastError, deliveredToJSON, message.UpdatedAt, message.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update outbox message: %w", err)
+	}
 
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		return fmt.Errorf("outbox message not found")
+	}
 	return nil
 }
 
+// GetOutboxMessage retrieves an outbox message
+func (s *PostgresRedisStorage) GetOutboxMessage(ctx context.Context, id string) (*types.OutboxMessage, error) {
+	message, err := scanOutboxMessage(s.db.QueryRowContext(ctx, `SELECT `+outboxColumns+` FROM outbox WHERE id = $1`, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("outbox message not found")
+		}
+		return nil, fmt.Errorf("failed to get outbox message: %w", err)
+	}
+	return message, nil
+}
+
+// ListOutboxMessages returns the oldest outbox messages with the given status
+func (s *PostgresRedisStorage) ListOutboxMessages(ctx context.Context, status types.OutboxStatus, limit int) ([]*types.OutboxMessage, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT `+outboxColumns+` FROM outbox WHERE status = $1 ORDER BY position LIMIT $2`, status, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query outbox: %w", err)
+	}
+	defer rows.Close()
+
+	return scanOutboxMessages(rows)
+}
+
+// PruneOutboxMessages deletes the messages with status last updated before the cutoff
+func (s *PostgresRedisStorage) PruneOutboxMessages(ctx context.Context, status types.OutboxStatus, before time.Time) (int, error) {
+	result, err := s.db.ExecContext(ctx, `DELETE FROM outbox WHERE status = $1 AND updated_at < $2`, status, before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to prune outbox: %w", err)
+	}
+	pruned, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to prune outbox: %w", err)
+	}
+	return int(pruned), nil
+}
+
+// DisableOutbox stops adding saved events to the outbox
+func (s *PostgresRedisStorage) DisableOutbox() {
+	s.outboxDisabled = true
+}
+
+// scanSessionEvents reads rows holding one event JSON column each
+func scanSessionEvents(rows *sql.Rows) ([]*types.SessionEvent, error) {
+	events := make([]*types.SessionEvent, 0)
+	for rows.Next() {
+		var eventJSON []byte
+		if err := rows.Scan(&eventJSON); err != nil {
+			return nil, fmt.Errorf("failed to scan session event: %w", err)
+		}
+
+		var event types.SessionEvent
+		if err := json.Unmarshal(eventJSON, &event); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal session event: %w", err)
+		}
+		events = append(events, &event)
+	}
+
+	return events, rows.Err()
+}
+
+// sessionConflict returns the conflict error for a save that did not match the stored revision
+func (s *PostgresRedisStorage) sessionConflict(ctx context.Context, session *types.Session) error {
+	// Read past the cache, which may hold the stale revision if an invalidation failed, and drop it
+	current, err := s.loadSession(ctx, session.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get conflicting session: %w", err)
+	}
+	s.cache.invalidate(ctx, cacheKindSession, session.ID)
+
+	return &SessionConflictError{SessionID: session.ID, Revision: session.Revision, Current: current}
+}
+
 // GetSession retrieves a session from the database
 func (s *PostgresRedisStorage) GetSession(ctx context.Context, sessionID string) (*types.Session, error) {
 	// Try to get from cache first
-	if session := s.getCachedSession(ctx, sessionID); session != nil {
-		return session, nil
+	var cached types.Session
+	hit, generation := s.cache.get(ctx, cacheKindSession, sessionID, &cached)
+	if hit {
+		return &cached, nil
 	}
 
-	query := `SELECT id, user_id, graph_id, current_node_id, data, history, status, retry_count, created_at, updated_at, completed_at
-			  FROM sessions WHERE id = $1`
+	session, err := s.loadSession(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
 
-	var session types.Session
-	var dataJSON, historyJSON []byte
-	var completedAt sql.NullTime
+	// Cache the session under the generation read before loading it
+	s.cache.set(ctx, cacheKindSession, sessionID, generation, session)
 
-	err := s.db.QueryRowContext(ctx, query, sessionID).Scan(
-		&session.ID, &session.UserID, &session.GraphID, &session.CurrentNodeID,
-		&dataJSON, &historyJSON, &session.Status, &session.RetryCount,
-		&session.CreatedAt, &session.UpdatedAt, &completedAt)
+	return session, nil
+}
 
+// loadSession reads a session from the database, bypassing the cache
+func (s *PostgresRedisStorage) loadSession(ctx context.Context, sessionID string) (*types.Session, error) {
+	query := `SELECT ` + sessionColumns + ` FROM sessions WHERE id = $1`
+
+	session, err := scanSession(s.db.QueryRowContext(ctx, query, sessionID))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, fmt.Errorf("session not found")
@@ -242,6 +699,32 @@ func (s *PostgresRedisStorage) GetSession(ctx context.Context, sessionID string)
 		return nil, fmt.Errorf("failed to get session: %w", err)
 	}
 
+	return session, nil
+}
+
+// sessionColumns lists the session columns in the order scanSession expects
+const sessionColumns = `id, user_id, graph_id, graph_revision, current_node_id, data, history, status, retry_count, dynamic_state, advanced_state, revision, created_at, updated_at, completed_at`
+
+// rowScanner is implemented by *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanSession scans a row selected with sessionColumns
+func scanSession(row rowScanner) (*types.Session, error) {
+	var session types.Session
+	var dataJSON, historyJSON, dynamicStateJSON, advancedStateJSON []byte
+	var completedAt sql.NullTime
+	var graphRevision sql.NullInt64
+
+	err := row.Scan(
+		&session.ID, &session.UserID, &session.GraphID, &graphRevision, &session.CurrentNodeID,
+		&dataJSON, &historyJSON, &session.Status, &session.RetryCount, &dynamicStateJSON, &advancedStateJSON, &session.Revision,
+		&session.CreatedAt, &session.UpdatedAt, &completedAt)
+	if err != nil {
+		return nil, err
+	}
+
 	// Unmarshal JSON fields
 	if err := json.Unmarshal(dataJSON, &session.Data); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal session data: %w", err)
@@ -251,13 +734,23 @@ func (s *PostgresRedisStorage) GetSession(ctx context.Context, sessionID string)
 		return nil, fmt.Errorf("failed to unmarshal session history: %w", err)
 	}
 
+	if len(dynamicStateJSON) > 0 {
+		if err := json.Unmarshal(dynamicStateJSON, &session.DynamicState); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal session dynamic state: %w", err)
+		}
+	}
+
+	if len(advancedStateJSON) > 0 {
+		if err := json.Unmarshal(advancedStateJSON, &session.AdvancedState); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal session advanced state: %w", err)
+		}
+	}
+
+	session.GraphRevision = int(graphRevision.Int64)
 	if completedAt.Valid {
 		session.CompletedAt = &completedAt.Time
 	}
 
-	// Cache the session
-	s.cacheSession(ctx, &session)
-
 	return &session, nil
 }
 
@@ -274,16 +767,14 @@ func (s *PostgresRedisStorage) DeleteSession(ctx context.Context, sessionID stri
 		return fmt.Errorf("failed to delete session: %w", err)
 	}
 
-	// Remove from cache
-	s.redis.Del(ctx, fmt.Sprintf("session:%s", sessionID))
+	s.cache.invalidate(ctx, cacheKindSession, sessionID)
 
 	return nil
 }
 
 // ListSessions lists sessions for a user
 func (s *PostgresRedisStorage) ListSessions(ctx context.Context, userID string) ([]*types.Session, error) {
-	query := `SELECT id, user_id, graph_id, current_node_id, data, history, status, retry_count, created_at, updated_at, completed_at
-			  FROM sessions WHERE user_id = $1 ORDER BY created_at DESC`
+	query := `SELECT ` + sessionColumns + ` FROM sessions WHERE user_id = $1 ORDER BY created_at DESC`
 
 	rows, err := s.db.QueryContext(ctx, query, userID)
 	if err != nil {
@@ -293,33 +784,12 @@ func (s *PostgresRedisStorage) ListSessions(ctx context.Context, userID string)
 
 	var sessions []*types.Session
 	for rows.Next() {
-		var session types.Session
-		var dataJSON, historyJSON []byte
-		var completedAt sql.NullTime
-
-		err := rows.Scan(
-			&session.ID, &session.UserID, &session.GraphID, &session.CurrentNodeID,
-			&dataJSON, &historyJSON, &session.Status, &session.RetryCount,
-			&session.CreatedAt, &session.UpdatedAt, &completedAt)
-
+		session, err := scanSession(rows)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan session: %w", err)
 		}
 
-		// Unmarshal JSON fields
-		if err := json.Unmarshal(dataJSON, &session.Data); err != nil {
-			return nil, fmt.Errorf("failed to unmarshal session data: %w", err)
-		}
-
-		if err := json.Unmarshal(historyJSON, &session.History); err != nil {
-			return nil, fmt.Errorf("failed to unmarshal session history: %w", err)
-		}
-
-		if completedAt.Valid {
-			session.CompletedAt = &completedAt.Time
-		}
-
-		sessions = append(sessions, &session)
+		sessions = append(sessions, session)
 	}
 
 	return sessions, nil
@@ -327,8 +797,7 @@ func (s *PostgresRedisStorage) ListSessions(ctx context.Context, userID string)
 
 // ListAllSessions lists all sessions for admin dashboard
 func (s *PostgresRedisStorage) ListAllSessions(ctx context.Context) ([]*types.Session, error) {
-	query := `SELECT id, user_id, graph_id, current_node_id, data, history, status, retry_count, created_at, updated_at, completed_at
-			  FROM sessions ORDER BY created_at DESC`
+	query := `SELECT ` + sessionColumns + ` FROM sessions ORDER BY created_at DESC`
 
 	rows, err := s.db.QueryContext(ctx, query)
 	if err != nil {
@@ -338,36 +807,50 @@ func (s *PostgresRedisStorage) ListAllSessions(ctx context.Context) ([]*types.Se
 
 	var sessions []*types.Session
 	for rows.Next() {
-		var session types.Session
-		var dataJSON, historyJSON []byte
-		var completedAt sql.NullTime
-
-		err := rows.Scan(
-			&session.ID, &session.UserID, &session.GraphID, &session.CurrentNodeID,
-			&dataJSON, &historyJSON, &session.Status, &session.RetryCount,
-			&session.CreatedAt, &session.UpdatedAt, &completedAt)
-
+		session, err := scanSession(rows)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan session: %w", err)
 		}
 
-		// Unmarshal JSON fields
-		if err := json.Unmarshal(dataJSON, &session.Data); err != nil {
-			return nil, fmt.Errorf("failed to unmarshal session data: %w", err)
-		}
+		sessions = append(sessions, session)
+	}
 
-		if err := json.Unmarshal(historyJSON, &session.History); err != nil {
-			return nil, fmt.Errorf("failed to unmarshal session history: %w", err)
-		}
+	return sessions, nil
+}
 
-		if completedAt.Valid {
-			session.CompletedAt = &completedAt.Time
-		}
+// ListSessionsQuery returns one page of the sessions matching query
+func (s *PostgresRedisStorage) ListSessionsQuery(ctx context.Context, query SessionQuery) (*SessionPage, error) {
+	query, cursor, err := query.normalize()
+	if err != nil {
+		return nil, err
+	}
+
+	statement, args := buildSessionQuery(sessionColumns, query, cursor, sessionQueryDialect{
+		placeholder:  func(n int) string { return fmt.Sprintf("$%d", n) },
+		businessType: "data->>'business_type'",
+		// Timestamps are stored without a time zone as the writer's local time
+		filterTime: func(t time.Time) time.Time { return t.Local() },
+	})
 
-		sessions = append(sessions, &session)
+	rows, err := s.db.QueryContext(ctx, statement, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
 	}
+	defer rows.Close()
 
-	return sessions, nil
+	var sessions []*types.Session
+	for rows.Next() {
+		session, err := scanSession(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan session: %w", err)
+		}
+		sessions = append(sessions, session)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+
+	return query.page(sessions)
 }
 
 // SaveGraph saves a graph to the database
@@ -378,15 +861,36 @@ func (s *PostgresRedisStorage) SaveGraph(ctx context.Context, graph *types.Graph
 	}
 	defer tx.Rollback()
 
+	if err := s.saveGraph(ctx, tx, graph); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	s.cache.invalidate(ctx, cacheKindGraph, graph.ID)
+
+	return nil
+}
+
+// saveGraph writes a graph with its nodes and edges within tx
+func (s *PostgresRedisStorage) saveGraph(ctx context.Context, tx *sql.Tx, graph *types.Graph) error {
 	// Save graph
-	graphQuery := `INSERT INTO graphs (id, name, description, version, start_node_id, metadata, created_at, updated_at)
-				   VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	graphQuery := `INSERT INTO graphs (id, name, description, version, revision, start_node_id, metadata, rule_groups, cross_node_validation, activation_rules, node_rules, entry_nodes, created_at, updated_at)
+				   VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
 				   ON CONFLICT (id) DO UPDATE SET
 				   name = EXCLUDED.name,
 				   description = EXCLUDED.description,
 				   version = EXCLUDED.version,
+				   revision = EXCLUDED.revision,
 				   start_node_id = EXCLUDED.start_node_id,
 				   metadata = EXCLUDED.metadata,
+				   rule_groups = EXCLUDED.rule_groups,
+				   cross_node_validation = EXCLUDED.cross_node_validation,
+				   activation_rules = EXCLUDED.activation_rules,
+				   node_rules = EXCLUDED.node_rules,
+				   entry_nodes = EXCLUDED.entry_nodes,
 				   updated_at = EXCLUDED.updated_at`
 
 	metadataJSON, err := json.Marshal(graph.Metadata)
@@ -394,14 +898,38 @@ func (s *PostgresRedisStorage) SaveGraph(ctx context.Context, graph *types.Graph
 		return fmt.Errorf("failed to marshal graph metadata: %w", err)
 	}
 
+	ruleGroupsJSON, err := json.Marshal(graph.RuleGroups)
+	if err != nil {
+		return fmt.Errorf("failed to marshal graph rule groups: %w", err)
+	}
+
+	crossNodeJSON, err := json.Marshal(graph.CrossNodeValidation)
+	if err != nil {
+		return fmt.Errorf("failed to marshal graph cross node validation: %w", err)
+	}
+
+	activationRulesJSON, nodeRulesJSON, entryNodesJSON, err := marshalAdvancedRules(graph)
+	if err != nil {
+		return err
+	}
+
 	_, err = tx.ExecContext(ctx, graphQuery,
-		graph.ID, graph.Name, graph.Description, graph.Version,
-		graph.StartNodeID, metadataJSON, graph.CreatedAt, graph.UpdatedAt)
+		graph.ID, graph.Name, graph.Description, graph.Version, graph.Revision,
+		graph.StartNodeID, metadataJSON, ruleGroupsJSON, crossNodeJSON,
+		activationRulesJSON, nodeRulesJSON, entryNodesJSON, graph.CreatedAt, graph.UpdatedAt)
 
 	if err != nil {
 		return fmt.Errorf("failed to save graph: %w", err)
 	}
 
+	// Replace nodes and edges so ones removed from the graph do not linger
+	if _, err := tx.ExecContext(ctx, `DELETE FROM edges WHERE graph_id = $1`, graph.ID); err != nil {
+		return fmt.Errorf("failed to clear graph edges: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM nodes WHERE graph_id = $1`, graph.ID); err != nil {
+		return fmt.Errorf("failed to clear graph nodes: %w", err)
+	}
+
 	// Save nodes
 	for _, node := range graph.Nodes {
 		if err := s.saveNode(ctx, tx, graph.ID, node); err != nil {
@@ -416,13 +944,6 @@ func (s *PostgresRedisStorage) SaveGraph(ctx context.Context, graph *types.Graph
 		}
 	}
 
-	if err := tx.Commit(); err != nil {
-		return fmt.Errorf("failed to commit transaction: %w", err)
-	}
-
-	// Cache in Redis
-	s.cacheGraph(ctx, graph)
-
 	return nil
 }
 
@@ -443,20 +964,43 @@ func (s *PostgresRedisStorage) saveNode(ctx context.Context, tx *sql.Tx, graphID
 		return fmt.Errorf("failed to marshal node metadata: %w", err)
 	}
 
-	query := `INSERT INTO nodes (id, graph_id, type, name, description, fields, validation, metadata, created_at, updated_at)
-			  VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
-			  ON CONFLICT (id) DO UPDATE SET
+	incomingJSON, err := json.Marshal(node.IncomingEdges)
+	if err != nil {
+		return fmt.Errorf("failed to marshal node incoming edges: %w", err)
+	}
+
+	outgoingJSON, err := json.Marshal(node.OutgoingEdges)
+	if err != nil {
+		return fmt.Errorf("failed to marshal node outgoing edges: %w", err)
+	}
+
+	dependenciesJSON, err := json.Marshal(node.Dependencies)
+	if err != nil {
+		return fmt.Errorf("failed to marshal node dependencies: %w", err)
+	}
+
+	query := `INSERT INTO nodes (id, graph_id, type, name, description, fields, validation, metadata,
+			  incoming_edges, outgoing_edges, is_independent, is_dependent, dependencies, created_at, updated_at)
+			  VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+			  ON CONFLICT (graph_id, id) DO UPDATE SET
 			  type = EXCLUDED.type,
 			  name = EXCLUDED.name,
 			  description = EXCLUDED.description,
 			  fields = EXCLUDED.fields,
 			  validation = EXCLUDED.validation,
 			  metadata = EXCLUDED.metadata,
+			  incoming_edges = EXCLUDED.incoming_edges,
+			  outgoing_edges = EXCLUDED.outgoing_edges,
+			  is_independent = EXCLUDED.is_independent,
+			  is_dependent = EXCLUDED.is_dependent,
+			  dependencies = EXCLUDED.dependencies,
 			  updated_at = EXCLUDED.updated_at`
 
 	_, err = tx.ExecContext(ctx, query,
 		node.ID, graphID, node.Type, node.Name, node.Description,
-		fieldsJSON, validationJSON, metadataJSON, node.CreatedAt, node.UpdatedAt)
+		fieldsJSON, validationJSON, metadataJSON,
+		incomingJSON, outgoingJSON, node.IsIndependent, node.IsDependent, dependenciesJSON,
+		node.CreatedAt, node.UpdatedAt)
 
 	return err
 }
@@ -475,7 +1019,7 @@ func (s *PostgresRedisStorage) saveEdge(ctx context.Context, tx *sql.Tx, graphID
 
 	query := `INSERT INTO edges (id, graph_id, from_node_id, to_node_id, condition, metadata, created_at)
 			  VALUES ($1, $2, $3, $4, $5, $6, $7)
-			  ON CONFLICT (id) DO UPDATE SET
+			  ON CONFLICT (graph_id, id) DO UPDATE SET
 			  from_node_id = EXCLUDED.from_node_id,
 			  to_node_id = EXCLUDED.to_node_id,
 			  condition = EXCLUDED.condition,
@@ -491,20 +1035,35 @@ func (s *PostgresRedisStorage) saveEdge(ctx context.Context, tx *sql.Tx, graphID
 // GetGraph retrieves a graph from the database
 func (s *PostgresRedisStorage) GetGraph(ctx context.Context, graphID string) (*types.Graph, error) {
 	// Try to get from cache first
-	if graph := s.getCachedGraph(ctx, graphID); graph != nil {
-		return graph, nil
+	var cached types.Graph
+	hit, generation := s.cache.get(ctx, cacheKindGraph, graphID, &cached)
+	if hit {
+		return &cached, nil
+	}
+
+	graph, err := s.loadGraph(ctx, graphID)
+	if err != nil {
+		return nil, err
 	}
 
-	// Get graph
-	graphQuery := `SELECT id, name, description, version, start_node_id, metadata, created_at, updated_at
+	// Cache the graph under the generation read before loading it
+	s.cache.set(ctx, cacheKindGraph, graphID, generation, graph)
+
+	return graph, nil
+}
+
+// loadGraph reads a graph with its nodes and edges from the database, bypassing the cache
+func (s *PostgresRedisStorage) loadGraph(ctx context.Context, graphID string) (*types.Graph, error) {
+	graphQuery := `SELECT id, name, description, version, COALESCE(revision, 0), start_node_id, metadata, rule_groups, cross_node_validation, activation_rules, node_rules, entry_nodes, created_at, updated_at
 				   FROM graphs WHERE id = $1`
 
 	var graph types.Graph
-	var metadataJSON []byte
+	var metadataJSON, ruleGroupsJSON, crossNodeJSON, activationRulesJSON, nodeRulesJSON, entryNodesJSON []byte
 
 	err := s.db.QueryRowContext(ctx, graphQuery, graphID).Scan(
-		&graph.ID, &graph.Name, &graph.Description, &graph.Version,
-		&graph.StartNodeID, &metadataJSON, &graph.CreatedAt, &graph.UpdatedAt)
+		&graph.ID, &graph.Name, &graph.Description, &graph.Version, &graph.Revision,
+		&graph.StartNodeID, &metadataJSON, &ruleGroupsJSON, &crossNodeJSON,
+		&activationRulesJSON, &nodeRulesJSON, &entryNodesJSON, &graph.CreatedAt, &graph.UpdatedAt)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -518,6 +1077,24 @@ func (s *PostgresRedisStorage) GetGraph(ctx context.Context, graphID string) (*t
 		return nil, fmt.Errorf("failed to unmarshal graph metadata: %w", err)
 	}
 
+	// Unmarshal rule groups
+	if len(ruleGroupsJSON) > 0 {
+		if err := json.Unmarshal(ruleGroupsJSON, &graph.RuleGroups); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal graph rule groups: %w", err)
+		}
+	}
+
+	// Unmarshal cross-node validation rules
+	if len(crossNodeJSON) > 0 {
+		if err := json.Unmarshal(crossNodeJSON, &graph.CrossNodeValidation); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal graph cross node validation: %w", err)
+		}
+	}
+
+	if err := unmarshalAdvancedRules(&graph, activationRulesJSON, nodeRulesJSON, entryNodesJSON); err != nil {
+		return nil, err
+	}
+
 	// Get nodes
 	nodes, err := s.getGraphNodes(ctx, graphID)
 	if err != nil {
@@ -532,15 +1109,48 @@ func (s *PostgresRedisStorage) GetGraph(ctx context.Context, graphID string) (*t
 	}
 	graph.Edges = edges
 
-	// Cache the graph
-	s.cacheGraph(ctx, &graph)
-
 	return &graph, nil
 }
 
+// marshalAdvancedRules encodes a graph's activation rules, node rules and entry nodes for storage
+func marshalAdvancedRules(graph *types.Graph) (activationRules, nodeRules, entryNodes []byte, err error) {
+	if activationRules, err = json.Marshal(graph.ActivationRules); err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to marshal graph activation rules: %w", err)
+	}
+	if nodeRules, err = json.Marshal(graph.NodeRules); err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to marshal graph node rules: %w", err)
+	}
+	if entryNodes, err = json.Marshal(graph.EntryNodes); err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to marshal graph entry nodes: %w", err)
+	}
+	return activationRules, nodeRules, entryNodes, nil
+}
+
+// unmarshalAdvancedRules decodes the columns written by marshalAdvancedRules; graphs saved before
+// the columns existed have none
+func unmarshalAdvancedRules(graph *types.Graph, activationRules, nodeRules, entryNodes []byte) error {
+	if len(activationRules) > 0 {
+		if err := json.Unmarshal(activationRules, &graph.ActivationRules); err != nil {
+			return fmt.Errorf("failed to unmarshal graph activation rules: %w", err)
+		}
+	}
+	if len(nodeRules) > 0 {
+		if err := json.Unmarshal(nodeRules, &graph.NodeRules); err != nil {
+			return fmt.Errorf("failed to unmarshal graph node rules: %w", err)
+		}
+	}
+	if len(entryNodes) > 0 {
+		if err := json.Unmarshal(entryNodes, &graph.EntryNodes); err != nil {
+			return fmt.Errorf("failed to unmarshal graph entry nodes: %w", err)
+		}
+	}
+	return nil
+}
+
 // getGraphNodes retrieves all nodes for a graph
 func (s *PostgresRedisStorage) getGraphNodes(ctx context.Context, graphID string) (map[string]*types.Node, error) {
-	query := `SELECT id, type, name, description, fields, validation, metadata, created_at, updated_at
+	query := `SELECT id, type, name, description, fields, validation, metadata,
+			  incoming_edges, outgoing_edges, is_independent, is_dependent, dependencies, created_at, updated_at
 			  FROM nodes WHERE graph_id = $1`
 
 	rows, err := s.db.QueryContext(ctx, query, graphID)
@@ -552,11 +1162,12 @@ func (s *PostgresRedisStorage) getGraphNodes(ctx context.Context, graphID string
 	nodes := make(map[string]*types.Node)
 	for rows.Next() {
 		var node types.Node
-		var fieldsJSON, validationJSON, metadataJSON []byte
+		var fieldsJSON, validationJSON, metadataJSON, incomingJSON, outgoingJSON, dependenciesJSON []byte
 
 		err := rows.Scan(
 			&node.ID, &node.Type, &node.Name, &node.Description,
 			&fieldsJSON, &validationJSON, &metadataJSON,
+			&incomingJSON, &outgoingJSON, &node.IsIndependent, &node.IsDependent, &dependenciesJSON,
 			&node.CreatedAt, &node.UpdatedAt)
 
 		if err != nil {
@@ -576,6 +1187,25 @@ func (s *PostgresRedisStorage) getGraphNodes(ctx context.Context, graphID string
 			return nil, fmt.Errorf("failed to unmarshal node metadata: %w", err)
 		}
 
+		// Edge tracking and dependencies are NULL for nodes saved before they were persisted
+		if len(incomingJSON) > 0 {
+			if err := json.Unmarshal(incomingJSON, &node.IncomingEdges); err != nil {
+				return nil, fmt.Errorf("failed to unmarshal node incoming edges: %w", err)
+			}
+		}
+
+		if len(outgoingJSON) > 0 {
+			if err := json.Unmarshal(outgoingJSON, &node.OutgoingEdges); err != nil {
+				return nil, fmt.Errorf("failed to unmarshal node outgoing edges: %w", err)
+			}
+		}
+
+		if len(dependenciesJSON) > 0 {
+			if err := json.Unmarshal(dependenciesJSON, &node.Dependencies); err != nil {
+				return nil, fmt.Errorf("failed to unmarshal node dependencies: %w", err)
+			}
+		}
+
 		nodes[node.ID] = &node
 	}
 
@@ -626,23 +1256,33 @@ func (s *PostgresRedisStorage) UpdateGraph(ctx context.Context, graph *types.Gra
 	return s.SaveGraph(ctx, graph)
 }
 
-// DeleteGraph deletes a graph from the database
+// DeleteGraph deletes a graph and its versions; nodes and edges are removed by the foreign key cascade
 func (s *PostgresRedisStorage) DeleteGraph(ctx context.Context, graphID string) error {
-	query := `DELETE FROM graphs WHERE id = $1`
-	_, err := s.db.ExecContext(ctx, query, graphID)
+	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM graph_versions WHERE graph_id = $1`, graphID); err != nil {
+		return fmt.Errorf("failed to delete graph versions: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM graphs WHERE id = $1`, graphID); err != nil {
 		return fmt.Errorf("failed to delete graph: %w", err)
 	}
 
-	// Remove from cache
-	s.redis.Del(ctx, fmt.Sprintf("graph:%s", graphID))
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	s.cache.invalidate(ctx, cacheKindGraph, graphID)
 
 	return nil
 }
 
 // ListGraphs lists all graphs
 func (s *PostgresRedisStorage) ListGraphs(ctx context.Context) ([]*types.Graph, error) {
-	query := `SELECT id, name, description, version, start_node_id, metadata, created_at, updated_at
+	query := `SELECT id, name, description, version, COALESCE(revision, 0), start_node_id, metadata, created_at, updated_at
 			  FROM graphs ORDER BY created_at DESC`
 
 	rows, err := s.db.QueryContext(ctx, query)
@@ -657,7 +1297,7 @@ func (s *PostgresRedisStorage) ListGraphs(ctx context.Context) ([]*types.Graph,
 		var metadataJSON []byte
 
 		err := rows.Scan(
-			&graph.ID, &graph.Name, &graph.Description, &graph.Version,
+			&graph.ID, &graph.Name, &graph.Description, &graph.Version, &graph.Revision,
 			&graph.StartNodeID, &metadataJSON, &graph.CreatedAt, &graph.UpdatedAt)
 
 		if err != nil {
@@ -675,70 +1315,134 @@ func (s *PostgresRedisStorage) ListGraphs(ctx context.Context) ([]*types.Graph,
 	return graphs, nil
 }
 
-// Close closes the storage connections
-func (s *PostgresRedisStorage) Close() error {
-	if err := s.db.Close(); err != nil {
-		return fmt.Errorf("failed to close database: %w", err)
+// SaveGraphVersion stores an immutable snapshot of a graph revision
+func (s *PostgresRedisStorage) SaveGraphVersion(ctx context.Context, graph *types.Graph) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
 	}
+	defer tx.Rollback()
 
-	if err := s.redis.Close(); err != nil {
-		return fmt.Errorf("failed to close redis: %w", err)
+	if err := s.saveGraphVersion(ctx, tx, graph); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
 	}
 
 	return nil
 }
 
-// Cache operations
-func (s *PostgresRedisStorage) cacheSession(ctx context.Context, session *types.Session) {
-	sessionJSON, err := json.Marshal(session)
+// PublishGraphVersion saves a graph revision and makes it the default graph in one transaction
+func (s *PostgresRedisStorage) PublishGraphVersion(ctx context.Context, graph *types.Graph) error {
+	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
-		s.logger.WithError(err).Error("Failed to marshal session for cache")
-		return
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// The version insert fails on an existing revision before the default graph is touched
+	if err := s.saveGraphVersion(ctx, tx, graph); err != nil {
+		return err
+	}
+	if err := s.saveGraph(ctx, tx, graph); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
 	}
 
-	key := fmt.Sprintf("session:%s", session.ID)
-	s.redis.Set(ctx, key, sessionJSON, 24*time.Hour)
+	s.cache.invalidate(ctx, cacheKindGraph, graph.ID)
+
+	return nil
 }
 
-func (s *PostgresRedisStorage) getCachedSession(ctx context.Context, sessionID string) *types.Session {
-	key := fmt.Sprintf("session:%s", sessionID)
-	sessionJSON, err := s.redis.Get(ctx, key).Result()
+// saveGraphVersion inserts a graph revision snapshot within tx
+func (s *PostgresRedisStorage) saveGraphVersion(ctx context.Context, tx *sql.Tx, graph *types.Graph) error {
+	definitionJSON, err := json.Marshal(graph)
 	if err != nil {
-		return nil
+		return fmt.Errorf("failed to marshal graph version: %w", err)
 	}
 
-	var session types.Session
-	if err := json.Unmarshal([]byte(sessionJSON), &session); err != nil {
-		s.logger.WithError(err).Error("Failed to unmarshal cached session")
-		return nil
+	query := `INSERT INTO graph_versions (graph_id, revision, definition, created_at)
+			  VALUES ($1, $2, $3, $4)
+			  ON CONFLICT (graph_id, revision) DO NOTHING`
+
+	result, err := tx.ExecContext(ctx, query, graph.ID, graph.Revision, definitionJSON, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to save graph version: %w", err)
 	}
 
-	return &session
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		return &GraphVersionExistsError{GraphID: graph.ID, Revision: graph.Revision}
+	}
+
+	return nil
 }
 
-func (s *PostgresRedisStorage) cacheGraph(ctx context.Context, graph *types.Graph) {
-	graphJSON, err := json.Marshal(graph)
-	if err != nil {
-		s.logger.WithError(err).Error("Failed to marshal graph for cache")
-		return
+// GetGraphVersion retrieves a specific revision of a graph
+func (s *PostgresRedisStorage) GetGraphVersion(ctx context.Context, graphID string, revision int) (*types.Graph, error) {
+	query := `SELECT definition FROM graph_versions WHERE graph_id = $1 AND revision = $2`
+
+	var definitionJSON []byte
+	if err := s.db.QueryRowContext(ctx, query, graphID, revision).Scan(&definitionJSON); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("graph version not found")
+		}
+		return nil, fmt.Errorf("failed to get graph version: %w", err)
 	}
 
-	key := fmt.Sprintf("graph:%s", graph.ID)
-	s.redis.Set(ctx, key, graphJSON, 24*time.Hour)
+	var graph types.Graph
+	if err := json.Unmarshal(definitionJSON, &graph); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal graph version: %w", err)
+	}
+
+	return &graph, nil
 }
 
-func (s *PostgresRedisStorage) getCachedGraph(ctx context.Context, graphID string) *types.Graph {
-	key := fmt.Sprintf("graph:%s", graphID)
-	graphJSON, err := s.redis.Get(ctx, key).Result()
+// ListGraphVersions lists all revisions of a graph, oldest first
+func (s *PostgresRedisStorage) ListGraphVersions(ctx context.Context, graphID string) ([]*types.GraphVersion, error) {
+	query := `SELECT revision, definition->>'name', COALESCE(definition->>'version', ''), created_at
+			  FROM graph_versions WHERE graph_id = $1 ORDER BY revision`
+
+	rows, err := s.db.QueryContext(ctx, query, graphID)
 	if err != nil {
-		return nil
+		return nil, fmt.Errorf("failed to list graph versions: %w", err)
 	}
+	defer rows.Close()
 
-	var graph types.Graph
-	if err := json.Unmarshal([]byte(graphJSON), &graph); err != nil {
-		s.logger.WithError(err).Error("Failed to unmarshal cached graph")
-		return nil
+	versions := make([]*types.GraphVersion, 0)
+	for rows.Next() {
+		version := &types.GraphVersion{GraphID: graphID}
+		if err := rows.Scan(&version.Revision, &version.Name, &version.Version, &version.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan graph version: %w", err)
+		}
+		versions = append(versions, version)
+	}
+
+	return versions, nil
+}
+
+// Close closes the storage connections
+func (s *PostgresRedisStorage) Close() error {
+	if err := s.cache.close(); err != nil {
+		s.logger.WithError(err).Warn("Failed to stop cache invalidation listener")
 	}
 
-	return &graph
+	if err := s.db.Close(); err != nil {
+		return fmt.Errorf("failed to close database: %w", err)
+	}
+
+	if err := s.redis.Close(); err != nil {
+		return fmt.Errorf("failed to close redis: %w", err)
+	}
+
+	return nil
+}
+
+// CacheStats returns the cache hit, miss and invalidation counters
+func (s *PostgresRedisStorage) CacheStats() CacheStats {
+	return s.cache.stats()
 }
@@ -0,0 +1,1034 @@
+// Package storagetest is a conformance suite that every storage.Storage implementation must pass.
+//
+// Implementations run it from their own tests:
+//
+//	storagetest.Run(t, func(t *testing.T) storage.Storage {
+//		return storage.NewMemoryStorage(logger)
+//	})
+//
+// Every test uses its own graph, session and user IDs, so the suite can run against a shared database.
+package storagetest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"onboarding-system/internal/storage"
+	"onboarding-system/internal/types"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Factory returns a new instance of the storage under test for each test, since tests may change
+// its settings; it registers any cleanup on t
+type Factory func(t *testing.T) storage.Storage
+
+// Run runs the conformance suite against storages created by newStorage
+func Run(t *testing.T, newStorage Factory) {
+	tests := []struct {
+		name string
+		fn   func(t *testing.T, store storage.Storage)
+	}{
+		{"GraphRoundTrip", testGraphRoundTrip},
+		{"GraphResaveReplacesNodesAndEdges", testGraphResave},
+		{"GraphsShareNodeIDs", testGraphsShareNodeIDs},
+		{"GraphVersions", testGraphVersions},
+		{"PublishGraphVersion", testPublishGraphVersion},
+		{"DeleteGraphCascades", testDeleteGraphCascades},
+		{"GraphCopyIsolation", testGraphCopyIsolation},
+		{"KeepsUpdatedAt", testKeepsUpdatedAt},
+		{"SessionFieldFidelity", testSessionFieldFidelity},
+		{"SessionUpdateReplacesState", testSessionUpdate},
+		{"SessionCopyIsolation", testSessionCopyIsolation},
+		{"SessionRevisionConflict", testSessionRevisionConflict},
+		{"SessionEvents", testSessionEvents},
+		{"Outbox", testOutbox},
+		{"PruneOutboxMessages", testPruneOutboxMessages},
+		{"DisableOutbox", testDisableOutbox},
+		{"ListSessionsByUser", testListSessionsByUser},
+		{"ListSessionsQuery", testListSessionsQuery},
+		{"DeleteSession", testDeleteSession},
+		{"NotFound", testNotFound},
+		{"ConcurrentWriters", testConcurrentWriters},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fn(t, newStorage(t))
+		})
+	}
+}
+
+// base is the timestamp fixtures are built from; whole seconds in UTC survive every backend
+var base = time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+
+// newID returns an ID unique to this run, so tests do not see each other's data. IDs are bare
+// UUIDs because the Postgres ID columns are VARCHAR(36).
+func newID() string {
+	return uuid.NewString()
+}
+
+// NewGraph returns a graph that sets every persisted graph, node and edge field
+func NewGraph(id string) *types.Graph {
+	return &types.Graph{
+		ID:          id,
+		Name:        "KYC",
+		Description: "Know your customer",
+		Version:     "1.0.0",
+		Revision:    2,
+		StartNodeID: "start",
+		Nodes: map[string]*types.Node{
+			"start": {
+				ID:   "start",
+				Type: types.NodeTypeStart,
+				Name: "Start",
+				Fields: []types.Field{{
+					ID: "business_type", Name: "Business Type", Type: types.FieldTypeSelect, Required: true,
+					Options:    []string{"individual", "llp"},
+					Validation: types.FieldValidation{CustomRules: []string{"business_type_validation"}},
+					Metadata:   map[string]interface{}{"help": "Pick one"},
+				}},
+				Validation:    types.ValidationRules{RequiredFields: []string{"business_type"}, CustomRules: []string{}, Conditions: []types.ValidationCondition{}},
+				OutgoingEdges: []string{"start_to_pan"},
+				IsIndependent: true,
+				Metadata:      map[string]interface{}{"step": float64(1)},
+				CreatedAt:     base,
+				UpdatedAt:     base,
+			},
+			"pan": {
+				ID:            "pan",
+				Type:          types.NodeTypeEnd,
+				Name:          "PAN",
+				Description:   "PAN details",
+				Fields:        []types.Field{},
+				IncomingEdges: []string{"start_to_pan"},
+				IsDependent:   true,
+				Dependencies: []types.NodeDependency{
+					{FieldID: "business_type", Operator: "eq", Value: "llp", Condition: "business_type eq llp", BusinessType: "llp"},
+					{Expression: &types.DependencyExpression{Any: []types.DependencyExpression{
+						{Field: "business_type", Operator: "in", Value: []interface{}{"llp", "trust"}},
+						{Not: &types.DependencyExpression{Field: "pan", Operator: "eq", ValueField: "signatory_pan"}},
+					}}},
+				},
+				Metadata:  map[string]interface{}{},
+				CreatedAt: base,
+				UpdatedAt: base,
+			},
+		},
+		Edges: map[string]*types.Edge{
+			"start_to_pan": {
+				ID:         "start_to_pan",
+				FromNodeID: "start",
+				ToNodeID:   "pan",
+				Condition:  types.EdgeCondition{Type: "field_value", Field: "business_type", Operator: "eq", Value: "llp"},
+				Metadata:   map[string]interface{}{"label": "LLP"},
+				CreatedAt:  base,
+			},
+		},
+		CrossNodeValidation: []types.CrossNodeValidationRule{{
+			ID:        "type_match",
+			Name:      "Type match",
+			Fields:    []types.CrossNodeFieldReference{{NodeID: "start", FieldID: "business_type", Alias: "type"}},
+			Condition: types.CrossNodeCondition{Type: "field_match", Operator: "eq", Fields: []string{"type"}},
+			ErrorMsg:  "Types must match",
+			Severity:  types.ValidationSeverityError,
+			Enabled:   true,
+		}},
+		RuleGroups: []types.RuleGroup{{ID: "llp", Name: "LLP", BusinessTypes: []string{"llp"}, RequiredNodes: []string{"pan"}}},
+		ActivationRules: []types.ActivationRule{{
+			ID:            "llp_activation",
+			Name:          "LLP activation",
+			Conditions:    []types.ActivationCondition{{Field: "business_type", Operator: "eq", Value: "llp", Required: true}},
+			RequiredNodes: []string{"pan"},
+			ExcludedNodes: []string{},
+		}},
+		NodeRules: []types.NodeRule{{
+			ID:         "individual_skips_pan",
+			NodeID:     "start",
+			RuleType:   "path_limitation",
+			Conditions: []types.ActivationCondition{{Field: "business_type", Operator: "eq", Value: "individual"}},
+			Actions:    []types.RuleAction{{Type: types.RuleActionDisableEdge, Target: "start_to_pan"}},
+		}},
+		EntryNodes: []string{"pan"},
+		Metadata:   map[string]interface{}{"owner": "risk"},
+		CreatedAt:  base,
+		UpdatedAt:  base,
+	}
+}
+
+// NewSession returns a session on graphID that sets every types.Session field
+func NewSession(id, userID, graphID string) *types.Session {
+	completedAt := base.Add(time.Hour)
+	return &types.Session{
+		ID:            id,
+		UserID:        userID,
+		GraphID:       graphID,
+		GraphRevision: 2,
+		CurrentNodeID: "pan",
+		Data:          map[string]interface{}{"business_type": "llp", "turnover": float64(1200), "directors": []interface{}{"a", "b"}},
+		History: []types.SessionStep{
+			{ID: "step-1", NodeID: "start", Data: map[string]interface{}{"business_type": "llp"}, Timestamp: base, Action: "forward"},
+			{ID: "step-2", NodeID: "pan", Data: map[string]interface{}{}, Timestamp: base.Add(time.Minute), Action: "backward"},
+		},
+		Status:      types.SessionStatusCompleted,
+		RetryCount:  1,
+		Revision:    1,
+		CreatedAt:   base,
+		UpdatedAt:   base,
+		CompletedAt: &completedAt,
+		DynamicState: &types.DynamicSessionState{
+			BusinessType: "llp",
+			NodeStatuses: map[string]types.NodeStatusInfo{
+				"pan": {
+					Status:        "completed",
+					InitialStatus: "blocked",
+					Dependencies: []types.DependencyInfo{{Condition: "business_type eq llp", Expression: &types.DependencyExpression{
+						All: []types.DependencyExpression{{Field: "business_type", Operator: "eq", Value: "llp"}},
+					}}},
+					Reason:        "it is required for business type llp",
+					LastUpdatedAt: base,
+					Metadata:      map[string]interface{}{"source": "dependency"},
+				},
+			},
+			LastEvaluatedAt:  base,
+			CompletionStatus: map[string]interface{}{"percent": float64(100)},
+		},
+		AdvancedState: &types.AdvancedSessionState{
+			EntryNodeID:      "start",
+			VisitedNodes:     []string{"start", "pan"},
+			DisabledEdges:    []string{},
+			ExcludedNodes:    []string{"gst"},
+			RequiredNodes:    []string{"pan"},
+			ActivationStatus: types.ActivationStatusActivated,
+			ActivationRules:  []string{"llp_activation"},
+			PathHistory: []types.PathStep{{
+				NodeID:        "start",
+				Timestamp:     base.UnixMilli(),
+				Data:          map[string]interface{}{"business_type": "llp"},
+				RulesApplied:  []string{},
+				EdgesDisabled: []string{},
+			}},
+		},
+	}
+}
+
+// assertSame compares values by their JSON encoding, so backends may return times in
+// any location and numbers as float64, as long as the API would serve the same document
+func assertSame(t *testing.T, expected, actual interface{}, msgAndArgs ...interface{}) {
+	t.Helper()
+	expectedJSON, err := json.Marshal(expected)
+	require.NoError(t, err)
+	actualJSON, err := json.Marshal(actual)
+	require.NoError(t, err)
+	assert.JSONEq(t, string(expectedJSON), string(actualJSON), msgAndArgs...)
+}
+
+// saveGraph saves a fresh fixture graph and returns it
+func saveGraph(t *testing.T, store storage.Storage) *types.Graph {
+	t.Helper()
+	graph := NewGraph(newID())
+	require.NoError(t, store.SaveGraph(context.Background(), graph))
+	return graph
+}
+
+func testGraphRoundTrip(t *testing.T, store storage.Storage) {
+	ctx := context.Background()
+	graph := saveGraph(t, store)
+
+	loaded, err := store.GetGraph(ctx, graph.ID)
+	require.NoError(t, err)
+	assertSame(t, graph, loaded)
+
+	graphs, err := store.ListGraphs(ctx)
+	require.NoError(t, err)
+	found := false
+	for _, listed := range graphs {
+		if listed.ID == graph.ID {
+			found = true
+			assert.Equal(t, graph.Name, listed.Name)
+		}
+	}
+	assert.True(t, found, "ListGraphs includes the saved graph")
+}
+
+func testGraphResave(t *testing.T, store storage.Storage) {
+	ctx := context.Background()
+	graph := saveGraph(t, store)
+
+	delete(graph.Nodes, "pan")
+	graph.Edges = map[string]*types.Edge{}
+	graph.Nodes["start"].OutgoingEdges = nil
+	graph.Name = "KYC v2"
+	require.NoError(t, store.UpdateGraph(ctx, graph))
+
+	loaded, err := store.GetGraph(ctx, graph.ID)
+	require.NoError(t, err)
+	assertSame(t, graph, loaded)
+}
+
+func testGraphsShareNodeIDs(t *testing.T, store storage.Storage) {
+	ctx := context.Background()
+	first := saveGraph(t, store)
+	second := saveGraph(t, store)
+
+	loaded, err := store.GetGraph(ctx, first.ID)
+	require.NoError(t, err)
+	assertSame(t, first, loaded, "saving a graph with the same node IDs leaves other graphs intact")
+
+	loaded, err = store.GetGraph(ctx, second.ID)
+	require.NoError(t, err)
+	assertSame(t, second, loaded)
+}
+
+func testGraphVersions(t *testing.T, store storage.Storage) {
+	ctx := context.Background()
+	graph := NewGraph(newID())
+	first := NewGraph(graph.ID)
+	first.Revision = 1
+	first.Name = "KYC v1"
+
+	require.NoError(t, store.SaveGraphVersion(ctx, first))
+	require.NoError(t, store.SaveGraphVersion(ctx, graph))
+	err := store.SaveGraphVersion(ctx, graph)
+	assert.EqualError(t, err, fmt.Sprintf("graph version %s@2 already exists", graph.ID))
+	assert.ErrorIs(t, err, storage.ErrGraphVersionExists)
+
+	loaded, err := store.GetGraphVersion(ctx, graph.ID, 1)
+	require.NoError(t, err)
+	assertSame(t, first, loaded)
+
+	versions, err := store.ListGraphVersions(ctx, graph.ID)
+	require.NoError(t, err)
+	require.Len(t, versions, 2)
+	for i, version := range versions {
+		assert.Equal(t, graph.ID, version.GraphID)
+		assert.Equal(t, i+1, version.Revision, "versions are listed oldest first")
+		assert.False(t, version.CreatedAt.IsZero())
+	}
+	assert.Equal(t, "KYC v1", versions[0].Name)
+	assert.Equal(t, "1.0.0", versions[1].Version)
+
+	// Saved versions are snapshots; later changes to the graph do not reach them
+	graph.Name = "Changed"
+	loaded, err = store.GetGraphVersion(ctx, graph.ID, 2)
+	require.NoError(t, err)
+	assert.Equal(t, "KYC", loaded.Name)
+}
+
+func testPublishGraphVersion(t *testing.T, store storage.Storage) {
+	ctx := context.Background()
+	first := NewGraph(newID())
+	first.Revision = 1
+	require.NoError(t, store.PublishGraphVersion(ctx, first))
+
+	// Publishing saves the revision and makes it the default graph
+	second := NewGraph(first.ID)
+	second.Description = "Second revision"
+	require.NoError(t, store.PublishGraphVersion(ctx, second))
+
+	loaded, err := store.GetGraph(ctx, first.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 2, loaded.Revision)
+	assert.Equal(t, "Second revision", loaded.Description)
+	version, err := store.GetGraphVersion(ctx, first.ID, 2)
+	require.NoError(t, err)
+	assertSame(t, second, version)
+
+	// A revision published twice is rejected without touching the default graph
+	again := NewGraph(first.ID)
+	again.Description = "Lost the race"
+	err = store.PublishGraphVersion(ctx, again)
+	assert.ErrorIs(t, err, storage.ErrGraphVersionExists)
+
+	loaded, err = store.GetGraph(ctx, first.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Second revision", loaded.Description)
+	version, err = store.GetGraphVersion(ctx, first.ID, 2)
+	require.NoError(t, err)
+	assert.Equal(t, "Second revision", version.Description)
+
+	versions, err := store.ListGraphVersions(ctx, first.ID)
+	require.NoError(t, err)
+	assert.Len(t, versions, 2)
+}
+
+func testDeleteGraphCascades(t *testing.T, store storage.Storage) {
+	ctx := context.Background()
+	graph := saveGraph(t, store)
+	require.NoError(t, store.SaveGraphVersion(ctx, graph))
+	session := NewSession(newID(), newID(), graph.ID)
+	require.NoError(t, store.SaveSession(ctx, session))
+
+	require.NoError(t, store.DeleteGraph(ctx, graph.ID))
+
+	_, err := store.GetGraph(ctx, graph.ID)
+	assert.EqualError(t, err, "graph not found")
+	_, err = store.GetGraphVersion(ctx, graph.ID, graph.Revision)
+	assert.EqualError(t, err, "graph version not found")
+	versions, err := store.ListGraphVersions(ctx, graph.ID)
+	require.NoError(t, err)
+	assert.Empty(t, versions)
+
+	// Sessions are the users' records, so they outlive the graph they were started on
+	loadedSession, err := store.GetSession(ctx, session.ID)
+	require.NoError(t, err)
+	assertSame(t, session, loadedSession)
+
+	// Recreating the graph starts from a clean slate, with no nodes or edges left behind
+	recreated := NewGraph(graph.ID)
+	recreated.Nodes = map[string]*types.Node{"start": recreated.Nodes["start"]}
+	recreated.Nodes["start"].OutgoingEdges = nil
+	recreated.Edges = map[string]*types.Edge{}
+	require.NoError(t, store.SaveGraph(ctx, recreated))
+	loaded, err := store.GetGraph(ctx, graph.ID)
+	require.NoError(t, err)
+	assertSame(t, recreated, loaded)
+}
+
+func testKeepsUpdatedAt(t *testing.T, store storage.Storage) {
+	ctx := context.Background()
+
+	// Callers decide when an entity last changed, so the storage keeps the time it is given
+	graph := saveGraph(t, store)
+	assert.Equal(t, base, graph.UpdatedAt)
+	published := NewGraph(newID())
+	require.NoError(t, store.PublishGraphVersion(ctx, published))
+	assert.Equal(t, base, published.UpdatedAt)
+	session := NewSession(newID(), newID(), graph.ID)
+	require.NoError(t, store.SaveSession(ctx, session))
+	assert.Equal(t, base, session.UpdatedAt)
+
+	loadedGraph, err := store.GetGraph(ctx, graph.ID)
+	require.NoError(t, err)
+	assert.True(t, base.Equal(loadedGraph.UpdatedAt), "graph updated at %v", loadedGraph.UpdatedAt)
+	loadedGraph, err = store.GetGraph(ctx, published.ID)
+	require.NoError(t, err)
+	assert.True(t, base.Equal(loadedGraph.UpdatedAt), "published graph updated at %v", loadedGraph.UpdatedAt)
+	loadedSession, err := store.GetSession(ctx, session.ID)
+	require.NoError(t, err)
+	assert.True(t, base.Equal(loadedSession.UpdatedAt), "session updated at %v", loadedSession.UpdatedAt)
+}
+
+func testGraphCopyIsolation(t *testing.T, store storage.Storage) {
+	ctx := context.Background()
+	graph := saveGraph(t, store)
+	expected := NewGraph(graph.ID)
+
+	// Changing the saved value does not change what is stored
+	graph.Name = "Changed"
+	graph.Nodes["start"].Name = "Changed"
+	graph.Metadata["owner"] = "changed"
+
+	loaded, err := store.GetGraph(ctx, graph.ID)
+	require.NoError(t, err)
+	assertSame(t, expected, loaded)
+
+	// Nor does changing a loaded value
+	loaded.Nodes["start"].Fields[0].Options[0] = "changed"
+	loaded.Edges["start_to_pan"].Metadata["label"] = "changed"
+	delete(loaded.Nodes, "pan")
+
+	reloaded, err := store.GetGraph(ctx, graph.ID)
+	require.NoError(t, err)
+	assertSame(t, expected, reloaded)
+}
+
+func testSessionFieldFidelity(t *testing.T, store storage.Storage) {
+	ctx := context.Background()
+	graph := saveGraph(t, store)
+	session := NewSession(newID(), newID(), graph.ID)
+
+	// The fixture must set every field, so a field added to types.Session is covered here too
+	value := reflect.ValueOf(session).Elem()
+	for i := 0; i < value.NumField(); i++ {
+		assert.False(t, value.Field(i).IsZero(), "NewSession does not set Session.%s", value.Type().Field(i).Name)
+	}
+
+	require.NoError(t, store.SaveSession(ctx, session))
+	loaded, err := store.GetSession(ctx, session.ID)
+	require.NoError(t, err)
+
+	// Compare field by field so a failure names the field that did not survive
+	loadedValue := reflect.ValueOf(loaded).Elem()
+	for i := 0; i < value.NumField(); i++ {
+		name := value.Type().Field(i).Name
+		assertSame(t, value.Field(i).Interface(), loadedValue.Field(i).Interface(), "Session.%s", name)
+	}
+}
+
+func testSessionUpdate(t *testing.T, store storage.Storage) {
+	ctx := context.Background()
+	graph := saveGraph(t, store)
+	session := NewSession(newID(), newID(), graph.ID)
+	require.NoError(t, store.SaveSession(ctx, session))
+
+	session.History = session.History[:1]
+	session.Data = map[string]interface{}{"business_type": "individual"}
+	session.DynamicState = nil
+	session.AdvancedState = nil
+	session.CompletedAt = nil
+	session.Status = types.SessionStatusActive
+	session.CurrentNodeID = "start"
+	session.RetryCount = 3
+	require.NoError(t, store.UpdateSession(ctx, session))
+
+	loaded, err := store.GetSession(ctx, session.ID)
+	require.NoError(t, err)
+	assertSame(t, session, loaded)
+}
+
+func testSessionCopyIsolation(t *testing.T, store storage.Storage) {
+	ctx := context.Background()
+	graph := saveGraph(t, store)
+	session := NewSession(newID(), newID(), graph.ID)
+	require.NoError(t, store.SaveSession(ctx, session))
+	expected := NewSession(session.ID, session.UserID, graph.ID)
+	expected.Revision = session.Revision
+
+	// Changing the saved value does not change what is stored
+	session.Data["business_type"] = "changed"
+	session.History[0].NodeID = "changed"
+	session.DynamicState.BusinessType = "changed"
+	session.AdvancedState.VisitedNodes[0] = "changed"
+
+	loaded, err := store.GetSession(ctx, session.ID)
+	require.NoError(t, err)
+	assertSame(t, expected, loaded)
+
+	// Nor does changing a loaded value
+	loaded.Data["business_type"] = "changed"
+	loaded.History = append(loaded.History, types.SessionStep{ID: "extra"})
+	loaded.DynamicState.NodeStatuses["pan"] = types.NodeStatusInfo{Status: "changed"}
+	*loaded.CompletedAt = time.Time{}
+
+	reloaded, err := store.GetSession(ctx, session.ID)
+	require.NoError(t, err)
+	assertSame(t, expected, reloaded)
+
+	listed, err := store.ListSessions(ctx, session.UserID)
+	require.NoError(t, err)
+	require.Len(t, listed, 1)
+	listed[0].Data["business_type"] = "changed"
+
+	reloaded, err = store.GetSession(ctx, session.ID)
+	require.NoError(t, err)
+	assertSame(t, expected, reloaded)
+}
+
+func testSessionRevisionConflict(t *testing.T, store storage.Storage) {
+	ctx := context.Background()
+	graph := saveGraph(t, store)
+	session := NewSession(newID(), newID(), graph.ID)
+	session.Revision = 0
+	require.NoError(t, store.SaveSession(ctx, session))
+	assert.Equal(t, 1, session.Revision, "saving increments the revision")
+
+	first, err := store.GetSession(ctx, session.ID)
+	require.NoError(t, err)
+	second, err := store.GetSession(ctx, session.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, first.Revision)
+
+	first.Data["turnover"] = float64(5000)
+	require.NoError(t, store.UpdateSession(ctx, first))
+	assert.Equal(t, 2, first.Revision)
+
+	// The second copy was loaded at revision 1, so saving it would lose the first write
+	second.Data["directors"] = []interface{}{"c"}
+	err = store.SaveSession(ctx, second)
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, storage.ErrSessionConflict))
+	var conflict *storage.SessionConflictError
+	require.True(t, errors.As(err, &conflict))
+	assert.Equal(t, session.ID, conflict.SessionID)
+	assert.Equal(t, 1, conflict.Revision)
+	assertSame(t, first, conflict.Current, "the conflict carries the stored session")
+	assert.Equal(t, 1, second.Revision, "a conflicting save leaves the revision alone")
+
+	loaded, err := store.GetSession(ctx, session.ID)
+	require.NoError(t, err)
+	assertSame(t, first, loaded, "a conflicting save changes nothing")
+
+	// Reapplying the change on top of the current session succeeds
+	current := conflict.Current
+	current.Data["directors"] = []interface{}{"c"}
+	require.NoError(t, store.SaveSession(ctx, current))
+	assert.Equal(t, 3, current.Revision)
+
+	loaded, err = store.GetSession(ctx, session.ID)
+	require.NoError(t, err)
+	assert.Equal(t, float64(5000), loaded.Data["turnover"])
+	assert.Equal(t, []interface{}{"c"}, loaded.Data["directors"])
+}
+
+// NewSessionEvent returns a fixture event of the given type that appended a history step
+func NewSessionEvent(eventType types.SessionEventType, nodeID string, occurredAt time.Time) *types.SessionEvent {
+	return &types.SessionEvent{
+		ID:     uuid.New().String(),
+		Type:   eventType,
+		UserID: "user",
+		NodeID: nodeID,
+		Status: types.SessionStatusActive,
+		Data:   map[string]interface{}{"node_id": nodeID, "data": map[string]interface{}{"pan": "ABCDE1234F"}},
+		Step: &types.SessionStep{
+			ID:        "step-" + nodeID,
+			NodeID:    nodeID,
+			Data:      map[string]interface{}{"pan": "ABCDE1234F"},
+			Timestamp: occurredAt,
+			Action:    "forward",
+		},
+		OccurredAt: occurredAt,
+	}
+}
+
+func testSessionEvents(t *testing.T, store storage.Storage) {
+	ctx := context.Background()
+	graph := saveGraph(t, store)
+	session := NewSession(newID(), newID(), graph.ID)
+	session.Revision = 0
+
+	started := NewSessionEvent(types.SessionEventStarted, "start", base)
+	submitted := NewSessionEvent(types.SessionEventSubmitted, "pan", base.Add(time.Minute))
+	require.NoError(t, store.SaveSessionWithEvents(ctx, session, []*types.SessionEvent{started, submitted}))
+	assert.Equal(t, session.ID, started.SessionID)
+	assert.Equal(t, []int{1, 2}, []int{started.Sequence, submitted.Sequence})
+	assert.Equal(t, []int{1, 1}, []int{started.Revision, submitted.Revision}, "events carry the revision they were saved as")
+
+	// Plain saves append nothing
+	require.NoError(t, store.SaveSession(ctx, session))
+
+	completed := NewSessionEvent(types.SessionEventCompleted, "end", base.Add(2*time.Minute))
+	require.NoError(t, store.SaveSessionWithEvents(ctx, session, []*types.SessionEvent{completed}))
+	assert.Equal(t, 3, completed.Sequence)
+	assert.Equal(t, 3, completed.Revision)
+
+	// A conflicting save appends nothing
+	stale := NewSession(session.ID, session.UserID, graph.ID)
+	stale.Revision = 1
+	rejected := NewSessionEvent(types.SessionEventNavigated, "pan", base.Add(3*time.Minute))
+	err := store.SaveSessionWithEvents(ctx, stale, []*types.SessionEvent{rejected})
+	require.True(t, errors.Is(err, storage.ErrSessionConflict))
+
+	events, err := store.ListSessionEvents(ctx, session.ID)
+	require.NoError(t, err)
+	assertSame(t, []*types.SessionEvent{started, submitted, completed}, events)
+
+	// Returned events are copies
+	events[0].Data["node_id"] = "changed"
+	events, err = store.ListSessionEvents(ctx, session.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "start", events[0].Data["node_id"])
+
+	events, err = store.ListSessionEvents(ctx, newID())
+	require.NoError(t, err)
+	assert.Empty(t, events)
+
+	// Deleting the session deletes its events
+	require.NoError(t, store.DeleteSession(ctx, session.ID))
+	session.Revision = 0
+	require.NoError(t, store.SaveSession(ctx, session))
+	events, err = store.ListSessionEvents(ctx, session.ID)
+	require.NoError(t, err)
+	assert.Empty(t, events)
+}
+
+func testOutbox(t *testing.T, store storage.Storage) {
+	ctx := context.Background()
+	graph := saveGraph(t, store)
+	session := NewSession(newID(), newID(), graph.ID)
+	session.Revision = 0
+
+	started := NewSessionEvent(types.SessionEventStarted, "start", base)
+	submitted := NewSessionEvent(types.SessionEventSubmitted, "pan", base.Add(time.Minute))
+	require.NoError(t, store.SaveSessionWithEvents(ctx, session, []*types.SessionEvent{started, submitted}))
+
+	// A conflicting save adds nothing to the outbox
+	stale := NewSession(session.ID, session.UserID, graph.ID)
+	stale.Revision = 0
+	rejected := NewSessionEvent(types.SessionEventNavigated, "pan", base)
+	require.True(t, errors.Is(store.SaveSessionWithEvents(ctx, stale, []*types.SessionEvent{rejected}), storage.ErrSessionConflict))
+	_, err := store.GetOutboxMessage(ctx, rejected.ID)
+	assert.EqualError(t, err, "outbox message not found")
+
+	message, err := store.GetOutboxMessage(ctx, submitted.ID)
+	require.NoError(t, err)
+	assert.Equal(t, session.ID, message.SessionID)
+	assert.Equal(t, types.SessionEventSubmitted, message.EventType)
+	assert.Equal(t, types.OutboxStatusPending, message.Status)
+	assert.Empty(t, message.DeliveredTo)
+	assertSame(t, submitted, message.Event, "the message carries the saved event")
+
+	// Claimed messages come oldest first and are hidden from other claims for the lease
+	now := time.Now().Add(time.Second)
+	claimed, err := store.ClaimOutboxMessages(ctx, now, time.Minute, 1000)
+	require.NoError(t, err)
+	assert.Equal(t, []string{started.ID, submitted.ID}, outboxIDs(claimed, session.ID))
+	claimed, err = store.ClaimOutboxMessages(ctx, now, time.Minute, 1000)
+	require.NoError(t, err)
+	assert.Empty(t, outboxIDs(claimed, session.ID), "claimed messages are leased")
+	claimed, err = store.ClaimOutboxMessages(ctx, now.Add(2*time.Minute), time.Minute, 1000)
+	require.NoError(t, err)
+	assert.Equal(t, []string{started.ID, submitted.ID}, outboxIDs(claimed, session.ID), "an expired lease can be claimed again")
+
+	message = claimed[len(claimed)-1]
+	message.Status = types.OutboxStatusDead
+	message.Attempts = 3
+	message.LastError = "endpoint returned 500"
+	message.DeliveredTo = []string{"https://crm.example.com/hooks"}
+	require.NoError(t, store.UpdateOutboxMessage(ctx, message))
+
+	loaded, err := store.GetOutboxMessage(ctx, submitted.ID)
+	require.NoError(t, err)
+	assert.Equal(t, types.OutboxStatusDead, loaded.Status)
+	assert.Equal(t, 3, loaded.Attempts)
+	assert.Equal(t, "endpoint returned 500", loaded.LastError)
+	assert.Equal(t, []string{"https://crm.example.com/hooks"}, loaded.DeliveredTo)
+
+	dead, err := store.ListOutboxMessages(ctx, types.OutboxStatusDead, 1000)
+	require.NoError(t, err)
+	assert.Equal(t, []string{submitted.ID}, outboxIDs(dead, session.ID))
+	pending, err := store.ListOutboxMessages(ctx, types.OutboxStatusPending, 1000)
+	require.NoError(t, err)
+	assert.Equal(t, []string{started.ID}, outboxIDs(pending, session.ID))
+
+	// Dead messages are never claimed
+	claimed, err = store.ClaimOutboxMessages(ctx, now.Add(time.Hour), time.Minute, 1000)
+	require.NoError(t, err)
+	assert.Equal(t, []string{started.ID}, outboxIDs(claimed, session.ID))
+
+	// Messages outlive their session
+	require.NoError(t, store.DeleteSession(ctx, session.ID))
+	_, err = store.GetOutboxMessage(ctx, started.ID)
+	assert.NoError(t, err)
+
+	missing := &types.OutboxMessage{ID: newID(), DeliveredTo: []string{}}
+	assert.EqualError(t, store.UpdateOutboxMessage(ctx, missing), "outbox message not found")
+}
+
+func testPruneOutboxMessages(t *testing.T, store storage.Storage) {
+	ctx := context.Background()
+	graph := saveGraph(t, store)
+	session := NewSession(newID(), newID(), graph.ID)
+	session.Revision = 0
+
+	started := NewSessionEvent(types.SessionEventStarted, "start", base)
+	submitted := NewSessionEvent(types.SessionEventSubmitted, "pan", base.Add(time.Minute))
+	completed := NewSessionEvent(types.SessionEventCompleted, "end", base.Add(2*time.Minute))
+	require.NoError(t, store.SaveSessionWithEvents(ctx, session, []*types.SessionEvent{started, submitted, completed}))
+
+	for id, status := range map[string]types.OutboxStatus{started.ID: types.OutboxStatusDelivered, submitted.ID: types.OutboxStatusDead} {
+		message, err := store.GetOutboxMessage(ctx, id)
+		require.NoError(t, err)
+		message.Status = status
+		require.NoError(t, store.UpdateOutboxMessage(ctx, message))
+	}
+
+	// Messages updated after the cutoff are kept
+	_, err := store.PruneOutboxMessages(ctx, types.OutboxStatusDelivered, time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	_, err = store.GetOutboxMessage(ctx, started.ID)
+	assert.NoError(t, err)
+
+	// Only messages with the given status are pruned
+	pruned, err := store.PruneOutboxMessages(ctx, types.OutboxStatusDelivered, time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, pruned, 1)
+	_, err = store.GetOutboxMessage(ctx, started.ID)
+	assert.EqualError(t, err, "outbox message not found")
+	for _, id := range []string{submitted.ID, completed.ID} {
+		_, err = store.GetOutboxMessage(ctx, id)
+		assert.NoError(t, err)
+	}
+
+	_, err = store.PruneOutboxMessages(ctx, types.OutboxStatusDead, time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	_, err = store.GetOutboxMessage(ctx, submitted.ID)
+	assert.EqualError(t, err, "outbox message not found")
+	_, err = store.GetOutboxMessage(ctx, completed.ID)
+	assert.NoError(t, err, "pending messages are kept")
+}
+
+func testDisableOutbox(t *testing.T, store storage.Storage) {
+	ctx := context.Background()
+	graph := saveGraph(t, store)
+	session := NewSession(newID(), newID(), graph.ID)
+	session.Revision = 0
+
+	store.DisableOutbox()
+	started := NewSessionEvent(types.SessionEventStarted, "start", base)
+	require.NoError(t, store.SaveSessionWithEvents(ctx, session, []*types.SessionEvent{started}))
+
+	// The event is logged but not queued for delivery
+	events, err := store.ListSessionEvents(ctx, session.ID)
+	require.NoError(t, err)
+	assertSame(t, []*types.SessionEvent{started}, events)
+	_, err = store.GetOutboxMessage(ctx, started.ID)
+	assert.EqualError(t, err, "outbox message not found")
+}
+
+// outboxIDs returns the IDs of the messages for one session, so tests ignore each other's messages
+func outboxIDs(messages []*types.OutboxMessage, sessionID string) []string {
+	ids := make([]string, 0)
+	for _, message := range messages {
+		if message.SessionID == sessionID {
+			ids = append(ids, message.ID)
+		}
+	}
+	return ids
+}
+
+func testListSessionsByUser(t *testing.T, store storage.Storage) {
+	ctx := context.Background()
+	graph := saveGraph(t, store)
+	alice, bob := newID(), newID()
+
+	var aliceSessions []string
+	for i := 0; i < 3; i++ {
+		session := NewSession(newID(), alice, graph.ID)
+		session.CreatedAt = base.Add(time.Duration(i) * time.Minute)
+		require.NoError(t, store.SaveSession(ctx, session))
+		aliceSessions = append(aliceSessions, session.ID)
+	}
+	bobSession := NewSession(newID(), bob, graph.ID)
+	require.NoError(t, store.SaveSession(ctx, bobSession))
+
+	sessions, err := store.ListSessions(ctx, alice)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, aliceSessions, sessionIDs(sessions))
+	for _, session := range sessions {
+		assert.Len(t, session.History, 2, "listed sessions are complete")
+	}
+
+	sessions, err = store.ListSessions(ctx, newID())
+	require.NoError(t, err)
+	assert.Empty(t, sessions)
+
+	sessions, err = store.ListAllSessions(ctx)
+	require.NoError(t, err)
+	assert.Subset(t, sessionIDs(sessions), append(aliceSessions, bobSession.ID))
+}
+
+func testListSessionsQuery(t *testing.T, store storage.Storage) {
+	ctx := context.Background()
+	graph := saveGraph(t, store)
+	alice, bob := newID(), newID()
+
+	fixtures := []struct {
+		userID       string
+		status       types.SessionStatus
+		businessType string
+		nodeID       string
+	}{
+		{alice, types.SessionStatusActive, "llp", "pan"},
+		{bob, types.SessionStatusActive, "private_limited", "gst"},
+		{alice, types.SessionStatusPaused, "llp", "gst"},
+		{bob, types.SessionStatusCompleted, "llp", "end"},
+		{alice, types.SessionStatusActive, "private_limited", "pan"},
+	}
+	ids := make([]string, 0, len(fixtures))
+	for i, fixture := range fixtures {
+		session := NewSession(newID(), fixture.userID, graph.ID)
+		session.Status = fixture.status
+		session.CurrentNodeID = fixture.nodeID
+		session.Data["business_type"] = fixture.businessType
+		session.CreatedAt = base.Add(time.Duration(i) * time.Minute)
+		require.NoError(t, store.SaveSession(ctx, session))
+		ids = append(ids, session.ID)
+	}
+
+	list := func(query storage.SessionQuery) *storage.SessionPage {
+		t.Helper()
+		query.GraphID = graph.ID
+		page, err := store.ListSessionsQuery(ctx, query)
+		require.NoError(t, err)
+		return page
+	}
+
+	// Newest first, two at a time
+	first := list(storage.SessionQuery{Limit: 2})
+	assert.Equal(t, []string{ids[4], ids[3]}, sessionIDs(first.Sessions))
+	require.NotEmpty(t, first.NextCursor)
+	second := list(storage.SessionQuery{Limit: 2, Cursor: first.NextCursor})
+	assert.Equal(t, []string{ids[2], ids[1]}, sessionIDs(second.Sessions))
+	last := list(storage.SessionQuery{Limit: 2, Cursor: second.NextCursor})
+	assert.Equal(t, []string{ids[0]}, sessionIDs(last.Sessions))
+	assert.Empty(t, last.NextCursor, "the last page has no cursor")
+	for _, session := range first.Sessions {
+		assert.Len(t, session.History, 2, "listed sessions are complete")
+	}
+
+	ascending := list(storage.SessionQuery{Ascending: true, Limit: 2})
+	assert.Equal(t, []string{ids[0], ids[1]}, sessionIDs(ascending.Sessions))
+
+	filters := map[string]struct {
+		query storage.SessionQuery
+		want  []string
+	}{
+		"user":          {storage.SessionQuery{UserID: alice}, []string{ids[4], ids[2], ids[0]}},
+		"statuses":      {storage.SessionQuery{Statuses: []types.SessionStatus{types.SessionStatusPaused, types.SessionStatusCompleted}}, []string{ids[3], ids[2]}},
+		"business type": {storage.SessionQuery{BusinessType: "private_limited"}, []string{ids[4], ids[1]}},
+		"current node":  {storage.SessionQuery{CurrentNodeID: "gst"}, []string{ids[2], ids[1]}},
+		"created range": {storage.SessionQuery{CreatedFrom: base.Add(time.Minute), CreatedTo: base.Add(3 * time.Minute)}, []string{ids[2], ids[1]}},
+		"combined":      {storage.SessionQuery{UserID: alice, Statuses: []types.SessionStatus{types.SessionStatusActive}, BusinessType: "llp"}, []string{ids[0]}},
+		"updated range": {storage.SessionQuery{UpdatedFrom: time.Now().Add(time.Hour)}, []string{}},
+	}
+	for name, tt := range filters {
+		assert.Equal(t, tt.want, sessionIDs(list(tt.query).Sessions), name)
+	}
+
+	// Sessions sharing a sort time are each listed exactly once
+	tied := NewSession(newID(), alice, graph.ID)
+	tied.CreatedAt = base.Add(2 * time.Minute)
+	require.NoError(t, store.SaveSession(ctx, tied))
+	var paged []string
+	query := storage.SessionQuery{Limit: 1}
+	for {
+		page := list(query)
+		paged = append(paged, sessionIDs(page.Sessions)...)
+		if page.NextCursor == "" {
+			break
+		}
+		query.Cursor = page.NextCursor
+	}
+	assert.ElementsMatch(t, append(ids, tied.ID), paged)
+	assert.Len(t, paged, len(ids)+1)
+
+	byUpdate := list(storage.SessionQuery{SortBy: storage.SessionSortUpdatedAt, Limit: 3})
+	require.Len(t, byUpdate.Sessions, 3)
+	assert.False(t, byUpdate.Sessions[0].UpdatedAt.Before(byUpdate.Sessions[2].UpdatedAt), "sorted by last update, newest first")
+	rest := list(storage.SessionQuery{SortBy: storage.SessionSortUpdatedAt, Limit: 3, Cursor: byUpdate.NextCursor})
+	assert.ElementsMatch(t, append(ids, tied.ID), append(sessionIDs(byUpdate.Sessions), sessionIDs(rest.Sessions)...))
+
+	_, err := store.ListSessionsQuery(ctx, storage.SessionQuery{SortBy: "user_id"})
+	assert.Error(t, err)
+
+	// Cursors only continue the listing they came from
+	_, err = store.ListSessionsQuery(ctx, storage.SessionQuery{GraphID: graph.ID, Ascending: true, Cursor: first.NextCursor})
+	assert.ErrorIs(t, err, storage.ErrInvalidCursor)
+	_, err = store.ListSessionsQuery(ctx, storage.SessionQuery{GraphID: graph.ID, Cursor: "not-a-cursor"})
+	assert.ErrorIs(t, err, storage.ErrInvalidCursor)
+}
+
+func testDeleteSession(t *testing.T, store storage.Storage) {
+	ctx := context.Background()
+	graph := saveGraph(t, store)
+	session := NewSession(newID(), newID(), graph.ID)
+	require.NoError(t, store.SaveSession(ctx, session))
+
+	require.NoError(t, store.DeleteSession(ctx, session.ID))
+
+	_, err := store.GetSession(ctx, session.ID)
+	assert.EqualError(t, err, "session not found")
+	sessions, err := store.ListSessions(ctx, session.UserID)
+	require.NoError(t, err)
+	assert.Empty(t, sessions)
+
+	// Saving the ID again does not bring back the deleted history
+	session.History = nil
+	require.NoError(t, store.SaveSession(ctx, session))
+	loaded, err := store.GetSession(ctx, session.ID)
+	require.NoError(t, err)
+	assert.Empty(t, loaded.History)
+}
+
+func testNotFound(t *testing.T, store storage.Storage) {
+	ctx := context.Background()
+	missing := newID()
+
+	_, err := store.GetGraph(ctx, missing)
+	assert.EqualError(t, err, "graph not found")
+	_, err = store.GetSession(ctx, missing)
+	assert.EqualError(t, err, "session not found")
+	_, err = store.GetGraphVersion(ctx, missing, 1)
+	assert.EqualError(t, err, "graph version not found")
+
+	versions, err := store.ListGraphVersions(ctx, missing)
+	require.NoError(t, err)
+	assert.Empty(t, versions)
+
+	assert.NoError(t, store.DeleteSession(ctx, missing), "deleting a missing session is not an error")
+	assert.NoError(t, store.DeleteGraph(ctx, missing), "deleting a missing graph is not an error")
+}
+
+func testConcurrentWriters(t *testing.T, store storage.Storage) {
+	ctx := context.Background()
+	graph := saveGraph(t, store)
+	userID := newID()
+	shared := NewSession(newID(), userID, graph.ID)
+	require.NoError(t, store.SaveSession(ctx, shared))
+
+	const writers = 8
+	var wg sync.WaitGroup
+	errs := make(chan error, writers*4)
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			// Each writer creates its own session, increments the shared session and rewrites the graph
+			own := NewSession(newID(), userID, graph.ID)
+			errs <- store.SaveSession(ctx, own)
+
+			errs <- incrementRetryCount(ctx, store, shared.ID)
+
+			graphUpdate := NewGraph(graph.ID)
+			graphUpdate.Description = fmt.Sprintf("writer %d", i)
+			errs <- store.UpdateGraph(ctx, graphUpdate)
+
+			_, err := store.GetSession(ctx, shared.ID)
+			errs <- err
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		assert.NoError(t, err)
+	}
+
+	sessions, err := store.ListSessions(ctx, userID)
+	require.NoError(t, err)
+	assert.Len(t, sessions, writers+1)
+
+	// Every increment of the shared session lands; the graph's last write wins whole
+	loaded, err := store.GetSession(ctx, shared.ID)
+	require.NoError(t, err)
+	assert.Len(t, loaded.History, 2)
+	assert.Equal(t, shared.RetryCount+writers, loaded.RetryCount)
+	assert.Equal(t, shared.Revision+writers, loaded.Revision)
+	loadedGraph, err := store.GetGraph(ctx, graph.ID)
+	require.NoError(t, err)
+	assert.Len(t, loadedGraph.Nodes, 2)
+	assert.Len(t, loadedGraph.Edges, 1)
+}
+
+// incrementRetryCount increments a session's retry count, reloading and retrying on conflict
+func incrementRetryCount(ctx context.Context, store storage.Storage, sessionID string) error {
+	for {
+		session, err := store.GetSession(ctx, sessionID)
+		if err != nil {
+			return err
+		}
+		session.RetryCount++
+		err = store.UpdateSession(ctx, session)
+		if !errors.Is(err, storage.ErrSessionConflict) {
+			return err
+		}
+	}
+}
+
+func sessionIDs(sessions []*types.Session) []string {
+	ids := make([]string, 0, len(sessions))
+	for _, session := range sessions {
+		ids = append(ids, session.ID)
+	}
+	return ids
+}
@@ -39,11 +39,25 @@ type Node struct {
 
 // NodeDependency represents what a node depends on
 type NodeDependency struct {
-	FieldID      string      `json:"field_id"`                // Field that must be filled
-	Operator     string      `json:"operator"`                // Comparison operator (eq, ne, in, etc.)
-	Value        interface{} `json:"value"`                   // Value to compare against
-	Condition    string      `json:"condition"`               // Human readable condition
-	BusinessType string      `json:"business_type,omitempty"` // Specific business type requirement
+	FieldID      string                `json:"field_id"`                // Field that must be filled
+	Operator     string                `json:"operator"`                // Comparison operator (eq, ne, in, etc.)
+	Value        interface{}           `json:"value"`                   // Value to compare against
+	Condition    string                `json:"condition"`               // Human readable condition
+	BusinessType string                `json:"business_type,omitempty"` // Specific business type requirement
+	Expression   *DependencyExpression `json:"expression,omitempty"`    // Replaces the field comparison when set
+}
+
+// DependencyExpression is a boolean expression over session data. A group sets exactly one of All,
+// Any or Not; a comparison sets Field and Operator and compares against Value, or against another
+// field when ValueField is set.
+type DependencyExpression struct {
+	All        []DependencyExpression `json:"all,omitempty"`
+	Any        []DependencyExpression `json:"any,omitempty"`
+	Not        *DependencyExpression  `json:"not,omitempty"`
+	Field      string                 `json:"field,omitempty"`
+	Operator   string                 `json:"operator,omitempty"`
+	Value      interface{}            `json:"value,omitempty"`
+	ValueField string                 `json:"value_field,omitempty"`
 }
 
 // CrossNodeValidationRule represents validation rules that span across multiple nodes
@@ -72,10 +86,105 @@ type CrossNodeCondition struct {
 	Operator string                 `json:"operator"`           // Comparison operator: "eq", "ne", "contains", "matches", "custom"
 	Fields   []string               `json:"fields"`             // Field aliases to compare (from CrossNodeFieldReference.Alias)
 	Value    interface{}            `json:"value,omitempty"`    // Static value to compare against (if applicable)
-	Logic    string                 `json:"logic,omitempty"`    // Custom validation logic (for complex cases)
+	Logic    string                 `json:"logic,omitempty"`    // Named logic or expression over field aliases
 	Metadata map[string]interface{} `json:"metadata,omitempty"` // Additional metadata for the condition
 }
 
+// Named cross-node logic implemented by the cross-node validation engine
+const (
+	CrossNodeLogicBusinessNameMatchesBankName = "business_name_matches_bank_name"
+	CrossNodeLogicPanMatchesSignatoryPan      = "pan_matches_signatory_pan"
+	CrossNodeLogicAddressConsistency          = "address_consistency"
+)
+
+// IsCrossNodeLogic reports whether a custom_logic name is implemented by the cross-node validation engine
+func IsCrossNodeLogic(logic string) bool {
+	switch logic {
+	case CrossNodeLogicBusinessNameMatchesBankName, CrossNodeLogicPanMatchesSignatoryPan, CrossNodeLogicAddressConsistency:
+		return true
+	default:
+		return false
+	}
+}
+
+// RuleGroup represents a group of rules that define a complete path to onboarding completion
+type RuleGroup struct {
+	ID                string                          `json:"id"`
+	Name              string                          `json:"name"`
+	Description       string                          `json:"description"`
+	BusinessTypes     []string                        `json:"business_types,omitempty"` // Business types this group applies to (empty for all)
+	RequiredNodes     []string                        `json:"required_nodes"`           // Node IDs that must be completed for this rule group
+	RequiredFields    map[string][]string             `json:"required_fields"`          // Node ID -> []Field IDs that must be filled
+	ConditionalFields map[string]ConditionalFieldRule `json:"conditional_fields"`       // Conditional field requirements
+}
+
+// AppliesTo reports whether the rule group applies to a business type
+func (rg *RuleGroup) AppliesTo(businessType string) bool {
+	if len(rg.BusinessTypes) == 0 {
+		return true
+	}
+	for _, bt := range rg.BusinessTypes {
+		if bt == businessType {
+			return true
+		}
+	}
+	return false
+}
+
+// ConditionalFieldRule defines when a field is required based on other field values
+type ConditionalFieldRule struct {
+	NodeID      string `json:"node_id"`
+	FieldID     string `json:"field_id"`
+	Condition   string `json:"condition"` // Field to check
+	Operator    string `json:"operator"`  // eq, ne, etc.
+	Value       string `json:"value"`     // Value to compare against
+	Description string `json:"description"`
+}
+
+// ActivationRule represents a rule that determines if a user can be activated
+type ActivationRule struct {
+	ID            string                 `json:"id"`
+	Name          string                 `json:"name"`
+	Description   string                 `json:"description"`
+	Conditions    []ActivationCondition  `json:"conditions"`
+	RequiredNodes []string               `json:"required_nodes"` // Nodes that must be visited
+	ExcludedNodes []string               `json:"excluded_nodes"` // Nodes that should not be visited
+	Metadata      map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// ActivationCondition represents a condition for activation
+type ActivationCondition struct {
+	Field    string      `json:"field"`
+	Operator string      `json:"operator"`
+	Value    interface{} `json:"value"`
+	Required bool        `json:"required"` // If true, this condition must be met
+}
+
+// NodeRule represents rules that apply when a node is visited
+type NodeRule struct {
+	ID         string                 `json:"id"`
+	NodeID     string                 `json:"node_id"`
+	RuleType   string                 `json:"rule_type"` // "validation", "path_limitation", "activation_check"
+	Conditions []ActivationCondition  `json:"conditions"`
+	Actions    []RuleAction           `json:"actions"`
+	Metadata   map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// RuleAction represents an action to take when a rule is triggered
+type RuleAction struct {
+	Type     string                 `json:"type"`   // "disable_edge", "require_node", "exclude_node", "check_activation"
+	Target   string                 `json:"target"` // Edge ID, Node ID, or Activation Rule ID
+	Metadata map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// Rule action types
+const (
+	RuleActionDisableEdge     = "disable_edge"
+	RuleActionRequireNode     = "require_node"
+	RuleActionExcludeNode     = "exclude_node"
+	RuleActionCheckActivation = "check_activation"
+)
+
 // ValidationSeverity represents the severity level of a validation rule
 type ValidationSeverity string
 
@@ -151,7 +260,7 @@ type EdgeCondition struct {
 	Field      string                 `json:"field,omitempty"`
 	Operator   string                 `json:"operator,omitempty"`
 	Value      interface{}            `json:"value,omitempty"`
-	CustomRule string                 `json:"custom_rule,omitempty"`
+	CustomRule string                 `json:"custom_rule,omitempty"` // Named rule or expression, e.g. business_type in ["llp","trust"]
 	Metadata   map[string]interface{} `json:"metadata,omitempty"`
 }
 
@@ -161,29 +270,47 @@ type Graph struct {
 	Name                string                    `json:"name"`
 	Description         string                    `json:"description"`
 	Version             string                    `json:"version"`
+	Revision            int                       `json:"revision"` // Monotonic published revision, 0 if never published
 	Nodes               map[string]*Node          `json:"nodes"`
 	Edges               map[string]*Edge          `json:"edges"`
 	StartNodeID         string                    `json:"start_node_id"`
 	CrossNodeValidation []CrossNodeValidationRule `json:"cross_node_validation,omitempty"` // Cross-node validation rules
+	RuleGroups          []RuleGroup               `json:"rule_groups,omitempty"`           // Completion paths, any one of which completes onboarding
+	ActivationRules     []ActivationRule          `json:"activation_rules,omitempty"`      // Alternative conditions for activating a user
+	NodeRules           []NodeRule                `json:"node_rules,omitempty"`            // Actions applied when a node is submitted
+	EntryNodes          []string                  `json:"entry_nodes,omitempty"`           // Nodes a session may start from besides the start node
 	Metadata            map[string]interface{}    `json:"metadata"`
 	CreatedAt           time.Time                 `json:"created_at"`
 	UpdatedAt           time.Time                 `json:"updated_at"`
 }
 
+// GraphVersion summarizes an immutable published revision of a graph
+type GraphVersion struct {
+	GraphID   string    `json:"graph_id"`
+	Revision  int       `json:"revision"`
+	Name      string    `json:"name"`
+	Version   string    `json:"version"`
+	IsDefault bool      `json:"is_default"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
 // Session represents an active onboarding session
 type Session struct {
 	ID            string                 `json:"id"`
 	UserID        string                 `json:"user_id"`
 	GraphID       string                 `json:"graph_id"`
+	GraphRevision int                    `json:"graph_revision"` // Graph revision the session was started on
 	CurrentNodeID string                 `json:"current_node_id"`
 	Data          map[string]interface{} `json:"data"`
 	History       []SessionStep          `json:"history"`
 	Status        SessionStatus          `json:"status"`
 	RetryCount    int                    `json:"retry_count"`
+	Revision      int                    `json:"revision"` // Incremented on every save; saves from an older revision conflict
 	CreatedAt     time.Time              `json:"created_at"`
 	UpdatedAt     time.Time              `json:"updated_at"`
 	CompletedAt   *time.Time             `json:"completed_at,omitempty"`
 	DynamicState  *DynamicSessionState   `json:"dynamic_state,omitempty"`
+	AdvancedState *AdvancedSessionState  `json:"advanced_state,omitempty"`
 }
 
 // DynamicSessionState represents the persistent state of dynamic nodes
@@ -194,22 +321,51 @@ type DynamicSessionState struct {
 	CompletionStatus map[string]interface{}    `json:"completion_status"`
 }
 
+// AdvancedSessionState is the activation tracking of a session started from an entry node
+type AdvancedSessionState struct {
+	EntryNodeID      string     `json:"entry_node_id"`
+	VisitedNodes     []string   `json:"visited_nodes"`
+	DisabledEdges    []string   `json:"disabled_edges"`
+	ExcludedNodes    []string   `json:"excluded_nodes"`
+	RequiredNodes    []string   `json:"required_nodes"`    // Nodes node rules require before activation
+	ActivationStatus string     `json:"activation_status"` // "pending" or "activated"
+	ActivationRules  []string   `json:"activation_rules"`  // Activation rules the session satisfies
+	PathHistory      []PathStep `json:"path_history"`
+}
+
+// PathStep represents a step in the user's path
+type PathStep struct {
+	NodeID        string                 `json:"node_id"`
+	Timestamp     int64                  `json:"timestamp"` // Unix milliseconds
+	Data          map[string]interface{} `json:"data"`
+	RulesApplied  []string               `json:"rules_applied"`
+	EdgesDisabled []string               `json:"edges_disabled"`
+}
+
+// Activation statuses of an advanced session
+const (
+	ActivationStatusPending   = "pending"
+	ActivationStatusActivated = "activated"
+)
+
 // NodeStatusInfo represents the persistent status information for a node
 type NodeStatusInfo struct {
 	Status        string                 `json:"status"`
 	InitialStatus string                 `json:"initial_status"`
 	Dependencies  []DependencyInfo       `json:"dependencies"`
+	Reason        string                 `json:"reason,omitempty"` // Why the node has its status
 	LastUpdatedAt time.Time              `json:"last_updated_at"`
 	Metadata      map[string]interface{} `json:"metadata,omitempty"`
 }
 
 // DependencyInfo represents a persistent dependency
 type DependencyInfo struct {
-	FieldID      string      `json:"field_id"`
-	Operator     string      `json:"operator"`
-	Value        interface{} `json:"value"`
-	Condition    string      `json:"condition"`
-	BusinessType string      `json:"business_type,omitempty"`
+	FieldID      string                `json:"field_id"`
+	Operator     string                `json:"operator"`
+	Value        interface{}           `json:"value"`
+	Condition    string                `json:"condition"`
+	BusinessType string                `json:"business_type,omitempty"`
+	Expression   *DependencyExpression `json:"expression,omitempty"`
 }
 
 // SessionStatus represents the status of an onboarding session
@@ -232,6 +388,67 @@ type SessionStep struct {
 	Action    string                 `json:"action"` // forward, backward, retry
 }
 
+// SessionEventType identifies a kind of session change
+type SessionEventType string
+
+const (
+	SessionEventStarted             SessionEventType = "session.started"
+	SessionEventSubmitted           SessionEventType = "session.submitted"
+	SessionEventNavigated           SessionEventType = "session.navigated"
+	SessionEventWentBack            SessionEventType = "session.went_back"
+	SessionEventBusinessTypeChanged SessionEventType = "session.business_type_changed"
+	SessionEventCompleted           SessionEventType = "session.completed"
+	SessionEventPaused              SessionEventType = "session.paused"
+	SessionEventResumed             SessionEventType = "session.resumed"
+	SessionEventExpired             SessionEventType = "session.expired"
+	SessionEventReactivated         SessionEventType = "session.reactivated"
+	SessionEventRetried             SessionEventType = "session.retried"
+	SessionEventMigrated            SessionEventType = "session.migrated"
+	SessionEventUpdated             SessionEventType = "session.updated"
+)
+
+// SessionEvent is an append-only record of a change to a session. Sequence and Revision are
+// assigned when the event is saved with the change.
+type SessionEvent struct {
+	ID         string                 `json:"id"`
+	SessionID  string                 `json:"session_id"`
+	Sequence   int                    `json:"sequence"` // Position in the session's event log, starting at 1
+	Revision   int                    `json:"revision"` // Session revision the change was saved as
+	Type       SessionEventType       `json:"type"`
+	UserID     string                 `json:"user_id"`
+	GraphID    string                 `json:"graph_id"`
+	NodeID     string                 `json:"node_id,omitempty"` // Current node after the change
+	Status     SessionStatus          `json:"status"`            // Session status after the change
+	Data       map[string]interface{} `json:"data,omitempty"`
+	Step       *SessionStep           `json:"step,omitempty"` // History step the change appended
+	OccurredAt time.Time              `json:"occurred_at"`
+}
+
+// OutboxStatus is the delivery state of an outbox message
+type OutboxStatus string
+
+const (
+	OutboxStatusPending   OutboxStatus = "pending"
+	OutboxStatusDelivered OutboxStatus = "delivered"
+	OutboxStatusDead      OutboxStatus = "dead" // Delivery gave up after the maximum attempts
+)
+
+// OutboxMessage is a session event awaiting delivery to webhook endpoints. It is written in the
+// same transaction as the change the event records.
+type OutboxMessage struct {
+	ID            string           `json:"id"` // ID of the event it delivers
+	SessionID     string           `json:"session_id"`
+	EventType     SessionEventType `json:"event_type"`
+	Event         *SessionEvent    `json:"event"`
+	Status        OutboxStatus     `json:"status"`
+	Attempts      int              `json:"attempts"`
+	NextAttemptAt time.Time        `json:"next_attempt_at"`
+	LastError     string           `json:"last_error,omitempty"`
+	DeliveredTo   []string         `json:"delivered_to"` // Endpoints that have accepted the message
+	CreatedAt     time.Time        `json:"created_at"`
+	UpdatedAt     time.Time        `json:"updated_at"`
+}
+
 // ValidationResult represents the result of a validation
 type ValidationResult struct {
 	Valid    bool                   `json:"valid"`
@@ -256,10 +473,11 @@ type ValidationWarning struct {
 
 // NextStepResult represents the result of determining the next step
 type NextStepResult struct {
-	NextNodeID     string                 `json:"next_node_id"`
-	AvailablePaths []string               `json:"available_paths"`
-	CanGoBack      bool                   `json:"can_go_back"`
-	Metadata       map[string]interface{} `json:"metadata"`
+	NextNodeID      string                 `json:"next_node_id"`
+	AvailablePaths  []string               `json:"available_paths"`
+	CanGoBack       bool                   `json:"can_go_back"`
+	SessionRevision int                    `json:"session_revision"` // Revision of the session after the step was saved
+	Metadata        map[string]interface{} `json:"metadata"`
 }
 
 // NewSession creates a new onboarding session
@@ -0,0 +1,94 @@
+package validators
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// builtinRules are registered in Default so graphs validate without the rules file;
+// config/validation_rules.yaml overrides them by name.
+var builtinRules = map[string]Rule{
+	"business_type_validation": {
+		Description: "Supported business types",
+		Field:       "business_type",
+		AllowedValues: []string{
+			"individual", "proprietorship", "sole_proprietorship", "private_limited", "public_limited",
+			"partnership", "llp", "trust", "society", "huf",
+		},
+	},
+	"user_type_validation": {
+		Description:   "Supported user types",
+		Field:         "user_type",
+		AllowedValues: []string{"individual", "company"},
+	},
+	"company_type_validation": {
+		Description:   "Supported company types",
+		Field:         "company_type",
+		AllowedValues: []string{"private_limited", "public_limited", "llp", "partnership"},
+	},
+}
+
+// builtinFuncs are identifier validators that need checksums or other submitted fields
+var builtinFuncs = map[string]struct {
+	field string
+	fn    Func
+}{
+	"pan_validation":     {"pan_number", validatePAN},
+	"aadhaar_validation": {"aadhaar_number", validateAadhaar},
+	"gst_validation":     {"gst_number", validateGSTIN},
+	"cin_validation":     {"cin_number", validateCIN},
+	"ifsc_validation":    {"ifsc_code", validateIFSC},
+}
+
+// builtinAliases are alternative names accepted for built-in validators
+var builtinAliases = map[string]string{
+	"pan_format":     "pan_validation",
+	"aadhaar_format": "aadhaar_validation",
+	"gst_format":     "gst_validation",
+}
+
+var bankAccountPattern = regexp.MustCompile(`^[0-9]{9,18}$`)
+
+func newDefaultRegistry() *Registry {
+	registry := NewRegistry()
+
+	registry.RegisterChecksum("verhoeff", Verhoeff)
+	registry.RegisterChecksum("gstin", GSTINChecksum)
+
+	for name, rule := range builtinRules {
+		if err := registry.RegisterRule(name, rule); err != nil {
+			panic(err)
+		}
+	}
+	for name, builtin := range builtinFuncs {
+		registry.Register(name, builtin.field, builtin.fn)
+	}
+	for alias, name := range builtinAliases {
+		registry.Register(alias, builtinFuncs[name].field, builtinFuncs[name].fn)
+	}
+
+	registry.Register("penny_testing_verification", "", pennyTestingVerification)
+
+	return registry
+}
+
+// pennyTestingVerification checks that the bank details can be sent for a penny drop.
+// The transfer itself happens outside the onboarding flow; missing fields are left to required field checks.
+func pennyTestingVerification(_ string, data map[string]interface{}) error {
+	if account, ok := submitted(data, "bank_account_number"); ok && !bankAccountPattern.MatchString(account) {
+		return &Error{Code: "BANK_ACCOUNT_INVALID", Message: "Bank account number must be 9 to 18 digits"}
+	}
+	if ifsc, ok := submitted(data, "ifsc_code"); ok {
+		return validateIFSC(ifsc, data)
+	}
+	return nil
+}
+
+// submitted returns a non-empty submitted value as a string
+func submitted(data map[string]interface{}, key string) (string, bool) {
+	value, exists := data[key]
+	if !exists || value == nil || value == "" {
+		return "", false
+	}
+	return fmt.Sprintf("%v", value), true
+}
@@ -0,0 +1,262 @@
+package validators
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Validation error codes for Indian identifiers
+const (
+	CodeAadhaarInvalidFormat   = "AADHAAR_INVALID_FORMAT"
+	CodeAadhaarChecksumInvalid = "AADHAAR_CHECKSUM_INVALID"
+	CodePANInvalidFormat       = "PAN_INVALID_FORMAT"
+	CodePANEntityTypeInvalid   = "PAN_ENTITY_TYPE_INVALID"
+	CodePANEntityTypeMismatch  = "PAN_ENTITY_TYPE_MISMATCH"
+	CodeGSTINInvalidFormat     = "GSTIN_INVALID_FORMAT"
+	CodeGSTINStateCodeInvalid  = "GSTIN_STATE_CODE_INVALID"
+	CodeGSTINChecksumInvalid   = "GSTIN_CHECKSUM_INVALID"
+	CodeGSTINPANMismatch       = "GSTIN_PAN_MISMATCH"
+	CodeIFSCInvalidFormat      = "IFSC_INVALID_FORMAT"
+	CodeIFSCReservedCharacter  = "IFSC_RESERVED_CHARACTER_INVALID"
+	CodeCINInvalidFormat       = "CIN_INVALID_FORMAT"
+	CodeCINYearInvalid         = "CIN_YEAR_INVALID"
+	CodeCINOwnershipInvalid    = "CIN_OWNERSHIP_INVALID"
+	CodeCINEntityTypeMismatch  = "CIN_ENTITY_TYPE_MISMATCH"
+)
+
+var (
+	aadhaarPattern = regexp.MustCompile(`^[2-9][0-9]{11}$`)
+	panPattern     = regexp.MustCompile(`^[A-Z]{3}([A-Z])[A-Z][0-9]{4}[A-Z]$`)
+	gstinPattern   = regexp.MustCompile(`^([0-9]{2})([A-Z]{5}[0-9]{4}[A-Z])[1-9A-Z]Z[0-9A-Z]$`)
+	ifscPattern    = regexp.MustCompile(`^[A-Z]{4}[A-Z0-9][A-Z0-9]{6}$`)
+	cinPattern     = regexp.MustCompile(`^([LU])([0-9]{5})([A-Z]{2})([0-9]{4})([A-Z]{3})([0-9]{6})$`)
+)
+
+// panEntityTypes names the holder types encoded in the fourth character of a PAN
+var panEntityTypes = map[byte]string{
+	'A': "association of persons",
+	'B': "body of individuals",
+	'C': "company",
+	'F': "firm",
+	'G': "government",
+	'H': "hindu undivided family",
+	'J': "artificial juridical person",
+	'L': "local authority",
+	'P': "individual",
+	'T': "trust",
+}
+
+// businessPANEntityTypes lists the PAN entity types accepted for each business type
+var businessPANEntityTypes = map[string]string{
+	"individual":          "P",
+	"proprietorship":      "P",
+	"sole_proprietorship": "P",
+	"private_limited":     "C",
+	"public_limited":      "C",
+	"company":             "C",
+	"partnership":         "F",
+	"llp":                 "F",
+	"huf":                 "H",
+	"trust":               "T",
+	"society":             "AT",
+}
+
+// cinOwnership lists the CIN ownership codes accepted for each company business type
+var cinOwnership = map[string][]string{
+	"private_limited": {"PTC", "OPC", "FTC"},
+	"public_limited":  {"PLC", "FLC", "GOI", "SGC"},
+	"company":         {"PTC", "OPC", "FTC", "PLC", "FLC", "GOI", "SGC"},
+}
+
+// cinOwnershipCodes are all ownership codes issued in a CIN
+var cinOwnershipCodes = map[string]bool{
+	"PLC": true, "PTC": true, "OPC": true, "FLC": true, "FTC": true, "GOI": true,
+	"SGC": true, "NPL": true, "GAP": true, "GAT": true, "ULL": true, "ULT": true,
+}
+
+// Verhoeff tables: the dihedral group D5 multiplication table and the position permutation table
+var (
+	verhoeffMultiplication = [10][10]int{
+		{0, 1, 2, 3, 4, 5, 6, 7, 8, 9},
+		{1, 2, 3, 4, 0, 6, 7, 8, 9, 5},
+		{2, 3, 4, 0, 1, 7, 8, 9, 5, 6},
+		{3, 4, 0, 1, 2, 8, 9, 5, 6, 7},
+		{4, 0, 1, 2, 3, 9, 5, 6, 7, 8},
+		{5, 9, 8, 7, 6, 0, 4, 3, 2, 1},
+		{6, 5, 9, 8, 7, 1, 0, 4, 3, 2},
+		{7, 6, 5, 9, 8, 2, 1, 0, 4, 3},
+		{8, 7, 6, 5, 9, 3, 2, 1, 0, 4},
+		{9, 8, 7, 6, 5, 4, 3, 2, 1, 0},
+	}
+	verhoeffPermutation = [8][10]int{
+		{0, 1, 2, 3, 4, 5, 6, 7, 8, 9},
+		{1, 5, 7, 6, 2, 8, 3, 0, 9, 4},
+		{5, 8, 0, 3, 7, 9, 6, 1, 4, 2},
+		{8, 9, 1, 6, 0, 4, 3, 5, 2, 7},
+		{9, 4, 5, 3, 1, 2, 8, 7, 0, 6},
+		{4, 2, 8, 6, 5, 7, 3, 9, 0, 1},
+		{2, 7, 9, 3, 8, 0, 6, 4, 1, 5},
+		{7, 0, 4, 6, 9, 1, 3, 2, 5, 8},
+	}
+)
+
+// Verhoeff reports whether a string of digits ends in a valid Verhoeff check digit
+func Verhoeff(number string) bool {
+	if number == "" {
+		return false
+	}
+
+	check := 0
+	for i := 0; i < len(number); i++ {
+		digit := number[len(number)-1-i]
+		if digit < '0' || digit > '9' {
+			return false
+		}
+		check = verhoeffMultiplication[check][verhoeffPermutation[i%8][digit-'0']]
+	}
+	return check == 0
+}
+
+const gstinAlphabet = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+
+// GSTINCheckCharacter computes the mod-36 check character for the first 14 characters of a GSTIN
+func GSTINCheckCharacter(gstin string) (byte, bool) {
+	if len(gstin) < 14 {
+		return 0, false
+	}
+
+	sum := 0
+	for i := 0; i < 14; i++ {
+		value := strings.IndexByte(gstinAlphabet, gstin[i])
+		if value < 0 {
+			return 0, false
+		}
+		product := value * (i%2 + 1)
+		sum += product/36 + product%36
+	}
+	return gstinAlphabet[(36-sum%36)%36], true
+}
+
+// GSTINChecksum reports whether a GSTIN ends in its mod-36 check character
+func GSTINChecksum(gstin string) bool {
+	check, ok := GSTINCheckCharacter(gstin)
+	return ok && len(gstin) == 15 && gstin[14] == check
+}
+
+// validGSTINStateCode reports whether a GSTIN state code is assigned: 01-38, 97 (other territory) or 99 (centre jurisdiction)
+func validGSTINStateCode(code string) bool {
+	n, err := strconv.Atoi(code)
+	if err != nil {
+		return false
+	}
+	return (n >= 1 && n <= 38) || n == 97 || n == 99
+}
+
+// validateAadhaar checks the format and Verhoeff check digit of an Aadhaar number
+func validateAadhaar(value string, _ map[string]interface{}) error {
+	aadhaar := strings.ReplaceAll(value, " ", "")
+	if !aadhaarPattern.MatchString(aadhaar) {
+		return &Error{Code: CodeAadhaarInvalidFormat, Message: "Aadhaar number must be 12 digits and cannot start with 0 or 1"}
+	}
+	if !Verhoeff(aadhaar) {
+		return &Error{Code: CodeAadhaarChecksumInvalid, Message: "Aadhaar number has an invalid check digit"}
+	}
+	return nil
+}
+
+// validatePAN checks the format of a PAN and, for the business PAN, that its entity type matches business_type.
+// Other PANs, such as a signatory's, are only checked for format.
+func validatePAN(value string, data map[string]interface{}) error {
+	match := panPattern.FindStringSubmatch(value)
+	if match == nil {
+		return &Error{Code: CodePANInvalidFormat, Message: "PAN must be 5 letters, 4 digits and a letter"}
+	}
+
+	entityType := match[1][0]
+	if _, known := panEntityTypes[entityType]; !known {
+		return &Error{Code: CodePANEntityTypeInvalid, Message: fmt.Sprintf("PAN has an unknown entity type %c in its fourth character", entityType)}
+	}
+
+	if businessPAN, ok := submitted(data, "pan_number"); ok && businessPAN != value {
+		return nil
+	}
+	businessType, ok := submitted(data, "business_type")
+	if !ok {
+		return nil
+	}
+	allowed, known := businessPANEntityTypes[businessType]
+	if known && !strings.ContainsRune(allowed, rune(entityType)) {
+		return &Error{
+			Code:    CodePANEntityTypeMismatch,
+			Message: fmt.Sprintf("PAN belongs to a %s but the business type is %s", panEntityTypes[entityType], businessType),
+		}
+	}
+	return nil
+}
+
+// validateGSTIN checks the format, state code and check character of a GSTIN and that it embeds pan_number
+func validateGSTIN(value string, data map[string]interface{}) error {
+	match := gstinPattern.FindStringSubmatch(value)
+	if match == nil {
+		return &Error{Code: CodeGSTINInvalidFormat, Message: "GSTIN must be 15 characters: state code, PAN, entity number, Z and check character"}
+	}
+	if !validGSTINStateCode(match[1]) {
+		return &Error{Code: CodeGSTINStateCodeInvalid, Message: fmt.Sprintf("GSTIN state code %s is not assigned", match[1])}
+	}
+	if !GSTINChecksum(value) {
+		return &Error{Code: CodeGSTINChecksumInvalid, Message: "GSTIN has an invalid check character"}
+	}
+	if pan, ok := submitted(data, "pan_number"); ok && pan != match[2] {
+		return &Error{Code: CodeGSTINPANMismatch, Message: "GSTIN does not contain the PAN entered for this business"}
+	}
+	return nil
+}
+
+// validateIFSC checks the format of an IFSC, whose fifth character is reserved and always 0
+func validateIFSC(value string, _ map[string]interface{}) error {
+	if !ifscPattern.MatchString(value) {
+		return &Error{Code: CodeIFSCInvalidFormat, Message: "IFSC must be 4 letters followed by 7 letters or digits"}
+	}
+	if value[4] != '0' {
+		return &Error{Code: CodeIFSCReservedCharacter, Message: "The fifth character of an IFSC must be 0"}
+	}
+	return nil
+}
+
+// validateCIN checks the format of a CIN and that its listing and ownership codes fit business_type
+func validateCIN(value string, data map[string]interface{}) error {
+	match := cinPattern.FindStringSubmatch(value)
+	if match == nil {
+		return &Error{Code: CodeCINInvalidFormat, Message: "CIN must be 21 characters: listing, industry code, state, year, ownership and registration number"}
+	}
+
+	listing, year, ownership := match[1], match[4], match[5]
+	if y, _ := strconv.Atoi(year); y < 1850 || y > time.Now().Year() {
+		return &Error{Code: CodeCINYearInvalid, Message: fmt.Sprintf("CIN year of incorporation %s is not valid", year)}
+	}
+	if !cinOwnershipCodes[ownership] {
+		return &Error{Code: CodeCINOwnershipInvalid, Message: fmt.Sprintf("CIN ownership code %s is not valid", ownership)}
+	}
+
+	businessType, ok := submitted(data, "business_type")
+	if !ok {
+		return nil
+	}
+	if _, isEntity := businessPANEntityTypes[businessType]; !isEntity {
+		return nil
+	}
+	allowed, isCompany := cinOwnership[businessType]
+	if !isCompany {
+		return &Error{Code: CodeCINEntityTypeMismatch, Message: fmt.Sprintf("A %s is not issued a CIN", businessType)}
+	}
+	if !contains(allowed, ownership) {
+		return &Error{Code: CodeCINEntityTypeMismatch, Message: fmt.Sprintf("CIN ownership code %s does not match business type %s", ownership, businessType)}
+	}
+	if listing == "L" && businessType == "private_limited" {
+		return &Error{Code: CodeCINEntityTypeMismatch, Message: "A private limited company cannot be listed"}
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package validators
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerhoeff(t *testing.T) {
+	assert.True(t, Verhoeff("2363"))
+	assert.True(t, Verhoeff("234123412346"))
+	assert.False(t, Verhoeff("234123412347"))
+	assert.False(t, Verhoeff("23412341234A"))
+	assert.False(t, Verhoeff(""))
+}
+
+func TestGSTINCheckCharacter(t *testing.T) {
+	check, ok := GSTINCheckCharacter("27AAPFU0939F1Z")
+	require.True(t, ok)
+	assert.Equal(t, byte('V'), check)
+
+	assert.True(t, GSTINChecksum("27AAPFU0939F1ZV"))
+	assert.True(t, GSTINChecksum("29AAGCB7383J1Z4"))
+	assert.False(t, GSTINChecksum("27AAPFU0939F1ZW"))
+}
+
+// code returns the error code of a validation failure, or "" when validation passed
+func code(t *testing.T, err error) string {
+	t.Helper()
+	if err == nil {
+		return ""
+	}
+	var ruleErr *Error
+	require.True(t, errors.As(err, &ruleErr), "unexpected error %v", err)
+	return ruleErr.Code
+}
+
+func TestIdentifierValidators(t *testing.T) {
+	tests := []struct {
+		name  string
+		rule  string
+		value string
+		data  map[string]interface{}
+		code  string
+	}{
+		{"valid aadhaar", "aadhaar_validation", "234123412346", nil, ""},
+		{"aadhaar with spaces", "aadhaar_validation", "2341 2341 2346", nil, ""},
+		{"aadhaar starting with 1", "aadhaar_validation", "134123412346", nil, CodeAadhaarInvalidFormat},
+		{"aadhaar check digit", "aadhaar_validation", "234123412347", nil, CodeAadhaarChecksumInvalid},
+
+		{"individual pan", "pan_validation", "ABCPE1234F", map[string]interface{}{"business_type": "individual"}, ""},
+		{"company pan", "pan_validation", "ABCCE1234F", map[string]interface{}{"business_type": "private_limited"}, ""},
+		{"pan format", "pan_validation", "ABCPE12345", nil, CodePANInvalidFormat},
+		{"pan unknown entity", "pan_validation", "ABCXE1234F", nil, CodePANEntityTypeInvalid},
+		{"pan entity mismatch", "pan_validation", "ABCCE1234F", map[string]interface{}{"business_type": "individual"}, CodePANEntityTypeMismatch},
+		{"signatory pan of company", "pan_validation", "ABCPE1234F", map[string]interface{}{"business_type": "private_limited", "pan_number": "ABCCE1234F"}, ""},
+
+		{"valid gstin", "gst_validation", "27AAPFU0939F1ZV", map[string]interface{}{"pan_number": "AAPFU0939F"}, ""},
+		{"gstin format", "gst_validation", "27AAPFU0939F1AV", nil, CodeGSTINInvalidFormat},
+		{"gstin state code", "gst_validation", "40AAPFU0939F1ZV", nil, CodeGSTINStateCodeInvalid},
+		{"gstin check character", "gst_validation", "27AAPFU0939F1ZW", nil, CodeGSTINChecksumInvalid},
+		{"gstin pan mismatch", "gst_validation", "27AAPFU0939F1ZV", map[string]interface{}{"pan_number": "AAPFU0939G"}, CodeGSTINPANMismatch},
+
+		{"valid ifsc", "ifsc_validation", "HDFC0000123", nil, ""},
+		{"ifsc format", "ifsc_validation", "HDF0000123", nil, CodeIFSCInvalidFormat},
+		{"ifsc reserved character", "ifsc_validation", "HDFC1000123", nil, CodeIFSCReservedCharacter},
+
+		{"listed public company cin", "cin_validation", "L17110MH1973PLC019786", map[string]interface{}{"business_type": "public_limited"}, ""},
+		{"private company cin", "cin_validation", "U72200KA2009PTC049889", map[string]interface{}{"business_type": "private_limited"}, ""},
+		{"cin format", "cin_validation", "U72200KA2009PTC04988", nil, CodeCINInvalidFormat},
+		{"cin year", "cin_validation", "U72200KA1209PTC049889", nil, CodeCINYearInvalid},
+		{"cin ownership", "cin_validation", "U72200KA2009XYZ049889", nil, CodeCINOwnershipInvalid},
+		{"cin ownership mismatch", "cin_validation", "U72200KA2009PTC049889", map[string]interface{}{"business_type": "public_limited"}, CodeCINEntityTypeMismatch},
+		{"listed private company", "cin_validation", "L72200KA2009PTC049889", map[string]interface{}{"business_type": "private_limited"}, CodeCINEntityTypeMismatch},
+		{"cin for partnership", "cin_validation", "U72200KA2009PTC049889", map[string]interface{}{"business_type": "partnership"}, CodeCINEntityTypeMismatch},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.code, code(t, Default.Validate(tt.rule, tt.value, tt.data)))
+		})
+	}
+}
+
+func TestChecksumRule(t *testing.T) {
+	registry := newDefaultRegistry()
+	require.NoError(t, registry.RegisterRule("vid", Rule{Pattern: `^[0-9]{16}$`, Checksum: "verhoeff", Code: "VID_INVALID"}))
+
+	assert.NoError(t, registry.Validate("vid", "9123412341234122", nil))
+	assert.Equal(t, "VID_INVALID", code(t, registry.Validate("vid", "9123412341234123", nil)))
+}
@@ -0,0 +1,60 @@
+package validators
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+// LoadFile registers the rules in a validation rules file, replacing registered rules with the same name
+func (r *Registry) LoadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read validation rules: %w", err)
+	}
+	if err := r.Load(data); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return nil
+}
+
+// Load registers the rules under the top-level rules key of a YAML document.
+// Unknown keys are rejected and nothing is registered unless every rule compiles.
+func (r *Registry) Load(data []byte) error {
+	// The compliance and dynamic_rules sections are not used by the registry
+	var document struct {
+		Rules        map[string]Rule `yaml:"rules"`
+		Compliance   yaml.Node       `yaml:"compliance"`
+		DynamicRules yaml.Node       `yaml:"dynamic_rules"`
+	}
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&document); err != nil && err != io.EOF {
+		return fmt.Errorf("failed to parse validation rules: %w", err)
+	}
+	rules := document.Rules
+
+	names := make([]string, 0, len(rules))
+	for name := range rules {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	compiled := make(map[string]Func, len(rules))
+	for _, name := range names {
+		fn, err := r.compile(name, rules[name])
+		if err != nil {
+			return err
+		}
+		compiled[name] = fn
+	}
+	for _, name := range names {
+		r.Register(name, rules[name].Field, compiled[name])
+	}
+
+	return nil
+}
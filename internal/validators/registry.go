@@ -0,0 +1,242 @@
+// Package validators is a registry of named validation rules referenced by graph custom rules.
+package validators
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrUnknownRule is returned when a rule name is not registered
+var ErrUnknownRule = errors.New("unknown validation rule")
+
+// Error is a validation failure with a code the UI can explain
+type Error struct {
+	Code    string // Empty when the rule does not define its own code
+	Message string
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Func validates a value in the context of the submitted data; a non-nil error fails validation
+type Func func(value string, data map[string]interface{}) error
+
+// ChecksumFunc reports whether a value's check digits are valid
+type ChecksumFunc func(value string) bool
+
+// Rule is a declarative validation rule, as written in config/validation_rules.yaml
+type Rule struct {
+	Description   string   `yaml:"description" json:"description"`
+	Field         string   `yaml:"field" json:"field,omitempty"` // Data key checked when the rule is used on a node
+	Pattern       string   `yaml:"pattern" json:"pattern,omitempty"`
+	MinLength     int      `yaml:"min_length" json:"min_length,omitempty"`
+	MaxLength     int      `yaml:"max_length" json:"max_length,omitempty"`
+	MinValue      *float64 `yaml:"min_value" json:"min_value,omitempty"`
+	MaxValue      *float64 `yaml:"max_value" json:"max_value,omitempty"`
+	MinAge        int      `yaml:"min_age" json:"min_age,omitempty"` // Minimum age in years of a YYYY-MM-DD date
+	AllowedValues []string `yaml:"allowed_values" json:"allowed_values,omitempty"`
+	Checksum      string   `yaml:"checksum" json:"checksum,omitempty"` // Name of a registered checksum
+	ErrorMessage  string   `yaml:"error_message" json:"error_message,omitempty"`
+	Code          string   `yaml:"code" json:"code,omitempty"` // Error code; the engine's generic code is used when empty
+}
+
+// validator is a registered rule
+type validator struct {
+	field string
+	fn    Func
+}
+
+// Registry maps rule names to validators
+type Registry struct {
+	mu         sync.RWMutex
+	validators map[string]validator
+	checksums  map[string]ChecksumFunc
+}
+
+// NewRegistry creates an empty registry
+func NewRegistry() *Registry {
+	return &Registry{
+		validators: make(map[string]validator),
+		checksums:  make(map[string]ChecksumFunc),
+	}
+}
+
+// Default is the registry used by the onboarding engines and the graph linter.
+// It starts with the built-in rules and is extended from the validation rules file at startup.
+var Default = newDefaultRegistry()
+
+// Register adds or replaces a validator func. field is the data key the func checks when the
+// rule is attached to a node; leave it empty for funcs that inspect the submitted data themselves.
+func (r *Registry) Register(name, field string, fn Func) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.validators[name] = validator{field: field, fn: fn}
+}
+
+// RegisterChecksum adds or replaces a checksum that rules can refer to by name
+func (r *Registry) RegisterChecksum(name string, fn ChecksumFunc) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.checksums[name] = fn
+}
+
+// RegisterRule compiles a declarative rule and registers it under name
+func (r *Registry) RegisterRule(name string, rule Rule) error {
+	fn, err := r.compile(name, rule)
+	if err != nil {
+		return err
+	}
+	r.Register(name, rule.Field, fn)
+	return nil
+}
+
+// Has reports whether a rule name is registered
+func (r *Registry) Has(name string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	_, exists := r.validators[name]
+	return exists
+}
+
+// Names returns the registered rule names in order
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	names := make([]string, 0, len(r.validators))
+	for name := range r.validators {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Validate checks a single field value against a named rule
+func (r *Registry) Validate(name, value string, data map[string]interface{}) error {
+	v, err := r.lookup(name)
+	if err != nil {
+		return err
+	}
+	return v.fn(value, data)
+}
+
+// ValidateData checks submitted data against a named rule attached to a node or edge.
+// Rules bound to a field pass when that field was not submitted.
+func (r *Registry) ValidateData(name string, data map[string]interface{}) error {
+	v, err := r.lookup(name)
+	if err != nil {
+		return err
+	}
+
+	value := ""
+	if v.field != "" {
+		submittedValue, ok := submitted(data, v.field)
+		if !ok {
+			return nil
+		}
+		value = submittedValue
+	}
+	return v.fn(value, data)
+}
+
+func (r *Registry) lookup(name string) (validator, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	v, exists := r.validators[name]
+	if !exists {
+		return validator{}, fmt.Errorf("%w: %s", ErrUnknownRule, name)
+	}
+	return v, nil
+}
+
+// compile turns a declarative rule into a validator func
+func (r *Registry) compile(name string, rule Rule) (Func, error) {
+	var pattern *regexp.Regexp
+	if rule.Pattern != "" {
+		compiled, err := regexp.Compile(rule.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("rule %s has an invalid pattern: %w", name, err)
+		}
+		pattern = compiled
+	}
+
+	var checksum ChecksumFunc
+	if rule.Checksum != "" {
+		r.mu.RLock()
+		checksum = r.checksums[rule.Checksum]
+		r.mu.RUnlock()
+		if checksum == nil {
+			return nil, fmt.Errorf("rule %s uses unknown checksum %s", name, rule.Checksum)
+		}
+	}
+
+	if pattern == nil && checksum == nil && rule.MinLength == 0 && rule.MaxLength == 0 &&
+		rule.MinValue == nil && rule.MaxValue == nil && rule.MinAge == 0 && len(rule.AllowedValues) == 0 {
+		return nil, fmt.Errorf("rule %s defines no checks", name)
+	}
+	if rule.MaxLength > 0 && rule.MinLength > rule.MaxLength {
+		return nil, fmt.Errorf("rule %s has min_length %d greater than max_length %d", name, rule.MinLength, rule.MaxLength)
+	}
+
+	fail := func(format string, args ...interface{}) error {
+		if rule.ErrorMessage != "" {
+			return &Error{Code: rule.Code, Message: rule.ErrorMessage}
+		}
+		return &Error{Code: rule.Code, Message: fmt.Sprintf(format, args...)}
+	}
+
+	return func(value string, data map[string]interface{}) error {
+		if rule.MinLength > 0 && len(value) < rule.MinLength {
+			return fail("%s must be at least %d characters long", name, rule.MinLength)
+		}
+		if rule.MaxLength > 0 && len(value) > rule.MaxLength {
+			return fail("%s must be at most %d characters long", name, rule.MaxLength)
+		}
+		if pattern != nil && !pattern.MatchString(value) {
+			return fail("%s does not match the required format", name)
+		}
+		if len(rule.AllowedValues) > 0 && !contains(rule.AllowedValues, value) {
+			return fail("%s must be one of %s", name, strings.Join(rule.AllowedValues, ", "))
+		}
+		if rule.MinValue != nil || rule.MaxValue != nil {
+			number, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return fail("%s must be a number", name)
+			}
+			if rule.MinValue != nil && number < *rule.MinValue {
+				return fail("%s must be at least %v", name, *rule.MinValue)
+			}
+			if rule.MaxValue != nil && number > *rule.MaxValue {
+				return fail("%s must be at most %v", name, *rule.MaxValue)
+			}
+		}
+		if rule.MinAge > 0 {
+			born, err := time.Parse("2006-01-02", value)
+			if err != nil {
+				return fail("%s must be a date in YYYY-MM-DD format", name)
+			}
+			if born.AddDate(rule.MinAge, 0, 0).After(time.Now()) {
+				return fail("%s requires a minimum age of %d", name, rule.MinAge)
+			}
+		}
+		if checksum != nil && !checksum(value) {
+			return fail("%s has an invalid checksum", name)
+		}
+		return nil
+	}, nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
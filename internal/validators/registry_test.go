@@ -0,0 +1,119 @@
+package validators
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegisterRule(t *testing.T) {
+	minValue := 18.0
+	registry := NewRegistry()
+	registry.RegisterChecksum("even_length", func(value string) bool { return len(value)%2 == 0 })
+
+	require.NoError(t, registry.RegisterRule("code", Rule{Pattern: `^[A-Z]+$`, MinLength: 2, MaxLength: 6, Checksum: "even_length", Code: "CODE_INVALID"}))
+	require.NoError(t, registry.RegisterRule("age", Rule{MinValue: &minValue, ErrorMessage: "Too young"}))
+	require.NoError(t, registry.RegisterRule("colour", Rule{AllowedValues: []string{"red", "blue"}}))
+
+	assert.NoError(t, registry.Validate("code", "ABCD", nil))
+	assert.NoError(t, registry.Validate("age", "21", nil))
+	assert.NoError(t, registry.Validate("colour", "red", nil))
+
+	tests := []struct {
+		rule, value, code, message string
+	}{
+		{"code", "A", "CODE_INVALID", "code must be at least 2 characters long"},
+		{"code", "ABCDEFGH", "CODE_INVALID", "code must be at most 6 characters long"},
+		{"code", "ab", "CODE_INVALID", "code does not match the required format"},
+		{"code", "ABC", "CODE_INVALID", "code has an invalid checksum"},
+		{"age", "12", "", "Too young"},
+		{"colour", "green", "", "colour must be one of red, blue"},
+	}
+	for _, tt := range tests {
+		err := registry.Validate(tt.rule, tt.value, nil)
+
+		var ruleErr *Error
+		require.True(t, errors.As(err, &ruleErr), "%s(%s)", tt.rule, tt.value)
+		assert.Equal(t, tt.code, ruleErr.Code)
+		assert.Equal(t, tt.message, ruleErr.Message)
+	}
+}
+
+func TestRegisterRuleErrors(t *testing.T) {
+	registry := NewRegistry()
+
+	assert.ErrorContains(t, registry.RegisterRule("empty", Rule{Description: "no checks"}), "defines no checks")
+	assert.ErrorContains(t, registry.RegisterRule("bad", Rule{Pattern: "("}), "invalid pattern")
+	assert.ErrorContains(t, registry.RegisterRule("sum", Rule{Checksum: "luhn"}), "unknown checksum")
+	assert.ErrorContains(t, registry.RegisterRule("len", Rule{MinLength: 5, MaxLength: 2}), "greater than")
+}
+
+func TestUnknownRule(t *testing.T) {
+	registry := NewRegistry()
+
+	assert.ErrorIs(t, registry.Validate("missing", "x", nil), ErrUnknownRule)
+	assert.ErrorIs(t, registry.ValidateData("missing", nil), ErrUnknownRule)
+	assert.False(t, registry.Has("missing"))
+}
+
+func TestValidateData(t *testing.T) {
+	registry := NewRegistry()
+	require.NoError(t, registry.RegisterRule("pan", Rule{Field: "pan_number", Pattern: `^[A-Z]{5}[0-9]{4}[A-Z]$`}))
+	registry.Register("both_names", "", func(_ string, data map[string]interface{}) error {
+		if data["first_name"] == nil || data["last_name"] == nil {
+			return &Error{Message: "both names are required"}
+		}
+		return nil
+	})
+
+	assert.NoError(t, registry.ValidateData("pan", map[string]interface{}{}), "unsubmitted field is left to required checks")
+	assert.NoError(t, registry.ValidateData("pan", map[string]interface{}{"pan_number": "ABCDE1234F"}))
+	assert.Error(t, registry.ValidateData("pan", map[string]interface{}{"pan_number": "ABC"}))
+
+	assert.Error(t, registry.ValidateData("both_names", map[string]interface{}{"first_name": "A"}))
+	assert.NoError(t, registry.ValidateData("both_names", map[string]interface{}{"first_name": "A", "last_name": "B"}))
+}
+
+func TestLoad(t *testing.T) {
+	registry := NewRegistry()
+
+	err := registry.Load([]byte(`
+rules:
+  pincode:
+    description: Indian postal code
+    field: pincode
+    pattern: "^[1-9][0-9]{5}$"
+    error_message: Enter a 6 digit PIN code
+compliance:
+  kyc_requirements: {}
+`))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"pincode"}, registry.Names())
+	assert.EqualError(t, registry.Validate("pincode", "012345", nil), "Enter a 6 digit PIN code")
+}
+
+func TestLoadRejectsInvalidRules(t *testing.T) {
+	registry := NewRegistry()
+
+	err := registry.Load([]byte("rules:\n  pincode:\n    patern: \"^[0-9]{6}$\"\n"))
+	assert.ErrorContains(t, err, "line 3: field patern not found")
+
+	err = registry.Load([]byte("rules:\n  good:\n    pattern: \"^a$\"\n  bad:\n    pattern: \"(\"\n"))
+	assert.ErrorContains(t, err, "rule bad has an invalid pattern")
+	assert.False(t, registry.Has("good"), "nothing is registered when a rule fails")
+}
+
+func TestDefaultRegistry(t *testing.T) {
+	for _, name := range []string{"pan_validation", "aadhaar_validation", "gst_validation", "cin_validation",
+		"business_type_validation", "user_type_validation", "penny_testing_verification"} {
+		assert.True(t, Default.Has(name), name)
+	}
+
+	assert.NoError(t, Default.ValidateData("penny_testing_verification", map[string]interface{}{"bank_account_number": "123456789012", "ifsc_code": "HDFC0001234"}))
+	err := Default.ValidateData("penny_testing_verification", map[string]interface{}{"bank_account_number": "123", "ifsc_code": "HDFC0001234"})
+	var ruleErr *Error
+	require.True(t, errors.As(err, &ruleErr))
+	assert.Equal(t, "BANK_ACCOUNT_INVALID", ruleErr.Code)
+}
@@ -0,0 +1,259 @@
+// Package webhook delivers session events from the storage outbox to configured HTTP endpoints.
+//
+// Each delivery is a POST of the event as JSON, signed with HMAC-SHA256 over the timestamp and body:
+//
+//	X-Onboarding-Timestamp: 1709287200
+//	X-Onboarding-Signature: sha256=<hex HMAC of "1709287200.<body>">
+//
+// Failed deliveries are retried with exponential backoff. After the maximum attempts a message is
+// marked dead and stays in the outbox until an admin requeues it or WEBHOOK_DEAD_RETENTION passes.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"onboarding-system/internal/config"
+	"onboarding-system/internal/storage"
+	"onboarding-system/internal/types"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Request headers sent with every delivery
+const (
+	HeaderEvent     = "X-Onboarding-Event"
+	HeaderDelivery  = "X-Onboarding-Delivery"
+	HeaderTimestamp = "X-Onboarding-Timestamp"
+	HeaderSignature = "X-Onboarding-Signature"
+)
+
+// maxBackoff caps the delay between retries
+const maxBackoff = time.Hour
+
+// batchSize is the number of messages claimed per poll
+const batchSize = 50
+
+// pruneInterval is how often delivered and dead messages past their retention are deleted
+const pruneInterval = time.Hour
+
+// Dispatcher delivers outbox messages to the configured endpoints
+type Dispatcher struct {
+	store  storage.Storage
+	config config.WebhookConfig
+	events map[types.SessionEventType]bool
+	client *http.Client
+	logger *logrus.Logger
+}
+
+// NewDispatcher creates a dispatcher for the outbox of store
+func NewDispatcher(store storage.Storage, cfg config.WebhookConfig, logger *logrus.Logger) *Dispatcher {
+	events := make(map[types.SessionEventType]bool, len(cfg.Events))
+	for _, eventType := range cfg.Events {
+		events[types.SessionEventType(eventType)] = true
+	}
+
+	return &Dispatcher{
+		store:  store,
+		config: cfg,
+		events: events,
+		client: &http.Client{Timeout: cfg.Timeout},
+		logger: logger,
+	}
+}
+
+// Run delivers due messages every poll interval, and prunes old messages every prune interval,
+// until ctx is done
+func (d *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.config.PollInterval)
+	defer ticker.Stop()
+	pruneTicker := time.NewTicker(pruneInterval)
+	defer pruneTicker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := d.DispatchDue(ctx, time.Now()); err != nil && ctx.Err() == nil {
+				d.logger.WithError(err).Error("Failed to dispatch webhooks")
+			}
+		case <-pruneTicker.C:
+			if _, err := d.Prune(ctx, time.Now()); err != nil && ctx.Err() == nil {
+				d.logger.WithError(err).Error("Failed to prune outbox")
+			}
+		}
+	}
+}
+
+// Prune deletes delivered messages older than the retention and dead-lettered messages older
+// than the dead-letter retention, and returns how many were deleted. Pending messages are kept.
+func (d *Dispatcher) Prune(ctx context.Context, now time.Time) (int, error) {
+	retentions := []struct {
+		status    types.OutboxStatus
+		retention time.Duration
+	}{
+		{types.OutboxStatusDelivered, d.config.Retention},
+		{types.OutboxStatusDead, d.config.DeadRetention},
+	}
+
+	pruned := 0
+	for _, r := range retentions {
+		if r.retention <= 0 {
+			continue
+		}
+		count, err := d.store.PruneOutboxMessages(ctx, r.status, now.Add(-r.retention))
+		if err != nil {
+			return pruned, err
+		}
+		pruned += count
+	}
+
+	if pruned > 0 {
+		d.logger.WithField("pruned", pruned).Info("Pruned outbox messages past their retention")
+	}
+	return pruned, nil
+}
+
+// DispatchDue delivers the messages due at now and returns how many were processed
+func (d *Dispatcher) DispatchDue(ctx context.Context, now time.Time) (int, error) {
+	// A claim lasts until every endpoint has had its full timeout, so no other dispatcher
+	// picks up a message that is still being delivered
+	lease := d.config.Timeout*time.Duration(len(d.config.URLs)) + time.Minute
+
+	messages, err := d.store.ClaimOutboxMessages(ctx, now, lease, batchSize)
+	if err != nil {
+		return 0, fmt.Errorf("failed to claim outbox messages: %w", err)
+	}
+
+	for i, message := range messages {
+		// Unprocessed claims become due again when their lease runs out
+		if err := ctx.Err(); err != nil {
+			return i, err
+		}
+		d.deliver(ctx, message, now)
+		if err := d.store.UpdateOutboxMessage(ctx, message); err != nil {
+			d.logger.WithError(err).WithField("message_id", message.ID).Error("Failed to update outbox message")
+		}
+	}
+
+	return len(messages), nil
+}
+
+// deliver posts a message to every endpoint that has not accepted it yet and records the outcome
+func (d *Dispatcher) deliver(ctx context.Context, message *types.OutboxMessage, now time.Time) {
+	if len(d.events) > 0 && !d.events[message.EventType] {
+		message.Status = types.OutboxStatusDelivered
+		return
+	}
+
+	body, err := json.Marshal(message.Event)
+	if err != nil {
+		message.Status = types.OutboxStatusDead
+		message.LastError = fmt.Sprintf("failed to marshal event: %v", err)
+		return
+	}
+
+	delivered := make(map[string]bool, len(message.DeliveredTo))
+	for _, url := range message.DeliveredTo {
+		delivered[url] = true
+	}
+
+	var failures []string
+	for _, url := range d.config.URLs {
+		if delivered[url] {
+			continue
+		}
+		if err := d.post(ctx, url, message, body); err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %v", url, err))
+			continue
+		}
+		message.DeliveredTo = append(message.DeliveredTo, url)
+	}
+
+	if len(failures) == 0 {
+		message.Status = types.OutboxStatusDelivered
+		message.LastError = ""
+		return
+	}
+
+	message.Attempts++
+	message.LastError = strings.Join(failures, "; ")
+	if message.Attempts >= d.config.MaxAttempts {
+		message.Status = types.OutboxStatusDead
+		d.logger.WithFields(logrus.Fields{
+			"message_id": message.ID,
+			"event":      message.EventType,
+			"attempts":   message.Attempts,
+			"error":      message.LastError,
+		}).Error("Webhook delivery failed permanently, moved to dead-letter queue")
+		return
+	}
+
+	message.NextAttemptAt = now.Add(Backoff(d.config.RetryBackoff, message.Attempts))
+	d.logger.WithFields(logrus.Fields{
+		"message_id":   message.ID,
+		"event":        message.EventType,
+		"attempts":     message.Attempts,
+		"next_attempt": message.NextAttemptAt,
+		"error":        message.LastError,
+	}).Warn("Webhook delivery failed, will retry")
+}
+
+// post sends one signed delivery; any response other than 2xx is a failure
+func (d *Dispatcher) post(ctx context.Context, url string, message *types.OutboxMessage, body []byte) error {
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	timestamp := time.Now().Unix()
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set(HeaderEvent, string(message.EventType))
+	request.Header.Set(HeaderDelivery, message.ID)
+	request.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	request.Header.Set(HeaderSignature, Sign([]byte(d.config.Secret), timestamp, body))
+
+	response, err := d.client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(response.Body, 64<<10))
+
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return fmt.Errorf("endpoint returned %s", response.Status)
+	}
+	return nil
+}
+
+// Sign returns the signature header value for a delivery body sent at timestamp
+func Sign(secret []byte, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Backoff returns the delay before the retry that follows the given number of failed attempts
+func Backoff(base time.Duration, attempts int) time.Duration {
+	delay := base
+	for i := 1; i < attempts && delay < maxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > maxBackoff {
+		delay = maxBackoff
+	}
+	return delay
+}
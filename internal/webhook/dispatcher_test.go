@@ -0,0 +1,279 @@
+package webhook
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"onboarding-system/internal/config"
+	"onboarding-system/internal/storage"
+	"onboarding-system/internal/storage/storagetest"
+	"onboarding-system/internal/types"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// endpoint records the deliveries it receives and answers with status
+type endpoint struct {
+	mu       sync.Mutex
+	status   int
+	requests []*http.Request
+	bodies   [][]byte
+}
+
+func (e *endpoint) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.requests = append(e.requests, r)
+	e.bodies = append(e.bodies, body)
+	w.WriteHeader(e.status)
+}
+
+func (e *endpoint) count() int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return len(e.requests)
+}
+
+func newEndpoint(t *testing.T, status int) (*endpoint, string) {
+	e := &endpoint{status: status}
+	server := httptest.NewServer(e)
+	t.Cleanup(server.Close)
+	return e, server.URL
+}
+
+func newTestConfig(urls ...string) config.WebhookConfig {
+	return config.WebhookConfig{
+		URLs:         urls,
+		Secret:       "secret",
+		MaxAttempts:  3,
+		RetryBackoff: time.Minute,
+		PollInterval: time.Second,
+		Timeout:      time.Second,
+	}
+}
+
+// saveEvents saves a session with the given events so they land in the outbox
+func saveEvents(t *testing.T, store storage.Storage, eventTypes ...types.SessionEventType) []*types.SessionEvent {
+	events := make([]*types.SessionEvent, 0, len(eventTypes))
+	for _, eventType := range eventTypes {
+		events = append(events, storagetest.NewSessionEvent(eventType, "pan", time.Now()))
+	}
+	session := storagetest.NewSession("session-1", "user", "graph")
+	require.NoError(t, store.SaveSessionWithEvents(context.Background(), session, events))
+	return events
+}
+
+func newStore() storage.Storage {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	return storage.NewMemoryStorage(logger)
+}
+
+func newTestDispatcher(store storage.Storage, cfg config.WebhookConfig) *Dispatcher {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	return NewDispatcher(store, cfg, logger)
+}
+
+func TestDispatchDeliversSignedEvents(t *testing.T) {
+	ctx := context.Background()
+	store := newStore()
+	received, url := newEndpoint(t, http.StatusNoContent)
+	events := saveEvents(t, store, types.SessionEventStarted)
+
+	processed, err := newTestDispatcher(store, newTestConfig(url)).DispatchDue(ctx, time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, 1, processed)
+
+	require.Equal(t, 1, received.count())
+	request, body := received.requests[0], received.bodies[0]
+	assert.Equal(t, "application/json", request.Header.Get("Content-Type"))
+	assert.Equal(t, string(types.SessionEventStarted), request.Header.Get(HeaderEvent))
+	assert.Equal(t, events[0].ID, request.Header.Get(HeaderDelivery))
+	assert.Contains(t, string(body), events[0].ID)
+
+	timestamp, err := strconv.ParseInt(request.Header.Get(HeaderTimestamp), 10, 64)
+	require.NoError(t, err)
+	assert.Equal(t, Sign([]byte("secret"), timestamp, body), request.Header.Get(HeaderSignature))
+	assert.NotEqual(t, Sign([]byte("other"), timestamp, body), request.Header.Get(HeaderSignature))
+
+	message, err := store.GetOutboxMessage(ctx, events[0].ID)
+	require.NoError(t, err)
+	assert.Equal(t, types.OutboxStatusDelivered, message.Status)
+	assert.Equal(t, []string{url}, message.DeliveredTo)
+
+	// Delivered messages are not sent again
+	processed, err = newTestDispatcher(store, newTestConfig(url)).DispatchDue(ctx, time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, 0, processed)
+	assert.Equal(t, 1, received.count())
+}
+
+func TestDispatchRetriesWithBackoffThenDeadLetters(t *testing.T) {
+	ctx := context.Background()
+	store := newStore()
+	failing, url := newEndpoint(t, http.StatusInternalServerError)
+	events := saveEvents(t, store, types.SessionEventSubmitted)
+	dispatcher := newTestDispatcher(store, newTestConfig(url))
+
+	now := time.Now()
+	_, err := dispatcher.DispatchDue(ctx, now)
+	require.NoError(t, err)
+
+	message, err := store.GetOutboxMessage(ctx, events[0].ID)
+	require.NoError(t, err)
+	assert.Equal(t, types.OutboxStatusPending, message.Status)
+	assert.Equal(t, 1, message.Attempts)
+	assert.Contains(t, message.LastError, "500")
+	assert.WithinDuration(t, now.Add(time.Minute), message.NextAttemptAt, time.Second)
+
+	// Nothing is retried before the backoff has passed
+	processed, err := dispatcher.DispatchDue(ctx, now.Add(30*time.Second))
+	require.NoError(t, err)
+	assert.Equal(t, 0, processed)
+
+	_, err = dispatcher.DispatchDue(ctx, now.Add(time.Minute+time.Second))
+	require.NoError(t, err)
+	message, err = store.GetOutboxMessage(ctx, events[0].ID)
+	require.NoError(t, err)
+	assert.Equal(t, 2, message.Attempts)
+	assert.WithinDuration(t, now.Add(3*time.Minute+time.Second), message.NextAttemptAt, time.Second)
+
+	_, err = dispatcher.DispatchDue(ctx, now.Add(4*time.Minute))
+	require.NoError(t, err)
+	message, err = store.GetOutboxMessage(ctx, events[0].ID)
+	require.NoError(t, err)
+	assert.Equal(t, types.OutboxStatusDead, message.Status)
+	assert.Equal(t, 3, message.Attempts)
+	assert.Equal(t, 3, failing.count())
+
+	// Dead messages wait for an admin to requeue them
+	processed, err = dispatcher.DispatchDue(ctx, now.Add(24*time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, 0, processed)
+}
+
+func TestDispatchSkipsEndpointsThatAlreadyAccepted(t *testing.T) {
+	ctx := context.Background()
+	store := newStore()
+	healthy, healthyURL := newEndpoint(t, http.StatusOK)
+	flaky, flakyURL := newEndpoint(t, http.StatusBadGateway)
+	events := saveEvents(t, store, types.SessionEventCompleted)
+	dispatcher := newTestDispatcher(store, newTestConfig(healthyURL, flakyURL))
+
+	now := time.Now()
+	_, err := dispatcher.DispatchDue(ctx, now)
+	require.NoError(t, err)
+	message, err := store.GetOutboxMessage(ctx, events[0].ID)
+	require.NoError(t, err)
+	assert.Equal(t, types.OutboxStatusPending, message.Status)
+	assert.Equal(t, []string{healthyURL}, message.DeliveredTo)
+
+	flaky.mu.Lock()
+	flaky.status = http.StatusOK
+	flaky.mu.Unlock()
+
+	_, err = dispatcher.DispatchDue(ctx, now.Add(time.Hour))
+	require.NoError(t, err)
+	message, err = store.GetOutboxMessage(ctx, events[0].ID)
+	require.NoError(t, err)
+	assert.Equal(t, types.OutboxStatusDelivered, message.Status)
+	assert.Equal(t, []string{healthyURL, flakyURL}, message.DeliveredTo)
+	assert.Equal(t, 1, healthy.count(), "the healthy endpoint is not sent the event twice")
+	assert.Equal(t, 2, flaky.count())
+}
+
+func TestDispatchFiltersEventTypes(t *testing.T) {
+	ctx := context.Background()
+	store := newStore()
+	received, url := newEndpoint(t, http.StatusOK)
+	events := saveEvents(t, store, types.SessionEventStarted, types.SessionEventCompleted)
+
+	cfg := newTestConfig(url)
+	cfg.Events = []string{string(types.SessionEventCompleted)}
+	processed, err := newTestDispatcher(store, cfg).DispatchDue(ctx, time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, 2, processed)
+
+	require.Equal(t, 1, received.count())
+	assert.Equal(t, string(types.SessionEventCompleted), received.requests[0].Header.Get(HeaderEvent))
+
+	for _, event := range events {
+		message, err := store.GetOutboxMessage(ctx, event.ID)
+		require.NoError(t, err)
+		assert.Equal(t, types.OutboxStatusDelivered, message.Status, "filtered events are settled without a delivery")
+	}
+}
+
+func TestPruneKeepsMessagesForTheirRetention(t *testing.T) {
+	ctx := context.Background()
+	store := newStore()
+	_, url := newEndpoint(t, http.StatusOK)
+	events := saveEvents(t, store, types.SessionEventStarted, types.SessionEventSubmitted, types.SessionEventCompleted)
+
+	// One message is delivered, one dead-lettered and one still pending
+	cfg := newTestConfig(url)
+	cfg.Retention = time.Hour
+	cfg.DeadRetention = 24 * time.Hour
+	for id, status := range map[string]types.OutboxStatus{events[0].ID: types.OutboxStatusDelivered, events[1].ID: types.OutboxStatusDead} {
+		message, err := store.GetOutboxMessage(ctx, id)
+		require.NoError(t, err)
+		message.Status = status
+		require.NoError(t, store.UpdateOutboxMessage(ctx, message))
+	}
+	dispatcher := newTestDispatcher(store, cfg)
+
+	pruned, err := dispatcher.Prune(ctx, time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, 0, pruned)
+
+	pruned, err = dispatcher.Prune(ctx, time.Now().Add(2*time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, 1, pruned, "delivered messages go after the retention")
+	_, err = store.GetOutboxMessage(ctx, events[0].ID)
+	assert.Error(t, err)
+
+	pruned, err = dispatcher.Prune(ctx, time.Now().Add(48*time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, 1, pruned, "dead messages go after the dead-letter retention")
+
+	_, err = store.GetOutboxMessage(ctx, events[2].ID)
+	assert.NoError(t, err, "pending messages are never pruned")
+
+	// A zero retention keeps messages
+	cfg.Retention = 0
+	message, err := store.GetOutboxMessage(ctx, events[2].ID)
+	require.NoError(t, err)
+	message.Status = types.OutboxStatusDelivered
+	require.NoError(t, store.UpdateOutboxMessage(ctx, message))
+	pruned, err = newTestDispatcher(store, cfg).Prune(ctx, time.Now().Add(48*time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, 0, pruned)
+}
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{1, 30 * time.Second},
+		{2, time.Minute},
+		{3, 2 * time.Minute},
+		{7, 32 * time.Minute},
+		{8, time.Hour},
+		{50, time.Hour},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, Backoff(30*time.Second, tt.attempts), "attempts=%d", tt.attempts)
+	}
+}
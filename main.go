@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"errors"
 	"log"
 	"net/http"
 	"os"
@@ -14,6 +15,8 @@ import (
 	"onboarding-system/internal/config"
 	"onboarding-system/internal/onboarding"
 	"onboarding-system/internal/storage"
+	"onboarding-system/internal/validators"
+	"onboarding-system/internal/webhook"
 
 	"github.com/sirupsen/logrus"
 )
@@ -25,6 +28,14 @@ func main() {
 		log.Fatalf("Failed to load configuration: %v", err)
 	}
 
+	// Load named validation rules on top of the built-in rules
+	if err := validators.Default.LoadFile(cfg.Onboarding.ValidationRules); err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			log.Fatalf("Failed to load validation rules: %v", err)
+		}
+		log.Printf("Validation rules file %s not found, using built-in rules", cfg.Onboarding.ValidationRules)
+	}
+
 	// Initialize storage
 	store, err := storage.New(cfg)
 	if err != nil {
@@ -38,6 +49,19 @@ func main() {
 	// Auto-seed demo data if no graphs exist
 	seedDemoDataIfNeeded(onboardingService)
 
+	// Load declarative graph definitions, reloading them on change if enabled
+	graphsCtx, stopGraphWatcher := context.WithCancel(context.Background())
+	defer stopGraphWatcher()
+	loadGraphDefinitions(graphsCtx, onboardingService, cfg)
+
+	// Pause and expire idle sessions until the server shuts down
+	lifecycleCtx, stopLifecycle := context.WithCancel(context.Background())
+	lifecycleDone := startSessionLifecycle(lifecycleCtx, onboardingService, cfg)
+
+	// Deliver recorded session events to webhook endpoints until the server shuts down
+	webhookCtx, stopWebhooks := context.WithCancel(context.Background())
+	webhooksDone := startWebhookDispatcher(webhookCtx, store, cfg)
+
 	// Initialize API handlers
 	handlers := api.NewHandlers(onboardingService)
 
@@ -45,9 +69,14 @@ func main() {
 	dynamicService := onboarding.NewDynamicService(store, cfg, logrus.New())
 	dynamicHandlers := api.NewDynamicHandlers(dynamicService, logrus.New())
 
+	// Initialize entry node and activation API handlers
+	advancedService := onboarding.NewAdvancedService(store, cfg, logrus.New())
+	advancedHandlers := api.NewAdvancedHandlers(advancedService, logrus.New())
+
 	// Setup HTTP server with combined router
 	router := handlers.Router()
 	dynamicHandlers.RegisterDynamicRoutes(router)
+	advancedHandlers.RegisterAdvancedRoutes(router)
 
 	server := &http.Server{
 		Addr:    cfg.Server.Address,
@@ -76,9 +105,82 @@ func main() {
 		log.Fatalf("Server forced to shutdown: %v", err)
 	}
 
+	// Let an in-progress sweep finish before storage is closed
+	stopLifecycle()
+	<-lifecycleDone
+	stopWebhooks()
+	<-webhooksDone
+
 	log.Println("Server exited")
 }
 
+// loadGraphDefinitions publishes the graph definition files and starts the reload watcher
+func loadGraphDefinitions(ctx context.Context, service *onboarding.Service, cfg *config.Config) {
+	dir := cfg.Onboarding.GraphsDir
+	if _, err := os.Stat(dir); err != nil {
+		log.Printf("Graph definition directory %s not found, skipping", dir)
+		return
+	}
+
+	if err := service.LoadGraphDefinitions(ctx, dir); err != nil {
+		log.Printf("Failed to load graph definitions: %v", err)
+	} else {
+		log.Printf("Loaded graph definitions from %s", dir)
+	}
+
+	if cfg.Onboarding.GraphsReload > 0 {
+		log.Printf("Watching %s for graph definition changes every %s", dir, cfg.Onboarding.GraphsReload)
+		go service.WatchGraphDefinitions(ctx, dir, cfg.Onboarding.GraphsReload)
+	}
+}
+
+// startSessionLifecycle runs the idle session sweeper; the returned channel closes when it stops
+func startSessionLifecycle(ctx context.Context, service *onboarding.Service, cfg *config.Config) <-chan struct{} {
+	done := make(chan struct{})
+	interval := cfg.Onboarding.SweepInterval
+	if interval <= 0 {
+		log.Printf("Session lifecycle sweeper disabled")
+		close(done)
+		return done
+	}
+
+	if cfg.Onboarding.SessionTimeout > 0 {
+		log.Printf("Expiring sessions idle for %s, checking every %s", cfg.Onboarding.SessionTimeout, interval)
+	}
+	if cfg.Onboarding.PauseAfter > 0 {
+		log.Printf("Pausing sessions idle for %s", cfg.Onboarding.PauseAfter)
+	}
+
+	go func() {
+		defer close(done)
+		service.RunSessionLifecycle(ctx, interval)
+	}()
+	return done
+}
+
+// startWebhookDispatcher runs the outbox dispatcher; the returned channel closes when it stops
+func startWebhookDispatcher(ctx context.Context, store storage.Storage, cfg *config.Config) <-chan struct{} {
+	done := make(chan struct{})
+	webhooks := cfg.Webhooks
+	if !webhooks.Enabled() {
+		log.Printf("Webhook delivery disabled; session events are not added to the outbox")
+		close(done)
+		return done
+	}
+
+	if webhooks.Secret == "" {
+		log.Printf("WEBHOOK_SECRET is not set, receivers cannot verify webhook signatures")
+	}
+	log.Printf("Delivering webhooks to %d endpoint(s), checking every %s", len(webhooks.URLs), webhooks.PollInterval)
+
+	dispatcher := webhook.NewDispatcher(store, webhooks, logrus.New())
+	go func() {
+		defer close(done)
+		dispatcher.Run(ctx)
+	}()
+	return done
+}
+
 // seedDemoDataIfNeeded seeds demo data if no graphs exist
 func seedDemoDataIfNeeded(service *onboarding.Service) {
 	ctx := context.Background()